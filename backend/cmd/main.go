@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Notification quiet hours and digests use the users' timezones
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/cors"
@@ -15,6 +16,7 @@ import (
 	"github.com/personal-finance-management/backend/internal/config"
 	"github.com/personal-finance-management/backend/internal/handlers"
 	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
 	"github.com/personal-finance-management/backend/internal/services"
 )
 
@@ -81,9 +83,49 @@ func main() {
 	// Initialize handlers
 	var reportsHandler *handlers.ReportsHandler
 	var goalsHandler *handlers.GoalsHandler
+	var transactionsHandler *handlers.TransactionsHandler
+	var transfersHandler *handlers.TransfersHandler
+	var reconciliationsHandler *handlers.ReconciliationsHandler
+	var rulesHandler *handlers.RulesHandler
+	var mlHandler *handlers.MLHandler
+	var categorizationHandler *handlers.CategorizationHandler
+	var merchantsHandler *handlers.MerchantsHandler
+	var customFieldsHandler *handlers.CustomFieldsHandler
+	var attachmentsHandler *handlers.AttachmentsHandler
+	var receiptsHandler *handlers.ReceiptsHandler
+	var scheduledTransactionsHandler *handlers.ScheduledTransactionsHandler
+	var insightsHandler *handlers.InsightsHandler
+	var notificationPreferencesHandler *handlers.NotificationPreferencesHandler
+	var webhooksHandler *handlers.WebhooksHandler
+	var reportCache *services.ReportCache
 	if dbService != nil {
-		reportsHandler = handlers.NewReportsHandler(dbService)
+		var err error
+		if reportCache, err = services.NewReportCacheFromConfig(cfg); err != nil {
+			log.Printf("Warning: Failed to initialize report cache: %v", err)
+		}
+		reportsHandler = handlers.NewReportsHandler(dbService, reportCache)
 		goalsHandler = handlers.NewGoalsHandler(dbService)
+		transactionsHandler = handlers.NewTransactionsHandler(dbService)
+		transfersHandler = handlers.NewTransfersHandler(dbService)
+		reconciliationsHandler = handlers.NewReconciliationsHandler(dbService)
+		rulesHandler = handlers.NewRulesHandler(dbService)
+		mlHandler = handlers.NewMLHandler(dbService)
+		categorizationHandler = handlers.NewCategorizationHandler(dbService)
+		merchantsHandler = handlers.NewMerchantsHandler(dbService)
+		customFieldsHandler = handlers.NewCustomFieldsHandler(dbService)
+		scheduledTransactionsHandler = handlers.NewScheduledTransactionsHandler(dbService)
+		insightsHandler = handlers.NewInsightsHandler(dbService)
+		notificationPreferencesHandler = handlers.NewNotificationPreferencesHandler(dbService)
+		webhooksHandler = handlers.NewWebhooksHandler(dbService)
+
+		var attachmentStorage services.ObjectStorage
+		if localStorage, err := services.NewLocalStorage(cfg.AttachmentStorageDir); err != nil {
+			log.Printf("Warning: Failed to initialize attachment storage: %v", err)
+		} else {
+			attachmentStorage = localStorage
+			attachmentsHandler = handlers.NewAttachmentsHandler(dbService, attachmentStorage, cfg.AttachmentMaxSize)
+		}
+		receiptsHandler = handlers.NewReceiptsHandler(dbService, attachmentStorage)
 	}
 	
 	// Initialize auth and notification handlers (no database required)
@@ -121,6 +163,12 @@ func main() {
 			notifications := protected.Group("/notifications")
 			{
 				notifications.GET("/", notificationHandler.GetNotifications)
+				if notificationPreferencesHandler != nil {
+					notifications.GET("/preferences", notificationPreferencesHandler.GetNotificationPreferences)
+					notifications.PUT("/preferences", notificationPreferencesHandler.UpdateNotificationPreferences)
+					notifications.GET("/deliveries", notificationPreferencesHandler.GetNotificationDeliveries)
+					notifications.GET("/digest/preview", notificationPreferencesHandler.PreviewDigest)
+				}
 				notifications.GET("/:id", notificationHandler.GetNotification)
 				notifications.PATCH("/:id", notificationHandler.UpdateNotification)
 				notifications.DELETE("/:id", notificationHandler.DeleteNotification)
@@ -132,10 +180,163 @@ func main() {
 				reports := protected.Group("/reports")
 				{
 					reports.GET("/monthly-summary", reportsHandler.GetMonthlySummary)
+					reports.GET("/tags", reportsHandler.GetTagSummary)
 					reports.GET("/spending-trends", reportsHandler.GetSpendingTrends)
 					reports.GET("/cash-flow", reportsHandler.GetCashFlow)
+					reports.GET("/cash-flow-forecast", reportsHandler.GetCashFlowForecast)
+					reports.GET("/comparison", reportsHandler.GetPeriodComparison)
+					reports.GET("/merchant-spending", reportsHandler.GetMerchantSpending)
+					reports.GET("/net-worth", reportsHandler.GetNetWorth)
+					reports.POST("/net-worth/snapshot", reportsHandler.RecordNetWorthSnapshot)
+					reports.GET("/income-statement", reportsHandler.GetIncomeStatement)
+					reports.GET("/balance-sheet", reportsHandler.GetBalanceSheet)
+					reports.POST("/pivot", reportsHandler.RunPivotQuery)
 					reports.GET("/summary", reportsHandler.GetReportSummary)
 					reports.GET("/budget-performance", reportsHandler.GetBudgetPerformance)
+					reports.DELETE("/cache", reportsHandler.ClearReportCache)
+					reports.GET("/cache/stats", middleware.RequireServiceRole(), reportsHandler.ReportCacheStats)
+				}
+			}
+
+			// Transactions endpoints
+			if transactionsHandler != nil {
+				transactions := protected.Group("/transactions")
+				{
+					transactions.GET("/", transactionsHandler.GetTransactions)
+					transactions.POST("/", transactionsHandler.CreateTransaction)
+					transactions.GET("/search", transactionsHandler.SearchTransactions)
+					transactions.GET("/duplicates", transactionsHandler.GetDuplicates)
+					transactions.POST("/merge", transactionsHandler.MergeTransactions)
+					transactions.GET("/:id", transactionsHandler.GetTransaction)
+					transactions.PUT("/:id", transactionsHandler.UpdateTransaction)
+					transactions.DELETE("/:id", transactionsHandler.DeleteTransaction)
+					transactions.POST("/:id/unlock", transactionsHandler.UnlockTransaction)
+				}
+			}
+
+			// Transfers endpoints
+			if transfersHandler != nil {
+				transfers := protected.Group("/transfers")
+				{
+					transfers.POST("/", transfersHandler.CreateTransfer)
+					transfers.GET("/:id", transfersHandler.GetTransfer)
+					transfers.PUT("/:id", transfersHandler.UpdateTransfer)
+					transfers.DELETE("/:id", transfersHandler.DeleteTransfer)
+				}
+			}
+
+			// Reconciliation endpoints
+			if reconciliationsHandler != nil {
+				reconciliations := protected.Group("/reconciliations")
+				{
+					reconciliations.GET("/", reconciliationsHandler.GetReconciliations)
+					reconciliations.POST("/", reconciliationsHandler.StartReconciliation)
+					reconciliations.GET("/:id", reconciliationsHandler.GetReconciliation)
+					reconciliations.DELETE("/:id", reconciliationsHandler.CancelReconciliation)
+					reconciliations.POST("/:id/clear", reconciliationsHandler.ClearTransactions)
+					reconciliations.POST("/:id/complete", reconciliationsHandler.CompleteReconciliation)
+				}
+			}
+
+			// Categorization rules endpoints
+			if rulesHandler != nil {
+				rules := protected.Group("/rules")
+				{
+					rules.GET("/", rulesHandler.GetRules)
+					rules.POST("/", rulesHandler.CreateRule)
+					rules.POST("/apply", rulesHandler.ApplyRules)
+					rules.GET("/:id", rulesHandler.GetRule)
+					rules.PUT("/:id", rulesHandler.UpdateRule)
+					rules.DELETE("/:id", rulesHandler.DeleteRule)
+				}
+			}
+
+			// ML categorization endpoints
+			if mlHandler != nil {
+				ml := protected.Group("/ml")
+				{
+					ml.GET("/model", mlHandler.GetModel)
+					ml.POST("/train", mlHandler.TrainModel)
+					ml.POST("/predict", mlHandler.Predict)
+					ml.POST("/feedback", mlHandler.RecordFeedback)
+				}
+			}
+
+			// Categorization analytics endpoints
+			if categorizationHandler != nil {
+				categorization := protected.Group("/categorization")
+				{
+					categorization.GET("/accuracy", categorizationHandler.GetAccuracy)
+				}
+			}
+
+			// Merchant endpoints
+			if merchantsHandler != nil {
+				merchants := protected.Group("/merchants")
+				{
+					merchants.GET("/", merchantsHandler.GetMerchants)
+					merchants.PUT("/:id", merchantsHandler.UpdateMerchant)
+					merchants.DELETE("/:id", merchantsHandler.DeleteMerchant)
+					merchants.GET("/aliases", merchantsHandler.GetMerchantAliases)
+					merchants.POST("/aliases", merchantsHandler.CreateMerchantAlias)
+					merchants.DELETE("/aliases/:id", merchantsHandler.DeleteMerchantAlias)
+					merchants.POST("/normalize", merchantsHandler.NormalizeMerchants)
+					merchants.POST("/backfill", merchantsHandler.BackfillMerchants)
+				}
+			}
+
+			// Custom field endpoints
+			if customFieldsHandler != nil {
+				customFields := protected.Group("/custom-fields")
+				{
+					customFields.GET("/", customFieldsHandler.GetCustomFields)
+					customFields.POST("/", customFieldsHandler.CreateCustomField)
+					customFields.PUT("/:id", customFieldsHandler.UpdateCustomField)
+					customFields.DELETE("/:id", customFieldsHandler.DeleteCustomField)
+				}
+			}
+
+			// Attachments endpoints
+			if attachmentsHandler != nil {
+				attachments := protected.Group("/attachments")
+				{
+					attachments.GET("/", attachmentsHandler.GetAttachments)
+					attachments.POST("/", attachmentsHandler.UploadAttachment)
+					attachments.GET("/:id", attachmentsHandler.GetAttachment)
+					attachments.PUT("/:id", attachmentsHandler.UpdateAttachment)
+					attachments.DELETE("/:id", attachmentsHandler.DeleteAttachment)
+					attachments.GET("/:id/download", attachmentsHandler.DownloadAttachment)
+					attachments.POST("/:id/links", attachmentsHandler.CreateAttachmentLink)
+					attachments.DELETE("/:id/links/:link_id", attachmentsHandler.DeleteAttachmentLink)
+				}
+			}
+
+			// Receipts endpoints
+			if receiptsHandler != nil {
+				receipts := protected.Group("/receipts")
+				{
+					receipts.POST("/parse", receiptsHandler.ParseReceipt)
+				}
+			}
+
+			// Insights endpoints
+			if insightsHandler != nil {
+				insights := protected.Group("/insights")
+				{
+					insights.GET("/anomalies", insightsHandler.GetAnomalies)
+					insights.POST("/anomalies/notify", insightsHandler.NotifyAnomalies)
+				}
+			}
+
+			// Scheduled transactions endpoints
+			if scheduledTransactionsHandler != nil {
+				scheduled := protected.Group("/scheduled-transactions")
+				{
+					scheduled.GET("/", scheduledTransactionsHandler.GetScheduledTransactions)
+					scheduled.POST("/", scheduledTransactionsHandler.CreateScheduledTransaction)
+					scheduled.GET("/:id", scheduledTransactionsHandler.GetScheduledTransaction)
+					scheduled.PUT("/:id", scheduledTransactionsHandler.UpdateScheduledTransaction)
+					scheduled.DELETE("/:id", scheduledTransactionsHandler.DeleteScheduledTransaction)
 				}
 			}
 
@@ -152,7 +353,44 @@ func main() {
 					goals.PATCH("/:id/progress", goalsHandler.UpdateGoalProgress)
 				}
 			}
+
+			// Webhook subscriptions endpoints
+			if webhooksHandler != nil {
+				webhooks := protected.Group("/webhooks")
+				{
+					webhooks.GET("/", webhooksHandler.GetWebhookSubscriptions)
+					webhooks.POST("/", webhooksHandler.CreateWebhookSubscription)
+					webhooks.GET("/:id", webhooksHandler.GetWebhookSubscription)
+					webhooks.PUT("/:id", webhooksHandler.UpdateWebhookSubscription)
+					webhooks.DELETE("/:id", webhooksHandler.DeleteWebhookSubscription)
+					webhooks.GET("/:id/deliveries", webhooksHandler.GetWebhookDeliveries)
+					webhooks.GET("/:id/deliveries/:delivery_id", webhooksHandler.GetWebhookDelivery)
+					webhooks.POST("/:id/deliveries/:delivery_id/replay", webhooksHandler.ReplayWebhookDelivery)
+				}
+			}
+		}
+	}
+
+	// Start background jobs; they stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if dbService != nil {
+		go services.NewNetWorthService(dbService.Repositories).RunDailySnapshots(jobsCtx)
+		go services.NewAnomalyService(dbService.Repositories).RunDailyAlerts(jobsCtx)
+		go reportCache.RunInvalidationListener(jobsCtx, dbService.Repositories.ListenForReportChanges)
+		go services.NewBudgetAlertService(dbService.Repositories).RunAlerts(jobsCtx, dbService.Repositories.ListenForReportChanges)
+
+		senders := map[models.NotificationChannel]services.NotificationSender{
+			models.NotificationChannelWebhook: services.NewWebhookNotificationSender(nil),
+		}
+		if mailer, err := services.NewMailSenderFromConfig(cfg); err != nil {
+			log.Printf("Warning: Failed to initialize mail sender: %v", err)
+		} else if mailer != nil {
+			senders[models.NotificationChannelEmail] = services.NewEmailNotificationSender(mailer)
+			go services.NewDigestService(dbService.Repositories, mailer).RunDigests(jobsCtx)
 		}
+		go services.NewNotificationDispatcher(dbService.Repositories, senders).Run(jobsCtx)
+		go services.NewWebhookDispatcher(dbService.Repositories, nil).Run(jobsCtx)
 	}
 
 	// Create HTTP server
@@ -174,6 +412,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// Give the server 5 seconds to finish current requests
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
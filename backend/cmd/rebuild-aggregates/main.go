@@ -0,0 +1,64 @@
+// Command rebuild-aggregates recomputes the monthly category totals that the
+// reports read from, for one user or for every user with transactions. The
+// totals are kept up to date on every transaction write; rebuilding is only
+// needed to repair them, for example after restoring data with triggers
+// disabled.
+//
+// Usage:
+//
+//	rebuild-aggregates [-user <user id>]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/personal-finance-management/backend/internal/config"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+func main() {
+	userID := flag.String("user", "", "rebuild only this user's totals")
+	flag.Parse()
+
+	cfg := config.Load()
+	dbService, err := services.NewDatabaseService(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize database service: %v", err)
+	}
+	defer dbService.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	userIDs := []string{*userID}
+	if *userID == "" {
+		userIDs, err = dbService.Repositories.GetUserIDsWithTransactions(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list users: %v", err)
+		}
+	}
+
+	failed := 0
+	for i, id := range userIDs {
+		months, err := dbService.Repositories.RebuildMonthlyCategoryTotals(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Fatalf("Interrupted after %d of %d users", i, len(userIDs))
+			}
+			log.Printf("Warning: Failed to rebuild monthly totals for user %s: %v", id, err)
+			failed++
+			continue
+		}
+		log.Printf("Rebuilt %d months for user %s (%d/%d)", months, id, i+1, len(userIDs))
+	}
+
+	if failed > 0 {
+		log.Printf("Failed to rebuild %d of %d users", failed, len(userIDs))
+		os.Exit(1)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -28,6 +29,23 @@ type Config struct {
 
 	// CORS Configuration
 	CORSAllowedOrigins []string
+
+	// Attachment Storage Configuration
+	AttachmentStorageDir string
+	AttachmentMaxSize    int64 // Bytes
+
+	// Report Cache Configuration
+	ReportCacheBackend  string // "memory", "redis" or "none"
+	ReportCacheSize     int64  // Entries kept by the memory backend
+	ReportCacheTTL      time.Duration
+	ReportCacheRedisURL string // redis://[user:password@]host:port[/db], or rediss:// for TLS
+
+	// Mail Configuration
+	SMTPHost     string // Email delivery is off when empty
+	SMTPPort     string // 465 uses implicit TLS, others STARTTLS when offered
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
 }
 
 func Load() *Config {
@@ -50,6 +68,20 @@ func Load() *Config {
 		JWTExpires: getEnvDuration("JWT_EXPIRES_IN", 24*time.Hour),
 
 		CORSAllowedOrigins: getEnvSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:4321"}),
+
+		AttachmentStorageDir: getEnv("ATTACHMENT_STORAGE_DIR", "./data/attachments"),
+		AttachmentMaxSize:    getEnvInt64("ATTACHMENT_MAX_SIZE", 10<<20),
+
+		ReportCacheBackend:  getEnv("REPORT_CACHE_BACKEND", "memory"),
+		ReportCacheSize:     getEnvInt64("REPORT_CACHE_SIZE", 1000),
+		ReportCacheTTL:      getEnvDuration("REPORT_CACHE_TTL", 5*time.Minute),
+		ReportCacheRedisURL: getEnv("REPORT_CACHE_REDIS_URL", ""),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", ""),
 	}
 }
 
@@ -69,6 +101,15 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func getEnvSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		// Simple comma-separated parsing
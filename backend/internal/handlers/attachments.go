@@ -0,0 +1,482 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// multipartOverhead is the allowance for multipart headers and boundaries on
+// top of the file size limit.
+const multipartOverhead = 1 << 20
+
+// AttachmentsHandler handles receipt and document attachment HTTP requests
+type AttachmentsHandler struct {
+	dbService *services.DatabaseService
+	storage   services.ObjectStorage
+	maxSize   int64
+}
+
+// NewAttachmentsHandler creates a new attachments handler storing file
+// contents in storage and rejecting files larger than maxSize bytes
+func NewAttachmentsHandler(dbService *services.DatabaseService, storage services.ObjectStorage, maxSize int64) *AttachmentsHandler {
+	if maxSize <= 0 {
+		maxSize = services.DefaultMaxAttachmentSize
+	}
+	return &AttachmentsHandler{
+		dbService: dbService,
+		storage:   storage,
+		maxSize:   maxSize,
+	}
+}
+
+// UpdateAttachmentRequest represents the request body for renaming an attachment
+type UpdateAttachmentRequest struct {
+	FileName string `json:"file_name" binding:"required"`
+}
+
+// CreateAttachmentLinkRequest represents the request body for linking an
+// attachment. Exactly one of the IDs must be set.
+type CreateAttachmentLinkRequest struct {
+	TransactionID *string `json:"transaction_id"`
+	TaxDocumentID *string `json:"tax_document_id"`
+}
+
+// GetAttachments handles GET /api/attachments. The transaction_id and
+// tax_document_id parameters restrict the list to files linked to them.
+func (h *AttachmentsHandler) GetAttachments(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var transactionID, taxDocumentID *string
+	if id := c.Query("transaction_id"); id != "" {
+		transactionID = &id
+	}
+	if id := c.Query("tax_document_id"); id != "" {
+		taxDocumentID = &id
+	}
+
+	attachments, err := h.dbService.Repositories.GetAttachmentsByUserID(c.Request.Context(), userID, transactionID, taxDocumentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get attachments",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attachments": attachments,
+	})
+}
+
+// GetAttachment handles GET /api/attachments/:id
+func (h *AttachmentsHandler) GetAttachment(c *gin.Context) {
+	attachment, ok := h.loadOwnedAttachment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// UploadAttachment handles POST /api/attachments as multipart/form-data with
+// the contents in the file field. The optional transaction_id or
+// tax_document_id fields link the upload right away. Uploading a file the
+// user already has returns the existing attachment with status 200.
+func (h *AttachmentsHandler) UploadAttachment(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.fileTooLarge(c)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "A file must be uploaded in the file field",
+			"details": err.Error(),
+		})
+		return
+	}
+	if fileHeader.Size > h.maxSize {
+		h.fileTooLarge(c)
+		return
+	}
+
+	link, ok := h.linkFromForm(c, userID)
+	if !ok {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to read uploaded file",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to read uploaded file",
+			"details": err.Error(),
+		})
+		return
+	}
+	if int64(len(data)) > h.maxSize {
+		h.fileTooLarge(c)
+		return
+	}
+
+	contentType, err := services.DetectAttachmentType(data)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error":   "Unsupported file type",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	hash := services.AttachmentHash(data)
+	attachment := &models.Attachment{
+		UserID:      userID,
+		FileName:    services.SanitizeFileName(fileHeader.Filename),
+		ContentType: contentType,
+		FileSize:    int64(len(data)),
+		ContentHash: hash,
+		StorageKey:  services.AttachmentStorageKey(userID, hash),
+	}
+
+	// Keys are content-addressed, so rewriting the object of a duplicate
+	// upload stores identical bytes
+	if err := h.storage.Put(ctx, attachment.StorageKey, bytes.NewReader(data)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to store file",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	created, err := h.dbService.Repositories.FindOrCreateAttachment(ctx, attachment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to save attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if link != nil && !hasAttachmentLink(attachment, link) {
+		link.AttachmentID = attachment.ID
+		if err := h.dbService.Repositories.CreateAttachmentLink(ctx, link); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Attachment saved but could not be linked",
+				"details": err.Error(),
+			})
+			return
+		}
+		attachment.Links = append(attachment.Links, *link)
+	}
+
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{
+		"attachment": attachment,
+		"duplicate":  !created,
+	})
+}
+
+// UpdateAttachment handles PUT /api/attachments/:id
+func (h *AttachmentsHandler) UpdateAttachment(c *gin.Context) {
+	var req UpdateAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	attachment, ok := h.loadOwnedAttachment(c)
+	if !ok {
+		return
+	}
+
+	attachment.FileName = services.SanitizeFileName(req.FileName)
+	if err := h.dbService.Repositories.UpdateAttachment(c.Request.Context(), attachment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// DownloadAttachment handles GET /api/attachments/:id/download. Files are
+// served as downloads unless inline=true is given.
+func (h *AttachmentsHandler) DownloadAttachment(c *gin.Context) {
+	attachment, ok := h.loadOwnedAttachment(c)
+	if !ok {
+		return
+	}
+
+	etag := `"` + attachment.ContentHash + `"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	reader, err := h.storage.Get(c.Request.Context(), attachment.StorageKey)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrObjectNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to read attachment file",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	disposition := "attachment"
+	if inline, _ := strconv.ParseBool(c.Query("inline")); inline {
+		disposition = "inline"
+	}
+
+	c.DataFromReader(http.StatusOK, attachment.FileSize, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=0, must-revalidate",
+		"ETag":                   etag,
+	})
+}
+
+// DeleteAttachment handles DELETE /api/attachments/:id. The attachment is
+// unlinked everywhere and its stored file removed.
+func (h *AttachmentsHandler) DeleteAttachment(c *gin.Context) {
+	attachment, ok := h.loadOwnedAttachment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.dbService.Repositories.DeleteAttachment(c.Request.Context(), attachment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.storage.Delete(c.Request.Context(), attachment.StorageKey); err != nil {
+		log.Printf("Warning: failed to delete stored file for attachment %s: %v", attachment.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment deleted successfully",
+	})
+}
+
+// CreateAttachmentLink handles POST /api/attachments/:id/links
+func (h *AttachmentsHandler) CreateAttachmentLink(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateAttachmentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	attachment, ok := h.loadOwnedAttachment(c)
+	if !ok {
+		return
+	}
+
+	link := &models.AttachmentLink{
+		UserID:        userID,
+		AttachmentID:  attachment.ID,
+		TransactionID: req.TransactionID,
+		TaxDocumentID: req.TaxDocumentID,
+	}
+	if !h.checkLinkTarget(c, link) {
+		return
+	}
+	if hasAttachmentLink(attachment, link) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Attachment is already linked there",
+		})
+		return
+	}
+
+	if err := h.dbService.Repositories.CreateAttachmentLink(c.Request.Context(), link); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to link attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// DeleteAttachmentLink handles DELETE /api/attachments/:id/links/:link_id
+func (h *AttachmentsHandler) DeleteAttachmentLink(c *gin.Context) {
+	attachment, ok := h.loadOwnedAttachment(c)
+	if !ok {
+		return
+	}
+
+	var link *models.AttachmentLink
+	for i := range attachment.Links {
+		if attachment.Links[i].ID == c.Param("link_id") {
+			link = &attachment.Links[i]
+			break
+		}
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Attachment link not found",
+		})
+		return
+	}
+
+	if err := h.dbService.Repositories.DeleteAttachmentLink(c.Request.Context(), link); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to unlink attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment unlinked successfully",
+	})
+}
+
+// linkFromForm reads the optional link target of an upload, writing the
+// error response if it is invalid. It returns nil when no target was given.
+func (h *AttachmentsHandler) linkFromForm(c *gin.Context, userID string) (*models.AttachmentLink, bool) {
+	link := &models.AttachmentLink{UserID: userID}
+	if id := c.PostForm("transaction_id"); id != "" {
+		link.TransactionID = &id
+	}
+	if id := c.PostForm("tax_document_id"); id != "" {
+		link.TaxDocumentID = &id
+	}
+	if link.TransactionID == nil && link.TaxDocumentID == nil {
+		return nil, true
+	}
+
+	if !h.checkLinkTarget(c, link) {
+		return nil, false
+	}
+	return link, true
+}
+
+// checkLinkTarget verifies that the link names exactly one transaction or tax
+// document and that it belongs to the user, writing the error response if not.
+func (h *AttachmentsHandler) checkLinkTarget(c *gin.Context, link *models.AttachmentLink) bool {
+	if (link.TransactionID == nil) == (link.TaxDocumentID == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Provide exactly one of transaction_id or tax_document_id",
+		})
+		return false
+	}
+
+	var ownerID string
+	if link.TransactionID != nil {
+		transaction, err := h.dbService.Repositories.GetTransactionByID(c.Request.Context(), *link.TransactionID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Transaction not found",
+			})
+			return false
+		}
+		ownerID = transaction.UserID
+	} else {
+		userID, err := h.dbService.Repositories.GetTaxDocumentUserID(c.Request.Context(), *link.TaxDocumentID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Tax document not found",
+			})
+			return false
+		}
+		ownerID = userID
+	}
+
+	if ownerID != link.UserID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return false
+	}
+
+	return true
+}
+
+// fileTooLarge writes the response for an upload over the size limit.
+func (h *AttachmentsHandler) fileTooLarge(c *gin.Context) {
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error": "File is too large. Maximum size is " + strconv.FormatInt(h.maxSize, 10) + " bytes",
+	})
+}
+
+// loadOwnedAttachment fetches the attachment named by the :id parameter and
+// verifies it belongs to the authenticated user, writing the error response if not.
+func (h *AttachmentsHandler) loadOwnedAttachment(c *gin.Context) (*models.Attachment, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	attachment, err := h.dbService.Repositories.GetAttachmentByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Attachment not found",
+		})
+		return nil, false
+	}
+
+	if attachment.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return attachment, true
+}
+
+// hasAttachmentLink reports whether the attachment is already linked to the
+// link's target.
+func hasAttachmentLink(attachment *models.Attachment, link *models.AttachmentLink) bool {
+	for _, existing := range attachment.Links {
+		if link.TransactionID != nil && existing.TransactionID != nil && *existing.TransactionID == *link.TransactionID {
+			return true
+		}
+		if link.TaxDocumentID != nil && existing.TaxDocumentID != nil && *existing.TaxDocumentID == *link.TaxDocumentID {
+			return true
+		}
+	}
+	return false
+}
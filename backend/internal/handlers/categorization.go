@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// CategorizationHandler handles auto-categorization analytics HTTP requests
+type CategorizationHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewCategorizationHandler creates a new categorization handler
+func NewCategorizationHandler(dbService *services.DatabaseService) *CategorizationHandler {
+	return &CategorizationHandler{
+		dbService: dbService,
+	}
+}
+
+// GetAccuracy handles GET /api/categorization/accuracy
+func (h *CategorizationHandler) GetAccuracy(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	// Parse query parameters
+	months, err := strconv.Atoi(c.DefaultQuery("months", "12"))
+	if err != nil || months < 1 || months > 60 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid months parameter. Must be between 1 and 60",
+		})
+		return
+	}
+
+	source := c.DefaultQuery("source", "all")
+	if source != "all" && source != "ai" && source != "ml" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid source parameter. Must be all, ai or ml",
+		})
+		return
+	}
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(months - 1), 0)
+
+	outcomes, err := h.dbService.Repositories.GetCategorizationOutcomes(c.Request.Context(), userID, startDate, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get categorization accuracy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if source != "all" {
+		filtered := outcomes[:0]
+		for _, o := range outcomes {
+			if o.Source == source {
+				filtered = append(filtered, o)
+			}
+		}
+		outcomes = filtered
+	}
+
+	report := &models.CategorizationAccuracy{
+		UserID:      userID,
+		Source:      source,
+		StartDate:   startDate,
+		EndDate:     now,
+		GeneratedAt: now,
+	}
+	services.BuildCategorizationAccuracy(report, outcomes)
+
+	c.JSON(http.StatusOK, report)
+}
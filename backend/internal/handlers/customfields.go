@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// CustomFieldsHandler handles custom field definition HTTP requests
+type CustomFieldsHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewCustomFieldsHandler creates a new custom fields handler
+func NewCustomFieldsHandler(dbService *services.DatabaseService) *CustomFieldsHandler {
+	return &CustomFieldsHandler{
+		dbService: dbService,
+	}
+}
+
+// CreateCustomFieldRequest represents the request body for defining a custom field
+type CreateCustomFieldRequest struct {
+	Name      string                 `json:"name" binding:"required"`
+	FieldType models.CustomFieldType `json:"field_type" binding:"required,oneof=text number date enum"`
+	Options   []string               `json:"options"` // Required for enum fields
+}
+
+// UpdateCustomFieldRequest represents the request body for updating a custom
+// field. The field type cannot be changed once values have been recorded.
+type UpdateCustomFieldRequest struct {
+	Name    *string   `json:"name"`
+	Options *[]string `json:"options"`
+}
+
+// GetCustomFields handles GET /api/custom-fields
+func (h *CustomFieldsHandler) GetCustomFields(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	defs, err := h.dbService.Repositories.GetCustomFieldDefinitions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get custom fields",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"custom_fields": defs,
+	})
+}
+
+// CreateCustomField handles POST /api/custom-fields
+func (h *CustomFieldsHandler) CreateCustomField(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	def := &models.CustomFieldDefinition{
+		UserID:    userID,
+		Name:      req.Name,
+		FieldType: req.FieldType,
+		Options:   req.Options,
+	}
+	if err := services.ValidateCustomFieldDefinition(def); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid custom field",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.dbService.Repositories.CreateCustomFieldDefinition(c.Request.Context(), def); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Failed to create custom field; the name may already be in use",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, def)
+}
+
+// UpdateCustomField handles PUT /api/custom-fields/:id. Removing an enum
+// option leaves values already recorded with it in place.
+func (h *CustomFieldsHandler) UpdateCustomField(c *gin.Context) {
+	var req UpdateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	def, ok := h.loadOwnedCustomField(c)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		def.Name = *req.Name
+	}
+	if req.Options != nil {
+		def.Options = *req.Options
+	}
+	if err := services.ValidateCustomFieldDefinition(def); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid custom field",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.dbService.Repositories.UpdateCustomFieldDefinition(c.Request.Context(), def); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Failed to update custom field; the name may already be in use",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, def)
+}
+
+// DeleteCustomField handles DELETE /api/custom-fields/:id. Values recorded
+// under the field are removed from every transaction.
+func (h *CustomFieldsHandler) DeleteCustomField(c *gin.Context) {
+	def, ok := h.loadOwnedCustomField(c)
+	if !ok {
+		return
+	}
+
+	if err := h.dbService.Repositories.DeleteCustomFieldDefinition(c.Request.Context(), def); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete custom field",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Custom field deleted successfully",
+	})
+}
+
+// loadOwnedCustomField fetches the definition named by the :id parameter and
+// verifies it belongs to the authenticated user, writing the error response if not.
+func (h *CustomFieldsHandler) loadOwnedCustomField(c *gin.Context) (*models.CustomFieldDefinition, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	def, err := h.dbService.Repositories.GetCustomFieldDefinitionByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Custom field not found",
+		})
+		return nil, false
+	}
+
+	if def.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return def, true
+}
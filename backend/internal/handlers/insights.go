@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// InsightsHandler handles spending insight HTTP requests
+type InsightsHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewInsightsHandler creates a new insights handler
+func NewInsightsHandler(dbService *services.DatabaseService) *InsightsHandler {
+	return &InsightsHandler{
+		dbService: dbService,
+	}
+}
+
+// GetAnomalies handles GET /api/insights/anomalies. It lists transactions
+// and category-months between start_date and end_date (default the current
+// month) whose spending is far above the user's baseline. ?threshold sets
+// the robust z-score cut-off (2 to 10, default 3.5).
+func (h *InsightsHandler) GetAnomalies(c *gin.Context) {
+	report, ok := h.detectAnomalies(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// NotifyAnomalies handles POST /api/insights/anomalies/notify. It takes the
+// same parameters as GetAnomalies and raises a transaction_alert
+// notification for each anomaly not alerted on before.
+func (h *InsightsHandler) NotifyAnomalies(c *gin.Context) {
+	report, ok := h.detectAnomalies(c)
+	if !ok {
+		return
+	}
+
+	service := services.NewAnomalyService(h.dbService.Repositories)
+	if _, err := service.Notify(c.Request.Context(), report); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create anomaly notifications",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// detectAnomalies reads the anomaly parameters and runs the detection,
+// writing the error response if it fails.
+func (h *InsightsHandler) detectAnomalies(c *gin.Context) (*models.AnomalyReport, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	startDate, endDate, ok := parseReportDateRange(c)
+	if !ok {
+		return nil, false
+	}
+
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", "3.5"), 64)
+	if err != nil || threshold < 2 || threshold > 10 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid threshold parameter. Must be between 2 and 10",
+		})
+		return nil, false
+	}
+
+	service := services.NewAnomalyService(h.dbService.Repositories)
+	report, err := service.Detect(c.Request.Context(), userID, startDate, endDate, time.Now().UTC(), threshold)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to detect spending anomalies",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return report, true
+}
@@ -0,0 +1,485 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// MerchantsHandler handles merchant and merchant alias HTTP requests
+type MerchantsHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewMerchantsHandler creates a new merchants handler
+func NewMerchantsHandler(dbService *services.DatabaseService) *MerchantsHandler {
+	return &MerchantsHandler{
+		dbService: dbService,
+	}
+}
+
+// UpdateMerchantRequest represents the request body for renaming a merchant
+type UpdateMerchantRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateMerchantAliasRequest represents the request body for creating an alias
+type CreateMerchantAliasRequest struct {
+	MerchantID string                   `json:"merchant_id" binding:"required"`
+	Pattern    string                   `json:"pattern" binding:"required"`
+	MatchType  models.MerchantMatchType `json:"match_type" binding:"omitempty,oneof=contains exact regex"` // Defaults to contains
+}
+
+// NormalizeMerchantsRequest represents the request body for previewing normalization
+type NormalizeMerchantsRequest struct {
+	Descriptions []string `json:"descriptions" binding:"required,min=1,max=500"`
+}
+
+// BackfillMerchantsRequest represents the request body for assigning merchants
+// to existing transactions
+type BackfillMerchantsRequest struct {
+	StartDate *string `json:"start_date"` // ISO date string; defaults to all history
+	EndDate   *string `json:"end_date"`   // ISO date string; defaults to today
+	Overwrite bool    `json:"overwrite"`  // Re-resolve transactions that already have a merchant
+	DryRun    *bool   `json:"dry_run"`    // Defaults to true
+}
+
+// GetMerchants handles GET /api/merchants
+func (h *MerchantsHandler) GetMerchants(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	merchants, err := h.dbService.Repositories.GetMerchantsByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get merchants",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"merchants": merchants,
+	})
+}
+
+// UpdateMerchant handles PUT /api/merchants/:id
+func (h *MerchantsHandler) UpdateMerchant(c *gin.Context) {
+	var req UpdateMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	merchant, ok := h.loadOwnedMerchant(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	key := services.MerchantKey(name)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Merchant name must contain letters or digits",
+		})
+		return
+	}
+
+	merchant.Name = name
+	merchant.NormalizedName = key
+	if err := h.dbService.Repositories.UpdateMerchant(c.Request.Context(), merchant); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Failed to update merchant; another merchant may already use this name",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, merchant)
+}
+
+// DeleteMerchant handles DELETE /api/merchants/:id
+func (h *MerchantsHandler) DeleteMerchant(c *gin.Context) {
+	merchant, ok := h.loadOwnedMerchant(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	if err := h.dbService.Repositories.DeleteMerchant(c.Request.Context(), merchant.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete merchant",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Merchant deleted successfully",
+	})
+}
+
+// GetMerchantAliases handles GET /api/merchants/aliases
+func (h *MerchantsHandler) GetMerchantAliases(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var merchantID *string
+	if id := c.Query("merchant_id"); id != "" {
+		merchantID = &id
+	}
+
+	aliases, err := h.dbService.Repositories.GetMerchantAliases(c.Request.Context(), userID, merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get merchant aliases",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"aliases": aliases,
+	})
+}
+
+// CreateMerchantAlias handles POST /api/merchants/aliases
+func (h *MerchantsHandler) CreateMerchantAlias(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateMerchantAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	merchant, ok := h.loadOwnedMerchant(c, req.MerchantID)
+	if !ok {
+		return
+	}
+
+	alias := &models.MerchantAlias{
+		UserID:       userID,
+		MerchantID:   merchant.ID,
+		MerchantName: merchant.Name,
+		Pattern:      strings.TrimSpace(req.Pattern),
+		MatchType:    req.MatchType,
+	}
+	if alias.MatchType == "" {
+		alias.MatchType = models.MerchantMatchContains
+	}
+	if alias.Pattern == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Pattern cannot be empty",
+		})
+		return
+	}
+	if _, err := services.CompileMerchantAlias(*alias); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid alias pattern",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.dbService.Repositories.CreateMerchantAlias(c.Request.Context(), alias); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create merchant alias",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, alias)
+}
+
+// DeleteMerchantAlias handles DELETE /api/merchants/aliases/:id
+func (h *MerchantsHandler) DeleteMerchantAlias(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	alias, err := h.dbService.Repositories.GetMerchantAliasByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Merchant alias not found",
+		})
+		return
+	}
+	if alias.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return
+	}
+
+	if err := h.dbService.Repositories.DeleteMerchantAlias(c.Request.Context(), alias.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete merchant alias",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Merchant alias deleted successfully",
+	})
+}
+
+// NormalizeMerchants handles POST /api/merchants/normalize. It shows which
+// merchant each description resolves to without saving anything.
+func (h *MerchantsHandler) NormalizeMerchants(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req NormalizeMerchantsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	aliases, err := h.dbService.Repositories.GetMerchantAliases(c.Request.Context(), userID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get merchant aliases",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	normalizer := services.NewMerchantNormalizer(aliases)
+	results := make([]gin.H, 0, len(req.Descriptions))
+	for _, description := range req.Descriptions {
+		results = append(results, gin.H{
+			"description": description,
+			"merchant":    normalizer.Normalize(description),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+	})
+}
+
+// BackfillMerchants handles POST /api/merchants/backfill. Transactions in the
+// date range are run through the normalizer; with dry_run (the default) the
+// resulting assignments are only reported.
+func (h *MerchantsHandler) BackfillMerchants(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	var req BackfillMerchantsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	startDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Now()
+	if req.StartDate != nil {
+		parsed, err := time.Parse("2006-01-02", *req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid start_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		startDate = parsed
+	}
+	if req.EndDate != nil {
+		parsed, err := time.Parse("2006-01-02", *req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		endDate = parsed
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "end_date must be after start_date",
+		})
+		return
+	}
+
+	dryRun := true
+	if req.DryRun != nil {
+		dryRun = *req.DryRun
+	}
+
+	transactions, err := h.dbService.Repositories.GetTransactionsByDateRange(ctx, userID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resolver, err := newMerchantResolver(ctx, h.dbService, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get merchant aliases",
+			"details": err.Error(),
+		})
+		return
+	}
+	// A dry run must not create merchants, so only names are resolved
+	resolver.dryRun = dryRun
+
+	changes := []gin.H{}
+	var updated []models.Transaction
+	for _, t := range transactions {
+		if t.TransferID != nil || t.TransactionType == models.TransactionTypeTransfer {
+			continue
+		}
+		if t.MerchantID != nil && !req.Overwrite {
+			continue
+		}
+
+		before := t.Merchant
+		name, err := resolver.resolve(ctx, &t)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to resolve merchant",
+				"details": err.Error(),
+			})
+			return
+		}
+		if name == "" || (before != nil && services.MerchantKey(before.Name) == services.MerchantKey(name)) {
+			continue
+		}
+
+		changes = append(changes, gin.H{
+			"transaction_id": t.ID,
+			"description":    t.Description,
+			"merchant_name":  name,
+		})
+		updated = append(updated, t)
+	}
+
+	var applied int64
+	if !dryRun && len(updated) > 0 {
+		applied, err = h.dbService.Repositories.SetTransactionMerchants(ctx, userID, updated)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to assign merchants",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":   dryRun,
+		"evaluated": len(transactions),
+		"matched":   len(changes),
+		"applied":   applied,
+		"changes":   changes,
+	})
+}
+
+// loadOwnedMerchant fetches a merchant and verifies it belongs to the current
+// user, writing the error response on failure.
+func (h *MerchantsHandler) loadOwnedMerchant(c *gin.Context, id string) (*models.Merchant, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	merchant, err := h.dbService.Repositories.GetMerchantByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Merchant not found",
+		})
+		return nil, false
+	}
+
+	if merchant.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return merchant, true
+}
+
+// merchantResolver assigns canonical merchants to transactions, creating
+// merchant rows on first use and caching them for the rest of the request.
+type merchantResolver struct {
+	dbService  *services.DatabaseService
+	userID     string
+	normalizer *services.MerchantNormalizer
+	merchants  map[string]string // normalized name -> merchant ID
+	dryRun     bool
+}
+
+func newMerchantResolver(ctx context.Context, dbService *services.DatabaseService, userID string) (*merchantResolver, error) {
+	aliases, err := dbService.Repositories.GetMerchantAliases(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &merchantResolver{
+		dbService:  dbService,
+		userID:     userID,
+		normalizer: services.NewMerchantNormalizer(aliases),
+		merchants:  make(map[string]string),
+	}, nil
+}
+
+// resolve sets the transaction's merchant from its description and returns
+// the merchant name, or clears it and returns "" when nothing is recognized.
+func (r *merchantResolver) resolve(ctx context.Context, transaction *models.Transaction) (string, error) {
+	description := ""
+	if transaction.Description != nil {
+		description = *transaction.Description
+	}
+
+	match := r.normalizer.Normalize(description)
+	key := ""
+	if match != nil {
+		key = services.MerchantKey(match.Name)
+	}
+	if key == "" {
+		transaction.MerchantID = nil
+		return "", nil
+	}
+
+	if match.MerchantID != nil {
+		transaction.MerchantID = match.MerchantID
+		return match.Name, nil
+	}
+	if r.dryRun {
+		return match.Name, nil
+	}
+
+	if id, ok := r.merchants[key]; ok {
+		transaction.MerchantID = &id
+		return match.Name, nil
+	}
+
+	merchant := &models.Merchant{
+		UserID:         r.userID,
+		Name:           match.Name,
+		NormalizedName: key,
+	}
+	if err := r.dbService.Repositories.FindOrCreateMerchant(ctx, merchant); err != nil {
+		return "", err
+	}
+
+	r.merchants[key] = merchant.ID
+	transaction.MerchantID = &merchant.ID
+	return merchant.Name, nil
+}
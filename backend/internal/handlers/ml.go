@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// MLHandler handles server-side ML categorization HTTP requests
+type MLHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewMLHandler creates a new ML handler
+func NewMLHandler(dbService *services.DatabaseService) *MLHandler {
+	return &MLHandler{
+		dbService: dbService,
+	}
+}
+
+// PredictCategoryRequest represents the request body for a category prediction
+type PredictCategoryRequest struct {
+	Description string  `json:"description" binding:"required"`
+	Amount      float64 `json:"amount"`
+}
+
+// TrainModelRequest represents the request body for training the model
+type TrainModelRequest struct {
+	Full bool `json:"full"` // Rebuild from all categorized transactions instead of new feedback only
+}
+
+// MLFeedbackRequest represents the request body for recording prediction feedback
+type MLFeedbackRequest struct {
+	TransactionID       string  `json:"transaction_id" binding:"required"`
+	PredictedCategoryID *string `json:"predicted_category_id"`
+	ActualCategoryID    string  `json:"actual_category_id" binding:"required"`
+	Confidence          int     `json:"confidence" binding:"min=0,max=100"`
+	FeedbackNotes       *string `json:"feedback_notes"`
+}
+
+// GetModel handles GET /api/ml/model
+func (h *MLHandler) GetModel(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	record, classifier, err := h.loadClassifier(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load ML model",
+			"details": err.Error(),
+		})
+		return
+	}
+	if record == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No ML model has been trained yet",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"model":           record,
+		"training_count":  classifier.DocCount,
+		"category_count":  len(classifier.Categories),
+		"trained_through": classifier.TrainedThrough,
+	})
+}
+
+// TrainModel handles POST /api/ml/train. Without an existing model, or when
+// full is set, the model is rebuilt from every categorized transaction with
+// feedback corrections applied; otherwise only feedback recorded since the
+// last run is folded in.
+func (h *MLHandler) TrainModel(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	var req TrainModelRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	started := time.Now()
+
+	record, classifier, err := h.loadClassifier(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load ML model",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	trainingType := "incremental"
+	var examples []models.TrainingExample
+	if record == nil || req.Full {
+		trainingType = "full"
+		classifier = services.NewCategoryClassifier()
+		examples, err = h.dbService.Repositories.GetCategorizedTrainingExamples(ctx, userID)
+	} else {
+		examples, err = h.dbService.Repositories.GetFeedbackTrainingExamples(ctx, userID, classifier.TrainedThrough)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load training data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validation examples are scored before and after training on the rest, so
+	// the recorded accuracy comes from examples the model has not seen. They
+	// are folded in afterwards so no training data is wasted.
+	training, validation := services.SplitValidation(examples)
+	accuracyBefore := classifier.Evaluate(validation)
+	classifier.Train(training)
+	accuracyAfter := classifier.Evaluate(validation)
+	classifier.Train(validation)
+
+	// Transactions and feedback carry different timestamps, so a full rebuild
+	// restarts the feedback watermark from now
+	if trainingType == "full" {
+		classifier.TrainedThrough = &started
+	}
+
+	if record == nil {
+		record = &models.UserMLModel{UserID: userID}
+	}
+	if len(validation) > 0 {
+		record.Accuracy = accuracyAfter
+	}
+	if err := h.saveClassifier(ctx, record, classifier); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to save ML model",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	session := &models.MLTrainingSession{
+		UserID:             userID,
+		TrainingDataCount:  len(examples),
+		AccuracyBefore:     accuracyBefore,
+		AccuracyAfter:      accuracyAfter,
+		TrainingDurationMs: int(time.Since(started).Milliseconds()),
+		ModelVersion:       services.ClassifierVersion,
+		TrainingType:       trainingType,
+	}
+	if err := h.dbService.Repositories.CreateMLTrainingSession(ctx, session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to record training session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session": session,
+		"model":   record,
+	})
+}
+
+// Predict handles POST /api/ml/predict
+func (h *MLHandler) Predict(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	var req PredictCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	record, classifier, err := h.loadClassifier(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load ML model",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var result *services.CategoryPrediction
+	if record != nil {
+		result = classifier.Predict(req.Description, req.Amount)
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No ML model has been trained yet",
+		})
+		return
+	}
+
+	alternatives, err := json.Marshal(result.Alternatives)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to encode alternatives",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reasoning := "No known words matched; prediction based on amount and category frequency"
+	if len(result.MatchedTokens) > 0 {
+		reasoning = fmt.Sprintf("Matched words: %s", strings.Join(result.MatchedTokens, ", "))
+	}
+
+	prediction := &models.MLPrediction{
+		UserID:                 userID,
+		TransactionDescription: req.Description,
+		TransactionAmount:      req.Amount,
+		PredictedCategoryID:    &result.CategoryID,
+		Confidence:             result.Confidence,
+		Reasoning:              &reasoning,
+		ModelVersion:           services.ClassifierVersion,
+		PredictionSources:      []string{"naive_bayes"},
+		Alternatives:           alternatives,
+		ModelAccuracy:          record.Accuracy,
+	}
+	if err := h.dbService.Repositories.CreateMLPrediction(ctx, prediction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to log prediction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"prediction_id": prediction.ID,
+		"category_id":   result.CategoryID,
+		"confidence":    result.Confidence,
+		"alternatives":  result.Alternatives,
+		"reasoning":     reasoning,
+		"model_version": services.ClassifierVersion,
+	})
+}
+
+// RecordFeedback handles POST /api/ml/feedback. The corrected category is
+// stored in ml_feedback and folded into the model straight away.
+func (h *MLHandler) RecordFeedback(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	var req MLFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transaction, err := h.dbService.Repositories.GetTransactionByID(ctx, req.TransactionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Transaction not found",
+		})
+		return
+	}
+	if transaction.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return
+	}
+
+	// Both categories are reported by name in accuracy analytics
+	for _, categoryID := range []*string{&req.ActualCategoryID, req.PredictedCategoryID} {
+		if categoryID == nil {
+			continue
+		}
+		category, err := h.dbService.Repositories.GetCategoryByID(ctx, *categoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Category not found",
+			})
+			return
+		}
+		if category.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return
+		}
+	}
+
+	feedback := &models.MLFeedback{
+		UserID:              userID,
+		TransactionID:       &transaction.ID,
+		PredictedCategoryID: req.PredictedCategoryID,
+		ActualCategoryID:    &req.ActualCategoryID,
+		Confidence:          req.Confidence,
+		WasCorrect:          req.PredictedCategoryID != nil && *req.PredictedCategoryID == req.ActualCategoryID,
+		ModelVersion:        services.ClassifierVersion,
+		FeedbackNotes:       req.FeedbackNotes,
+	}
+	if err := h.dbService.Repositories.CreateMLFeedback(ctx, feedback); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to record feedback",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	record, classifier, err := h.loadClassifier(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load ML model",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Until a model is trained, feedback simply waits for the first training run
+	if record != nil {
+		description := ""
+		if transaction.Description != nil {
+			description = *transaction.Description
+		}
+		classifier.Train([]models.TrainingExample{{
+			Description: description,
+			Amount:      transaction.Amount,
+			CategoryID:  req.ActualCategoryID,
+			CreatedAt:   feedback.CreatedAt,
+		}})
+
+		record.TotalPredictions++
+		if feedback.WasCorrect {
+			record.CorrectPredictions++
+		}
+
+		if err := h.saveClassifier(ctx, record, classifier); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update ML model",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, feedback)
+}
+
+// loadClassifier returns the stored model record and its decoded classifier.
+// Both are nil when the user has no model yet.
+func (h *MLHandler) loadClassifier(ctx context.Context, userID string) (*models.UserMLModel, *services.CategoryClassifier, error) {
+	record, err := h.dbService.Repositories.GetUserMLModel(ctx, userID)
+	if err != nil || record == nil {
+		return nil, nil, err
+	}
+
+	classifier := services.NewCategoryClassifier()
+	if err := json.Unmarshal(record.ModelData, classifier); err != nil || classifier.Version != services.ClassifierVersion {
+		// Models written by the browser or an older version are rebuilt from scratch
+		return nil, nil, nil
+	}
+
+	return record, classifier, nil
+}
+
+// saveClassifier serializes the classifier into the model record and stores it.
+func (h *MLHandler) saveClassifier(ctx context.Context, record *models.UserMLModel, classifier *services.CategoryClassifier) error {
+	data, err := json.Marshal(classifier)
+	if err != nil {
+		return fmt.Errorf("failed to encode ML model: %w", err)
+	}
+
+	record.ModelData = data
+	record.PatternCount = classifier.PatternCount()
+	record.ModelVersion = services.ClassifierVersion
+	record.LastTrained = time.Now()
+
+	return h.dbService.Repositories.SaveUserMLModel(ctx, record)
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// NotificationPreferencesHandler handles notification preference and
+// delivery HTTP requests
+type NotificationPreferencesHandler struct {
+	dbService *services.DatabaseService
+	digests   *services.DigestService
+}
+
+// NewNotificationPreferencesHandler creates a new notification preferences handler
+func NewNotificationPreferencesHandler(dbService *services.DatabaseService) *NotificationPreferencesHandler {
+	return &NotificationPreferencesHandler{
+		dbService: dbService,
+		digests:   services.NewDigestService(dbService.Repositories, nil),
+	}
+}
+
+// NotificationSettingsRequest represents changes to the user's channel
+// settings. Omitted fields are left as they are; an empty string clears one.
+type NotificationSettingsRequest struct {
+	Email           *string `json:"email"`
+	WebhookURL      *string `json:"webhook_url"`
+	WebhookSecret   *string `json:"webhook_secret"`
+	QuietHoursStart *string `json:"quiet_hours_start"` // HH:MM
+	QuietHoursEnd   *string `json:"quiet_hours_end"`   // HH:MM
+	Timezone        *string `json:"timezone"`
+	DigestFrequency *string `json:"digest_frequency" binding:"omitempty,oneof=off weekly monthly"`
+}
+
+// NotificationPreferenceRequest represents the preference for one
+// notification type
+type NotificationPreferenceRequest struct {
+	NotificationType  models.NotificationType         `json:"notification_type" binding:"required,oneof=budget_alert goal_milestone transaction_alert info"`
+	InApp             bool                            `json:"in_app"`
+	Email             bool                            `json:"email"`
+	Webhook           bool                            `json:"webhook"`
+	Delivery          models.NotificationDeliveryMode `json:"delivery" binding:"omitempty,oneof=immediate digest"`
+	RespectQuietHours bool                            `json:"respect_quiet_hours"`
+}
+
+// UpdateNotificationPreferencesRequest represents the request body for
+// updating notification preferences. Types left out keep their preference.
+type UpdateNotificationPreferencesRequest struct {
+	Settings    *NotificationSettingsRequest    `json:"settings"`
+	Preferences []NotificationPreferenceRequest `json:"preferences" binding:"dive"`
+}
+
+// GetNotificationPreferences handles GET /api/notifications/preferences,
+// returning the user's channel settings and their preference for every
+// notification type
+func (h *NotificationPreferencesHandler) GetNotificationPreferences(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	settings, preferences, ok := h.loadPreferences(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings":    settings,
+		"preferences": services.CompleteNotificationPreferences(userID, preferences),
+	})
+}
+
+// UpdateNotificationPreferences handles PUT /api/notifications/preferences
+func (h *NotificationPreferencesHandler) UpdateNotificationPreferences(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	settings, saved, ok := h.loadPreferences(c, userID)
+	if !ok {
+		return
+	}
+
+	if req.Settings != nil {
+		applySettingsRequest(settings, req.Settings)
+		if err := services.ValidateNotificationSettings(*settings); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid notification settings",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	changed := make([]models.NotificationPreference, 0, len(req.Preferences))
+	for _, p := range req.Preferences {
+		delivery := p.Delivery
+		if delivery == "" {
+			delivery = models.NotificationDeliveryImmediate
+		}
+		changed = append(changed, models.NotificationPreference{
+			UserID:            userID,
+			NotificationType:  p.NotificationType,
+			InApp:             p.InApp,
+			Email:             p.Email,
+			Webhook:           p.Webhook,
+			Delivery:          delivery,
+			RespectQuietHours: p.RespectQuietHours,
+		})
+	}
+	// Later entries win, as they are saved in order
+	preferences := services.CompleteNotificationPreferences(userID, append(saved, changed...))
+	if err := services.ValidateNotificationPreferences(*settings, preferences); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid notification preferences",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.Settings != nil {
+		if err := h.dbService.Repositories.UpsertNotificationSettings(ctx, settings); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to save notification settings",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+	for i := range changed {
+		if err := h.dbService.Repositories.UpsertNotificationPreference(ctx, &changed[i]); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to save notification preferences",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings":    settings,
+		"preferences": preferences,
+	})
+}
+
+// GetNotificationDeliveries handles GET /api/notifications/deliveries,
+// listing the user's recent email and webhook deliveries. Supports ?status=
+// (pending, digest, sent or failed) and ?limit= (default 50, at most 200).
+func (h *NotificationPreferencesHandler) GetNotificationDeliveries(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	status := models.NotificationDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", models.NotificationDeliveryPending, models.NotificationDeliveryInDigest,
+		models.NotificationDeliverySent, models.NotificationDeliveryFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid status. Use pending, digest, sent or failed",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	deliveries, err := h.dbService.Repositories.GetNotificationDeliveries(c.Request.Context(), userID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get notification deliveries",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+	})
+}
+
+// PreviewDigest handles GET /api/notifications/digest/preview, composing
+// the user's latest digest without sending it. Supports ?frequency= (weekly
+// or monthly, default the user's setting or weekly) and ?format= (json,
+// html or text).
+func (h *NotificationPreferencesHandler) PreviewDigest(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	settings, err := h.dbService.Repositories.GetNotificationSettings(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get notification settings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	frequency := models.DigestFrequency(c.Query("frequency"))
+	if frequency == "" {
+		frequency = settings.DigestFrequency
+		if frequency == models.DigestFrequencyOff {
+			frequency = models.DigestFrequencyWeekly
+		}
+	}
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now := time.Now().UTC()
+	period, _, ok := services.DigestPeriod(frequency, now, loc)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid frequency. Use weekly or monthly",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "html" && format != "text" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid format. Use json, html or text",
+		})
+		return
+	}
+
+	digest, err := h.digests.Generate(c.Request.Context(), userID, frequency, period, settings.Timezone, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate digest",
+			"details": err.Error(),
+		})
+		return
+	}
+	if format == "json" {
+		c.JSON(http.StatusOK, digest)
+		return
+	}
+
+	mail, err := services.RenderDigestMail(digest, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to render digest",
+			"details": err.Error(),
+		})
+		return
+	}
+	if format == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(mail.HTML))
+		return
+	}
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(mail.Text))
+}
+
+// loadPreferences loads the user's settings and saved preferences, writing
+// the error response if it fails
+func (h *NotificationPreferencesHandler) loadPreferences(c *gin.Context, userID string) (*models.NotificationSettings, []models.NotificationPreference, bool) {
+	ctx := c.Request.Context()
+	settings, err := h.dbService.Repositories.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get notification settings",
+			"details": err.Error(),
+		})
+		return nil, nil, false
+	}
+	preferences, err := h.dbService.Repositories.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get notification preferences",
+			"details": err.Error(),
+		})
+		return nil, nil, false
+	}
+	return settings, preferences, true
+}
+
+// applySettingsRequest applies the requested changes to settings
+func applySettingsRequest(settings *models.NotificationSettings, req *NotificationSettingsRequest) {
+	set := func(field **string, value *string) {
+		if value == nil {
+			return
+		}
+		if *value == "" {
+			*field = nil
+			return
+		}
+		v := *value
+		*field = &v
+	}
+	set(&settings.Email, req.Email)
+	set(&settings.WebhookURL, req.WebhookURL)
+	set(&settings.WebhookSecret, req.WebhookSecret)
+	set(&settings.QuietHoursStart, req.QuietHoursStart)
+	set(&settings.QuietHoursEnd, req.QuietHoursEnd)
+	if req.Timezone != nil {
+		settings.Timezone = *req.Timezone
+	}
+	if req.DigestFrequency != nil {
+		settings.DigestFrequency = models.DigestFrequency(*req.DigestFrequency)
+	}
+	settings.WebhookSecretSet = settings.WebhookSecret != nil
+}
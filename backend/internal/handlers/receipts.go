@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// ReceiptsHandler handles receipt parsing HTTP requests
+type ReceiptsHandler struct {
+	dbService *services.DatabaseService
+	storage   services.ObjectStorage // Nil when attachment storage is unavailable
+}
+
+// NewReceiptsHandler creates a new receipts handler. storage may be nil, in
+// which case receipts can only be parsed from submitted text.
+func NewReceiptsHandler(dbService *services.DatabaseService, storage services.ObjectStorage) *ReceiptsHandler {
+	return &ReceiptsHandler{
+		dbService: dbService,
+		storage:   storage,
+	}
+}
+
+// ParseReceiptRequest represents the request body for parsing a receipt.
+// Exactly one of Text and AttachmentID must be set.
+type ParseReceiptRequest struct {
+	Text         string  `json:"text"`          // OCR output or an e-receipt email body
+	AttachmentID *string `json:"attachment_id"` // A plain text attachment to parse instead
+	Locale       string  `json:"locale"`        // Language tag such as en-US or de-DE; defaults to en-US
+	AccountID    *string `json:"account_id"`    // Account to put on the draft
+}
+
+// ParseReceipt handles POST /api/receipts/parse. Nothing is saved: the
+// response holds what was read from the receipt and a draft expense, split
+// by line item where the items add up, for the user to confirm through
+// POST /api/transactions.
+func (h *ReceiptsHandler) ParseReceipt(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req ParseReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if (req.Text == "") == (req.AttachmentID == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Provide exactly one of text or attachment_id",
+		})
+		return
+	}
+
+	locale, err := services.ParseReceiptLocale(req.Locale)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid locale",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	text := req.Text
+	if req.AttachmentID != nil {
+		var ok bool
+		if text, ok = h.attachmentText(c, userID, *req.AttachmentID); !ok {
+			return
+		}
+	}
+
+	receipt, err := services.ParseReceipt(text, locale)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to parse receipt",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	draft := services.ReceiptDraft(receipt, time.Now())
+	draft.UserID = userID
+	if req.AccountID != nil {
+		account, err := h.dbService.Repositories.GetAccountByID(c.Request.Context(), *req.AccountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Account not found",
+			})
+			return
+		}
+		if account.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return
+		}
+		draft.AccountID = account.ID
+	}
+	h.suggestDetails(c, &draft)
+
+	c.JSON(http.StatusOK, gin.H{
+		"receipt": receipt,
+		"draft":   draft,
+	})
+}
+
+// attachmentText reads a plain text attachment owned by the user, writing
+// the error response if it cannot be used.
+func (h *ReceiptsHandler) attachmentText(c *gin.Context, userID, attachmentID string) (string, bool) {
+	if h.storage == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Attachment storage is not available",
+		})
+		return "", false
+	}
+
+	attachment, err := h.dbService.Repositories.GetAttachmentByID(c.Request.Context(), attachmentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Attachment not found",
+		})
+		return "", false
+	}
+	if attachment.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return "", false
+	}
+	if attachment.ContentType != "text/plain" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Only plain text attachments can be parsed; submit OCR output as text instead",
+		})
+		return "", false
+	}
+
+	reader, err := h.storage.Get(c.Request.Context(), attachment.StorageKey)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrObjectNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to read attachment file",
+			"details": err.Error(),
+		})
+		return "", false
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, services.MaxReceiptTextLength+1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to read attachment file",
+			"details": err.Error(),
+		})
+		return "", false
+	}
+
+	return string(data), true
+}
+
+// suggestDetails resolves the draft's merchant without creating one and lets
+// the user's categorization rules suggest a category. Failures only leave
+// the suggestions out.
+func (h *ReceiptsHandler) suggestDetails(c *gin.Context, draft *models.Transaction) {
+	ctx := c.Request.Context()
+
+	resolver, err := newMerchantResolver(ctx, h.dbService, draft.UserID)
+	if err != nil {
+		log.Printf("Warning: Failed to load merchant aliases: %v", err)
+	} else {
+		resolver.dryRun = true
+		if name, err := resolver.resolve(ctx, draft); err != nil {
+			log.Printf("Warning: Failed to resolve merchant: %v", err)
+		} else if name != "" {
+			draft.Merchant = &models.Merchant{Name: name}
+			if draft.MerchantID != nil {
+				draft.Merchant.ID = *draft.MerchantID
+			}
+		}
+	}
+
+	rules, err := h.dbService.Repositories.GetRulesByUserID(ctx, draft.UserID)
+	if err != nil {
+		log.Printf("Warning: Failed to load categorization rules: %v", err)
+		return
+	}
+	engine, err := services.NewRuleEngine(rules)
+	if err != nil {
+		log.Printf("Warning: Failed to compile categorization rules: %v", err)
+		return
+	}
+	engine.Apply(draft, false)
+}
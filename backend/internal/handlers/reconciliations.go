@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// ReconciliationsHandler handles statement reconciliation HTTP requests
+type ReconciliationsHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewReconciliationsHandler creates a new reconciliations handler
+func NewReconciliationsHandler(dbService *services.DatabaseService) *ReconciliationsHandler {
+	return &ReconciliationsHandler{
+		dbService: dbService,
+	}
+}
+
+// StartReconciliationRequest represents the request body for starting a reconciliation
+type StartReconciliationRequest struct {
+	AccountID        string   `json:"account_id" binding:"required"`
+	StatementDate    string   `json:"statement_date" binding:"required"` // ISO date string
+	StatementBalance *float64 `json:"statement_balance" binding:"required"`
+}
+
+// ClearTransactionsRequest represents the request body for marking transactions cleared
+type ClearTransactionsRequest struct {
+	TransactionIDs []string `json:"transaction_ids" binding:"required,min=1"`
+	Cleared        *bool    `json:"cleared"` // Defaults to true
+}
+
+// StartReconciliation handles POST /api/reconciliations
+func (h *ReconciliationsHandler) StartReconciliation(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	var req StartReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	statementDate, err := time.Parse("2006-01-02", req.StatementDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid statement_date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+
+	account, err := h.dbService.Repositories.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Account not found",
+		})
+		return
+	}
+	if account.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return
+	}
+
+	// Only one reconciliation may be open per account
+	history, err := h.dbService.Repositories.GetReconciliationsByUserID(ctx, userID, &req.AccountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get reconciliations",
+			"details": err.Error(),
+		})
+		return
+	}
+	for _, rec := range history {
+		if rec.Status == models.ReconciliationStatusInProgress {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":             "A reconciliation is already in progress for this account",
+				"reconciliation_id": rec.ID,
+			})
+			return
+		}
+	}
+
+	reconciliation := &models.Reconciliation{
+		UserID:           userID,
+		AccountID:        req.AccountID,
+		StatementDate:    statementDate,
+		StatementBalance: *req.StatementBalance,
+	}
+
+	err = h.dbService.Repositories.CreateReconciliation(ctx, reconciliation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to start reconciliation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.respondWithWorksheet(c, reconciliation)
+}
+
+// GetReconciliations handles GET /api/reconciliations
+func (h *ReconciliationsHandler) GetReconciliations(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var accountID *string
+	if id := c.Query("account_id"); id != "" {
+		accountID = &id
+	}
+
+	reconciliations, err := h.dbService.Repositories.GetReconciliationsByUserID(c.Request.Context(), userID, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get reconciliations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reconciliations": reconciliations,
+		"count":           len(reconciliations),
+	})
+}
+
+// GetReconciliation handles GET /api/reconciliations/:id
+func (h *ReconciliationsHandler) GetReconciliation(c *gin.Context) {
+	reconciliation, ok := h.loadOwnedReconciliation(c)
+	if !ok {
+		return
+	}
+
+	if reconciliation.Status != models.ReconciliationStatusInProgress {
+		c.JSON(http.StatusOK, gin.H{
+			"reconciliation": reconciliation,
+		})
+		return
+	}
+
+	h.respondWithWorksheet(c, reconciliation)
+}
+
+// ClearTransactions handles POST /api/reconciliations/:id/clear
+func (h *ReconciliationsHandler) ClearTransactions(c *gin.Context) {
+	var req ClearTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reconciliation, ok := h.loadOpenReconciliation(c)
+	if !ok {
+		return
+	}
+
+	cleared := true
+	if req.Cleared != nil {
+		cleared = *req.Cleared
+	}
+
+	_, err := h.dbService.Repositories.SetTransactionsCleared(c.Request.Context(), reconciliation, req.TransactionIDs, cleared)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update cleared transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.respondWithWorksheet(c, reconciliation)
+}
+
+// CompleteReconciliation handles POST /api/reconciliations/:id/complete
+func (h *ReconciliationsHandler) CompleteReconciliation(c *gin.Context) {
+	reconciliation, ok := h.loadOpenReconciliation(c)
+	if !ok {
+		return
+	}
+
+	if !h.refreshBalances(c, reconciliation) {
+		return
+	}
+
+	if reconciliation.Difference != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Cleared balance does not match the statement balance",
+			"difference": reconciliation.Difference,
+		})
+		return
+	}
+
+	err := h.dbService.Repositories.CompleteReconciliation(c.Request.Context(), reconciliation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to complete reconciliation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reconciliation": reconciliation,
+	})
+}
+
+// CancelReconciliation handles DELETE /api/reconciliations/:id
+func (h *ReconciliationsHandler) CancelReconciliation(c *gin.Context) {
+	reconciliation, ok := h.loadOpenReconciliation(c)
+	if !ok {
+		return
+	}
+
+	err := h.dbService.Repositories.CancelReconciliation(c.Request.Context(), reconciliation.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to cancel reconciliation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reconciliation cancelled successfully",
+	})
+}
+
+// respondWithWorksheet writes the open reconciliation with its live cleared
+// balance, difference and the transactions still available to clear. The
+// tag filter only narrows the listed transactions, never the balances.
+func (h *ReconciliationsHandler) respondWithWorksheet(c *gin.Context, reconciliation *models.Reconciliation) {
+	tagFilter, ok := parseTagFilter(c)
+	if !ok {
+		return
+	}
+
+	if !h.refreshBalances(c, reconciliation) {
+		return
+	}
+
+	transactions, err := h.dbService.Repositories.GetReconciliationTransactions(c.Request.Context(), reconciliation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get reconciliation transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reconciliation": reconciliation,
+		"transactions":   filterByTags(transactions, tagFilter),
+	})
+}
+
+// refreshBalances recomputes the cleared balance and difference of an open
+// reconciliation, writing the error response on failure.
+func (h *ReconciliationsHandler) refreshBalances(c *gin.Context, reconciliation *models.Reconciliation) bool {
+	balance, count, err := h.dbService.Repositories.GetClearedBalance(c.Request.Context(), reconciliation.AccountID, reconciliation.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to calculate cleared balance",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	reconciliation.ClearedBalance = balance
+	reconciliation.ClearedCount = count
+	reconciliation.Difference = services.ReconciliationDifference(reconciliation.StatementBalance, balance)
+
+	return true
+}
+
+// loadOwnedReconciliation fetches the reconciliation named by the :id parameter
+// and verifies it belongs to the authenticated user, writing the error response if not.
+func (h *ReconciliationsHandler) loadOwnedReconciliation(c *gin.Context) (*models.Reconciliation, bool) {
+	userID := middleware.MustGetUserID(c)
+	reconciliationID := c.Param("id")
+
+	reconciliation, err := h.dbService.Repositories.GetReconciliationByID(c.Request.Context(), reconciliationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Reconciliation not found",
+		})
+		return nil, false
+	}
+
+	// Ensure the reconciliation belongs to the authenticated user
+	if reconciliation.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return reconciliation, true
+}
+
+// loadOpenReconciliation is loadOwnedReconciliation restricted to
+// reconciliations that are still in progress.
+func (h *ReconciliationsHandler) loadOpenReconciliation(c *gin.Context) (*models.Reconciliation, bool) {
+	reconciliation, ok := h.loadOwnedReconciliation(c)
+	if !ok {
+		return nil, false
+	}
+
+	if reconciliation.Status != models.ReconciliationStatusInProgress {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Reconciliation is no longer in progress",
+		})
+		return nil, false
+	}
+
+	return reconciliation, true
+}
@@ -1,27 +1,92 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
 	"github.com/personal-finance-management/backend/internal/services"
 )
 
 // ReportsHandler handles report-related HTTP requests
 type ReportsHandler struct {
 	dbService *services.DatabaseService
+	cache     *services.ReportCache
 }
 
-// NewReportsHandler creates a new reports handler
-func NewReportsHandler(dbService *services.DatabaseService) *ReportsHandler {
+// NewReportsHandler creates a new reports handler. cache may be nil to build
+// every report on request.
+func NewReportsHandler(dbService *services.DatabaseService, cache *services.ReportCache) *ReportsHandler {
 	return &ReportsHandler{
 		dbService: dbService,
+		cache:     cache,
 	}
 }
 
+// respondCached responds with the cached copy of a report when there is
+// one. Otherwise build produces the report, writing its own error response
+// and returning nil when it fails, and the result is cached. params must
+// capture everything the report depends on besides the user.
+func (h *ReportsHandler) respondCached(c *gin.Context, report, params string, build func() interface{}) {
+	ctx := c.Request.Context()
+	userID := middleware.MustGetUserID(c)
+
+	body, key, hit := h.cache.Lookup(ctx, userID, report, params)
+	if hit {
+		c.Header("X-Cache", "HIT")
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+
+	result := build()
+	if result == nil {
+		return
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to encode report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.cache.Store(ctx, key, body)
+	if h.cache != nil {
+		c.Header("X-Cache", "MISS")
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// reportRangeParams identifies a report date range in cache keys
+func reportRangeParams(startDate, endDate time.Time) string {
+	return startDate.Format(time.RFC3339) + "/" + endDate.Format(time.RFC3339)
+}
+
+// ClearReportCache handles DELETE /api/reports/cache, dropping the user's
+// cached reports after changes the server was not told about.
+func (h *ReportsHandler) ClearReportCache(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	h.cache.Invalidate(c.Request.Context(), userID)
+	c.Status(http.StatusNoContent)
+}
+
+// ReportCacheStats handles GET /api/reports/cache/stats, reporting cache
+// hits and misses per report since the server started. The figures cover
+// every user, so the route is limited to service-role callers.
+func (h *ReportsHandler) ReportCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cache.Stats())
+}
+
 // GetMonthlySummary handles GET /api/reports/monthly-summary
 func (h *ReportsHandler) GetMonthlySummary(c *gin.Context) {
 	userID := middleware.MustGetUserID(c)
@@ -46,120 +111,403 @@ func (h *ReportsHandler) GetMonthlySummary(c *gin.Context) {
 		return
 	}
 
-	// Get the monthly summary
-	summary, err := h.dbService.Repositories.GetMonthlySummary(c.Request.Context(), userID, month, year)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to generate monthly summary",
-			"details": err.Error(),
+	h.respondCached(c, "monthly-summary", fmt.Sprintf("%d-%02d", year, month), func() interface{} {
+		// Get the monthly summary
+		summary, err := h.dbService.Repositories.GetMonthlySummary(c.Request.Context(), userID, month, year)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate monthly summary",
+				"details": err.Error(),
+			})
+			return nil
+		}
+		return summary
+	})
+}
+
+// GetTagSummary handles GET /api/reports/tags
+func (h *ReportsHandler) GetTagSummary(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	month, err := strconv.Atoi(c.DefaultQuery("month", strconv.Itoa(int(time.Now().Month()))))
+	if err != nil || month < 1 || month > 12 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid month parameter. Must be between 1 and 12",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, summary)
+	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	if err != nil || year < 1900 || year > 2100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid year parameter. Must be between 1900 and 2100",
+		})
+		return
+	}
+
+	h.respondCached(c, "tags", fmt.Sprintf("%d-%02d", year, month), func() interface{} {
+		summary, err := h.dbService.Repositories.GetTagSummary(c.Request.Context(), userID, month, year)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate tag summary",
+				"details": err.Error(),
+			})
+			return nil
+		}
+		return summary
+	})
+}
+
+// maxTrendPeriods caps how many buckets a spending trends report may span
+var maxTrendPeriods = map[models.TrendGranularity]int{
+	models.TrendGranularityWeekly:    156,
+	models.TrendGranularityMonthly:   60,
+	models.TrendGranularityQuarterly: 40,
+	models.TrendGranularityYearly:    20,
 }
 
+// maxTrendCategories caps how many category series a trends report returns
+const maxTrendCategories = 10
+
 // GetSpendingTrends handles GET /api/reports/spending-trends
 func (h *ReportsHandler) GetSpendingTrends(c *gin.Context) {
 	userID := middleware.MustGetUserID(c)
 
-	// Parse query parameters
-	monthsStr := c.DefaultQuery("months", "12")
-	categoryID := c.Query("category_id")
+	query, ok := parseSpendingTrendsQuery(c)
+	if !ok {
+		return
+	}
+
+	h.respondCached(c, "spending-trends", fmt.Sprintf("%+v", query), func() interface{} {
+		// Get spending trends
+		trends, err := h.dbService.Repositories.GetSpendingTrends(c.Request.Context(), userID, query)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate spending trends",
+				"details": err.Error(),
+			})
+			return nil
+		}
+		return trends
+	})
+}
 
-	months, err := strconv.Atoi(monthsStr)
-	if err != nil || months < 1 || months > 60 {
+// parseSpendingTrendsQuery reads the granularity, periods, category_id
+// (comma-separated or repeated), include_income and end_date query
+// parameters, writing the error response when they are invalid. months is
+// still accepted as the number of monthly periods.
+func parseSpendingTrendsQuery(c *gin.Context) (models.SpendingTrendsQuery, bool) {
+	query := models.SpendingTrendsQuery{
+		Granularity: models.TrendGranularity(c.DefaultQuery("granularity", string(models.TrendGranularityMonthly))),
+	}
+
+	maxPeriods, ok := maxTrendPeriods[query.Granularity]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid granularity parameter. Must be weekly, monthly, quarterly or yearly",
+		})
+		return query, false
+	}
+
+	periodsStr := c.Query("periods")
+	if periodsStr == "" && query.Granularity == models.TrendGranularityMonthly {
+		periodsStr = c.Query("months")
+	}
+	if periodsStr == "" {
+		periodsStr = "12"
+	}
+	periods, err := strconv.Atoi(periodsStr)
+	if err != nil || periods < 1 || periods > maxPeriods {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid periods parameter. Must be between 1 and " + strconv.Itoa(maxPeriods) + " for " + string(query.Granularity) + " trends",
+		})
+		return query, false
+	}
+	query.Periods = periods
+
+	seen := make(map[string]bool)
+	for _, value := range c.QueryArray("category_id") {
+		for _, id := range strings.Split(value, ",") {
+			id = strings.TrimSpace(id)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				query.CategoryIDs = append(query.CategoryIDs, id)
+			}
+		}
+	}
+	if len(query.CategoryIDs) > maxTrendCategories {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid months parameter. Must be between 1 and 60",
+			"error": "Too many categories. At most " + strconv.Itoa(maxTrendCategories) + " are allowed",
 		})
+		return query, false
+	}
+
+	if includeIncome := c.Query("include_income"); includeIncome != "" {
+		query.IncludeIncome, err = strconv.ParseBool(includeIncome)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid include_income parameter. Must be true or false",
+			})
+			return query, false
+		}
+	}
+
+	now := time.Now().UTC()
+	query.EndDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		query.EndDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			})
+			return query, false
+		}
+	}
+
+	return query, true
+}
+
+// GetCashFlow handles GET /api/reports/cash-flow
+func (h *ReportsHandler) GetCashFlow(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	startDate, endDate, ok := parseReportDateRange(c)
+	if !ok {
 		return
 	}
 
-	var categoryPtr *string
-	if categoryID != "" {
-		categoryPtr = &categoryID
+	h.respondCached(c, "cash-flow", reportRangeParams(startDate, endDate), func() interface{} {
+		// Get cash flow data
+		cashFlow, err := h.dbService.Repositories.GetCashFlow(c.Request.Context(), userID, startDate, endDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate cash flow report",
+				"details": err.Error(),
+			})
+			return nil
+		}
+		return cashFlow
+	})
+}
+
+// GetCashFlowForecast handles GET /api/reports/cash-flow-forecast. It
+// projects daily balances for the next ?days (30 to 365, default 90) from
+// scheduled transactions, recurring transactions detected in the last year
+// and average discretionary spend, optionally for a single ?account_id.
+func (h *ReportsHandler) GetCashFlowForecast(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days < services.MinForecastDays || days > services.MaxForecastDays {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid days parameter. Must be between 30 and 365",
+		})
+		return
 	}
 
-	// Get spending trends
-	trends, err := h.dbService.Repositories.GetSpendingTrends(c.Request.Context(), userID, categoryPtr, months)
+	accounts, err := h.dbService.Repositories.GetActiveAccountsByUserID(ctx, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to generate spending trends",
+			"error":   "Failed to get accounts",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, trends)
-}
+	if accountID := c.Query("account_id"); accountID != "" {
+		account, err := h.dbService.Repositories.GetAccountByID(ctx, accountID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Account not found",
+			})
+			return
+		}
+		if account.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return
+		}
+		accounts = []models.Account{*account}
+	}
 
-// GetCashFlow handles GET /api/reports/cash-flow
-func (h *ReportsHandler) GetCashFlow(c *gin.Context) {
-	userID := middleware.MustGetUserID(c)
+	today := time.Now().UTC()
+	history, err := h.dbService.Repositories.GetTransactionsByDateRange(ctx, userID, today.AddDate(0, 0, -services.RecurringHistoryDays), today)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get transaction history",
+			"details": err.Error(),
+		})
+		return
+	}
 
-	// Parse query parameters
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
+	scheduled, err := h.dbService.Repositories.GetScheduledTransactionsByUserID(ctx, userID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get scheduled transactions",
+			"details": err.Error(),
+		})
+		return
+	}
 
-	// Default to current month if no dates provided
-	now := time.Now()
-	defaultStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-	defaultEnd := defaultStart.AddDate(0, 1, 0).Add(-time.Second)
+	forecast := services.ForecastCashFlow(services.ForecastInput{
+		Accounts:  accounts,
+		History:   history,
+		Scheduled: scheduled,
+		Today:     today,
+		Days:      days,
+	})
+	forecast.UserID = userID
 
-	var startDate, endDate time.Time
-	var err error
+	c.JSON(http.StatusOK, forecast)
+}
 
-	if startDateStr != "" {
-		startDate, err = time.Parse("2006-01-02", startDateStr)
+// GetPeriodComparison handles GET /api/reports/comparison. Either pass a
+// ?preset (month, month_yoy, quarter, quarter_yoy, ytd or year) for the
+// period containing ?date (default today), or give both periods explicitly
+// with current_start, current_end, previous_start and previous_end.
+// ?movers sets how many top movers to list (1 to 20, default 5).
+func (h *ReportsHandler) GetPeriodComparison(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	movers, err := strconv.Atoi(c.DefaultQuery("movers", strconv.Itoa(services.DefaultComparisonMovers)))
+	if err != nil || movers < 1 || movers > 20 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid movers parameter. Must be between 1 and 20",
+		})
+		return
+	}
+
+	var current, previous models.ReportPeriod
+	if preset := c.Query("preset"); preset != "" {
+		anchor := time.Now().UTC()
+		if raw := c.Query("date"); raw != "" {
+			anchor, err = time.Parse("2006-01-02", raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid date format. Use YYYY-MM-DD",
+				})
+				return
+			}
+		}
+		current, previous, err = services.ResolveComparisonPeriods(preset, anchor)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Invalid start_date format. Use YYYY-MM-DD",
+				"error":   "Invalid preset. Must be month, month_yoy, quarter, quarter_yoy, ytd or year",
+				"details": err.Error(),
 			})
 			return
 		}
 	} else {
-		startDate = defaultStart
+		var ok bool
+		if current, ok = parseComparisonPeriod(c, "current"); !ok {
+			return
+		}
+		if previous, ok = parseComparisonPeriod(c, "previous"); !ok {
+			return
+		}
 	}
 
-	if endDateStr != "" {
-		endDate, err = time.Parse("2006-01-02", endDateStr)
+	h.respondCached(c, "comparison", fmt.Sprintf("%+v %+v %d", current, previous, movers), func() interface{} {
+		currentTotals, err := h.dbService.Repositories.GetCategoryTotals(ctx, userID, current.StartDate, current.EndDate)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate comparison report",
+				"details": err.Error(),
 			})
-			return
+			return nil
 		}
-	} else {
-		endDate = defaultEnd
+		previousTotals, err := h.dbService.Repositories.GetCategoryTotals(ctx, userID, previous.StartDate, previous.EndDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate comparison report",
+				"details": err.Error(),
+			})
+			return nil
+		}
+
+		comparison := services.ComparePeriods(current, previous, currentTotals, previousTotals, movers)
+		comparison.UserID = userID
+		return comparison
+	})
+}
+
+// parseComparisonPeriod reads the <name>_start and <name>_end parameters of
+// an explicit comparison period, writing the error response if they are
+// missing or invalid.
+func parseComparisonPeriod(c *gin.Context, name string) (models.ReportPeriod, bool) {
+	startRaw, endRaw := c.Query(name+"_start"), c.Query(name+"_end")
+	if startRaw == "" || endRaw == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Provide a preset, or current_start, current_end, previous_start and previous_end",
+		})
+		return models.ReportPeriod{}, false
+	}
+
+	startDate, err := time.Parse("2006-01-02", startRaw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid " + name + "_start format. Use YYYY-MM-DD",
+		})
+		return models.ReportPeriod{}, false
+	}
+	endDate, err := time.Parse("2006-01-02", endRaw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid " + name + "_end format. Use YYYY-MM-DD",
+		})
+		return models.ReportPeriod{}, false
 	}
 
-	// Validate date range
 	if endDate.Before(startDate) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "end_date must be after start_date",
+			"error": name + "_end must not be before " + name + "_start",
 		})
+		return models.ReportPeriod{}, false
+	}
+	if endDate.Sub(startDate) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Each period cannot exceed 366 days",
+		})
+		return models.ReportPeriod{}, false
+	}
+
+	return models.ReportPeriod{
+		Label:     startRaw + " to " + endRaw,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}, true
+}
+
+// GetMerchantSpending handles GET /api/reports/merchant-spending
+func (h *ReportsHandler) GetMerchantSpending(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	startDate, endDate, ok := parseReportDateRange(c)
+	if !ok {
 		return
 	}
 
-	// Limit the date range to prevent excessive queries
-	if endDate.Sub(startDate) > 365*24*time.Hour {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Date range cannot exceed 365 days",
+			"error": "Invalid limit parameter. Must be between 1 and 100",
 		})
 		return
 	}
 
-	// Get cash flow data
-	cashFlow, err := h.dbService.Repositories.GetCashFlow(c.Request.Context(), userID, startDate, endDate)
+	spending, err := h.dbService.Repositories.GetMerchantSpending(c.Request.Context(), userID, startDate, endDate, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to generate cash flow report",
+			"error":   "Failed to generate merchant spending report",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, cashFlow)
+	c.JSON(http.StatusOK, spending)
 }
 
 // GetReportSummary handles GET /api/reports/summary
@@ -171,48 +519,53 @@ func (h *ReportsHandler) GetReportSummary(c *gin.Context) {
 	currentMonth := int(now.Month())
 	currentYear := now.Year()
 
-	monthlySummary, err := h.dbService.Repositories.GetMonthlySummary(c.Request.Context(), userID, currentMonth, currentYear)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to generate report summary",
-			"details": err.Error(),
-		})
-		return
-	}
+	h.respondCached(c, "summary", now.Format("2006-01-02"), func() interface{} {
+		monthlySummary, err := h.dbService.Repositories.GetMonthlySummary(c.Request.Context(), userID, currentMonth, currentYear)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate report summary",
+				"details": err.Error(),
+			})
+			return nil
+		}
 
-	// Get spending trends for last 6 months
-	trends, err := h.dbService.Repositories.GetSpendingTrends(c.Request.Context(), userID, nil, 6)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get spending trends",
-			"details": err.Error(),
+		// Get spending trends for last 6 months
+		trends, err := h.dbService.Repositories.GetSpendingTrends(c.Request.Context(), userID, models.SpendingTrendsQuery{
+			Granularity: models.TrendGranularityMonthly,
+			Periods:     6,
+			EndDate:     now,
 		})
-		return
-	}
-
-	// Get cash flow for current month
-	startOfMonth := time.Date(currentYear, time.Month(currentMonth), 1, 0, 0, 0, 0, time.UTC)
-	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to get spending trends",
+				"details": err.Error(),
+			})
+			return nil
+		}
 
-	cashFlow, err := h.dbService.Repositories.GetCashFlow(c.Request.Context(), userID, startOfMonth, endOfMonth)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get cash flow",
-			"details": err.Error(),
-		})
-		return
-	}
+		// Get cash flow for current month
+		startOfMonth := time.Date(currentYear, time.Month(currentMonth), 1, 0, 0, 0, 0, time.UTC)
+		endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
 
-	// Create summary response
-	summary := gin.H{
-		"user_id":         userID,
-		"generated_at":    time.Now(),
-		"current_month":   monthlySummary,
-		"spending_trends": trends,
-		"cash_flow":       cashFlow,
-	}
+		cashFlow, err := h.dbService.Repositories.GetCashFlow(c.Request.Context(), userID, startOfMonth, endOfMonth)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to get cash flow",
+				"details": err.Error(),
+			})
+			return nil
+		}
 
-	c.JSON(http.StatusOK, summary)
+		// Create summary response
+		summary := gin.H{
+			"user_id":         userID,
+			"generated_at":    time.Now(),
+			"current_month":   monthlySummary,
+			"spending_trends": trends,
+			"cash_flow":       cashFlow,
+		}
+		return summary
+	})
 }
 
 // GetBudgetPerformance handles GET /api/reports/budget-performance
@@ -239,99 +592,426 @@ func (h *ReportsHandler) GetBudgetPerformance(c *gin.Context) {
 		return
 	}
 
-	// Calculate date range for the month
-	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, 0).Add(-time.Second)
+	// Budgets are measured over their own periods as of today, or as of the
+	// nearest day of the requested month when it is not the current one, so
+	// the results change daily and today is part of the cache key
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	asOf := today
+	if asOf.Before(monthStart) {
+		asOf = monthStart
+	} else if asOf.After(monthEnd) {
+		asOf = monthEnd
+	}
+
+	params := fmt.Sprintf("%d-%02d:%s", year, month, today.Format("2006-01-02"))
+	h.respondCached(c, "budget-performance", params, func() interface{} {
+		// Get all active budgets
+		budgets, err := h.dbService.Repositories.GetBudgetsByUserID(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to get budgets",
+				"details": err.Error(),
+			})
+			return nil
+		}
+
+		// Calculate performance for each budget over its period containing
+		// asOf, with split lines counted against their own categories and the
+		// status from each budget's own alert thresholds
+		type window struct{ start, end time.Time }
+		spending := make(map[window]map[string]float64)
+		var budgetPerformance []gin.H
+		budgetsCount := 0
+		totalBudgeted := 0.0
+		totalSpent := 0.0
+		overBudgetCount := 0
+
+		for _, budget := range budgets {
+			periodStart, periodEnd, ok := services.BudgetPeriodBounds(budget, asOf)
+			if !ok {
+				continue
+			}
+			// Spending is counted up to today; budgets sharing a period share
+			// one spending query
+			w := window{periodStart, periodEnd}
+			if _, ok := spending[w]; !ok {
+				spentThrough := periodEnd
+				if today.Before(spentThrough) {
+					spentThrough = today
+				}
+				spending[w], err = h.dbService.Repositories.GetPeriodCategorySpending(c.Request.Context(), userID, periodStart, spentThrough)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{
+						"error":   "Failed to get category spending",
+						"details": err.Error(),
+					})
+					return nil
+				}
+			}
+
+			budgetsCount++
+			categorySpending := spending[w][budget.CategoryID]
+			evaluation := services.EvaluateBudget(budget, categorySpending, periodStart, periodEnd, today)
+			isOverBudget := evaluation.Status == models.BudgetStatusOverBudget
+
+			if isOverBudget {
+				overBudgetCount++
+			}
+
+			totalBudgeted += budget.Amount
+			totalSpent += categorySpending
+
+			budgetPerformance = append(budgetPerformance, gin.H{
+				"budget_id":             budget.ID,
+				"budget_name":           budget.Name,
+				"category_id":           budget.CategoryID,
+				"budgeted_amount":       budget.Amount,
+				"spent_amount":          categorySpending,
+				"remaining":             budget.Amount - categorySpending,
+				"percentage_used":       (categorySpending / budget.Amount) * 100,
+				"status":                evaluation.Status,
+				"is_over_budget":        isOverBudget,
+				"period":                budget.Period,
+				"period_start":          periodStart.Format("2006-01-02"),
+				"period_end":            periodEnd.Format("2006-01-02"),
+				"alert_thresholds":      budget.AlertThresholds,
+				"threshold_reached":     evaluation.ThresholdReached,
+				"projected_amount":      evaluation.ProjectedAmount,
+				"projected_exceed_date": evaluation.ProjectedExceedDate,
+			})
+		}
+
+		// Calculate overall performance
+		overallPerformance := gin.H{
+			"total_budgeted":     totalBudgeted,
+			"total_spent":        totalSpent,
+			"total_remaining":    totalBudgeted - totalSpent,
+			"overall_percentage": (totalSpent / totalBudgeted) * 100,
+			"budgets_count":      budgetsCount,
+			"over_budget_count":  overBudgetCount,
+			"on_track_count":     budgetsCount - overBudgetCount,
+		}
 
-	// Get all active budgets
-	budgets, err := h.dbService.Repositories.GetBudgetsByUserID(c.Request.Context(), userID)
+		response := gin.H{
+			"user_id":             userID,
+			"month":               month,
+			"year":                year,
+			"budget_performance":  budgetPerformance,
+			"overall_performance": overallPerformance,
+			"generated_at":        time.Now(),
+		}
+		return response
+	})
+}
+
+// GetNetWorth handles GET /api/reports/net-worth. It returns the daily
+// snapshots between from and to (YYYY-MM-DD, default the last 90 days) along
+// with the net worth computed from current balances.
+func (h *ReportsHandler) GetNetWorth(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	now := time.Now().UTC()
+
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid to format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -90)
+	if raw := c.Query("from"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "to must not be before from",
+		})
+		return
+	}
+	if to.Sub(from) > 5*366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Date range cannot exceed 5 years",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	current, err := services.NewNetWorthService(h.dbService.Repositories).Current(ctx, userID, now)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get budgets",
+			"error":   "Failed to compute net worth",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	// Calculate performance for each budget
-	var budgetPerformance []gin.H
-	totalBudgeted := 0.0
-	totalSpent := 0.0
-	overBudgetCount := 0
+	snapshots, err := h.dbService.Repositories.GetNetWorthSnapshots(ctx, userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get net worth history",
+			"details": err.Error(),
+		})
+		return
+	}
+	if snapshots == nil {
+		snapshots = []models.NetWorthSnapshot{}
+	}
+
+	change, changePercent := services.NetWorthChange(snapshots)
+	c.JSON(http.StatusOK, models.NetWorthHistory{
+		UserID:        userID,
+		From:          from,
+		To:            to,
+		Current:       current,
+		Snapshots:     snapshots,
+		Change:        change,
+		ChangePercent: changePercent,
+		GeneratedAt:   time.Now(),
+	})
+}
+
+// RecordNetWorthSnapshot handles POST /api/reports/net-worth/snapshot,
+// recording today's snapshot now instead of waiting for the daily job.
+func (h *ReportsHandler) RecordNetWorthSnapshot(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	snapshot, err := services.NewNetWorthService(h.dbService.Repositories).Snapshot(c.Request.Context(), userID, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to record net worth snapshot",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, snapshot)
+}
+
+// GetIncomeStatement handles GET /api/reports/income-statement, listing
+// revenue and expenses by category hierarchy for start_date to end_date.
+func (h *ReportsHandler) GetIncomeStatement(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	startDate, endDate, ok := parseReportDateRange(c)
+	if !ok {
+		return
+	}
+	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
 
-	for _, budget := range budgets {
-		// Get transactions for this budget's category in the date range
-		transactions, err := h.dbService.Repositories.GetTransactionsByDateRange(c.Request.Context(), userID, startDate, endDate)
+	h.respondCached(c, "income-statement", reportRangeParams(startDate, endDate), func() interface{} {
+		statement, err := services.NewFinancialStatementsService(h.dbService.Repositories).IncomeStatement(c.Request.Context(), userID, startDate, endDate)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Failed to get transactions",
+				"error":   "Failed to generate income statement",
 				"details": err.Error(),
 			})
+			return nil
+		}
+		return statement
+	})
+}
+
+// GetBalanceSheet handles GET /api/reports/balance-sheet, reporting assets,
+// liabilities and equity by account type at the end of as_of (YYYY-MM-DD,
+// default today).
+func (h *ReportsHandler) GetBalanceSheet(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	today := time.Now().UTC()
+	asOf := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		parsed, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid as_of format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		if parsed.After(asOf) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "as_of cannot be in the future",
+			})
 			return
 		}
+		asOf = parsed
+	}
 
-		// Calculate spending for this category
-		categorySpending := 0.0
-		for _, transaction := range transactions {
-			if transaction.CategoryID != nil && *transaction.CategoryID == budget.CategoryID && transaction.TransactionType == "expense" {
-				categorySpending += transaction.Amount
-			}
+	h.respondCached(c, "balance-sheet", asOf.Format("2006-01-02"), func() interface{} {
+		sheet, err := services.NewFinancialStatementsService(h.dbService.Repositories).BalanceSheet(c.Request.Context(), userID, asOf)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate balance sheet",
+				"details": err.Error(),
+			})
+			return nil
 		}
+		return sheet
+	})
+}
 
-		// Calculate performance metrics
-		budgetAmount := budget.Amount
-		remaining := budgetAmount - categorySpending
-		percentageUsed := (categorySpending / budgetAmount) * 100
-		isOverBudget := categorySpending > budgetAmount
+// PivotRequest represents the request body of a pivot query. Dates are
+// YYYY-MM-DD and default to the current month.
+type PivotRequest struct {
+	Dimensions      []models.PivotDimension `json:"dimensions" binding:"required"`
+	Measures        []models.PivotMeasure   `json:"measures"`
+	StartDate       string                  `json:"start_date"`
+	EndDate         string                  `json:"end_date"`
+	TransactionType models.TransactionType  `json:"transaction_type"`
+	AccountIDs      []string                `json:"account_ids"`
+	CategoryIDs     []string                `json:"category_ids"`
+	MerchantIDs     []string                `json:"merchant_ids"`
+	Tags            []string                `json:"tags"`
+	MinAmount       *float64                `json:"min_amount"`
+	MaxAmount       *float64                `json:"max_amount"`
+	Limit           int                     `json:"limit"`
+}
 
-		if isOverBudget {
-			overBudgetCount++
-		}
+// RunPivotQuery handles POST /api/reports/pivot, aggregating the user's
+// transaction lines by the requested dimensions and measures.
+func (h *ReportsHandler) RunPivotQuery(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req PivotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
 
-		totalBudgeted += budgetAmount
-		totalSpent += categorySpending
+	now := time.Now()
+	query := models.PivotQuery{
+		Dimensions: req.Dimensions,
+		Measures:   req.Measures,
+		Limit:      req.Limit,
+		Filter: models.PivotFilter{
+			StartDate:       time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+			TransactionType: req.TransactionType,
+			AccountIDs:      req.AccountIDs,
+			CategoryIDs:     req.CategoryIDs,
+			MerchantIDs:     req.MerchantIDs,
+			Tags:            req.Tags,
+			MinAmount:       req.MinAmount,
+			MaxAmount:       req.MaxAmount,
+		},
+	}
+	query.Filter.EndDate = query.Filter.StartDate.AddDate(0, 1, -1)
 
-		// Determine status
-		status := "under_budget"
-		if isOverBudget {
-			status = "over_budget"
-		} else if percentageUsed >= 80 {
-			status = "at_risk"
+	var err error
+	if req.StartDate != "" {
+		if query.Filter.StartDate, err = time.Parse("2006-01-02", req.StartDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid start_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+	}
+	if req.EndDate != "" {
+		if query.Filter.EndDate, err = time.Parse("2006-01-02", req.EndDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			})
+			return
 		}
+	}
 
-		budgetPerformance = append(budgetPerformance, gin.H{
-			"budget_id":       budget.ID,
-			"budget_name":     budget.Name,
-			"category_id":     budget.CategoryID,
-			"budgeted_amount": budgetAmount,
-			"spent_amount":    categorySpending,
-			"remaining":       remaining,
-			"percentage_used": percentageUsed,
-			"status":          status,
-			"is_over_budget":  isOverBudget,
-			"period":          budget.Period,
+	if err := services.NormalizePivotQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid pivot query",
+			"details": err.Error(),
 		})
+		return
 	}
 
-	// Calculate overall performance
-	overallPerformance := gin.H{
-		"total_budgeted":     totalBudgeted,
-		"total_spent":        totalSpent,
-		"total_remaining":    totalBudgeted - totalSpent,
-		"overall_percentage": (totalSpent / totalBudgeted) * 100,
-		"budgets_count":      len(budgets),
-		"over_budget_count":  overBudgetCount,
-		"on_track_count":     len(budgets) - overBudgetCount,
+	result, err := h.dbService.Repositories.RunPivotQuery(c.Request.Context(), userID, query)
+	if errors.Is(err, repositories.ErrQueryTimeout) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Pivot query took too long. Narrow the date range or add filters",
+		})
+		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to run pivot query",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseReportDateRange reads start_date and end_date (YYYY-MM-DD), defaulting
+// to the current month and capping the range at 365 days. It writes the error
+// response and returns false when the parameters are invalid.
+func parseReportDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+
+	// Default to current month if no dates provided
+	now := time.Now()
+	defaultStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	defaultEnd := defaultStart.AddDate(0, 1, 0).Add(-time.Second)
 
-	response := gin.H{
-		"user_id":             userID,
-		"month":               month,
-		"year":                year,
-		"budget_performance":  budgetPerformance,
-		"overall_performance": overallPerformance,
-		"generated_at":        time.Now(),
+	var startDate, endDate time.Time
+	var err error
+
+	if startDateStr != "" {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid start_date format. Use YYYY-MM-DD",
+			})
+			return time.Time{}, time.Time{}, false
+		}
+	} else {
+		startDate = defaultStart
 	}
 
-	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+	if endDateStr != "" {
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			})
+			return time.Time{}, time.Time{}, false
+		}
+	} else {
+		endDate = defaultEnd
+	}
+
+	// Validate date range
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "end_date must be after start_date",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+
+	// Limit the date range to prevent excessive queries
+	if endDate.Sub(startDate) > 365*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Date range cannot exceed 365 days",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+
+	return startDate, endDate, true
+}
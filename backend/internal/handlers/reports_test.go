@@ -175,4 +175,50 @@ func TestGetCashFlow_InvalidDateRange(t *testing.T) {
 	
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "end_date must be after start_date")
-}
\ No newline at end of file
+}
+
+func TestParseSpendingTrendsQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var query models.SpendingTrendsQuery
+	router := gin.New()
+	router.GET("/spending-trends", func(c *gin.Context) {
+		var ok bool
+		if query, ok = parseSpendingTrendsQuery(c); ok {
+			c.JSON(http.StatusOK, gin.H{"message": "success"})
+		}
+	})
+
+	request := func(rawQuery string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/spending-trends?"+rawQuery, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("granularity=weekly&periods=8&category_id=a,b&category_id=a&category_id=c&include_income=true&end_date=2026-03-04")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.TrendGranularityWeekly, query.Granularity)
+	assert.Equal(t, 8, query.Periods)
+	assert.Equal(t, []string{"a", "b", "c"}, query.CategoryIDs)
+	assert.True(t, query.IncludeIncome)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), query.EndDate)
+
+	// months is still honoured for monthly trends
+	w = request("months=6")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.TrendGranularityMonthly, query.Granularity)
+	assert.Equal(t, 6, query.Periods)
+	assert.False(t, query.IncludeIncome)
+
+	w = request("granularity=daily")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid granularity parameter")
+
+	w = request("granularity=yearly&periods=21")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "between 1 and 20 for yearly trends")
+
+	w = request("include_income=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
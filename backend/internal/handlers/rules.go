@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// RulesHandler handles auto-categorization rule HTTP requests
+type RulesHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewRulesHandler creates a new rules handler
+func NewRulesHandler(dbService *services.DatabaseService) *RulesHandler {
+	return &RulesHandler{
+		dbService: dbService,
+	}
+}
+
+// CreateRuleRequest represents the request body for creating a rule
+type CreateRuleRequest struct {
+	Name           string                `json:"name" binding:"required"`
+	Priority       *int                  `json:"priority"`  // Defaults to 100; lower runs first
+	IsActive       *bool                 `json:"is_active"` // Defaults to true
+	StopProcessing bool                  `json:"stop_processing"`
+	Conditions     models.RuleConditions `json:"conditions"`
+	Actions        models.RuleActions    `json:"actions"`
+}
+
+// UpdateRuleRequest represents the request body for updating a rule.
+// Conditions and Actions replace the existing ones when present.
+type UpdateRuleRequest struct {
+	Name           *string                `json:"name"`
+	Priority       *int                   `json:"priority"`
+	IsActive       *bool                  `json:"is_active"`
+	StopProcessing *bool                  `json:"stop_processing"`
+	Conditions     *models.RuleConditions `json:"conditions"`
+	Actions        *models.RuleActions    `json:"actions"`
+}
+
+// ApplyRulesRequest represents the request body for running rules over existing transactions
+type ApplyRulesRequest struct {
+	StartDate *string  `json:"start_date"` // ISO date string; defaults to all history
+	EndDate   *string  `json:"end_date"`   // ISO date string; defaults to today
+	RuleIDs   []string `json:"rule_ids"`   // Restrict to these rules; defaults to all active rules
+	Overwrite bool     `json:"overwrite"`  // Replace categories and notes that are already set
+	DryRun    *bool    `json:"dry_run"`    // Defaults to true
+}
+
+// GetRules handles GET /api/rules
+func (h *RulesHandler) GetRules(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	rules, err := h.dbService.Repositories.GetRulesByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get rules",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+// GetRule handles GET /api/rules/:id
+func (h *RulesHandler) GetRule(c *gin.Context) {
+	rule, ok := h.loadOwnedRule(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRule handles POST /api/rules
+func (h *RulesHandler) CreateRule(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule := &models.CategorizationRule{
+		UserID:         userID,
+		Name:           req.Name,
+		Priority:       100,
+		IsActive:       true,
+		StopProcessing: req.StopProcessing,
+		Conditions:     req.Conditions,
+		Actions:        req.Actions,
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if !validateRule(c, rule) || !h.checkRuleReferences(c, rule) {
+		return
+	}
+
+	err := h.dbService.Repositories.CreateRule(c.Request.Context(), rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule handles PUT /api/rules/:id
+func (h *RulesHandler) UpdateRule(c *gin.Context) {
+	var req UpdateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, ok := h.loadOwnedRule(c)
+	if !ok {
+		return
+	}
+
+	// Update fields if provided
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if req.StopProcessing != nil {
+		rule.StopProcessing = *req.StopProcessing
+	}
+	if req.Conditions != nil {
+		rule.Conditions = *req.Conditions
+	}
+	if req.Actions != nil {
+		rule.Actions = *req.Actions
+	}
+
+	if !validateRule(c, rule) || !h.checkRuleReferences(c, rule) {
+		return
+	}
+
+	err := h.dbService.Repositories.UpdateRule(c.Request.Context(), rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule handles DELETE /api/rules/:id
+func (h *RulesHandler) DeleteRule(c *gin.Context) {
+	rule, ok := h.loadOwnedRule(c)
+	if !ok {
+		return
+	}
+
+	err := h.dbService.Repositories.DeleteRule(c.Request.Context(), rule.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rule deleted successfully",
+	})
+}
+
+// ApplyRules handles POST /api/rules/apply. It runs the rules over existing
+// transactions and returns the resulting changes; nothing is written unless
+// dry_run is explicitly false.
+func (h *RulesHandler) ApplyRules(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	var req ApplyRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	startDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Now()
+	if req.StartDate != nil {
+		parsed, err := time.Parse("2006-01-02", *req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid start_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		startDate = parsed
+	}
+	if req.EndDate != nil {
+		parsed, err := time.Parse("2006-01-02", *req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		endDate = parsed
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "end_date must be after start_date",
+		})
+		return
+	}
+
+	dryRun := true
+	if req.DryRun != nil {
+		dryRun = *req.DryRun
+	}
+
+	rules, err := h.dbService.Repositories.GetRulesByUserID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get rules",
+			"details": err.Error(),
+		})
+		return
+	}
+	if len(req.RuleIDs) > 0 {
+		selected := make(map[string]bool, len(req.RuleIDs))
+		for _, id := range req.RuleIDs {
+			selected[id] = true
+		}
+		filtered := rules[:0]
+		for _, rule := range rules {
+			if selected[rule.ID] {
+				filtered = append(filtered, rule)
+			}
+		}
+		rules = filtered
+	}
+
+	engine, err := services.NewRuleEngine(rules)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transactions, err := h.dbService.Repositories.GetTransactionsByDateRange(ctx, userID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	changes, updated := engine.Preview(transactions, req.Overwrite)
+
+	var applied int64
+	if !dryRun && len(updated) > 0 {
+		applied, err = h.dbService.Repositories.ApplyRuleChanges(ctx, userID, updated)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to apply rules",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":   dryRun,
+		"scanned":   len(transactions),
+		"changes":   changes,
+		"count":     len(changes),
+		"applied":   applied,
+		"overwrite": req.Overwrite,
+	})
+}
+
+// loadOwnedRule fetches the rule named by the :id parameter and verifies it
+// belongs to the authenticated user, writing the error response if not.
+func (h *RulesHandler) loadOwnedRule(c *gin.Context) (*models.CategorizationRule, bool) {
+	userID := middleware.MustGetUserID(c)
+	ruleID := c.Param("id")
+
+	rule, err := h.dbService.Repositories.GetRuleByID(c.Request.Context(), ruleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Rule not found",
+		})
+		return nil, false
+	}
+
+	// Ensure the rule belongs to the authenticated user
+	if rule.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return rule, true
+}
+
+// checkRuleReferences verifies that the accounts a rule matches on and the
+// category it assigns belong to the rule's user, writing the error response
+// if not.
+func (h *RulesHandler) checkRuleReferences(c *gin.Context, rule *models.CategorizationRule) bool {
+	ctx := c.Request.Context()
+
+	for _, accountID := range rule.Conditions.AccountIDs {
+		account, err := h.dbService.Repositories.GetAccountByID(ctx, accountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Account not found",
+			})
+			return false
+		}
+		if account.UserID != rule.UserID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return false
+		}
+	}
+
+	if rule.Actions.CategoryID != nil {
+		category, err := h.dbService.Repositories.GetCategoryByID(ctx, *rule.Actions.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Category not found",
+			})
+			return false
+		}
+		if category.UserID != rule.UserID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateRule writes the error response if the rule is malformed.
+func validateRule(c *gin.Context, rule *models.CategorizationRule) bool {
+	if err := services.ValidateRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid rule",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// ScheduledTransactionsHandler handles scheduled transaction HTTP requests
+type ScheduledTransactionsHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewScheduledTransactionsHandler creates a new scheduled transactions handler
+func NewScheduledTransactionsHandler(dbService *services.DatabaseService) *ScheduledTransactionsHandler {
+	return &ScheduledTransactionsHandler{
+		dbService: dbService,
+	}
+}
+
+// CreateScheduledTransactionRequest represents the request body for scheduling a transaction
+type CreateScheduledTransactionRequest struct {
+	AccountID       string                   `json:"account_id" binding:"required"`
+	CategoryID      *string                  `json:"category_id"`
+	Amount          float64                  `json:"amount" binding:"required,gt=0"`
+	TransactionType models.TransactionType   `json:"transaction_type" binding:"required,oneof=income expense"`
+	Description     *string                  `json:"description"`
+	Frequency       models.ScheduleFrequency `json:"frequency" binding:"required"`
+	NextDate        string                   `json:"next_date" binding:"required"` // YYYY-MM-DD
+	EndDate         *string                  `json:"end_date"`                     // YYYY-MM-DD
+}
+
+// UpdateScheduledTransactionRequest represents the request body for updating
+// a scheduled transaction. An empty end_date removes the end date.
+type UpdateScheduledTransactionRequest struct {
+	AccountID       *string                   `json:"account_id"`
+	CategoryID      *string                   `json:"category_id"`
+	Amount          *float64                  `json:"amount" binding:"omitempty,gt=0"`
+	TransactionType *models.TransactionType   `json:"transaction_type" binding:"omitempty,oneof=income expense"`
+	Description     *string                   `json:"description"`
+	Frequency       *models.ScheduleFrequency `json:"frequency"`
+	NextDate        *string                   `json:"next_date"`
+	EndDate         *string                   `json:"end_date"`
+	IsActive        *bool                     `json:"is_active"`
+}
+
+// GetScheduledTransactions handles GET /api/scheduled-transactions. Pass
+// ?active=true to leave out paused schedules.
+func (h *ScheduledTransactionsHandler) GetScheduledTransactions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	scheduled, err := h.dbService.Repositories.GetScheduledTransactionsByUserID(c.Request.Context(), userID, c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get scheduled transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scheduled_transactions": scheduled,
+	})
+}
+
+// GetScheduledTransaction handles GET /api/scheduled-transactions/:id
+func (h *ScheduledTransactionsHandler) GetScheduledTransaction(c *gin.Context) {
+	scheduled, ok := h.loadOwnedScheduledTransaction(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// CreateScheduledTransaction handles POST /api/scheduled-transactions
+func (h *ScheduledTransactionsHandler) CreateScheduledTransaction(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateScheduledTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	nextDate, err := time.Parse("2006-01-02", req.NextDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid next_date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+
+	scheduled := &models.ScheduledTransaction{
+		UserID:          userID,
+		AccountID:       req.AccountID,
+		CategoryID:      req.CategoryID,
+		Amount:          req.Amount,
+		TransactionType: req.TransactionType,
+		Description:     req.Description,
+		Frequency:       req.Frequency,
+		NextDate:        nextDate,
+		IsActive:        true,
+	}
+	if req.EndDate != nil && *req.EndDate != "" {
+		endDate, err := time.Parse("2006-01-02", *req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		scheduled.EndDate = &endDate
+	}
+
+	if !h.validateScheduledTransaction(c, scheduled) {
+		return
+	}
+
+	if err := h.dbService.Repositories.CreateScheduledTransaction(c.Request.Context(), scheduled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create scheduled transaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, scheduled)
+}
+
+// UpdateScheduledTransaction handles PUT /api/scheduled-transactions/:id
+func (h *ScheduledTransactionsHandler) UpdateScheduledTransaction(c *gin.Context) {
+	var req UpdateScheduledTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	scheduled, ok := h.loadOwnedScheduledTransaction(c)
+	if !ok {
+		return
+	}
+
+	if req.AccountID != nil {
+		scheduled.AccountID = *req.AccountID
+	}
+	if req.CategoryID != nil {
+		scheduled.CategoryID = req.CategoryID
+	}
+	if req.Amount != nil {
+		scheduled.Amount = *req.Amount
+	}
+	if req.TransactionType != nil {
+		scheduled.TransactionType = *req.TransactionType
+	}
+	if req.Description != nil {
+		scheduled.Description = req.Description
+	}
+	if req.Frequency != nil {
+		scheduled.Frequency = *req.Frequency
+	}
+	if req.NextDate != nil {
+		nextDate, err := time.Parse("2006-01-02", *req.NextDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid next_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		scheduled.NextDate = nextDate
+	}
+	if req.EndDate != nil {
+		scheduled.EndDate = nil
+		if *req.EndDate != "" {
+			endDate, err := time.Parse("2006-01-02", *req.EndDate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid end_date format. Use YYYY-MM-DD",
+				})
+				return
+			}
+			scheduled.EndDate = &endDate
+		}
+	}
+	if req.IsActive != nil {
+		scheduled.IsActive = *req.IsActive
+	}
+
+	if !h.validateScheduledTransaction(c, scheduled) {
+		return
+	}
+
+	if err := h.dbService.Repositories.UpdateScheduledTransaction(c.Request.Context(), scheduled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update scheduled transaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// DeleteScheduledTransaction handles DELETE /api/scheduled-transactions/:id
+func (h *ScheduledTransactionsHandler) DeleteScheduledTransaction(c *gin.Context) {
+	scheduled, ok := h.loadOwnedScheduledTransaction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.dbService.Repositories.DeleteScheduledTransaction(c.Request.Context(), scheduled.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete scheduled transaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scheduled transaction deleted successfully",
+	})
+}
+
+// validateScheduledTransaction checks the frequency, the date range and that
+// the account and category belong to the user, writing the error response if
+// not.
+func (h *ScheduledTransactionsHandler) validateScheduledTransaction(c *gin.Context, scheduled *models.ScheduledTransaction) bool {
+	ctx := c.Request.Context()
+
+	if !services.ValidScheduleFrequency(scheduled.Frequency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid frequency. Must be once, weekly, biweekly, monthly, quarterly or yearly",
+		})
+		return false
+	}
+	if scheduled.EndDate != nil && scheduled.EndDate.Before(scheduled.NextDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "end_date must not be before next_date",
+		})
+		return false
+	}
+
+	account, err := h.dbService.Repositories.GetAccountByID(ctx, scheduled.AccountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Account not found",
+		})
+		return false
+	}
+	if account.UserID != scheduled.UserID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return false
+	}
+
+	if scheduled.CategoryID != nil {
+		category, err := h.dbService.Repositories.GetCategoryByID(ctx, *scheduled.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Category not found",
+			})
+			return false
+		}
+		if category.UserID != scheduled.UserID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return false
+		}
+	}
+
+	return true
+}
+
+// loadOwnedScheduledTransaction fetches the scheduled transaction named by the
+// :id parameter and verifies it belongs to the authenticated user, writing the
+// error response if not.
+func (h *ScheduledTransactionsHandler) loadOwnedScheduledTransaction(c *gin.Context) (*models.ScheduledTransaction, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	scheduled, err := h.dbService.Repositories.GetScheduledTransactionByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Scheduled transaction not found",
+		})
+		return nil, false
+	}
+
+	if scheduled.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return scheduled, true
+}
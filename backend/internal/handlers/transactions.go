@@ -0,0 +1,864 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// TransactionsHandler handles transaction-related HTTP requests
+type TransactionsHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewTransactionsHandler creates a new transactions handler
+func NewTransactionsHandler(dbService *services.DatabaseService) *TransactionsHandler {
+	return &TransactionsHandler{
+		dbService: dbService,
+	}
+}
+
+// TransactionSplitRequest represents a single split line in a transaction request
+type TransactionSplitRequest struct {
+	CategoryID  *string `json:"category_id"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Description *string `json:"description"`
+}
+
+// CreateTransactionRequest represents the request body for creating a transaction
+type CreateTransactionRequest struct {
+	AccountID       string                    `json:"account_id" binding:"required"`
+	CategoryID      *string                   `json:"category_id"`
+	Amount          float64                   `json:"amount" binding:"required,gt=0"`
+	TransactionType models.TransactionType    `json:"transaction_type" binding:"required,oneof=income expense"`
+	Description     *string                   `json:"description"`
+	TransactionDate string                    `json:"transaction_date" binding:"required"` // ISO date string
+	Notes           *string                   `json:"notes"`
+	Tags            []string                  `json:"tags"`
+	CustomFields    map[string]interface{}    `json:"custom_fields"` // Keyed by custom field definition ID
+	Splits          []TransactionSplitRequest `json:"splits" binding:"dive"`
+}
+
+// UpdateTransactionRequest represents the request body for updating a transaction.
+// Splits and Tags replace the existing ones when present; an empty list removes them.
+// CustomFields is merged into the existing values, and a null value removes a field.
+type UpdateTransactionRequest struct {
+	AccountID       *string                    `json:"account_id"`
+	CategoryID      *string                    `json:"category_id"`
+	Amount          *float64                   `json:"amount"`
+	TransactionType *models.TransactionType    `json:"transaction_type"`
+	Description     *string                    `json:"description"`
+	TransactionDate *string                    `json:"transaction_date"` // ISO date string
+	Notes           *string                    `json:"notes"`
+	MerchantID      *string                    `json:"merchant_id"` // Overrides the resolved merchant; "" clears it
+	Tags            *[]string                  `json:"tags"`
+	CustomFields    map[string]interface{}     `json:"custom_fields"`
+	Splits          *[]TransactionSplitRequest `json:"splits"`
+}
+
+// MergeTransactionsRequest represents the request body for merging a duplicate
+// transaction into the one being kept
+type MergeTransactionsRequest struct {
+	KeepID      string `json:"keep_id" binding:"required"`
+	DuplicateID string `json:"duplicate_id" binding:"required"`
+}
+
+// GetTransactions handles GET /api/transactions
+func (h *TransactionsHandler) GetTransactions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid limit parameter. Must be between 1 and 500",
+		})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid offset parameter. Must be 0 or greater",
+		})
+		return
+	}
+
+	tagFilter, ok := parseTagFilter(c)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.dbService.Repositories.GetTransactionsByUserID(c.Request.Context(), userID, tagFilter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": transactions,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
+
+// GetTransaction handles GET /api/transactions/:id
+func (h *TransactionsHandler) GetTransaction(c *gin.Context) {
+	transaction, ok := h.loadOwnedTransaction(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// SearchTransactions handles GET /api/transactions/search. The q parameter
+// uses the search language described by services.ParseSearchQuery; results
+// are ranked by relevance and paged with the opaque next_cursor.
+func (h *TransactionsHandler) SearchTransactions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	search, err := services.ParseSearchQuery(c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid search query",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid limit parameter. Must be between 1 and 100",
+		})
+		return
+	}
+
+	var cursor *models.SearchCursor
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err = services.DecodeSearchCursor(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid cursor parameter",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	hits, err := h.dbService.Repositories.SearchTransactions(c.Request.Context(), userID, search, cursor, limit+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to search transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var nextCursor *string
+	if len(hits) > limit {
+		hits = hits[:limit]
+		last := hits[len(hits)-1]
+		encoded := services.EncodeSearchCursor(models.SearchCursor{
+			Rank:            last.Rank,
+			TransactionDate: last.Transaction.TransactionDate,
+			ID:              last.Transaction.ID,
+		})
+		nextCursor = &encoded
+	}
+	if hits == nil {
+		hits = []models.SearchHit{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":     hits,
+		"count":       len(hits),
+		"next_cursor": nextCursor,
+		"query":       search,
+	})
+}
+
+// CreateTransaction handles POST /api/transactions
+func (h *TransactionsHandler) CreateTransaction(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transactionDate, err := time.Parse("2006-01-02", req.TransactionDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid transaction_date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+
+	if !h.checkAccountOwnership(c, userID, req.AccountID) {
+		return
+	}
+
+	transaction := &models.Transaction{
+		UserID:          userID,
+		AccountID:       req.AccountID,
+		CategoryID:      req.CategoryID,
+		Amount:          req.Amount,
+		TransactionType: req.TransactionType,
+		Description:     req.Description,
+		TransactionDate: transactionDate,
+		Notes:           req.Notes,
+		Splits:          splitsFromRequest(req.Splits),
+	}
+
+	if !validateTransactionSplits(c, transaction) || !h.checkCategoryOwnership(c, userID, transaction) {
+		return
+	}
+
+	if !setTransactionTags(c, transaction, req.Tags) {
+		return
+	}
+
+	if !h.applyCustomFields(c, transaction, req.CustomFields) {
+		return
+	}
+
+	h.applyRules(c, transaction)
+	h.assignMerchant(c, transaction)
+
+	err = h.dbService.Repositories.CreateTransaction(c.Request.Context(), transaction)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create transaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// UpdateTransaction handles PUT /api/transactions/:id
+func (h *TransactionsHandler) UpdateTransaction(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req UpdateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transaction, ok := h.loadOwnedTransaction(c)
+	if !ok {
+		return
+	}
+
+	// Transfer legs are edited as a pair so they cannot drift apart
+	if transaction.TransferID != nil || transaction.TransactionType == models.TransactionTypeTransfer {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Transfers must be edited through /api/transfers",
+		})
+		return
+	}
+
+	original := *transaction
+
+	// Update fields if provided
+	if req.AccountID != nil && *req.AccountID != transaction.AccountID {
+		if !h.checkAccountOwnership(c, userID, *req.AccountID) {
+			return
+		}
+		transaction.AccountID = *req.AccountID
+	}
+	if req.CategoryID != nil {
+		if *req.CategoryID == "" {
+			transaction.CategoryID = nil
+		} else {
+			transaction.CategoryID = req.CategoryID
+		}
+	}
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Amount must be greater than 0",
+			})
+			return
+		}
+		transaction.Amount = *req.Amount
+	}
+	if req.TransactionType != nil {
+		if *req.TransactionType != models.TransactionTypeIncome && *req.TransactionType != models.TransactionTypeExpense {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "transaction_type must be income or expense",
+			})
+			return
+		}
+		transaction.TransactionType = *req.TransactionType
+	}
+	if req.Description != nil {
+		transaction.Description = req.Description
+	}
+	if req.TransactionDate != nil {
+		parsed, err := time.Parse("2006-01-02", *req.TransactionDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid transaction_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		transaction.TransactionDate = parsed
+	}
+	if req.Notes != nil {
+		transaction.Notes = req.Notes
+	}
+	if req.MerchantID != nil {
+		if *req.MerchantID == "" {
+			transaction.MerchantID = nil
+		} else {
+			merchant, err := h.dbService.Repositories.GetMerchantByID(c.Request.Context(), *req.MerchantID)
+			if err != nil || merchant.UserID != userID {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Merchant not found",
+				})
+				return
+			}
+			transaction.MerchantID = &merchant.ID
+		}
+	} else if req.Description != nil {
+		h.assignMerchant(c, transaction)
+	}
+	if req.Tags != nil && !setTransactionTags(c, transaction, *req.Tags) {
+		return
+	}
+	if !h.applyCustomFields(c, transaction, req.CustomFields) {
+		return
+	}
+	if req.Splits != nil {
+		for _, split := range *req.Splits {
+			if split.Amount <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Split amounts must be greater than 0",
+				})
+				return
+			}
+		}
+		transaction.Splits = splitsFromRequest(*req.Splits)
+	}
+
+	// Locked transactions keep their financial fields; the category, notes,
+	// tags and other descriptive fields can still be edited
+	if lockedFieldsChanged(original, *transaction) && !checkUnlocked(c, transaction) {
+		return
+	}
+
+	// Existing splits are re-validated too, so changing the amount alone
+	// cannot leave the split lines out of balance
+	if !validateTransactionSplits(c, transaction) || !h.checkCategoryOwnership(c, userID, transaction) {
+		return
+	}
+
+	err := h.dbService.Repositories.UpdateTransaction(c.Request.Context(), transaction)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update transaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// DeleteTransaction handles DELETE /api/transactions/:id
+func (h *TransactionsHandler) DeleteTransaction(c *gin.Context) {
+	transaction, ok := h.loadOwnedTransaction(c)
+	if !ok {
+		return
+	}
+
+	// Deleting either leg of a transfer removes the whole transfer. A leg
+	// whose counterpart is missing is deleted on its own.
+	var transfer *models.Transfer
+	var err error
+	if transaction.TransferID != nil {
+		transfer, err = h.dbService.Repositories.GetTransferByID(c.Request.Context(), *transaction.TransferID)
+		if err != nil && !errors.Is(err, repositories.ErrTransferNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to get transfer",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+	if transfer != nil {
+		if !checkUnlocked(c, transfer.OutgoingLeg, transfer.IncomingLeg) {
+			return
+		}
+		err = h.dbService.Repositories.DeleteTransfer(c.Request.Context(), transfer.TransferID)
+	} else {
+		if !checkUnlocked(c, transaction) {
+			return
+		}
+		err = h.dbService.Repositories.DeleteTransaction(c.Request.Context(), transaction.ID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete transaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Transaction deleted successfully",
+	})
+}
+
+// GetDuplicates handles GET /api/transactions/duplicates. It scans the
+// last `days` days (default 90) for likely duplicate pairs.
+func (h *TransactionsHandler) GetDuplicates(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days < 1 || days > 730 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid days parameter. Must be between 1 and 730",
+		})
+		return
+	}
+
+	windowDays, err := strconv.Atoi(c.DefaultQuery("window_days", strconv.Itoa(services.DefaultDuplicateWindowDays)))
+	if err != nil || windowDays < 0 || windowDays > 30 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid window_days parameter. Must be between 0 and 30",
+		})
+		return
+	}
+
+	minScore := services.DefaultDuplicateMinScore
+	if raw := c.Query("min_score"); raw != "" {
+		minScore, err = strconv.ParseFloat(raw, 64)
+		if err != nil || minScore < 0 || minScore > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid min_score parameter. Must be between 0 and 1",
+			})
+			return
+		}
+	}
+
+	tagFilter, ok := parseTagFilter(c)
+	if !ok {
+		return
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	transactions, err := h.dbService.Repositories.GetTransactionsByDateRange(c.Request.Context(), userID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if accountID := c.Query("account_id"); accountID != "" {
+		filtered := transactions[:0]
+		for _, t := range transactions {
+			if t.AccountID == accountID {
+				filtered = append(filtered, t)
+			}
+		}
+		transactions = filtered
+	}
+	transactions = filterByTags(transactions, tagFilter)
+
+	pairs := services.FindDuplicates(transactions, services.DuplicateOptions{
+		WindowDays: windowDays,
+		MinScore:   minScore,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"duplicates":  pairs,
+		"count":       len(pairs),
+		"window_days": windowDays,
+		"min_score":   minScore,
+	})
+}
+
+// MergeTransactions handles POST /api/transactions/merge. The duplicate is
+// deleted after its notes, description, category and receipts are folded
+// into the transaction being kept.
+func (h *TransactionsHandler) MergeTransactions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	var req MergeTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.KeepID == req.DuplicateID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Cannot merge a transaction with itself",
+		})
+		return
+	}
+
+	var merging [2]*models.Transaction
+	for i, id := range []string{req.KeepID, req.DuplicateID} {
+		transaction, err := h.dbService.Repositories.GetTransactionByID(ctx, id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Transaction not found",
+				"id":    id,
+			})
+			return
+		}
+		if transaction.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return
+		}
+		if transaction.TransferID != nil || transaction.TransactionType == models.TransactionTypeTransfer {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Transfers cannot be merged",
+			})
+			return
+		}
+		merging[i] = transaction
+	}
+	keep, duplicate := merging[0], merging[1]
+
+	if !checkUnlocked(c, keep, duplicate) {
+		return
+	}
+
+	services.MergeDuplicate(keep, *duplicate)
+
+	err := h.dbService.Repositories.MergeTransactions(ctx, keep, duplicate.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to merge transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transaction": keep,
+		"merged_id":   duplicate.ID,
+		"message":     "Transactions merged successfully",
+	})
+}
+
+// UnlockTransaction handles POST /api/transactions/:id/unlock. It releases a
+// transaction locked by a completed reconciliation so it can be edited again.
+func (h *TransactionsHandler) UnlockTransaction(c *gin.Context) {
+	transaction, ok := h.loadOwnedTransaction(c)
+	if !ok {
+		return
+	}
+
+	if !transaction.IsLocked {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Transaction is not locked",
+		})
+		return
+	}
+
+	err := h.dbService.Repositories.UnlockTransaction(c.Request.Context(), transaction.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to unlock transaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transaction.IsLocked = false
+	c.JSON(http.StatusOK, transaction)
+}
+
+// applyRules runs the user's categorization rules over a new transaction.
+// Fields the user filled in are left alone. A rule failure never blocks the
+// transaction from being recorded.
+func (h *TransactionsHandler) applyRules(c *gin.Context, transaction *models.Transaction) {
+	rules, err := h.dbService.Repositories.GetRulesByUserID(c.Request.Context(), transaction.UserID)
+	if err != nil {
+		log.Printf("Warning: Failed to load categorization rules: %v", err)
+		return
+	}
+
+	engine, err := services.NewRuleEngine(rules)
+	if err != nil {
+		log.Printf("Warning: Failed to compile categorization rules: %v", err)
+		return
+	}
+
+	engine.Apply(transaction, false)
+}
+
+// assignMerchant resolves the canonical merchant from the transaction's
+// description. Like rules, a failure here never blocks the transaction.
+func (h *TransactionsHandler) assignMerchant(c *gin.Context, transaction *models.Transaction) {
+	resolver, err := newMerchantResolver(c.Request.Context(), h.dbService, transaction.UserID)
+	if err != nil {
+		log.Printf("Warning: Failed to load merchant aliases: %v", err)
+		return
+	}
+
+	if _, err := resolver.resolve(c.Request.Context(), transaction); err != nil {
+		log.Printf("Warning: Failed to resolve merchant: %v", err)
+	}
+}
+
+// applyCustomFields validates submitted custom field values against the
+// user's definitions and merges them into the transaction, writing the error
+// response on failure.
+func (h *TransactionsHandler) applyCustomFields(c *gin.Context, transaction *models.Transaction, submitted map[string]interface{}) bool {
+	if len(submitted) == 0 {
+		return true
+	}
+
+	defs, err := h.dbService.Repositories.GetCustomFieldDefinitions(c.Request.Context(), transaction.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get custom fields",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	fields, err := services.ApplyCustomFields(defs, transaction.CustomFields, submitted)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid custom fields",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	transaction.CustomFields = fields
+	return true
+}
+
+// loadOwnedTransaction fetches the transaction named by the :id parameter and
+// verifies it belongs to the authenticated user, writing the error response if not.
+func (h *TransactionsHandler) loadOwnedTransaction(c *gin.Context) (*models.Transaction, bool) {
+	userID := middleware.MustGetUserID(c)
+	transactionID := c.Param("id")
+
+	transaction, err := h.dbService.Repositories.GetTransactionByID(c.Request.Context(), transactionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Transaction not found",
+		})
+		return nil, false
+	}
+
+	// Ensure the transaction belongs to the authenticated user
+	if transaction.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return transaction, true
+}
+
+// checkAccountOwnership verifies the account exists and belongs to the user,
+// writing the error response if not.
+func (h *TransactionsHandler) checkAccountOwnership(c *gin.Context, userID, accountID string) bool {
+	account, err := h.dbService.Repositories.GetAccountByID(c.Request.Context(), accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Account not found",
+		})
+		return false
+	}
+
+	if account.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return false
+	}
+
+	return true
+}
+
+// checkCategoryOwnership verifies the transaction's category and the
+// categories of its split lines exist and belong to the user, writing the
+// error response if not.
+func (h *TransactionsHandler) checkCategoryOwnership(c *gin.Context, userID string, transaction *models.Transaction) bool {
+	categoryIDs := []*string{transaction.CategoryID}
+	for _, split := range transaction.Splits {
+		categoryIDs = append(categoryIDs, split.CategoryID)
+	}
+
+	checked := make(map[string]bool)
+	for _, categoryID := range categoryIDs {
+		if categoryID == nil || checked[*categoryID] {
+			continue
+		}
+		checked[*categoryID] = true
+
+		category, err := h.dbService.Repositories.GetCategoryByID(c.Request.Context(), *categoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Category not found",
+			})
+			return false
+		}
+		if category.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateTransactionSplits checks the split lines against the transaction amount,
+// writing the error response if they do not balance.
+func validateTransactionSplits(c *gin.Context, transaction *models.Transaction) bool {
+	if err := services.ValidateSplits(transaction.Amount, transaction.Splits); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid transaction splits",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	// The split lines carry the categories, so the parent is left uncategorized
+	if len(transaction.Splits) > 0 {
+		transaction.CategoryID = nil
+	}
+
+	return true
+}
+
+// checkUnlocked rejects changes to transactions locked by a completed
+// reconciliation, writing the error response if any of them is locked.
+func checkUnlocked(c *gin.Context, transactions ...*models.Transaction) bool {
+	for _, t := range transactions {
+		if t != nil && t.IsLocked {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":          "Transaction is locked by a reconciliation",
+				"transaction_id": t.ID,
+			})
+			return false
+		}
+	}
+
+	return true
+}
+
+// lockedFieldsChanged reports whether an edit touches the fields a
+// reconciliation locks: the account, amount, type and date
+func lockedFieldsChanged(before, after models.Transaction) bool {
+	return before.AccountID != after.AccountID ||
+		before.Amount != after.Amount ||
+		before.TransactionType != after.TransactionType ||
+		!before.TransactionDate.Equal(after.TransactionDate)
+}
+
+// setTransactionTags normalizes and sets the transaction's tags, writing the
+// error response when a tag is invalid.
+func setTransactionTags(c *gin.Context, transaction *models.Transaction, tags []string) bool {
+	normalized, err := services.NormalizeTags(tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tags",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	transaction.Tags = normalized
+	return true
+}
+
+func splitsFromRequest(reqs []TransactionSplitRequest) []models.TransactionSplit {
+	splits := make([]models.TransactionSplit, 0, len(reqs))
+	for _, req := range reqs {
+		splits = append(splits, models.TransactionSplit{
+			CategoryID:  req.CategoryID,
+			Amount:      req.Amount,
+			Description: req.Description,
+		})
+	}
+	return splits
+}
+
+// parseTagFilter reads the tags (comma-separated, or repeated tag parameters)
+// and tag_match (any or all) query parameters shared by transaction listings,
+// writing the error response when they are invalid.
+func parseTagFilter(c *gin.Context) (models.TagFilter, bool) {
+	var filter models.TagFilter
+
+	var raw []string
+	for _, value := range c.QueryArray("tags") {
+		raw = append(raw, strings.Split(value, ",")...)
+	}
+	raw = append(raw, c.QueryArray("tag")...)
+	filter.Tags = services.MergeTags(nil, raw)
+
+	switch c.DefaultQuery("tag_match", "any") {
+	case "any":
+	case "all":
+		filter.MatchAll = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid tag_match parameter. Must be any or all",
+		})
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// filterByTags keeps the transactions matching the tag filter.
+func filterByTags(transactions []models.Transaction, filter models.TagFilter) []models.Transaction {
+	if len(filter.Tags) == 0 {
+		return transactions
+	}
+
+	filtered := make([]models.Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		if services.MatchesTagFilter(t.Tags, filter) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
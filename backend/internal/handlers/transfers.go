@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// TransfersHandler handles transfers between a user's accounts
+type TransfersHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewTransfersHandler creates a new transfers handler
+func NewTransfersHandler(dbService *services.DatabaseService) *TransfersHandler {
+	return &TransfersHandler{
+		dbService: dbService,
+	}
+}
+
+// CreateTransferRequest represents the request body for creating a transfer
+type CreateTransferRequest struct {
+	FromAccountID   string   `json:"from_account_id" binding:"required"`
+	ToAccountID     string   `json:"to_account_id" binding:"required"`
+	Amount          float64  `json:"amount" binding:"required,gt=0"`
+	TransactionDate string   `json:"transaction_date" binding:"required"` // ISO date string
+	Description     *string  `json:"description"`
+	Notes           *string  `json:"notes"`
+	ExchangeRate    *float64 `json:"exchange_rate" binding:"omitempty,gt=0"` // Optional; looked up when omitted
+}
+
+// UpdateTransferRequest represents the request body for updating a transfer
+type UpdateTransferRequest struct {
+	FromAccountID   *string  `json:"from_account_id"`
+	ToAccountID     *string  `json:"to_account_id"`
+	Amount          *float64 `json:"amount"`
+	TransactionDate *string  `json:"transaction_date"` // ISO date string
+	Description     *string  `json:"description"`
+	Notes           *string  `json:"notes"`
+	ExchangeRate    *float64 `json:"exchange_rate"`
+}
+
+// CreateTransfer handles POST /api/transfers
+func (h *TransfersHandler) CreateTransfer(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transactionDate, err := time.Parse("2006-01-02", req.TransactionDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid transaction_date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+
+	transfer := &models.Transfer{
+		UserID:          userID,
+		FromAccountID:   req.FromAccountID,
+		ToAccountID:     req.ToAccountID,
+		Amount:          req.Amount,
+		TransactionDate: transactionDate,
+		Description:     req.Description,
+		Notes:           req.Notes,
+	}
+
+	if !h.resolveAccounts(c, transfer, req.ExchangeRate) {
+		return
+	}
+
+	err = h.dbService.Repositories.CreateTransfer(c.Request.Context(), transfer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create transfer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// GetTransfer handles GET /api/transfers/:id
+func (h *TransfersHandler) GetTransfer(c *gin.Context) {
+	transfer, ok := h.loadOwnedTransfer(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// UpdateTransfer handles PUT /api/transfers/:id
+func (h *TransfersHandler) UpdateTransfer(c *gin.Context) {
+	var req UpdateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transfer, ok := h.loadOwnedTransfer(c)
+	if !ok {
+		return
+	}
+
+	if !checkUnlocked(c, transfer.OutgoingLeg, transfer.IncomingLeg) {
+		return
+	}
+
+	// Update fields if provided
+	if req.FromAccountID != nil {
+		transfer.FromAccountID = *req.FromAccountID
+	}
+	if req.ToAccountID != nil {
+		transfer.ToAccountID = *req.ToAccountID
+	}
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Amount must be greater than 0",
+			})
+			return
+		}
+		transfer.Amount = *req.Amount
+	}
+	if req.ExchangeRate != nil && *req.ExchangeRate <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Exchange rate must be greater than 0",
+		})
+		return
+	}
+	if req.TransactionDate != nil {
+		parsed, err := time.Parse("2006-01-02", *req.TransactionDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid transaction_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		transfer.TransactionDate = parsed
+	}
+	if req.Description != nil {
+		transfer.Description = req.Description
+	}
+	if req.Notes != nil {
+		transfer.Notes = req.Notes
+	}
+
+	if !h.resolveAccounts(c, transfer, req.ExchangeRate) {
+		return
+	}
+
+	err := h.dbService.Repositories.UpdateTransfer(c.Request.Context(), transfer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update transfer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// DeleteTransfer handles DELETE /api/transfers/:id
+func (h *TransfersHandler) DeleteTransfer(c *gin.Context) {
+	transfer, ok := h.loadOwnedTransfer(c)
+	if !ok {
+		return
+	}
+
+	if !checkUnlocked(c, transfer.OutgoingLeg, transfer.IncomingLeg) {
+		return
+	}
+
+	err := h.dbService.Repositories.DeleteTransfer(c.Request.Context(), transfer.TransferID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete transfer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Transfer deleted successfully",
+	})
+}
+
+// loadOwnedTransfer fetches the transfer named by the :id parameter and
+// verifies it belongs to the authenticated user, writing the error response if not.
+func (h *TransfersHandler) loadOwnedTransfer(c *gin.Context) (*models.Transfer, bool) {
+	userID := middleware.MustGetUserID(c)
+	transferID := c.Param("id")
+
+	transfer, err := h.dbService.Repositories.GetTransferByID(c.Request.Context(), transferID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Transfer not found",
+		})
+		return nil, false
+	}
+
+	// Ensure the transfer belongs to the authenticated user
+	if transfer.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return transfer, true
+}
+
+// resolveAccounts verifies both accounts belong to the user, then settles the
+// currencies, exchange rate and converted amount of the transfer. An explicit
+// rate wins; otherwise the stored rate is kept unless the currencies changed,
+// in which case the latest known rate is looked up.
+func (h *TransfersHandler) resolveAccounts(c *gin.Context, transfer *models.Transfer, rate *float64) bool {
+	ctx := c.Request.Context()
+
+	if transfer.FromAccountID == transfer.ToAccountID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Cannot transfer to the same account",
+		})
+		return false
+	}
+
+	var accounts [2]*models.Account
+	for i, accountID := range []string{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := h.dbService.Repositories.GetAccountByID(ctx, accountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Account not found",
+			})
+			return false
+		}
+		if account.UserID != transfer.UserID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return false
+		}
+		accounts[i] = account
+	}
+	from, to := accounts[0], accounts[1]
+
+	currenciesChanged := from.Currency != transfer.FromCurrency || to.Currency != transfer.ToCurrency
+	transfer.FromCurrency = from.Currency
+	transfer.ToCurrency = to.Currency
+
+	switch {
+	case from.Currency == to.Currency:
+		transfer.ExchangeRate = 1
+	case rate != nil:
+		transfer.ExchangeRate = *rate
+	case currenciesChanged || transfer.ExchangeRate <= 0:
+		latest, err := h.dbService.Repositories.GetLatestExchangeRate(ctx, from.Currency, to.Currency)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Exchange rate required for cross-currency transfer",
+				"details": err.Error(),
+			})
+			return false
+		}
+		transfer.ExchangeRate = latest
+	}
+
+	transfer.ConvertedAmount = services.ConvertAmount(transfer.Amount, transfer.ExchangeRate)
+	if transfer.ConvertedAmount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Converted amount must be greater than 0",
+		})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/personal-finance-management/backend/internal/middleware"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/services"
+)
+
+// WebhooksHandler handles webhook subscription and delivery HTTP requests
+type WebhooksHandler struct {
+	dbService *services.DatabaseService
+}
+
+// NewWebhooksHandler creates a new webhooks handler
+func NewWebhooksHandler(dbService *services.DatabaseService) *WebhooksHandler {
+	return &WebhooksHandler{
+		dbService: dbService,
+	}
+}
+
+// CreateWebhookSubscriptionRequest represents the request body for
+// subscribing to webhook events. A secret is generated when none is given.
+type CreateWebhookSubscriptionRequest struct {
+	URL         string                    `json:"url" binding:"required"`
+	EventTypes  []models.WebhookEventType `json:"event_types" binding:"required,min=1"`
+	Secret      *string                   `json:"secret"`
+	Description *string                   `json:"description"`
+}
+
+// UpdateWebhookSubscriptionRequest represents the request body for updating
+// a webhook subscription. rotate_secret replaces the secret with a new
+// generated one, which is returned once.
+type UpdateWebhookSubscriptionRequest struct {
+	URL          *string                   `json:"url"`
+	EventTypes   []models.WebhookEventType `json:"event_types" binding:"omitempty,min=1"`
+	Description  *string                   `json:"description"`
+	IsActive     *bool                     `json:"is_active"`
+	RotateSecret bool                      `json:"rotate_secret"`
+}
+
+// webhookSubscriptionWithSecret is a subscription shown with its secret,
+// only when the secret has just been set
+type webhookSubscriptionWithSecret struct {
+	*models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// GetWebhookSubscriptions handles GET /api/webhooks
+func (h *WebhooksHandler) GetWebhookSubscriptions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	subscriptions, err := h.dbService.Repositories.GetWebhookSubscriptionsByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get webhook subscriptions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks":    subscriptions,
+		"event_types": services.WebhookEventTypes,
+	})
+}
+
+// GetWebhookSubscription handles GET /api/webhooks/:id
+func (h *WebhooksHandler) GetWebhookSubscription(c *gin.Context) {
+	subscription, ok := h.loadOwnedSubscription(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// CreateWebhookSubscription handles POST /api/webhooks, returning the new
+// subscription with its secret
+func (h *WebhooksHandler) CreateWebhookSubscription(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	subscription := &models.WebhookSubscription{
+		UserID:      userID,
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if req.Secret != nil {
+		subscription.Secret = *req.Secret
+	} else {
+		secret, err := services.GenerateWebhookSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to create webhook subscription",
+				"details": err.Error(),
+			})
+			return
+		}
+		subscription.Secret = secret
+	}
+
+	if err := services.ValidateWebhookSubscription(subscription); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid webhook subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.dbService.Repositories.CreateWebhookSubscription(c.Request.Context(), subscription); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create webhook subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookSubscriptionWithSecret{
+		WebhookSubscription: subscription,
+		Secret:              subscription.Secret,
+	})
+}
+
+// UpdateWebhookSubscription handles PUT /api/webhooks/:id
+func (h *WebhooksHandler) UpdateWebhookSubscription(c *gin.Context) {
+	var req UpdateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	subscription, ok := h.loadOwnedSubscription(c)
+	if !ok {
+		return
+	}
+
+	if req.URL != nil {
+		subscription.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		subscription.EventTypes = req.EventTypes
+	}
+	if req.Description != nil {
+		subscription.Description = req.Description
+		if *req.Description == "" {
+			subscription.Description = nil
+		}
+	}
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+	if req.RotateSecret {
+		secret, err := services.GenerateWebhookSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update webhook subscription",
+				"details": err.Error(),
+			})
+			return
+		}
+		subscription.Secret = secret
+	}
+
+	if err := services.ValidateWebhookSubscription(subscription); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid webhook subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.dbService.Repositories.UpdateWebhookSubscription(c.Request.Context(), subscription); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update webhook subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.RotateSecret {
+		c.JSON(http.StatusOK, webhookSubscriptionWithSecret{
+			WebhookSubscription: subscription,
+			Secret:              subscription.Secret,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteWebhookSubscription handles DELETE /api/webhooks/:id, removing the
+// subscription with its delivery logs
+func (h *WebhooksHandler) DeleteWebhookSubscription(c *gin.Context) {
+	subscription, ok := h.loadOwnedSubscription(c)
+	if !ok {
+		return
+	}
+
+	if err := h.dbService.Repositories.DeleteWebhookSubscription(c.Request.Context(), subscription.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete webhook subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook subscription deleted successfully",
+	})
+}
+
+// GetWebhookDeliveries handles GET /api/webhooks/:id/deliveries, listing the
+// subscription's recent deliveries with their events. Supports ?status=
+// (pending, sent or failed) and ?limit= (default 50, at most 200).
+func (h *WebhooksHandler) GetWebhookDeliveries(c *gin.Context) {
+	subscription, ok := h.loadOwnedSubscription(c)
+	if !ok {
+		return
+	}
+
+	status := models.WebhookDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySent, models.WebhookDeliveryFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid status. Use pending, sent or failed",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	deliveries, err := h.dbService.Repositories.GetWebhookDeliveries(c.Request.Context(), subscription.ID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get webhook deliveries",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+	})
+}
+
+// GetWebhookDelivery handles GET /api/webhooks/:id/deliveries/:delivery_id,
+// returning the delivery with its event and the log of every attempt
+func (h *WebhooksHandler) GetWebhookDelivery(c *gin.Context) {
+	_, delivery, ok := h.loadOwnedDelivery(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ReplayWebhookDelivery handles POST
+// /api/webhooks/:id/deliveries/:delivery_id/replay, queueing the delivery's
+// event to be sent again now. The replay is a new delivery with its own
+// attempts; the event keeps its id.
+func (h *WebhooksHandler) ReplayWebhookDelivery(c *gin.Context) {
+	subscription, delivery, ok := h.loadOwnedDelivery(c)
+	if !ok {
+		return
+	}
+	if !subscription.IsActive {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Webhook subscription is inactive. Turn it back on to replay deliveries",
+		})
+		return
+	}
+
+	replay, err := h.dbService.Repositories.ReplayWebhookDelivery(c.Request.Context(), delivery.ID, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to replay webhook delivery",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, replay)
+}
+
+// loadOwnedSubscription fetches the subscription named by the :id parameter
+// and verifies it belongs to the authenticated user, writing the error
+// response if not.
+func (h *WebhooksHandler) loadOwnedSubscription(c *gin.Context) (*models.WebhookSubscription, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	subscription, err := h.dbService.Repositories.GetWebhookSubscriptionByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Webhook subscription not found",
+		})
+		return nil, false
+	}
+
+	if subscription.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, false
+	}
+
+	return subscription, true
+}
+
+// loadOwnedDelivery fetches the delivery named by the :delivery_id parameter
+// of the subscription named by :id, returning both, writing the error
+// response if either is missing or not the authenticated user's.
+func (h *WebhooksHandler) loadOwnedDelivery(c *gin.Context) (*models.WebhookSubscription, *models.WebhookDelivery, bool) {
+	subscription, ok := h.loadOwnedSubscription(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	delivery, err := h.dbService.Repositories.GetWebhookDeliveryByID(c.Request.Context(), c.Param("delivery_id"))
+	if err != nil || delivery.SubscriptionID != subscription.ID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Webhook delivery not found",
+		})
+		return nil, nil, false
+	}
+
+	return subscription, delivery, true
+}
@@ -294,4 +294,24 @@ func MustGetUserID(c *gin.Context) string {
 		panic("user ID not found in context")
 	}
 	return userID
+}
+
+// ServiceRole is the role Supabase puts in service-role tokens, which only
+// trusted backend callers hold
+const ServiceRole = "service_role"
+
+// RequireServiceRole middleware rejects requests whose token does not carry
+// the service role, for operational endpoints ordinary users must not see
+func RequireServiceRole() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("user_role"); role != ServiceRole {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
 }
\ No newline at end of file
@@ -133,4 +133,31 @@ func TestGetUserEmail(t *testing.T) {
 	router.ServeHTTP(w, req)
 	
 	assert.Equal(t, http.StatusOK, w.Code)
-}
\ No newline at end of file
+}
+
+func TestRequireServiceRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for role, want := range map[string]int{
+		"service_role":  http.StatusOK,
+		"authenticated": http.StatusForbidden,
+		"":              http.StatusForbidden,
+	} {
+		router := gin.New()
+		router.GET("/test", func(c *gin.Context) {
+			if role != "" {
+				c.Set("user_role", role)
+			}
+			c.Next()
+		}, RequireServiceRole(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code, role)
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -42,6 +43,7 @@ type Account struct {
 	AccountType AccountType `json:"account_type" db:"account_type"`
 	Balance     float64     `json:"balance" db:"balance"`
 	Description *string     `json:"description,omitempty" db:"description"`
+	Currency    string      `json:"currency,omitempty" db:"currency"`
 	IsActive    bool        `json:"is_active" db:"is_active"`
 	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
@@ -55,6 +57,7 @@ type Category struct {
 	Description *string   `json:"description,omitempty" db:"description"`
 	Color       string    `json:"color" db:"color"`
 	Icon        *string   `json:"icon,omitempty" db:"icon"`
+	ParentID    *string   `json:"parent_id,omitempty" db:"parent_id"`
 	IsActive    bool      `json:"is_active" db:"is_active"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
@@ -71,22 +74,333 @@ const (
 
 // Transaction represents the public.transactions table
 type Transaction struct {
-	ID              string          `json:"id" db:"id"`
-	UserID          string          `json:"user_id" db:"user_id"`
-	AccountID       string          `json:"account_id" db:"account_id"`
-	CategoryID      *string         `json:"category_id,omitempty" db:"category_id"`
-	Amount          float64         `json:"amount" db:"amount"`
-	TransactionType TransactionType `json:"transaction_type" db:"transaction_type"`
-	Description     *string         `json:"description,omitempty" db:"description"`
-	TransactionDate time.Time       `json:"transaction_date" db:"transaction_date"`
-	Notes           *string         `json:"notes,omitempty" db:"notes"`
-	TransferID      *string         `json:"transfer_id,omitempty" db:"transfer_id"`
-	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+	ID               string                 `json:"id" db:"id"`
+	UserID           string                 `json:"user_id" db:"user_id"`
+	AccountID        string                 `json:"account_id" db:"account_id"`
+	CategoryID       *string                `json:"category_id,omitempty" db:"category_id"`
+	Amount           float64                `json:"amount" db:"amount"`
+	TransactionType  TransactionType        `json:"transaction_type" db:"transaction_type"`
+	Description      *string                `json:"description,omitempty" db:"description"`
+	TransactionDate  time.Time              `json:"transaction_date" db:"transaction_date"`
+	Notes            *string                `json:"notes,omitempty" db:"notes"`
+	TransferID       *string                `json:"transfer_id,omitempty" db:"transfer_id"`
+	Currency         *string                `json:"currency,omitempty" db:"currency"`
+	OriginalCurrency *string                `json:"original_currency,omitempty" db:"original_currency"`
+	ExchangeRate     *float64               `json:"exchange_rate,omitempty" db:"exchange_rate"`
+	IsCleared        bool                   `json:"is_cleared" db:"is_cleared"`
+	IsLocked         bool                   `json:"is_locked" db:"is_locked"`
+	ReconciliationID *string                `json:"reconciliation_id,omitempty" db:"reconciliation_id"`
+	Tags             []string               `json:"tags,omitempty" db:"tags"`
+	MerchantID       *string                `json:"merchant_id,omitempty" db:"merchant_id"`
+	CustomFields     map[string]interface{} `json:"custom_fields,omitempty" db:"custom_fields"` // Keyed by custom field definition ID
+	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at" db:"updated_at"`
 
 	// Joined fields from related tables
 	Account  *Account  `json:"account,omitempty"`
 	Category *Category `json:"category,omitempty"`
+	Merchant *Merchant `json:"merchant,omitempty"`
+
+	// Split lines, when the transaction is divided across categories
+	Splits []TransactionSplit `json:"splits,omitempty"`
+}
+
+// TransactionSplit represents the public.transaction_splits table
+type TransactionSplit struct {
+	ID            string    `json:"id" db:"id"`
+	TransactionID string    `json:"transaction_id" db:"transaction_id"`
+	UserID        string    `json:"user_id" db:"user_id"`
+	CategoryID    *string   `json:"category_id,omitempty" db:"category_id"`
+	Amount        float64   `json:"amount" db:"amount"`
+	Description   *string   `json:"description,omitempty" db:"description"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Transfer represents both legs of a transfer between two accounts, linked by
+// a shared transfer_id. Amount is debited from the source account in its
+// currency; ConvertedAmount is credited to the destination account.
+type Transfer struct {
+	TransferID      string    `json:"transfer_id"`
+	UserID          string    `json:"user_id"`
+	FromAccountID   string    `json:"from_account_id"`
+	ToAccountID     string    `json:"to_account_id"`
+	Amount          float64   `json:"amount"`
+	ConvertedAmount float64   `json:"converted_amount"`
+	FromCurrency    string    `json:"from_currency"`
+	ToCurrency      string    `json:"to_currency"`
+	ExchangeRate    float64   `json:"exchange_rate"`
+	TransactionDate time.Time `json:"transaction_date"`
+	Description     *string   `json:"description,omitempty"`
+	Notes           *string   `json:"notes,omitempty"`
+
+	// The two transaction rows backing the transfer
+	OutgoingLeg *Transaction `json:"outgoing_leg,omitempty"`
+	IncomingLeg *Transaction `json:"incoming_leg,omitempty"`
+}
+
+// ReconciliationStatus enum
+type ReconciliationStatus string
+
+const (
+	ReconciliationStatusInProgress ReconciliationStatus = "in_progress"
+	ReconciliationStatusCompleted  ReconciliationStatus = "completed"
+	ReconciliationStatusCancelled  ReconciliationStatus = "cancelled"
+)
+
+// Reconciliation represents the public.reconciliations table. ClearedBalance
+// and Difference are computed live while in progress and frozen on completion.
+type Reconciliation struct {
+	ID               string               `json:"id" db:"id"`
+	UserID           string               `json:"user_id" db:"user_id"`
+	AccountID        string               `json:"account_id" db:"account_id"`
+	StatementDate    time.Time            `json:"statement_date" db:"statement_date"`
+	StatementBalance float64              `json:"statement_balance" db:"statement_balance"`
+	ClearedBalance   float64              `json:"cleared_balance" db:"cleared_balance"`
+	Difference       float64              `json:"difference" db:"difference"`
+	ClearedCount     int                  `json:"cleared_count" db:"cleared_count"`
+	Status           ReconciliationStatus `json:"status" db:"status"`
+	CompletedAt      *time.Time           `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// TagFilter restricts transaction listings to transactions carrying the given
+// tags. Tags are compared case-insensitively.
+type TagFilter struct {
+	Tags     []string
+	MatchAll bool // Require every tag instead of any of them
+}
+
+// CustomFieldType enum
+type CustomFieldType string
+
+const (
+	CustomFieldTypeText   CustomFieldType = "text"
+	CustomFieldTypeNumber CustomFieldType = "number"
+	CustomFieldTypeDate   CustomFieldType = "date"
+	CustomFieldTypeEnum   CustomFieldType = "enum"
+)
+
+// CustomFieldDefinition represents the public.custom_field_definitions table
+type CustomFieldDefinition struct {
+	ID        string          `json:"id" db:"id"`
+	UserID    string          `json:"user_id" db:"user_id"`
+	Name      string          `json:"name" db:"name"`
+	FieldType CustomFieldType `json:"field_type" db:"field_type"`
+	Options   []string        `json:"options,omitempty" db:"options"` // Allowed values of enum fields
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Merchant represents the public.merchants table: a canonical merchant that
+// raw transaction descriptions are normalized into
+type Merchant struct {
+	ID             string    `json:"id" db:"id"`
+	UserID         string    `json:"user_id" db:"user_id"`
+	Name           string    `json:"name" db:"name"`
+	NormalizedName string    `json:"normalized_name" db:"normalized_name"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MerchantMatchType enum
+type MerchantMatchType string
+
+const (
+	MerchantMatchContains MerchantMatchType = "contains"
+	MerchantMatchExact    MerchantMatchType = "exact"
+	MerchantMatchRegex    MerchantMatchType = "regex"
+)
+
+// MerchantAlias represents the public.merchant_aliases table: a user-defined
+// pattern mapping raw descriptions to a merchant
+type MerchantAlias struct {
+	ID           string            `json:"id" db:"id"`
+	UserID       string            `json:"user_id" db:"user_id"`
+	MerchantID   string            `json:"merchant_id" db:"merchant_id"`
+	MerchantName string            `json:"merchant_name,omitempty"`
+	Pattern      string            `json:"pattern" db:"pattern"`
+	MatchType    MerchantMatchType `json:"match_type" db:"match_type"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+}
+
+// Attachment represents the public.attachments table: an uploaded receipt or
+// document whose contents are held in object storage under StorageKey
+type Attachment struct {
+	ID          string           `json:"id" db:"id"`
+	UserID      string           `json:"user_id" db:"user_id"`
+	FileName    string           `json:"file_name" db:"file_name"`
+	ContentType string           `json:"content_type" db:"content_type"`
+	FileSize    int64            `json:"file_size" db:"file_size"`
+	ContentHash string           `json:"content_hash" db:"content_hash"` // Hex SHA-256 of the contents
+	StorageKey  string           `json:"-" db:"storage_key"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+	Links       []AttachmentLink `json:"links,omitempty"`
+}
+
+// AttachmentLink represents the public.attachment_links table. Exactly one of
+// TransactionID and TaxDocumentID is set.
+type AttachmentLink struct {
+	ID            string    `json:"id" db:"id"`
+	UserID        string    `json:"user_id" db:"user_id"`
+	AttachmentID  string    `json:"attachment_id" db:"attachment_id"`
+	TransactionID *string   `json:"transaction_id,omitempty" db:"transaction_id"`
+	TaxDocumentID *string   `json:"tax_document_id,omitempty" db:"tax_document_id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReceiptLineItem is one purchased item read from a receipt. Amount is the
+// line total after any discount printed below the item.
+type ReceiptLineItem struct {
+	Description string   `json:"description"`
+	Quantity    *float64 `json:"quantity,omitempty"`
+	UnitPrice   *float64 `json:"unit_price,omitempty"`
+	Amount      float64  `json:"amount"`
+}
+
+// ParsedReceipt holds what could be read from receipt text. Fields that were
+// not found are nil; Warnings explains gaps the user should check.
+type ParsedReceipt struct {
+	Merchant  *string           `json:"merchant"`
+	Date      *time.Time        `json:"date"`
+	Currency  *string           `json:"currency"`
+	Subtotal  *float64          `json:"subtotal"`
+	Tax       *float64          `json:"tax"`
+	Total     *float64          `json:"total"`
+	LineItems []ReceiptLineItem `json:"line_items"`
+	Warnings  []string          `json:"warnings"`
+}
+
+// RuleConditions are the match criteria of a categorization rule. Every
+// condition that is set must hold for the rule to match.
+type RuleConditions struct {
+	DescriptionPattern *string          `json:"description_pattern,omitempty"` // Case-insensitive regular expression
+	MinAmount          *float64         `json:"min_amount,omitempty"`
+	MaxAmount          *float64         `json:"max_amount,omitempty"`
+	AccountIDs         []string         `json:"account_ids,omitempty"`
+	Weekdays           []time.Weekday   `json:"weekdays,omitempty"` // 0 = Sunday
+	TransactionType    *TransactionType `json:"transaction_type,omitempty"`
+}
+
+// RuleActions are the changes a categorization rule applies to a matching transaction
+type RuleActions struct {
+	CategoryID *string  `json:"category_id,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Notes      *string  `json:"notes,omitempty"`
+}
+
+// CategorizationRule represents the public.categorization_rules table.
+// Rules run in ascending Priority order.
+type CategorizationRule struct {
+	ID             string         `json:"id" db:"id"`
+	UserID         string         `json:"user_id" db:"user_id"`
+	Name           string         `json:"name" db:"name"`
+	Priority       int            `json:"priority" db:"priority"`
+	IsActive       bool           `json:"is_active" db:"is_active"`
+	StopProcessing bool           `json:"stop_processing" db:"stop_processing"`
+	Conditions     RuleConditions `json:"conditions" db:"conditions"`
+	Actions        RuleActions    `json:"actions" db:"actions"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// UserMLModel represents the public.user_ml_models table. ModelData holds the
+// serialized classifier.
+type UserMLModel struct {
+	ID                 string          `json:"id" db:"id"`
+	UserID             string          `json:"user_id" db:"user_id"`
+	ModelData          json.RawMessage `json:"-" db:"model_data"`
+	Accuracy           float64         `json:"accuracy" db:"accuracy"`
+	TotalPredictions   int             `json:"total_predictions" db:"total_predictions"`
+	CorrectPredictions int             `json:"correct_predictions" db:"correct_predictions"`
+	PatternCount       int             `json:"pattern_count" db:"pattern_count"`
+	LastTrained        time.Time       `json:"last_trained" db:"last_trained"`
+	ModelVersion       string          `json:"model_version" db:"model_version"`
+	CreatedAt          time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// TrainingExample is one labelled transaction used to train the categorizer
+type TrainingExample struct {
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+	CategoryID  string    `json:"category_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MLPrediction represents the public.ml_predictions table
+type MLPrediction struct {
+	ID                     string          `json:"id" db:"id"`
+	UserID                 string          `json:"user_id" db:"user_id"`
+	TransactionDescription string          `json:"transaction_description" db:"transaction_description"`
+	TransactionAmount      float64         `json:"transaction_amount" db:"transaction_amount"`
+	PredictedCategoryID    *string         `json:"predicted_category_id,omitempty" db:"predicted_category_id"`
+	Confidence             int             `json:"confidence" db:"confidence"`
+	Reasoning              *string         `json:"reasoning,omitempty" db:"reasoning"`
+	ModelVersion           string          `json:"model_version" db:"model_version"`
+	PredictionSources      []string        `json:"prediction_sources" db:"prediction_sources"`
+	Alternatives           json.RawMessage `json:"alternatives" db:"alternatives"`
+	ModelAccuracy          float64         `json:"model_accuracy" db:"model_accuracy"`
+	CreatedAt              time.Time       `json:"created_at" db:"created_at"`
+}
+
+// MLFeedback represents the public.ml_feedback table
+type MLFeedback struct {
+	ID                  string    `json:"id" db:"id"`
+	UserID              string    `json:"user_id" db:"user_id"`
+	TransactionID       *string   `json:"transaction_id,omitempty" db:"transaction_id"`
+	PredictedCategoryID *string   `json:"predicted_category_id,omitempty" db:"predicted_category_id"`
+	ActualCategoryID    *string   `json:"actual_category_id,omitempty" db:"actual_category_id"`
+	Confidence          int       `json:"confidence" db:"confidence"`
+	WasCorrect          bool      `json:"was_correct" db:"was_correct"`
+	ModelVersion        string    `json:"model_version" db:"model_version"`
+	FeedbackNotes       *string   `json:"feedback_notes,omitempty" db:"feedback_notes"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+}
+
+// MLTrainingSession represents the public.ml_training_sessions table
+type MLTrainingSession struct {
+	ID                 string    `json:"id" db:"id"`
+	UserID             string    `json:"user_id" db:"user_id"`
+	TrainingDataCount  int       `json:"training_data_count" db:"training_data_count"`
+	AccuracyBefore     float64   `json:"accuracy_before" db:"accuracy_before"`
+	AccuracyAfter      float64   `json:"accuracy_after" db:"accuracy_after"`
+	TrainingDurationMs int       `json:"training_duration_ms" db:"training_duration_ms"`
+	ModelVersion       string    `json:"model_version" db:"model_version"`
+	TrainingType       string    `json:"training_type" db:"training_type"` // full, incremental, validation
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
+
+// ScheduleFrequency enum
+type ScheduleFrequency string
+
+const (
+	ScheduleFrequencyOnce      ScheduleFrequency = "once"
+	ScheduleFrequencyWeekly    ScheduleFrequency = "weekly"
+	ScheduleFrequencyBiweekly  ScheduleFrequency = "biweekly"
+	ScheduleFrequencyMonthly   ScheduleFrequency = "monthly"
+	ScheduleFrequencyQuarterly ScheduleFrequency = "quarterly"
+	ScheduleFrequencyYearly    ScheduleFrequency = "yearly"
+)
+
+// ScheduledTransaction represents the public.scheduled_transactions table: a
+// planned transaction used for forecasting. It is not posted automatically.
+type ScheduledTransaction struct {
+	ID              string            `json:"id" db:"id"`
+	UserID          string            `json:"user_id" db:"user_id"`
+	AccountID       string            `json:"account_id" db:"account_id"`
+	CategoryID      *string           `json:"category_id,omitempty" db:"category_id"`
+	Amount          float64           `json:"amount" db:"amount"`
+	TransactionType TransactionType   `json:"transaction_type" db:"transaction_type"`
+	Description     *string           `json:"description,omitempty" db:"description"`
+	Frequency       ScheduleFrequency `json:"frequency" db:"frequency"`
+	NextDate        time.Time         `json:"next_date" db:"next_date"`
+	EndDate         *time.Time        `json:"end_date,omitempty" db:"end_date"`
+	IsActive        bool              `json:"is_active" db:"is_active"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
 }
 
 // BudgetPeriod enum
@@ -114,10 +428,46 @@ type Budget struct {
 	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
 
+	// Alert settings: percentages of Amount that raise an alert once per
+	// period, and whether to warn when spending is on pace to exceed Amount
+	AlertThresholds []int `json:"alert_thresholds" db:"alert_thresholds"`
+	PaceAlerts      bool  `json:"pace_alerts" db:"pace_alerts"`
+
 	// Joined fields
 	Category *Category `json:"category,omitempty"`
 }
 
+// BudgetStatus is how a budget stands in a period
+type BudgetStatus string
+
+const (
+	BudgetStatusUnderBudget BudgetStatus = "under_budget"
+	BudgetStatusAtRisk      BudgetStatus = "at_risk"
+	BudgetStatusOverBudget  BudgetStatus = "over_budget"
+)
+
+// BudgetEvaluation is the state of a budget in one of its periods.
+// ThresholdReached is the highest of the budget's alert thresholds reached.
+// The projection is only made while the period is in progress, from the
+// average daily spending so far.
+type BudgetEvaluation struct {
+	BudgetID            string       `json:"budget_id"`
+	BudgetName          string       `json:"budget_name"`
+	CategoryID          string       `json:"category_id"`
+	CategoryName        string       `json:"category_name,omitempty"`
+	Period              BudgetPeriod `json:"period"`
+	PeriodStart         time.Time    `json:"period_start"`
+	PeriodEnd           time.Time    `json:"period_end"`
+	Budgeted            float64      `json:"budgeted_amount"`
+	Spent               float64      `json:"spent_amount"`
+	Remaining           float64      `json:"remaining"`
+	PercentageUsed      float64      `json:"percentage_used"`
+	ThresholdReached    *int         `json:"threshold_reached,omitempty"`
+	ProjectedAmount     *float64     `json:"projected_amount,omitempty"`
+	ProjectedExceedDate *time.Time   `json:"projected_exceed_date,omitempty"`
+	Status              BudgetStatus `json:"status"`
+}
+
 // Goal represents the public.goals table
 type Goal struct {
 	ID            string     `json:"id" db:"id"`
@@ -153,20 +503,59 @@ type MonthlySummary struct {
 	GeneratedAt   time.Time            `json:"generated_at"`
 }
 
-// SpendingTrendItem represents a single point in spending trends
+// TrendGranularity is the bucket size of a trends report
+type TrendGranularity string
+
+const (
+	TrendGranularityWeekly    TrendGranularity = "weekly" // ISO weeks, starting Monday
+	TrendGranularityMonthly   TrendGranularity = "monthly"
+	TrendGranularityQuarterly TrendGranularity = "quarterly"
+	TrendGranularityYearly    TrendGranularity = "yearly"
+)
+
+// SpendingTrendsQuery selects what a spending trends report covers
+type SpendingTrendsQuery struct {
+	Granularity   TrendGranularity
+	Periods       int       // Number of buckets, ending with the one containing EndDate
+	EndDate       time.Time // Defaults to today
+	CategoryIDs   []string  // Limit to these categories and return a series for each
+	IncludeIncome bool
+}
+
+// SpendingTrendItem represents a single point in spending trends. Month and
+// Year are those of the bucket's first day.
 type SpendingTrendItem struct {
-	Month  int     `json:"month" db:"month"`
-	Year   int     `json:"year" db:"year"`
-	Amount float64 `json:"amount" db:"amount"`
+	Month       int       `json:"month" db:"month"`
+	Year        int       `json:"year" db:"year"`
+	Amount      float64   `json:"amount" db:"amount"`
+	Label       string    `json:"label"` // e.g. 2026-W07, 2026-02, 2026-Q1 or 2026
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Count       int       `json:"count"` // Transactions in the bucket
+}
+
+// SpendingTrendSeries is the trend of one category and transaction type
+type SpendingTrendSeries struct {
+	CategoryID      string              `json:"category_id"`
+	CategoryName    string              `json:"category_name"`
+	TransactionType TransactionType     `json:"transaction_type"`
+	Total           float64             `json:"total"`
+	Trends          []SpendingTrendItem `json:"trends"`
 }
 
-// SpendingTrends represents spending trends over time
+// SpendingTrends represents spending trends over time. Every bucket in the
+// range is present, with zero amounts where nothing was spent.
 type SpendingTrends struct {
-	UserID      string              `json:"user_id"`
-	CategoryID  *string             `json:"category_id,omitempty"`
-	Period      string              `json:"period"` // "monthly", "quarterly", "yearly"
-	Trends      []SpendingTrendItem `json:"trends"`
-	GeneratedAt time.Time           `json:"generated_at"`
+	UserID       string                `json:"user_id"`
+	CategoryID   *string               `json:"category_id,omitempty"` // Set when a single category was requested
+	CategoryIDs  []string              `json:"category_ids,omitempty"`
+	Period       string                `json:"period"` // "weekly", "monthly", "quarterly", "yearly"
+	StartDate    time.Time             `json:"start_date"`
+	EndDate      time.Time             `json:"end_date"`
+	Trends       []SpendingTrendItem   `json:"trends"`                  // Expenses, across the requested categories
+	IncomeTrends []SpendingTrendItem   `json:"income_trends,omitempty"` // Income, when requested
+	Series       []SpendingTrendSeries `json:"series,omitempty"`        // One per requested category and type
+	GeneratedAt  time.Time             `json:"generated_at"`
 }
 
 // CashFlowItem represents a cash flow entry
@@ -179,12 +568,742 @@ type CashFlowItem struct {
 
 // CashFlow represents cash flow analysis
 type CashFlow struct {
-	UserID      string         `json:"user_id"`
-	StartDate   time.Time      `json:"start_date"`
-	EndDate     time.Time      `json:"end_date"`
-	Items       []CashFlowItem `json:"items"`
-	TotalIncome float64        `json:"total_income"`
-	TotalExpense float64       `json:"total_expense"`
-	NetCashFlow float64        `json:"net_cash_flow"`
-	GeneratedAt time.Time      `json:"generated_at"`
-}
\ No newline at end of file
+	UserID       string         `json:"user_id"`
+	StartDate    time.Time      `json:"start_date"`
+	EndDate      time.Time      `json:"end_date"`
+	Items        []CashFlowItem `json:"items"`
+	TotalIncome  float64        `json:"total_income"`
+	TotalExpense float64        `json:"total_expense"`
+	NetCashFlow  float64        `json:"net_cash_flow"`
+	GeneratedAt  time.Time      `json:"generated_at"`
+}
+
+// CategoryTotal is the total of one transaction type in one category over a
+// period. Uncategorized transactions have an empty CategoryID.
+type CategoryTotal struct {
+	CategoryID      string          `json:"category_id" db:"category_id"`
+	CategoryName    string          `json:"category_name" db:"category_name"`
+	TransactionType TransactionType `json:"transaction_type" db:"transaction_type"`
+	Amount          float64         `json:"amount" db:"amount"`
+	Count           int             `json:"count" db:"count"`
+}
+
+// ReportPeriod is a labelled, inclusive date range
+type ReportPeriod struct {
+	Label     string    `json:"label"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// ComparisonValue compares one figure across two periods. ChangePercent is
+// nil when the previous value is zero.
+type ComparisonValue struct {
+	Current       float64  `json:"current"`
+	Previous      float64  `json:"previous"`
+	Change        float64  `json:"change"`
+	ChangePercent *float64 `json:"change_percent"`
+}
+
+// CategoryComparison compares a category's total across two periods
+type CategoryComparison struct {
+	CategoryID      string          `json:"category_id"`
+	CategoryName    string          `json:"category_name"`
+	TransactionType TransactionType `json:"transaction_type"`
+	ComparisonValue
+	CurrentCount  int `json:"current_count"`
+	PreviousCount int `json:"previous_count"`
+}
+
+// PeriodComparison represents the period comparison report response
+type PeriodComparison struct {
+	UserID       string               `json:"user_id"`
+	Current      ReportPeriod         `json:"current"`
+	Previous     ReportPeriod         `json:"previous"`
+	Income       ComparisonValue      `json:"income"`
+	Expenses     ComparisonValue      `json:"expenses"`
+	Net          ComparisonValue      `json:"net"`
+	Categories   []CategoryComparison `json:"categories"`
+	TopIncreases []CategoryComparison `json:"top_increases"` // Expense categories with the largest rises
+	TopDecreases []CategoryComparison `json:"top_decreases"` // Expense categories with the largest falls
+	GeneratedAt  time.Time            `json:"generated_at"`
+}
+
+// StatementLine is a category on an income statement. Amount is what was
+// booked to the category itself; Total adds its subcategories.
+type StatementLine struct {
+	CategoryID   string          `json:"category_id"` // Empty for uncategorized
+	CategoryName string          `json:"category_name"`
+	Amount       float64         `json:"amount"`
+	Total        float64         `json:"total"`
+	Count        int             `json:"count"`
+	Children     []StatementLine `json:"children,omitempty"`
+}
+
+// StatementSection is the revenue or expense side of an income statement,
+// with top-level categories as lines
+type StatementSection struct {
+	Total float64         `json:"total"`
+	Lines []StatementLine `json:"lines"`
+}
+
+// IncomeStatement is revenue and expenses by category hierarchy for a period
+type IncomeStatement struct {
+	UserID      string           `json:"user_id"`
+	StartDate   time.Time        `json:"start_date"`
+	EndDate     time.Time        `json:"end_date"`
+	Revenue     StatementSection `json:"revenue"`
+	Expenses    StatementSection `json:"expenses"`
+	NetIncome   float64          `json:"net_income"`
+	NetMargin   *float64         `json:"net_margin,omitempty"` // Net income as a percentage of revenue
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// BalanceSheetAccount is one account on a balance sheet. Balance is in the
+// account's currency; Amount is in the base currency and, for liabilities,
+// is what is owed.
+type BalanceSheetAccount struct {
+	AccountID string  `json:"account_id"`
+	Name      string  `json:"name"`
+	Currency  string  `json:"currency"`
+	Balance   float64 `json:"balance"`
+	Amount    float64 `json:"amount"`
+}
+
+// BalanceSheetGroup is the accounts of one account type
+type BalanceSheetGroup struct {
+	AccountType AccountType           `json:"account_type"`
+	Total       float64               `json:"total"`
+	Accounts    []BalanceSheetAccount `json:"accounts"`
+}
+
+// BalanceSheetSection is the assets or liabilities of a balance sheet
+type BalanceSheetSection struct {
+	Total  float64             `json:"total"`
+	Groups []BalanceSheetGroup `json:"groups"`
+}
+
+// BalanceSheetEquity is what remains after liabilities, split into the net
+// income of the year so far and everything before it
+type BalanceSheetEquity struct {
+	Total               float64 `json:"total"`
+	RetainedEarnings    float64 `json:"retained_earnings"`
+	CurrentYearEarnings float64 `json:"current_year_earnings"`
+}
+
+// BalanceSheet is the user's assets, liabilities and equity as of a date, in
+// their base currency
+type BalanceSheet struct {
+	UserID       string              `json:"user_id"`
+	AsOf         time.Time           `json:"as_of"`
+	BaseCurrency string              `json:"base_currency"`
+	Assets       BalanceSheetSection `json:"assets"`
+	Liabilities  BalanceSheetSection `json:"liabilities"`
+	Equity       BalanceSheetEquity  `json:"equity"`
+	GeneratedAt  time.Time           `json:"generated_at"`
+
+	// Account currencies left out because no exchange rate to the base
+	// currency was available
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty"`
+}
+
+// PivotDimension is a field a pivot query can group by
+type PivotDimension string
+
+const (
+	PivotDimensionCategory PivotDimension = "category"
+	PivotDimensionAccount  PivotDimension = "account"
+	PivotDimensionMerchant PivotDimension = "merchant"
+	PivotDimensionTag      PivotDimension = "tag" // Lines with several tags count under each
+	PivotDimensionMonth    PivotDimension = "month"
+	PivotDimensionWeekday  PivotDimension = "weekday" // ISO weekday, 1 is Monday
+)
+
+// PivotMeasure is an aggregate a pivot query computes over line amounts
+type PivotMeasure string
+
+const (
+	PivotMeasureSum    PivotMeasure = "sum"
+	PivotMeasureCount  PivotMeasure = "count"
+	PivotMeasureAvg    PivotMeasure = "avg"
+	PivotMeasureMedian PivotMeasure = "median"
+)
+
+// PivotFilter restricts the transaction lines a pivot query aggregates.
+// Empty lists match everything.
+type PivotFilter struct {
+	StartDate       time.Time
+	EndDate         time.Time
+	TransactionType TransactionType
+	AccountIDs      []string
+	CategoryIDs     []string
+	MerchantIDs     []string
+	Tags            []string // Lines carrying any of the tags
+	MinAmount       *float64
+	MaxAmount       *float64
+}
+
+// PivotQuery is an ad-hoc aggregation of the user's transaction lines
+type PivotQuery struct {
+	Dimensions []PivotDimension
+	Measures   []PivotMeasure
+	Filter     PivotFilter
+	Limit      int // Maximum rows returned
+}
+
+// PivotRow is one group of a pivot result. Keys holds each dimension's value
+// (an id, YYYY-MM month or weekday number; empty when unset) and Labels its
+// display name.
+type PivotRow struct {
+	Keys     map[PivotDimension]string `json:"keys"`
+	Labels   map[PivotDimension]string `json:"labels"`
+	Measures map[PivotMeasure]float64  `json:"measures"`
+}
+
+// PivotResult is the outcome of a pivot query. Rows are ordered by the first
+// measure, largest first; Totals are over all matching lines.
+type PivotResult struct {
+	UserID      string                   `json:"user_id"`
+	Dimensions  []PivotDimension         `json:"dimensions"`
+	Measures    []PivotMeasure           `json:"measures"`
+	StartDate   time.Time                `json:"start_date"`
+	EndDate     time.Time                `json:"end_date"`
+	Rows        []PivotRow               `json:"rows"`
+	Totals      map[PivotMeasure]float64 `json:"totals"`
+	Truncated   bool                     `json:"truncated"` // More groups matched than the limit
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+// ReportCacheCounts is how often one report was served from the cache
+type ReportCacheCounts struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"` // Percentage of lookups that hit
+}
+
+// ReportCacheStats is the report cache's activity since the server started
+type ReportCacheStats struct {
+	Backend       string                       `json:"backend"`
+	Hits          int64                        `json:"hits"`
+	Misses        int64                        `json:"misses"`
+	HitRate       float64                      `json:"hit_rate"`
+	Invalidations int64                        `json:"invalidations"`
+	Errors        int64                        `json:"errors"` // Backend failures, served as misses
+	Reports       map[string]ReportCacheCounts `json:"reports"`
+}
+
+// CategorizationOutcome is one reviewed auto-categorization, from either
+// ai_categorization_logs or ml_feedback
+type CategorizationOutcome struct {
+	Source                string    `json:"source"` // "ai" or "ml"
+	PredictedCategoryID   *string   `json:"predicted_category_id,omitempty"`
+	PredictedCategoryName *string   `json:"predicted_category_name,omitempty"`
+	ActualCategoryID      *string   `json:"actual_category_id,omitempty"`
+	ActualCategoryName    *string   `json:"actual_category_name,omitempty"`
+	Confidence            int       `json:"confidence"`
+	WasCorrect            bool      `json:"was_correct"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// AccuracySummary holds headline accuracy figures. Percentages are 0-100.
+type AccuracySummary struct {
+	Total                  int     `json:"total"`
+	Correct                int     `json:"correct"`
+	Accuracy               float64 `json:"accuracy"`
+	HighConfidenceTotal    int     `json:"high_confidence_total"`
+	HighConfidenceCorrect  int     `json:"high_confidence_correct"`
+	HighConfidenceAccuracy float64 `json:"high_confidence_accuracy"`
+	AverageConfidence      float64 `json:"average_confidence"`
+}
+
+// CategoryAccuracy holds precision and recall for one category
+type CategoryAccuracy struct {
+	CategoryID    string  `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	Predicted     int     `json:"predicted"`
+	Actual        int     `json:"actual"`
+	TruePositives int     `json:"true_positives"`
+	Precision     float64 `json:"precision"`
+	Recall        float64 `json:"recall"`
+	F1Score       float64 `json:"f1_score"`
+}
+
+// CalibrationBucket compares stated confidence with observed accuracy
+type CalibrationBucket struct {
+	MinConfidence     int     `json:"min_confidence"`
+	MaxConfidence     int     `json:"max_confidence"`
+	Count             int     `json:"count"`
+	AverageConfidence float64 `json:"average_confidence"`
+	Accuracy          float64 `json:"accuracy"`
+}
+
+// AccuracyTrendItem is the accuracy of one month
+type AccuracyTrendItem struct {
+	Month    int     `json:"month"`
+	Year     int     `json:"year"`
+	Total    int     `json:"total"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// CategorizationAccuracy represents the categorization accuracy report
+type CategorizationAccuracy struct {
+	UserID      string                     `json:"user_id"`
+	Source      string                     `json:"source"` // "all", "ai" or "ml"
+	StartDate   time.Time                  `json:"start_date"`
+	EndDate     time.Time                  `json:"end_date"`
+	Overall     AccuracySummary            `json:"overall"`
+	BySource    map[string]AccuracySummary `json:"by_source"`
+	Categories  []CategoryAccuracy         `json:"categories"`
+	Calibration []CalibrationBucket        `json:"calibration"`
+	Trend       []AccuracyTrendItem        `json:"trend"`
+	GeneratedAt time.Time                  `json:"generated_at"`
+}
+
+// MerchantSpendingItem is the spending at one merchant
+type MerchantSpendingItem struct {
+	MerchantID    string  `json:"merchant_id" db:"merchant_id"`
+	MerchantName  string  `json:"merchant_name" db:"merchant_name"`
+	TotalAmount   float64 `json:"total_amount" db:"total_amount"`
+	Count         int     `json:"count" db:"count"`
+	AverageAmount float64 `json:"average_amount" db:"average_amount"`
+}
+
+// MerchantSpending represents per-merchant spending over a date range
+type MerchantSpending struct {
+	UserID             string                 `json:"user_id"`
+	StartDate          time.Time              `json:"start_date"`
+	EndDate            time.Time              `json:"end_date"`
+	TotalExpenses      float64                `json:"total_expenses"`
+	UnattributedAmount float64                `json:"unattributed_amount"`
+	Merchants          []MerchantSpendingItem `json:"merchants"`
+	GeneratedAt        time.Time              `json:"generated_at"`
+}
+
+// TagSummaryItem is the spending under one tag
+type TagSummaryItem struct {
+	Tag         string  `json:"tag" db:"tag"`
+	TotalAmount float64 `json:"total_amount" db:"total_amount"`
+	Count       int     `json:"count" db:"count"`
+}
+
+// TagSummary represents the monthly spending breakdown by tag. A transaction
+// with several tags counts towards each of them.
+type TagSummary struct {
+	UserID         string           `json:"user_id"`
+	Month          int              `json:"month"`
+	Year           int              `json:"year"`
+	TotalExpenses  float64          `json:"total_expenses"`
+	UntaggedAmount float64          `json:"untagged_amount"`
+	Tags           []TagSummaryItem `json:"tags"`
+	GeneratedAt    time.Time        `json:"generated_at"`
+}
+
+// TransactionSearch is a parsed transaction search query. Text holds the
+// free words and quoted phrases in websearch syntax for full-text matching;
+// the remaining fields are structured filters that must all match.
+type TransactionSearch struct {
+	Text            string           `json:"text,omitempty"`
+	Merchants       []string         `json:"merchants,omitempty"`
+	Categories      []string         `json:"categories,omitempty"`
+	Accounts        []string         `json:"accounts,omitempty"`
+	Tags            []string         `json:"tags,omitempty"`
+	TransactionType *TransactionType `json:"transaction_type,omitempty"`
+	MinAmount       *float64         `json:"min_amount,omitempty"`
+	MaxAmount       *float64         `json:"max_amount,omitempty"`
+	MinExclusive    bool             `json:"min_exclusive,omitempty"`
+	MaxExclusive    bool             `json:"max_exclusive,omitempty"`
+	After           *time.Time       `json:"after,omitempty"`
+	Before          *time.Time       `json:"before,omitempty"`
+}
+
+// SearchCursor marks the last result of a search page. Rank is only
+// meaningful for searches with free text.
+type SearchCursor struct {
+	Rank            float32   `json:"r"`
+	TransactionDate time.Time `json:"d"`
+	ID              string    `json:"i"`
+}
+
+// SearchHit is a transaction matched by a search with its relevance rank
+type SearchHit struct {
+	Transaction Transaction `json:"transaction"`
+	Rank        float32     `json:"rank"`
+}
+
+// NetWorthBreakdownItem is the converted total of one account type. Amount is
+// what the accounts are worth for assets and what is owed for liabilities.
+type NetWorthBreakdownItem struct {
+	AccountType  AccountType `json:"account_type"`
+	IsLiability  bool        `json:"is_liability"`
+	Amount       float64     `json:"amount"`
+	AccountCount int         `json:"account_count"`
+}
+
+// NetWorthSnapshot represents the public.net_worth_snapshots table: the
+// user's assets and liabilities on one day in their base currency
+type NetWorthSnapshot struct {
+	ID               string                  `json:"id,omitempty" db:"id"`
+	UserID           string                  `json:"user_id" db:"user_id"`
+	SnapshotDate     time.Time               `json:"snapshot_date" db:"snapshot_date"`
+	BaseCurrency     string                  `json:"base_currency" db:"base_currency"`
+	TotalAssets      float64                 `json:"total_assets" db:"total_assets"`
+	TotalLiabilities float64                 `json:"total_liabilities" db:"total_liabilities"`
+	NetWorth         float64                 `json:"net_worth" db:"net_worth"`
+	Breakdown        []NetWorthBreakdownItem `json:"breakdown" db:"breakdown"`
+	CreatedAt        time.Time               `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at,omitempty" db:"updated_at"`
+
+	// Account currencies left out because no exchange rate to the base
+	// currency was available. Not stored.
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty"`
+}
+
+// NetWorthHistory is the net worth time series between two dates. Current is
+// computed live from today's balances.
+type NetWorthHistory struct {
+	UserID        string             `json:"user_id"`
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	Current       *NetWorthSnapshot  `json:"current"`
+	Snapshots     []NetWorthSnapshot `json:"snapshots"`
+	Change        float64            `json:"change"`                   // Last minus first snapshot
+	ChangePercent *float64           `json:"change_percent,omitempty"` // Unset when the first net worth is zero
+	GeneratedAt   time.Time          `json:"generated_at"`
+}
+
+// RecurringPattern is a repeating transaction detected in the history.
+// Amount is the typical amount with the sign of its effect on the balance.
+type RecurringPattern struct {
+	AccountID       string            `json:"account_id"`
+	Description     string            `json:"description"`
+	MerchantID      *string           `json:"merchant_id,omitempty"`
+	TransactionType TransactionType   `json:"transaction_type"`
+	Amount          float64           `json:"amount"`
+	Frequency       ScheduleFrequency `json:"frequency"`
+	Occurrences     int               `json:"occurrences"`
+	LastDate        time.Time         `json:"last_date"`
+	NextDate        time.Time         `json:"next_date"`
+}
+
+// ForecastEvent is one projected transaction. Amount is signed: negative
+// amounts lower the balance.
+type ForecastEvent struct {
+	Date        time.Time `json:"date"`
+	AccountID   string    `json:"account_id"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+	Source      string    `json:"source"` // "scheduled" or "recurring"
+}
+
+// ForecastDay is an account's projected end-of-day balance
+type ForecastDay struct {
+	Date       time.Time `json:"date"`
+	Balance    float64   `json:"balance"`
+	Change     float64   `json:"change"`
+	IsNegative bool      `json:"is_negative,omitempty"` // Only flagged for checking accounts
+}
+
+// AccountForecast is the projected daily balance of one account
+type AccountForecast struct {
+	AccountID          string        `json:"account_id"`
+	AccountName        string        `json:"account_name"`
+	AccountType        AccountType   `json:"account_type"`
+	StartingBalance    float64       `json:"starting_balance"`
+	EndingBalance      float64       `json:"ending_balance"`
+	LowestBalance      float64       `json:"lowest_balance"`
+	LowestBalanceDate  time.Time     `json:"lowest_balance_date"`
+	DailyDiscretionary float64       `json:"daily_discretionary"` // Average non-recurring spend deducted each day
+	FirstNegativeDate  *time.Time    `json:"first_negative_date,omitempty"`
+	NegativeDays       int           `json:"negative_days"`
+	Days               []ForecastDay `json:"days"`
+}
+
+// CashFlowForecast projects the user's account balances forward
+type CashFlowForecast struct {
+	UserID            string             `json:"user_id"`
+	StartDate         time.Time          `json:"start_date"`
+	EndDate           time.Time          `json:"end_date"`
+	Days              int                `json:"days"`
+	Accounts          []AccountForecast  `json:"accounts"`
+	Events            []ForecastEvent    `json:"events"`
+	RecurringPatterns []RecurringPattern `json:"recurring_patterns"`
+	NegativeAccounts  []string           `json:"negative_accounts"` // Checking accounts projected to go negative
+	GeneratedAt       time.Time          `json:"generated_at"`
+}
+
+// NotificationType enum, matching the notification_type database enum
+type NotificationType string
+
+const (
+	NotificationTypeBudgetAlert      NotificationType = "budget_alert"
+	NotificationTypeGoalMilestone    NotificationType = "goal_milestone"
+	NotificationTypeTransactionAlert NotificationType = "transaction_alert"
+	NotificationTypeInfo             NotificationType = "info"
+)
+
+// Notification represents the public.notifications table
+type Notification struct {
+	ID        string                 `json:"id" db:"id"`
+	UserID    string                 `json:"user_id" db:"user_id"`
+	Title     string                 `json:"title" db:"title"`
+	Message   string                 `json:"message" db:"message"`
+	Type      NotificationType       `json:"type" db:"type"`
+	IsRead    bool                   `json:"is_read" db:"is_read"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	InApp     bool                   `json:"in_app" db:"in_app"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// NotificationChannel is a way of delivering notifications outside the app
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail   NotificationChannel = "email"
+	NotificationChannelWebhook NotificationChannel = "webhook"
+)
+
+// NotificationDeliveryMode is whether notifications of a type are sent as
+// they are raised or collected for the periodic digest
+type NotificationDeliveryMode string
+
+const (
+	NotificationDeliveryImmediate NotificationDeliveryMode = "immediate"
+	NotificationDeliveryDigest    NotificationDeliveryMode = "digest"
+)
+
+// NotificationPreference represents the public.notification_preferences table
+type NotificationPreference struct {
+	UserID            string                   `json:"user_id" db:"user_id"`
+	NotificationType  NotificationType         `json:"notification_type" db:"notification_type"`
+	InApp             bool                     `json:"in_app" db:"in_app"`
+	Email             bool                     `json:"email" db:"email"`
+	Webhook           bool                     `json:"webhook" db:"webhook"`
+	Delivery          NotificationDeliveryMode `json:"delivery" db:"delivery"`
+	RespectQuietHours bool                     `json:"respect_quiet_hours" db:"respect_quiet_hours"`
+}
+
+// NotificationSettings represents the public.notification_settings table.
+// Quiet hours are HH:MM local times in Timezone. The webhook secret is never
+// returned, only whether one is set.
+type NotificationSettings struct {
+	UserID           string          `json:"user_id" db:"user_id"`
+	Email            *string         `json:"email,omitempty" db:"email"`
+	WebhookURL       *string         `json:"webhook_url,omitempty" db:"webhook_url"`
+	WebhookSecret    *string         `json:"-" db:"webhook_secret"`
+	WebhookSecretSet bool            `json:"webhook_secret_set"`
+	QuietHoursStart  *string         `json:"quiet_hours_start,omitempty" db:"quiet_hours_start"`
+	QuietHoursEnd    *string         `json:"quiet_hours_end,omitempty" db:"quiet_hours_end"`
+	Timezone         string          `json:"timezone" db:"timezone"`
+	DigestFrequency  DigestFrequency `json:"digest_frequency" db:"digest_frequency"`
+
+	// Joined fields
+	AccountEmail string `json:"-"` // The address the user signed up with
+}
+
+// NotificationDeliveryStatus is where a delivery is in the outgoing queue
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryPending  NotificationDeliveryStatus = "pending"
+	NotificationDeliveryInDigest NotificationDeliveryStatus = "digest"
+	NotificationDeliverySent     NotificationDeliveryStatus = "sent"
+	NotificationDeliveryFailed   NotificationDeliveryStatus = "failed"
+)
+
+// NotificationDelivery represents the public.notification_deliveries table
+type NotificationDelivery struct {
+	ID             string                     `json:"id" db:"id"`
+	NotificationID string                     `json:"notification_id" db:"notification_id"`
+	UserID         string                     `json:"user_id" db:"user_id"`
+	Channel        NotificationChannel        `json:"channel" db:"channel"`
+	Status         NotificationDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                        `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time                  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError      *string                    `json:"last_error,omitempty" db:"last_error"`
+	SentAt         *time.Time                 `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt      time.Time                  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time                  `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	Notification      *Notification         `json:"notification,omitempty"`
+	Settings          *NotificationSettings `json:"-"`
+	RespectQuietHours bool                  `json:"-"`
+}
+
+// DigestFrequency is how often a user is emailed a digest
+type DigestFrequency string
+
+const (
+	DigestFrequencyOff     DigestFrequency = "off"
+	DigestFrequencyWeekly  DigestFrequency = "weekly"
+	DigestFrequencyMonthly DigestFrequency = "monthly"
+)
+
+// DigestGoal is an active goal's progress in a digest
+type DigestGoal struct {
+	GoalID          string     `json:"goal_id"`
+	Name            string     `json:"name"`
+	TargetAmount    float64    `json:"target_amount"`
+	CurrentAmount   float64    `json:"current_amount"`
+	PercentComplete float64    `json:"percent_complete"`
+	TargetDate      *time.Time `json:"target_date,omitempty"`
+}
+
+// DigestBill is an upcoming scheduled expense in a digest
+type DigestBill struct {
+	ScheduledTransactionID string    `json:"scheduled_transaction_id"`
+	Description            string    `json:"description"`
+	CategoryName           string    `json:"category_name"`
+	Amount                 float64   `json:"amount"`
+	DueDate                time.Time `json:"due_date"`
+}
+
+// NotificationDigest is a summary of a user's finances over a week or a
+// month: spending against the period before, where their budgets stand now,
+// goal progress, the bills due over the next period and the notifications
+// held for the digest.
+type NotificationDigest struct {
+	UserID        string                 `json:"user_id"`
+	Frequency     DigestFrequency        `json:"frequency"`
+	Period        ReportPeriod           `json:"period"`
+	Spending      *PeriodComparison      `json:"spending"`
+	Budgets       []BudgetEvaluation     `json:"budgets"`
+	Goals         []DigestGoal           `json:"goals"`
+	UpcomingBills []DigestBill           `json:"upcoming_bills"`
+	Notifications []NotificationDelivery `json:"notifications"`
+	GeneratedAt   time.Time              `json:"generated_at"`
+}
+
+// WebhookEventType is a finance event webhooks can subscribe to
+type WebhookEventType string
+
+const (
+	WebhookEventTransactionCreated WebhookEventType = "transaction.created"
+	WebhookEventBudgetExceeded     WebhookEventType = "budget.exceeded"
+	WebhookEventGoalCompleted      WebhookEventType = "goal.completed"
+)
+
+// WebhookSubscription represents the public.webhook_subscriptions table.
+// The secret is only returned when the subscription is created or its
+// secret is rotated.
+type WebhookSubscription struct {
+	ID          string             `json:"id" db:"id"`
+	UserID      string             `json:"user_id" db:"user_id"`
+	URL         string             `json:"url" db:"url"`
+	Secret      string             `json:"-" db:"secret"`
+	EventTypes  []WebhookEventType `json:"event_types" db:"event_types"`
+	Description *string            `json:"description,omitempty" db:"description"`
+	IsActive    bool               `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookEvent represents the public.webhook_events table
+type WebhookEvent struct {
+	ID        string           `json:"id" db:"id"`
+	UserID    string           `json:"user_id" db:"user_id"`
+	EventType WebhookEventType `json:"event_type" db:"event_type"`
+	Data      json.RawMessage  `json:"data" db:"data"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+// WebhookDeliveryStatus is where a webhook delivery is in the outgoing queue
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	WebhookDeliverySent    WebhookDeliveryStatus = "sent"
+	WebhookDeliveryFailed  WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery represents the public.webhook_deliveries table
+type WebhookDelivery struct {
+	ID                 string                `json:"id" db:"id"`
+	EventID            string                `json:"event_id" db:"event_id"`
+	SubscriptionID     string                `json:"subscription_id" db:"subscription_id"`
+	UserID             string                `json:"user_id" db:"user_id"`
+	Status             WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts           int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt      time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	LastResponseStatus *int                  `json:"last_response_status,omitempty" db:"last_response_status"`
+	LastError          *string               `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt        *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+	ReplayOf           *string               `json:"replay_of,omitempty" db:"replay_of"`
+	CreatedAt          time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time             `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	Event        *WebhookEvent            `json:"event,omitempty"`
+	Subscription *WebhookSubscription     `json:"-"`
+	AttemptLog   []WebhookDeliveryAttempt `json:"attempt_log,omitempty"`
+}
+
+// WebhookDeliveryAttempt represents the public.webhook_delivery_attempts
+// table: one attempt at a delivery. ResponseStatus is unset when no
+// response came back.
+type WebhookDeliveryAttempt struct {
+	ID             string    `json:"id" db:"id"`
+	DeliveryID     string    `json:"delivery_id" db:"delivery_id"`
+	Attempt        int       `json:"attempt" db:"attempt"`
+	ResponseStatus *int      `json:"response_status,omitempty" db:"response_status"`
+	Error          *string   `json:"error,omitempty" db:"error"`
+	DurationMs     int       `json:"duration_ms" db:"duration_ms"`
+	AttemptedAt    time.Time `json:"attempted_at" db:"attempted_at"`
+}
+
+// ExpenseLine is one expense line from the transaction_lines view: a whole
+// transaction, or one split line of a split transaction. Amount is positive.
+type ExpenseLine struct {
+	TransactionID   string    `json:"transaction_id"`
+	AccountID       string    `json:"account_id"`
+	CategoryID      *string   `json:"category_id,omitempty"`
+	CategoryName    string    `json:"category_name"`
+	Description     *string   `json:"description,omitempty"`
+	Amount          float64   `json:"amount"`
+	TransactionDate time.Time `json:"transaction_date"`
+}
+
+// TransactionAnomaly is an expense far above what the user usually spends
+// in its category
+type TransactionAnomaly struct {
+	TransactionID   string    `json:"transaction_id"`
+	AccountID       string    `json:"account_id"`
+	CategoryID      *string   `json:"category_id,omitempty"`
+	CategoryName    string    `json:"category_name"`
+	Description     *string   `json:"description,omitempty"`
+	TransactionDate time.Time `json:"transaction_date"`
+	Amount          float64   `json:"amount"`
+	BaselineMedian  float64   `json:"baseline_median"`
+	BaselineMAD     float64   `json:"baseline_mad"` // Median absolute deviation
+	SampleSize      int       `json:"sample_size"`
+	Score           float64   `json:"score"` // Robust z-score
+}
+
+// CategoryMonthAnomaly is a month in which spending in a category was far
+// above its usual monthly total
+type CategoryMonthAnomaly struct {
+	CategoryID        *string  `json:"category_id,omitempty"`
+	CategoryName      string   `json:"category_name"`
+	Month             int      `json:"month"`
+	Year              int      `json:"year"`
+	Total             float64  `json:"total"`
+	BaselineMedian    float64  `json:"baseline_median"`
+	BaselineMAD       float64  `json:"baseline_mad"`
+	MonthsOfHistory   int      `json:"months_of_history"`
+	SameMonthLastYear *float64 `json:"same_month_last_year,omitempty"`
+	Score             float64  `json:"score"`
+	MonthToDate       bool     `json:"month_to_date,omitempty"` // The month is not over yet
+}
+
+// AnomalyReport lists the unusual spending found in a period
+type AnomalyReport struct {
+	UserID               string                 `json:"user_id"`
+	StartDate            time.Time              `json:"start_date"`
+	EndDate              time.Time              `json:"end_date"`
+	Threshold            float64                `json:"threshold"`
+	Transactions         []TransactionAnomaly   `json:"transactions"`
+	CategoryMonths       []CategoryMonthAnomaly `json:"category_months"`
+	NotificationsCreated int                    `json:"notifications_created"`
+	GeneratedAt          time.Time              `json:"generated_at"`
+}
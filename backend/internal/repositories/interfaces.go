@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/personal-finance-management/backend/internal/models"
@@ -52,6 +53,208 @@ type TransactionRepository interface {
 	Delete(ctx context.Context, id string) error
 }
 
+// TransactionSplitRepository defines transaction persistence including split lines
+type TransactionSplitRepository interface {
+	GetTransactionByID(ctx context.Context, id string) (*models.Transaction, error)
+	GetTransactionSplits(ctx context.Context, transactionID string) ([]models.TransactionSplit, error)
+	CreateTransaction(ctx context.Context, transaction *models.Transaction) error
+	UpdateTransaction(ctx context.Context, transaction *models.Transaction) error
+	DeleteTransaction(ctx context.Context, id string) error
+	MergeTransactions(ctx context.Context, keep *models.Transaction, duplicateID string) error
+}
+
+// TransactionSearchRepository defines the interface for transaction search
+type TransactionSearchRepository interface {
+	SearchTransactions(ctx context.Context, userID string, search *models.TransactionSearch, cursor *models.SearchCursor, limit int) ([]models.SearchHit, error)
+}
+
+// ErrTransferNotFound is returned when a transfer_id does not name exactly
+// one outgoing and one incoming leg
+var ErrTransferNotFound = errors.New("transfer not found")
+
+// TransferRepository defines the interface for linked transfer operations
+type TransferRepository interface {
+	CreateTransfer(ctx context.Context, transfer *models.Transfer) error
+	GetTransferByID(ctx context.Context, transferID string) (*models.Transfer, error)
+	UpdateTransfer(ctx context.Context, transfer *models.Transfer) error
+	DeleteTransfer(ctx context.Context, transferID string) error
+	GetLatestExchangeRate(ctx context.Context, baseCurrency, targetCurrency string) (float64, error)
+}
+
+// ReconciliationRepository defines the interface for statement reconciliation operations
+type ReconciliationRepository interface {
+	CreateReconciliation(ctx context.Context, reconciliation *models.Reconciliation) error
+	GetReconciliationByID(ctx context.Context, id string) (*models.Reconciliation, error)
+	GetReconciliationsByUserID(ctx context.Context, userID string, accountID *string) ([]models.Reconciliation, error)
+	GetClearedBalance(ctx context.Context, accountID, reconciliationID string) (float64, int, error)
+	GetReconciliationTransactions(ctx context.Context, reconciliation *models.Reconciliation) ([]models.Transaction, error)
+	SetTransactionsCleared(ctx context.Context, reconciliation *models.Reconciliation, transactionIDs []string, cleared bool) (int64, error)
+	CompleteReconciliation(ctx context.Context, reconciliation *models.Reconciliation) error
+	CancelReconciliation(ctx context.Context, id string) error
+	UnlockTransaction(ctx context.Context, id string) error
+}
+
+// CategorizationRuleRepository defines the interface for auto-categorization rule operations
+type CategorizationRuleRepository interface {
+	GetRulesByUserID(ctx context.Context, userID string) ([]models.CategorizationRule, error)
+	GetRuleByID(ctx context.Context, id string) (*models.CategorizationRule, error)
+	CreateRule(ctx context.Context, rule *models.CategorizationRule) error
+	UpdateRule(ctx context.Context, rule *models.CategorizationRule) error
+	DeleteRule(ctx context.Context, id string) error
+	ApplyRuleChanges(ctx context.Context, userID string, transactions []models.Transaction) (int64, error)
+}
+
+// MLRepository defines the interface for server-side ML categorization persistence
+type MLRepository interface {
+	GetUserMLModel(ctx context.Context, userID string) (*models.UserMLModel, error)
+	SaveUserMLModel(ctx context.Context, model *models.UserMLModel) error
+	GetCategorizedTrainingExamples(ctx context.Context, userID string) ([]models.TrainingExample, error)
+	GetFeedbackTrainingExamples(ctx context.Context, userID string, since *time.Time) ([]models.TrainingExample, error)
+	CreateMLPrediction(ctx context.Context, prediction *models.MLPrediction) error
+	CreateMLFeedback(ctx context.Context, feedback *models.MLFeedback) error
+	CreateMLTrainingSession(ctx context.Context, session *models.MLTrainingSession) error
+}
+
+// CategorizationAnalyticsRepository defines the interface for auto-categorization accuracy data
+type CategorizationAnalyticsRepository interface {
+	GetCategorizationOutcomes(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.CategorizationOutcome, error)
+}
+
+// MerchantRepository defines the interface for merchant data operations
+type MerchantRepository interface {
+	GetMerchantsByUserID(ctx context.Context, userID string) ([]models.Merchant, error)
+	GetMerchantByID(ctx context.Context, id string) (*models.Merchant, error)
+	FindOrCreateMerchant(ctx context.Context, merchant *models.Merchant) error
+	UpdateMerchant(ctx context.Context, merchant *models.Merchant) error
+	DeleteMerchant(ctx context.Context, id string) error
+	GetMerchantAliases(ctx context.Context, userID string, merchantID *string) ([]models.MerchantAlias, error)
+	GetMerchantAliasByID(ctx context.Context, id string) (*models.MerchantAlias, error)
+	CreateMerchantAlias(ctx context.Context, alias *models.MerchantAlias) error
+	DeleteMerchantAlias(ctx context.Context, id string) error
+	SetTransactionMerchants(ctx context.Context, userID string, transactions []models.Transaction) (int64, error)
+	GetMerchantSpending(ctx context.Context, userID string, startDate, endDate time.Time, limit int) (*models.MerchantSpending, error)
+}
+
+// CustomFieldRepository defines the interface for custom field definitions
+type CustomFieldRepository interface {
+	GetCustomFieldDefinitions(ctx context.Context, userID string) ([]models.CustomFieldDefinition, error)
+	GetCustomFieldDefinitionByID(ctx context.Context, id string) (*models.CustomFieldDefinition, error)
+	CreateCustomFieldDefinition(ctx context.Context, def *models.CustomFieldDefinition) error
+	UpdateCustomFieldDefinition(ctx context.Context, def *models.CustomFieldDefinition) error
+	DeleteCustomFieldDefinition(ctx context.Context, def *models.CustomFieldDefinition) error
+}
+
+// AttachmentRepository defines the interface for attachment metadata and links
+type AttachmentRepository interface {
+	GetAttachmentsByUserID(ctx context.Context, userID string, transactionID, taxDocumentID *string) ([]models.Attachment, error)
+	GetAttachmentByID(ctx context.Context, id string) (*models.Attachment, error)
+	FindOrCreateAttachment(ctx context.Context, attachment *models.Attachment) (bool, error)
+	UpdateAttachment(ctx context.Context, attachment *models.Attachment) error
+	DeleteAttachment(ctx context.Context, attachment *models.Attachment) error
+	GetAttachmentLinkByID(ctx context.Context, id string) (*models.AttachmentLink, error)
+	CreateAttachmentLink(ctx context.Context, link *models.AttachmentLink) error
+	DeleteAttachmentLink(ctx context.Context, link *models.AttachmentLink) error
+	GetTaxDocumentUserID(ctx context.Context, id string) (string, error)
+}
+
+// NetWorthRepository defines the interface for net worth snapshot operations
+type NetWorthRepository interface {
+	GetActiveAccountsByUserID(ctx context.Context, userID string) ([]models.Account, error)
+	GetBaseCurrency(ctx context.Context, userID string) (string, error)
+	GetUserIDsWithActiveAccounts(ctx context.Context) ([]string, error)
+	UpsertNetWorthSnapshot(ctx context.Context, snapshot *models.NetWorthSnapshot) error
+	GetNetWorthSnapshots(ctx context.Context, userID string, from, to time.Time) ([]models.NetWorthSnapshot, error)
+}
+
+// ScheduledTransactionRepository defines the interface for scheduled transaction operations
+type ScheduledTransactionRepository interface {
+	GetScheduledTransactionsByUserID(ctx context.Context, userID string, activeOnly bool) ([]models.ScheduledTransaction, error)
+	GetScheduledTransactionByID(ctx context.Context, id string) (*models.ScheduledTransaction, error)
+	CreateScheduledTransaction(ctx context.Context, scheduled *models.ScheduledTransaction) error
+	UpdateScheduledTransaction(ctx context.Context, scheduled *models.ScheduledTransaction) error
+	DeleteScheduledTransaction(ctx context.Context, id string) error
+}
+
+// NotificationRepository defines the interface for creating notifications
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, notification *models.Notification, dedupeKey string) (bool, error)
+}
+
+// NotificationDeliveryRepository defines the interface for notification preferences and the delivery queue
+type NotificationDeliveryRepository interface {
+	GetNotificationSettings(ctx context.Context, userID string) (*models.NotificationSettings, error)
+	UpsertNotificationSettings(ctx context.Context, settings *models.NotificationSettings) error
+	GetNotificationPreferences(ctx context.Context, userID string) ([]models.NotificationPreference, error)
+	UpsertNotificationPreference(ctx context.Context, preference *models.NotificationPreference) error
+	ClaimDueNotificationDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.NotificationDelivery, error)
+	MarkNotificationDeliverySent(ctx context.Context, id string, attempts int, sentAt time.Time) error
+	RescheduleNotificationDelivery(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, lastError *string) error
+	MarkNotificationDeliveryFailed(ctx context.Context, id string, attempts int, lastError string) error
+	GetNotificationDeliveries(ctx context.Context, userID string, status models.NotificationDeliveryStatus, limit int) ([]models.NotificationDelivery, error)
+}
+
+// NotificationDigestRepository defines the interface for scheduling and sending notification digests
+type NotificationDigestRepository interface {
+	GetDigestRecipients(ctx context.Context) ([]models.NotificationSettings, error)
+	ClaimNotificationDigest(ctx context.Context, userID string, frequency models.DigestFrequency, periodStart, periodEnd, now, retryBefore time.Time, maxAttempts int) (string, bool, error)
+	MarkNotificationDigestSent(ctx context.Context, id string, sentAt time.Time) error
+	MarkNotificationDigestFailed(ctx context.Context, id string, lastError string) error
+	GetDigestNotificationDeliveries(ctx context.Context, userID string, limit int) ([]models.NotificationDelivery, error)
+	MarkDigestDeliveriesSent(ctx context.Context, ids []string, sentAt time.Time) error
+}
+
+// WebhookRepository defines the interface for webhook subscriptions and their deliveries
+type WebhookRepository interface {
+	GetWebhookSubscriptionsByUserID(ctx context.Context, userID string) ([]models.WebhookSubscription, error)
+	GetWebhookSubscriptionByID(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	CreateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	UpdateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	DeleteWebhookSubscription(ctx context.Context, id string) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionID string, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error)
+	GetWebhookDeliveryByID(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, id string, now time.Time) (*models.WebhookDelivery, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt, status models.WebhookDeliveryStatus, nextAttemptAt time.Time) error
+}
+
+// InsightsRepository defines the interface for spending insight data
+type InsightsRepository interface {
+	GetExpenseLines(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.ExpenseLine, error)
+}
+
+// FinancialStatementsRepository defines the data behind income statements and balance sheets
+type FinancialStatementsRepository interface {
+	GetCategoriesByUserID(ctx context.Context, userID string) ([]models.Category, error)
+	GetCategoryByID(ctx context.Context, id string) (*models.Category, error)
+	GetAccountBalancesAsOf(ctx context.Context, userID string, asOf time.Time) ([]models.Account, error)
+}
+
+// ErrQueryTimeout is returned when an ad-hoc analytics query runs past its time limit
+var ErrQueryTimeout = errors.New("query exceeded its time limit")
+
+// AnalyticsRepository defines the interface for ad-hoc analytics queries
+type AnalyticsRepository interface {
+	RunPivotQuery(ctx context.Context, userID string, query models.PivotQuery) (*models.PivotResult, error)
+}
+
+// ReportChangeRepository defines the interface for following changes to report data
+type ReportChangeRepository interface {
+	ListenForReportChanges(ctx context.Context, onChange func(userID string)) error
+}
+
+// ReportAggregateRepository defines the interface for the maintained monthly report totals
+type ReportAggregateRepository interface {
+	GetPeriodCategorySpending(ctx context.Context, userID string, startDate, endDate time.Time) (map[string]float64, error)
+	RebuildMonthlyCategoryTotals(ctx context.Context, userID string) (int, error)
+	GetUserIDsWithTransactions(ctx context.Context) ([]string, error)
+}
+
+// BudgetAlertRepository defines the interface for reading budgets to alert on
+type BudgetAlertRepository interface {
+	GetBudgetsByUserID(ctx context.Context, userID string) ([]models.Budget, error)
+	GetUserIDsWithActiveBudgets(ctx context.Context) ([]string, error)
+}
+
 // BudgetRepository defines the interface for budget data operations
 type BudgetRepository interface {
 	GetByUserID(ctx context.Context, userID string) ([]models.Budget, error)
@@ -74,6 +277,9 @@ type GoalRepository interface {
 // ReportsRepository defines the interface for reporting operations
 type ReportsRepository interface {
 	GetMonthlySummary(ctx context.Context, userID string, month, year int) (*models.MonthlySummary, error)
-	GetSpendingTrends(ctx context.Context, userID string, categoryID *string, months int) (*models.SpendingTrends, error)
+	GetTagSummary(ctx context.Context, userID string, month, year int) (*models.TagSummary, error)
+	GetSpendingTrends(ctx context.Context, userID string, query models.SpendingTrendsQuery) (*models.SpendingTrends, error)
 	GetCashFlow(ctx context.Context, userID string, startDate, endDate time.Time) (*models.CashFlow, error)
+	GetCategorySpending(ctx context.Context, userID string, startDate, endDate time.Time) (map[string]float64, error)
+	GetCategoryTotals(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.CategoryTotal, error)
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// Account Repository Implementation
+func (r *PostgresRepositories) GetAccountByID(ctx context.Context, id string) (*models.Account, error) {
+	query := `
+		SELECT id, user_id, name, account_type, balance, description, COALESCE(currency, 'USD'),
+		       is_active, created_at, updated_at
+		FROM public.accounts
+		WHERE id = $1`
+
+	account := &models.Account{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&account.ID,
+		&account.UserID,
+		&account.Name,
+		&account.AccountType,
+		&account.Balance,
+		&account.Description,
+		&account.Currency,
+		&account.IsActive,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account by ID: %w", err)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Report Aggregate Repository Implementation
+
+// GetPeriodCategorySpending returns expense totals per category from
+// startDate to endDate inclusive. Whole calendar months in the range are read
+// from the maintained monthly totals; only the partial months at either end
+// are summed from transaction lines. Uncategorized spending is keyed by the
+// empty string.
+func (r *PostgresRepositories) GetPeriodCategorySpending(ctx context.Context, userID string, startDate, endDate time.Time) (map[string]float64, error) {
+	wholeFrom, wholeTo := wholeMonths(startDate, endDate)
+
+	query := `
+		SELECT category_id, SUM(amount)
+		FROM (
+			SELECT COALESCE(category_id::text, '') AS category_id, total_amount AS amount
+			FROM public.monthly_category_totals
+			WHERE user_id = $1
+			  AND transaction_type = 'expense'
+			  AND month >= $4 AND month < $5
+			UNION ALL
+			SELECT COALESCE(category_id::text, ''), ABS(amount)
+			FROM public.transaction_lines
+			WHERE user_id = $1
+			  AND transaction_type = 'expense'
+			  AND transaction_date >= $2 AND transaction_date <= $3
+			  AND NOT (transaction_date >= $4 AND transaction_date < $5)
+		) spending
+		GROUP BY category_id`
+
+	rows, err := r.pool.Query(ctx, query, userID, startDate, endDate, wholeFrom, wholeTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category spending: %w", err)
+	}
+	defer rows.Close()
+
+	spending := make(map[string]float64)
+	for rows.Next() {
+		var categoryID string
+		var amount float64
+		if err := rows.Scan(&categoryID, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan category spending: %w", err)
+		}
+		spending[categoryID] = amount
+	}
+
+	return spending, nil
+}
+
+// wholeMonths returns the calendar months lying entirely within startDate to
+// endDate inclusive, as the first day of the first such month and the first
+// day after the last. Both are equal when there is none.
+func wholeMonths(startDate, endDate time.Time) (from, to time.Time) {
+	from = time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if startDate.Day() != 1 {
+		from = from.AddDate(0, 1, 0)
+	}
+	after := endDate.AddDate(0, 0, 1)
+	to = time.Date(after.Year(), after.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if to.Before(from) {
+		to = from
+	}
+	return from, to
+}
+
+// RebuildMonthlyCategoryTotals recomputes all of the user's monthly totals
+// from their transactions, returning how many months have activity.
+func (r *PostgresRepositories) RebuildMonthlyCategoryTotals(ctx context.Context, userID string) (int, error) {
+	var months int
+	err := r.pool.QueryRow(ctx, `SELECT public.rebuild_monthly_category_totals($1)`, userID).Scan(&months)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rebuild monthly totals: %w", err)
+	}
+	return months, nil
+}
+
+// GetUserIDsWithTransactions returns every user with at least one transaction
+func (r *PostgresRepositories) GetUserIDsWithTransactions(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT user_id FROM public.transactions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user ID: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return userIDs, nil
+}
+
+// Interface compliance check
+var _ repositories.ReportAggregateRepository = (*PostgresRepositories)(nil)
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWholeMonths(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	for name, tc := range map[string]struct {
+		start, end, from, to time.Time
+	}{
+		"calendar month":  {date(2026, 3, 1), date(2026, 3, 31), date(2026, 3, 1), date(2026, 4, 1)},
+		"quarter":         {date(2026, 1, 1), date(2026, 3, 31), date(2026, 1, 1), date(2026, 4, 1)},
+		"partial ends":    {date(2026, 1, 15), date(2026, 4, 14), date(2026, 2, 1), date(2026, 4, 1)},
+		"month to date":   {date(2026, 3, 1), date(2026, 3, 18), date(2026, 3, 1), date(2026, 3, 1)},
+		"within a month":  {date(2026, 3, 9), date(2026, 3, 15), date(2026, 4, 1), date(2026, 4, 1)},
+		"across year end": {date(2025, 12, 10), date(2026, 2, 28), date(2026, 1, 1), date(2026, 3, 1)},
+	} {
+		from, to := wholeMonths(tc.start, tc.end)
+		assert.Equal(t, tc.from, from, name)
+		assert.Equal(t, tc.to, to, name)
+	}
+}
@@ -0,0 +1,331 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Attachment Repository Implementation
+
+// GetAttachmentsByUserID returns the user's attachments, newest first. When
+// transactionID or taxDocumentID is set only attachments linked to it are
+// returned.
+func (r *PostgresRepositories) GetAttachmentsByUserID(ctx context.Context, userID string, transactionID, taxDocumentID *string) ([]models.Attachment, error) {
+	query := `
+		SELECT a.id, a.user_id, a.file_name, a.content_type, a.file_size, a.content_hash,
+		       a.storage_key, a.created_at, a.updated_at
+		FROM public.attachments a
+		WHERE a.user_id = $1
+		  AND ($2::uuid IS NULL OR EXISTS (
+		    SELECT 1 FROM public.attachment_links l
+		    WHERE l.attachment_id = a.id AND l.transaction_id = $2::uuid
+		  ))
+		  AND ($3::uuid IS NULL OR EXISTS (
+		    SELECT 1 FROM public.attachment_links l
+		    WHERE l.attachment_id = a.id AND l.tax_document_id = $3::uuid
+		  ))
+		ORDER BY a.created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID, transactionID, taxDocumentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []models.Attachment
+	for rows.Next() {
+		var a models.Attachment
+		if err := scanAttachment(rows, &a); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	rows.Close()
+
+	if err := r.attachLinks(ctx, attachments); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *PostgresRepositories) GetAttachmentByID(ctx context.Context, id string) (*models.Attachment, error) {
+	query := `
+		SELECT a.id, a.user_id, a.file_name, a.content_type, a.file_size, a.content_hash,
+		       a.storage_key, a.created_at, a.updated_at
+		FROM public.attachments a
+		WHERE a.id = $1`
+
+	a := &models.Attachment{}
+	if err := scanAttachment(r.pool.QueryRow(ctx, query, id), a); err != nil {
+		return nil, err
+	}
+
+	attachments := []models.Attachment{*a}
+	if err := r.attachLinks(ctx, attachments); err != nil {
+		return nil, err
+	}
+
+	return &attachments[0], nil
+}
+
+// FindOrCreateAttachment records an uploaded file. When the user already has
+// a file with the same content hash, the existing attachment is loaded into
+// attachment instead and created is false.
+func (r *PostgresRepositories) FindOrCreateAttachment(ctx context.Context, attachment *models.Attachment) (bool, error) {
+	query := `
+		INSERT INTO public.attachments (user_id, file_name, content_type, file_size, content_hash, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, content_hash) DO UPDATE
+		SET content_hash = EXCLUDED.content_hash
+		RETURNING id, user_id, file_name, content_type, file_size, content_hash,
+		          storage_key, created_at, updated_at, (xmax = 0) AS created`
+
+	var created bool
+	err := r.pool.QueryRow(ctx, query,
+		attachment.UserID,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.FileSize,
+		attachment.ContentHash,
+		attachment.StorageKey,
+	).Scan(
+		&attachment.ID,
+		&attachment.UserID,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.FileSize,
+		&attachment.ContentHash,
+		&attachment.StorageKey,
+		&attachment.CreatedAt,
+		&attachment.UpdatedAt,
+		&created,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	if !created {
+		attachments := []models.Attachment{*attachment}
+		if err := r.attachLinks(ctx, attachments); err != nil {
+			return false, err
+		}
+		*attachment = attachments[0]
+	}
+
+	return created, nil
+}
+
+func (r *PostgresRepositories) UpdateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	query := `
+		UPDATE public.attachments
+		SET file_name = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.pool.QueryRow(ctx, query, attachment.ID, attachment.FileName).Scan(&attachment.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update attachment: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAttachment removes the attachment and its links, clearing the file
+// reference of any tax document that pointed at it. The stored object is
+// left for the caller to delete.
+func (r *PostgresRepositories) DeleteAttachment(ctx context.Context, attachment *models.Attachment) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE public.tax_documents d
+		SET file_path = NULL, file_size = NULL
+		FROM public.attachment_links l
+		WHERE l.attachment_id = $1 AND l.tax_document_id = d.id AND d.file_path = $2`,
+		attachment.ID, attachment.StorageKey)
+	if err != nil {
+		return fmt.Errorf("failed to clear tax document files: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM public.attachments WHERE id = $1`, attachment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("attachment not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) GetAttachmentLinkByID(ctx context.Context, id string) (*models.AttachmentLink, error) {
+	query := `
+		SELECT id, user_id, attachment_id, transaction_id, tax_document_id, created_at
+		FROM public.attachment_links
+		WHERE id = $1`
+
+	l := &models.AttachmentLink{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(&l.ID, &l.UserID, &l.AttachmentID, &l.TransactionID, &l.TaxDocumentID, &l.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment link by ID: %w", err)
+	}
+
+	return l, nil
+}
+
+// CreateAttachmentLink links an attachment to a transaction or tax document.
+// Linking a tax document also records the file on the document itself.
+func (r *PostgresRepositories) CreateAttachmentLink(ctx context.Context, link *models.AttachmentLink) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO public.attachment_links (user_id, attachment_id, transaction_id, tax_document_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		link.UserID,
+		link.AttachmentID,
+		link.TransactionID,
+		link.TaxDocumentID,
+	).Scan(&link.ID, &link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment link: %w", err)
+	}
+
+	if link.TaxDocumentID != nil {
+		_, err = tx.Exec(ctx, `
+			UPDATE public.tax_documents d
+			SET file_path = a.storage_key, file_size = a.file_size, uploaded_at = NOW()
+			FROM public.attachments a
+			WHERE d.id = $1 AND a.id = $2`,
+			*link.TaxDocumentID, link.AttachmentID)
+		if err != nil {
+			return fmt.Errorf("failed to update tax document file: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAttachmentLink removes the link, clearing the tax document's file
+// reference when it still points at the linked attachment.
+func (r *PostgresRepositories) DeleteAttachmentLink(ctx context.Context, link *models.AttachmentLink) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if link.TaxDocumentID != nil {
+		_, err = tx.Exec(ctx, `
+			UPDATE public.tax_documents d
+			SET file_path = NULL, file_size = NULL
+			FROM public.attachments a
+			WHERE d.id = $1 AND a.id = $2 AND d.file_path = a.storage_key`,
+			*link.TaxDocumentID, link.AttachmentID)
+		if err != nil {
+			return fmt.Errorf("failed to clear tax document file: %w", err)
+		}
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM public.attachment_links WHERE id = $1`, link.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment link: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("attachment link not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetTaxDocumentUserID returns the owner of a tax document.
+func (r *PostgresRepositories) GetTaxDocumentUserID(ctx context.Context, id string) (string, error) {
+	var userID string
+	err := r.pool.QueryRow(ctx, `SELECT user_id FROM public.tax_documents WHERE id = $1`, id).Scan(&userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tax document: %w", err)
+	}
+
+	return userID, nil
+}
+
+// scanAttachment scans the attachment columns selected by the queries above.
+func scanAttachment(row pgx.Row, a *models.Attachment) error {
+	err := row.Scan(
+		&a.ID,
+		&a.UserID,
+		&a.FileName,
+		&a.ContentType,
+		&a.FileSize,
+		&a.ContentHash,
+		&a.StorageKey,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to scan attachment: %w", err)
+	}
+	return nil
+}
+
+// attachLinks loads the links of each attachment in place.
+func (r *PostgresRepositories) attachLinks(ctx context.Context, attachments []models.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(attachments))
+	index := make(map[string]int, len(attachments))
+	for i, a := range attachments {
+		ids[i] = a.ID
+		index[a.ID] = i
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, attachment_id, transaction_id, tax_document_id, created_at
+		FROM public.attachment_links
+		WHERE attachment_id = ANY($1::uuid[])
+		ORDER BY created_at`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get attachment links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.AttachmentLink
+		if err := rows.Scan(&l.ID, &l.UserID, &l.AttachmentID, &l.TransactionID, &l.TaxDocumentID, &l.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan attachment link: %w", err)
+		}
+		i := index[l.AttachmentID]
+		attachments[i].Links = append(attachments[i].Links, l)
+	}
+
+	return nil
+}
+
+// Interface compliance check
+var _ repositories.AttachmentRepository = (*PostgresRepositories)(nil)
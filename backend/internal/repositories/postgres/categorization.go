@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Categorization Analytics Repository Implementation
+
+// GetCategorizationOutcomes returns every reviewed prediction in the date
+// range from both the AI categorizer logs and ML feedback, oldest first.
+// AI logs only count once the user has confirmed or corrected them.
+func (r *PostgresRepositories) GetCategorizationOutcomes(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.CategorizationOutcome, error) {
+	query := `
+		SELECT o.source, o.predicted_category_id::text, pc.name, o.actual_category_id::text, ac.name,
+		       o.confidence, o.was_correct, o.created_at
+		FROM (
+			SELECT 'ai' AS source, predicted_category_id, actual_category_id, confidence,
+			       was_correct, created_at
+			FROM public.ai_categorization_logs
+			WHERE user_id = $1 AND was_correct IS NOT NULL
+			  AND created_at >= $2 AND created_at < $3
+			UNION ALL
+			SELECT 'ml' AS source, predicted_category_id, actual_category_id, confidence,
+			       was_correct, created_at
+			FROM public.ml_feedback
+			WHERE user_id = $1
+			  AND created_at >= $2 AND created_at < $3
+		) o
+		LEFT JOIN public.categories pc ON o.predicted_category_id = pc.id AND pc.user_id = $1
+		LEFT JOIN public.categories ac ON o.actual_category_id = ac.id AND ac.user_id = $1
+		ORDER BY o.created_at`
+
+	rows, err := r.pool.Query(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categorization outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var outcomes []models.CategorizationOutcome
+	for rows.Next() {
+		var o models.CategorizationOutcome
+		err := rows.Scan(
+			&o.Source,
+			&o.PredictedCategoryID,
+			&o.PredictedCategoryName,
+			&o.ActualCategoryID,
+			&o.ActualCategoryName,
+			&o.Confidence,
+			&o.WasCorrect,
+			&o.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan categorization outcome: %w", err)
+		}
+		outcomes = append(outcomes, o)
+	}
+
+	return outcomes, nil
+}
+
+// Interface compliance check
+var _ repositories.CategorizationAnalyticsRepository = (*PostgresRepositories)(nil)
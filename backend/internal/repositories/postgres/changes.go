@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Report Change Repository Implementation
+
+// reportChangeChannel is the channel the report data triggers notify on
+const reportChangeChannel = "report_data_changed"
+
+// ListenForReportChanges calls onChange with the user's ID whenever data
+// behind their reports is committed, blocking until the context is cancelled
+// or the connection fails. It holds a dedicated connection taken out of the
+// pool for as long as it runs.
+func (r *PostgresRepositories) ListenForReportChanges(ctx context.Context, onChange func(userID string)) error {
+	pooled, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+reportChangeChannel); err != nil {
+		return fmt.Errorf("failed to listen for report changes: %w", err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for report changes: %w", err)
+		}
+		if notification.Payload != "" {
+			onChange(notification.Payload)
+		}
+	}
+}
+
+// Interface compliance check
+var _ repositories.ReportChangeRepository = (*PostgresRepositories)(nil)
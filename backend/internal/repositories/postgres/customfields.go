@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Custom Field Repository Implementation
+
+func (r *PostgresRepositories) GetCustomFieldDefinitions(ctx context.Context, userID string) ([]models.CustomFieldDefinition, error) {
+	query := `
+		SELECT id, user_id, name, field_type, options, created_at, updated_at
+		FROM public.custom_field_definitions
+		WHERE user_id = $1
+		ORDER BY name`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom field definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var defs []models.CustomFieldDefinition
+	for rows.Next() {
+		var def models.CustomFieldDefinition
+		err := rows.Scan(&def.ID, &def.UserID, &def.Name, &def.FieldType, &def.Options, &def.CreatedAt, &def.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custom field definition: %w", err)
+		}
+		defs = append(defs, def)
+	}
+
+	return defs, nil
+}
+
+func (r *PostgresRepositories) GetCustomFieldDefinitionByID(ctx context.Context, id string) (*models.CustomFieldDefinition, error) {
+	query := `
+		SELECT id, user_id, name, field_type, options, created_at, updated_at
+		FROM public.custom_field_definitions
+		WHERE id = $1`
+
+	def := &models.CustomFieldDefinition{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(&def.ID, &def.UserID, &def.Name, &def.FieldType, &def.Options, &def.CreatedAt, &def.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom field definition by ID: %w", err)
+	}
+
+	return def, nil
+}
+
+func (r *PostgresRepositories) CreateCustomFieldDefinition(ctx context.Context, def *models.CustomFieldDefinition) error {
+	query := `
+		INSERT INTO public.custom_field_definitions (user_id, name, field_type, options)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		def.UserID,
+		def.Name,
+		def.FieldType,
+		tagsParam(def.Options),
+	).Scan(&def.ID, &def.CreatedAt, &def.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create custom field definition: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) UpdateCustomFieldDefinition(ctx context.Context, def *models.CustomFieldDefinition) error {
+	query := `
+		UPDATE public.custom_field_definitions
+		SET name = $2, options = $3, updated_at = NOW()
+		WHERE id = $1 AND user_id = $4
+		RETURNING updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		def.ID,
+		def.Name,
+		tagsParam(def.Options),
+		def.UserID,
+	).Scan(&def.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update custom field definition: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCustomFieldDefinition removes the definition together with the
+// values stored under it on the user's transactions.
+func (r *PostgresRepositories) DeleteCustomFieldDefinition(ctx context.Context, def *models.CustomFieldDefinition) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE public.transactions
+		SET custom_fields = custom_fields - $1::text
+		WHERE user_id = $2 AND custom_fields ? $1::text`,
+		def.ID, def.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to remove custom field values: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM public.custom_field_definitions WHERE id = $1`, def.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete custom field definition: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("custom field definition not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Interface compliance check
+var _ repositories.CustomFieldRepository = (*PostgresRepositories)(nil)
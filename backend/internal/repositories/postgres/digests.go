@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Notification Digest Repository Implementation
+
+// GetDigestRecipients returns the settings of every user with digests turned on
+func (r *PostgresRepositories) GetDigestRecipients(ctx context.Context) ([]models.NotificationSettings, error) {
+	query := `
+		SELECT s.user_id, COALESCE(u.email, ''), s.email, s.timezone, s.digest_frequency
+		FROM public.notification_settings s
+		JOIN auth.users u ON u.id = s.user_id
+		WHERE s.digest_frequency <> 'off'
+		ORDER BY s.user_id`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []models.NotificationSettings
+	for rows.Next() {
+		var s models.NotificationSettings
+		if err := rows.Scan(&s.UserID, &s.AccountEmail, &s.Email, &s.Timezone, &s.DigestFrequency); err != nil {
+			return nil, fmt.Errorf("failed to scan digest recipient: %w", err)
+		}
+		recipients = append(recipients, s)
+	}
+
+	return recipients, nil
+}
+
+// ClaimNotificationDigest claims the user's digest for the period, returning
+// its ID, or ok false when it has been sent, is being sent by another
+// claim since before retryBefore, or has used up maxAttempts.
+func (r *PostgresRepositories) ClaimNotificationDigest(ctx context.Context, userID string, frequency models.DigestFrequency, periodStart, periodEnd, now, retryBefore time.Time, maxAttempts int) (string, bool, error) {
+	query := `
+		INSERT INTO public.notification_digests (user_id, frequency, period_start, period_end, claimed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, frequency, period_start) DO UPDATE SET
+		    status = 'sending',
+		    attempts = notification_digests.attempts + 1,
+		    claimed_at = EXCLUDED.claimed_at
+		WHERE notification_digests.status <> 'sent'
+		  AND notification_digests.attempts < $7
+		  AND notification_digests.claimed_at <= $6
+		RETURNING id`
+
+	var id string
+	err := r.pool.QueryRow(ctx, query, userID, frequency, periodStart, periodEnd, now, retryBefore, maxAttempts).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to claim notification digest: %w", err)
+	}
+	return id, true, nil
+}
+
+// MarkNotificationDigestSent records that a claimed digest went out
+func (r *PostgresRepositories) MarkNotificationDigestSent(ctx context.Context, id string, sentAt time.Time) error {
+	query := `
+		UPDATE public.notification_digests
+		SET status = 'sent', sent_at = $2, last_error = NULL
+		WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, sentAt); err != nil {
+		return fmt.Errorf("failed to mark notification digest sent: %w", err)
+	}
+	return nil
+}
+
+// MarkNotificationDigestFailed records a failed attempt at a claimed digest
+func (r *PostgresRepositories) MarkNotificationDigestFailed(ctx context.Context, id string, lastError string) error {
+	query := `
+		UPDATE public.notification_digests
+		SET status = 'failed', last_error = $2
+		WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("failed to mark notification digest failed: %w", err)
+	}
+	return nil
+}
+
+// GetDigestNotificationDeliveries returns the user's email deliveries held
+// for the digest, oldest first, with their notifications
+func (r *PostgresRepositories) GetDigestNotificationDeliveries(ctx context.Context, userID string, limit int) ([]models.NotificationDelivery, error) {
+	query := `
+		SELECT d.id, d.notification_id, d.user_id, d.channel, d.status, d.attempts,
+		       d.next_attempt_at, d.last_error, d.sent_at, d.created_at, d.updated_at,
+		       n.title, n.message, n.type, n.created_at
+		FROM public.notification_deliveries d
+		JOIN public.notifications n ON n.id = d.notification_id
+		WHERE d.user_id = $1
+		  AND d.status = 'digest'
+		  AND d.channel = 'email'
+		ORDER BY d.created_at
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest notifications: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.NotificationDelivery{}
+	for rows.Next() {
+		d := models.NotificationDelivery{Notification: &models.Notification{}}
+		err := rows.Scan(
+			&d.ID, &d.NotificationID, &d.UserID, &d.Channel, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.SentAt, &d.CreatedAt, &d.UpdatedAt,
+			&d.Notification.Title, &d.Notification.Message, &d.Notification.Type, &d.Notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan digest notification: %w", err)
+		}
+		d.Notification.ID = d.NotificationID
+		d.Notification.UserID = d.UserID
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+// MarkDigestDeliveriesSent records that the deliveries went out in a digest
+func (r *PostgresRepositories) MarkDigestDeliveriesSent(ctx context.Context, ids []string, sentAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := `
+		UPDATE public.notification_deliveries
+		SET status = 'sent', attempts = attempts + 1, sent_at = $2
+		WHERE id = ANY($1) AND status = 'digest'`
+
+	if _, err := r.pool.Exec(ctx, query, ids, sentAt); err != nil {
+		return fmt.Errorf("failed to mark digest notifications sent: %w", err)
+	}
+	return nil
+}
+
+// Interface compliance check
+var _ repositories.NotificationDigestRepository = (*PostgresRepositories)(nil)
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Insights Repository Implementation
+
+// GetExpenseLines returns the user's expense lines between the dates,
+// inclusive, oldest first. Split transactions contribute one line per split.
+func (r *PostgresRepositories) GetExpenseLines(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.ExpenseLine, error) {
+	query := `
+		SELECT tl.transaction_id, tl.account_id, tl.category_id,
+		       COALESCE(c.name, 'Uncategorized'), t.description,
+		       ABS(tl.amount), tl.transaction_date
+		FROM public.transaction_lines tl
+		JOIN public.transactions t ON t.id = tl.transaction_id
+		LEFT JOIN public.categories c ON c.id = tl.category_id
+		WHERE tl.user_id = $1
+		  AND tl.transaction_type = 'expense'
+		  AND tl.transaction_date >= $2 AND tl.transaction_date <= $3
+		ORDER BY tl.transaction_date, tl.transaction_id`
+
+	rows, err := r.pool.Query(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []models.ExpenseLine
+	for rows.Next() {
+		var line models.ExpenseLine
+		err := rows.Scan(
+			&line.TransactionID,
+			&line.AccountID,
+			&line.CategoryID,
+			&line.CategoryName,
+			&line.Description,
+			&line.Amount,
+			&line.TransactionDate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expense line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// Interface compliance check
+var _ repositories.InsightsRepository = (*PostgresRepositories)(nil)
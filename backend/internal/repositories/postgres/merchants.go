@@ -0,0 +1,282 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Merchant Repository Implementation
+
+// GetMerchantsByUserID returns the user's merchants ordered by name.
+func (r *PostgresRepositories) GetMerchantsByUserID(ctx context.Context, userID string) ([]models.Merchant, error) {
+	query := `
+		SELECT id, user_id, name, normalized_name, created_at, updated_at
+		FROM public.merchants
+		WHERE user_id = $1
+		ORDER BY name`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchants: %w", err)
+	}
+	defer rows.Close()
+
+	var merchants []models.Merchant
+	for rows.Next() {
+		var m models.Merchant
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Name, &m.NormalizedName, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant: %w", err)
+		}
+		merchants = append(merchants, m)
+	}
+
+	return merchants, nil
+}
+
+func (r *PostgresRepositories) GetMerchantByID(ctx context.Context, id string) (*models.Merchant, error) {
+	query := `
+		SELECT id, user_id, name, normalized_name, created_at, updated_at
+		FROM public.merchants
+		WHERE id = $1`
+
+	m := &models.Merchant{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(&m.ID, &m.UserID, &m.Name, &m.NormalizedName, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant by ID: %w", err)
+	}
+
+	return m, nil
+}
+
+// FindOrCreateMerchant returns the user's merchant with the same normalized
+// name, creating it when none exists. An existing merchant keeps its name.
+func (r *PostgresRepositories) FindOrCreateMerchant(ctx context.Context, merchant *models.Merchant) error {
+	query := `
+		INSERT INTO public.merchants (user_id, name, normalized_name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, normalized_name) DO UPDATE
+		SET normalized_name = EXCLUDED.normalized_name
+		RETURNING id, name, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		merchant.UserID,
+		merchant.Name,
+		merchant.NormalizedName,
+	).Scan(&merchant.ID, &merchant.Name, &merchant.CreatedAt, &merchant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to find or create merchant: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) UpdateMerchant(ctx context.Context, merchant *models.Merchant) error {
+	query := `
+		UPDATE public.merchants
+		SET name = $2, normalized_name = $3, updated_at = NOW()
+		WHERE id = $1 AND user_id = $4
+		RETURNING updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		merchant.ID,
+		merchant.Name,
+		merchant.NormalizedName,
+		merchant.UserID,
+	).Scan(&merchant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update merchant: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMerchant removes a merchant and its aliases; transactions keep their
+// description and simply lose the merchant link.
+func (r *PostgresRepositories) DeleteMerchant(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM public.merchants WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete merchant: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("merchant not found")
+	}
+
+	return nil
+}
+
+// GetMerchantAliases returns the user's aliases with their merchant names,
+// oldest first so earlier aliases take precedence when several match.
+func (r *PostgresRepositories) GetMerchantAliases(ctx context.Context, userID string, merchantID *string) ([]models.MerchantAlias, error) {
+	query := `
+		SELECT a.id, a.user_id, a.merchant_id, m.name, a.pattern, a.match_type, a.created_at
+		FROM public.merchant_aliases a
+		JOIN public.merchants m ON a.merchant_id = m.id
+		WHERE a.user_id = $1
+		  AND ($2::uuid IS NULL OR a.merchant_id = $2)
+		ORDER BY a.created_at`
+
+	rows, err := r.pool.Query(ctx, query, userID, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []models.MerchantAlias
+	for rows.Next() {
+		var a models.MerchantAlias
+		err := rows.Scan(&a.ID, &a.UserID, &a.MerchantID, &a.MerchantName, &a.Pattern, &a.MatchType, &a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan merchant alias: %w", err)
+		}
+		aliases = append(aliases, a)
+	}
+
+	return aliases, nil
+}
+
+func (r *PostgresRepositories) GetMerchantAliasByID(ctx context.Context, id string) (*models.MerchantAlias, error) {
+	query := `
+		SELECT a.id, a.user_id, a.merchant_id, m.name, a.pattern, a.match_type, a.created_at
+		FROM public.merchant_aliases a
+		JOIN public.merchants m ON a.merchant_id = m.id
+		WHERE a.id = $1`
+
+	a := &models.MerchantAlias{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(&a.ID, &a.UserID, &a.MerchantID, &a.MerchantName, &a.Pattern, &a.MatchType, &a.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant alias by ID: %w", err)
+	}
+
+	return a, nil
+}
+
+func (r *PostgresRepositories) CreateMerchantAlias(ctx context.Context, alias *models.MerchantAlias) error {
+	query := `
+		INSERT INTO public.merchant_aliases (user_id, merchant_id, pattern, match_type)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		alias.UserID,
+		alias.MerchantID,
+		alias.Pattern,
+		alias.MatchType,
+	).Scan(&alias.ID, &alias.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create merchant alias: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) DeleteMerchantAlias(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM public.merchant_aliases WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete merchant alias: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("merchant alias not found")
+	}
+
+	return nil
+}
+
+// SetTransactionMerchants writes the merchant of each given transaction in one
+// database transaction and returns the number of rows that changed. The
+// merchant is descriptive only and not among the columns the reconciliation
+// lock guards, so reconciled transactions are updated too.
+func (r *PostgresRepositories) SetTransactionMerchants(ctx context.Context, userID string, transactions []models.Transaction) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE public.transactions
+		SET merchant_id = $2, updated_at = NOW()
+		WHERE id = $1 AND user_id = $3 AND merchant_id IS DISTINCT FROM $2`
+
+	var updated int64
+	for _, t := range transactions {
+		result, err := tx.Exec(ctx, query, t.ID, t.MerchantID, userID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to set transaction merchant: %w", err)
+		}
+		updated += result.RowsAffected()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit merchant changes: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetMerchantSpending returns expenses per merchant over the date range,
+// largest first. Spending without a merchant is reported as unattributed.
+func (r *PostgresRepositories) GetMerchantSpending(ctx context.Context, userID string, startDate, endDate time.Time, limit int) (*models.MerchantSpending, error) {
+	query := `
+		SELECT l.merchant_id, m.name,
+		       SUM(ABS(l.amount)) as total_amount,
+		       COUNT(DISTINCT l.transaction_id) as count
+		FROM public.transaction_lines l
+		LEFT JOIN public.merchants m ON l.merchant_id = m.id
+		WHERE l.user_id = $1
+		  AND l.transaction_type = 'expense'
+		  AND l.transaction_date >= $2
+		  AND l.transaction_date <= $3
+		GROUP BY l.merchant_id, m.name
+		ORDER BY total_amount DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant spending: %w", err)
+	}
+	defer rows.Close()
+
+	report := &models.MerchantSpending{
+		UserID:    userID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Merchants: []models.MerchantSpendingItem{},
+	}
+
+	for rows.Next() {
+		var merchantID, merchantName *string
+		var item models.MerchantSpendingItem
+		if err := rows.Scan(&merchantID, &merchantName, &item.TotalAmount, &item.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant spending: %w", err)
+		}
+
+		report.TotalExpenses += item.TotalAmount
+		if merchantID == nil {
+			report.UnattributedAmount += item.TotalAmount
+			continue
+		}
+		if len(report.Merchants) >= limit {
+			continue
+		}
+
+		item.MerchantID = *merchantID
+		if merchantName != nil {
+			item.MerchantName = *merchantName
+		}
+		if item.Count > 0 {
+			item.AverageAmount = item.TotalAmount / float64(item.Count)
+		}
+		report.Merchants = append(report.Merchants, item)
+	}
+
+	report.GeneratedAt = time.Now()
+	return report, nil
+}
+
+// Interface compliance check
+var _ repositories.MerchantRepository = (*PostgresRepositories)(nil)
@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// ML Categorization Repository Implementation
+
+// GetUserMLModel returns the user's persisted model, or nil when none has
+// been trained yet.
+func (r *PostgresRepositories) GetUserMLModel(ctx context.Context, userID string) (*models.UserMLModel, error) {
+	query := `
+		SELECT id, user_id, model_data, COALESCE(accuracy, 0), COALESCE(total_predictions, 0),
+		       COALESCE(correct_predictions, 0), COALESCE(pattern_count, 0), last_trained,
+		       COALESCE(model_version, ''), created_at, updated_at
+		FROM public.user_ml_models
+		WHERE user_id = $1`
+
+	model := &models.UserMLModel{}
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&model.ID,
+		&model.UserID,
+		&model.ModelData,
+		&model.Accuracy,
+		&model.TotalPredictions,
+		&model.CorrectPredictions,
+		&model.PatternCount,
+		&model.LastTrained,
+		&model.ModelVersion,
+		&model.CreatedAt,
+		&model.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ML model: %w", err)
+	}
+
+	return model, nil
+}
+
+// SaveUserMLModel inserts or replaces the user's model.
+func (r *PostgresRepositories) SaveUserMLModel(ctx context.Context, model *models.UserMLModel) error {
+	query := `
+		INSERT INTO public.user_ml_models (user_id, model_data, accuracy, total_predictions,
+		                                   correct_predictions, pattern_count, last_trained, model_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE
+		SET model_data = EXCLUDED.model_data,
+		    accuracy = EXCLUDED.accuracy,
+		    total_predictions = EXCLUDED.total_predictions,
+		    correct_predictions = EXCLUDED.correct_predictions,
+		    pattern_count = EXCLUDED.pattern_count,
+		    last_trained = EXCLUDED.last_trained,
+		    model_version = EXCLUDED.model_version,
+		    updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		model.UserID,
+		model.ModelData,
+		model.Accuracy,
+		model.TotalPredictions,
+		model.CorrectPredictions,
+		model.PatternCount,
+		model.LastTrained,
+		model.ModelVersion,
+	).Scan(&model.ID, &model.CreatedAt, &model.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save ML model: %w", err)
+	}
+
+	return nil
+}
+
+// GetCategorizedTrainingExamples returns every categorized, non-transfer
+// transaction of the user as a training example. A transaction's latest
+// ml_feedback correction wins over its category unless the transaction was
+// edited after the feedback was given.
+func (r *PostgresRepositories) GetCategorizedTrainingExamples(ctx context.Context, userID string) ([]models.TrainingExample, error) {
+	query := `
+		SELECT COALESCE(t.description, ''), t.amount,
+		       (CASE WHEN f.created_at > t.updated_at THEN f.actual_category_id
+		             ELSE COALESCE(t.category_id, f.actual_category_id) END)::text,
+		       t.created_at
+		FROM public.transactions t
+		LEFT JOIN LATERAL (
+			SELECT actual_category_id, created_at
+			FROM public.ml_feedback
+			WHERE transaction_id = t.id
+			  AND user_id = t.user_id
+			  AND actual_category_id IS NOT NULL
+			ORDER BY created_at DESC
+			LIMIT 1
+		) f ON true
+		WHERE t.user_id = $1
+		  AND (t.category_id IS NOT NULL OR f.actual_category_id IS NOT NULL)
+		  AND t.transaction_type != 'transfer'
+		  AND t.transfer_id IS NULL
+		ORDER BY t.created_at`
+
+	return r.queryTrainingExamples(ctx, query, userID)
+}
+
+// GetFeedbackTrainingExamples returns corrected categories from ml_feedback
+// recorded after the given time (all feedback when since is nil).
+func (r *PostgresRepositories) GetFeedbackTrainingExamples(ctx context.Context, userID string, since *time.Time) ([]models.TrainingExample, error) {
+	query := `
+		SELECT COALESCE(t.description, ''), t.amount, f.actual_category_id::text, f.created_at
+		FROM public.ml_feedback f
+		JOIN public.transactions t ON f.transaction_id = t.id
+		WHERE f.user_id = $1
+		  AND f.actual_category_id IS NOT NULL
+		  AND ($2::timestamptz IS NULL OR f.created_at > $2)
+		ORDER BY f.created_at`
+
+	return r.queryTrainingExamples(ctx, query, userID, since)
+}
+
+func (r *PostgresRepositories) queryTrainingExamples(ctx context.Context, query string, args ...interface{}) ([]models.TrainingExample, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get training examples: %w", err)
+	}
+	defer rows.Close()
+
+	var examples []models.TrainingExample
+	for rows.Next() {
+		var ex models.TrainingExample
+		if err := rows.Scan(&ex.Description, &ex.Amount, &ex.CategoryID, &ex.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan training example: %w", err)
+		}
+		examples = append(examples, ex)
+	}
+
+	return examples, nil
+}
+
+func (r *PostgresRepositories) CreateMLPrediction(ctx context.Context, prediction *models.MLPrediction) error {
+	query := `
+		INSERT INTO public.ml_predictions (user_id, transaction_description, transaction_amount,
+		                                   predicted_category_id, confidence, reasoning, model_version,
+		                                   prediction_sources, alternatives, model_accuracy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		prediction.UserID,
+		prediction.TransactionDescription,
+		prediction.TransactionAmount,
+		prediction.PredictedCategoryID,
+		prediction.Confidence,
+		prediction.Reasoning,
+		prediction.ModelVersion,
+		prediction.PredictionSources,
+		prediction.Alternatives,
+		prediction.ModelAccuracy,
+	).Scan(&prediction.ID, &prediction.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to log ML prediction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) CreateMLFeedback(ctx context.Context, feedback *models.MLFeedback) error {
+	query := `
+		INSERT INTO public.ml_feedback (user_id, transaction_id, predicted_category_id, actual_category_id,
+		                                confidence, was_correct, model_version, feedback_notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		feedback.UserID,
+		feedback.TransactionID,
+		feedback.PredictedCategoryID,
+		feedback.ActualCategoryID,
+		feedback.Confidence,
+		feedback.WasCorrect,
+		feedback.ModelVersion,
+		feedback.FeedbackNotes,
+	).Scan(&feedback.ID, &feedback.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create ML feedback: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) CreateMLTrainingSession(ctx context.Context, session *models.MLTrainingSession) error {
+	query := `
+		INSERT INTO public.ml_training_sessions (user_id, training_data_count, accuracy_before,
+		                                         accuracy_after, training_duration_ms, model_version,
+		                                         training_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		session.UserID,
+		session.TrainingDataCount,
+		session.AccuracyBefore,
+		session.AccuracyAfter,
+		session.TrainingDurationMs,
+		session.ModelVersion,
+		session.TrainingType,
+	).Scan(&session.ID, &session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record ML training session: %w", err)
+	}
+
+	return nil
+}
+
+// Interface compliance check
+var _ repositories.MLRepository = (*PostgresRepositories)(nil)
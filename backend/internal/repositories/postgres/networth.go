@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Net Worth Repository Implementation
+
+// GetActiveAccountsByUserID returns the user's active accounts.
+func (r *PostgresRepositories) GetActiveAccountsByUserID(ctx context.Context, userID string) ([]models.Account, error) {
+	query := `
+		SELECT id, user_id, name, account_type, balance, description, COALESCE(currency, 'USD'),
+		       is_active, created_at, updated_at
+		FROM public.accounts
+		WHERE user_id = $1 AND is_active = true
+		ORDER BY name`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []models.Account
+	for rows.Next() {
+		var a models.Account
+		err := rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.Name,
+			&a.AccountType,
+			&a.Balance,
+			&a.Description,
+			&a.Currency,
+			&a.IsActive,
+			&a.CreatedAt,
+			&a.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, nil
+}
+
+// GetBaseCurrency returns the user's base currency from their currency
+// preferences, falling back to the profile preference and then USD.
+func (r *PostgresRepositories) GetBaseCurrency(ctx context.Context, userID string) (string, error) {
+	query := `
+		SELECT COALESCE(
+		    (SELECT base_currency FROM public.currency_preferences WHERE user_id = $1),
+		    (SELECT currency_preference FROM public.profiles WHERE id = $1),
+		    'USD'
+		)`
+
+	var currency string
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&currency); err != nil {
+		return "", fmt.Errorf("failed to get base currency: %w", err)
+	}
+
+	return currency, nil
+}
+
+// GetUserIDsWithActiveAccounts returns every user with at least one active
+// account, for the snapshot job.
+func (r *PostgresRepositories) GetUserIDsWithActiveAccounts(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT user_id FROM public.accounts WHERE is_active = true`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user ID: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return userIDs, nil
+}
+
+// UpsertNetWorthSnapshot stores the snapshot, replacing any earlier one for
+// the same user and day.
+func (r *PostgresRepositories) UpsertNetWorthSnapshot(ctx context.Context, snapshot *models.NetWorthSnapshot) error {
+	query := `
+		INSERT INTO public.net_worth_snapshots
+		    (user_id, snapshot_date, base_currency, total_assets, total_liabilities, net_worth, breakdown)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, snapshot_date) DO UPDATE
+		SET base_currency = EXCLUDED.base_currency,
+		    total_assets = EXCLUDED.total_assets,
+		    total_liabilities = EXCLUDED.total_liabilities,
+		    net_worth = EXCLUDED.net_worth,
+		    breakdown = EXCLUDED.breakdown,
+		    updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+
+	breakdown := snapshot.Breakdown
+	if breakdown == nil {
+		breakdown = []models.NetWorthBreakdownItem{}
+	}
+
+	err := r.pool.QueryRow(ctx, query,
+		snapshot.UserID,
+		snapshot.SnapshotDate,
+		snapshot.BaseCurrency,
+		snapshot.TotalAssets,
+		snapshot.TotalLiabilities,
+		snapshot.NetWorth,
+		breakdown,
+	).Scan(&snapshot.ID, &snapshot.CreatedAt, &snapshot.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save net worth snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetNetWorthSnapshots returns the user's snapshots between the dates,
+// inclusive, oldest first.
+func (r *PostgresRepositories) GetNetWorthSnapshots(ctx context.Context, userID string, from, to time.Time) ([]models.NetWorthSnapshot, error) {
+	query := `
+		SELECT id, user_id, snapshot_date, base_currency, total_assets, total_liabilities,
+		       net_worth, breakdown, created_at, updated_at
+		FROM public.net_worth_snapshots
+		WHERE user_id = $1 AND snapshot_date BETWEEN $2 AND $3
+		ORDER BY snapshot_date`
+
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get net worth snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []models.NetWorthSnapshot
+	for rows.Next() {
+		var s models.NetWorthSnapshot
+		err := rows.Scan(
+			&s.ID,
+			&s.UserID,
+			&s.SnapshotDate,
+			&s.BaseCurrency,
+			&s.TotalAssets,
+			&s.TotalLiabilities,
+			&s.NetWorth,
+			&s.Breakdown,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan net worth snapshot: %w", err)
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, nil
+}
+
+// Interface compliance check
+var _ repositories.NetWorthRepository = (*PostgresRepositories)(nil)
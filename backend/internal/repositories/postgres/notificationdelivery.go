@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Notification Delivery Repository Implementation
+
+// GetNotificationSettings returns the user's channel settings, with the
+// defaults when they have saved none
+func (r *PostgresRepositories) GetNotificationSettings(ctx context.Context, userID string) (*models.NotificationSettings, error) {
+	query := `
+		SELECT u.id, COALESCE(u.email, ''), s.email, s.webhook_url, s.webhook_secret,
+		       to_char(s.quiet_hours_start, 'HH24:MI'), to_char(s.quiet_hours_end, 'HH24:MI'),
+		       COALESCE(s.timezone, 'UTC'), COALESCE(s.digest_frequency, 'off')
+		FROM auth.users u
+		LEFT JOIN public.notification_settings s ON s.user_id = u.id
+		WHERE u.id = $1`
+
+	settings := &models.NotificationSettings{}
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&settings.UserID,
+		&settings.AccountEmail,
+		&settings.Email,
+		&settings.WebhookURL,
+		&settings.WebhookSecret,
+		&settings.QuietHoursStart,
+		&settings.QuietHoursEnd,
+		&settings.Timezone,
+		&settings.DigestFrequency,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+	settings.WebhookSecretSet = settings.WebhookSecret != nil
+	return settings, nil
+}
+
+// UpsertNotificationSettings saves the user's channel settings
+func (r *PostgresRepositories) UpsertNotificationSettings(ctx context.Context, settings *models.NotificationSettings) error {
+	query := `
+		INSERT INTO public.notification_settings
+		    (user_id, email, webhook_url, webhook_secret, quiet_hours_start, quiet_hours_end, timezone, digest_frequency)
+		VALUES ($1, $2, $3, $4, $5::time, $6::time, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+		    email = EXCLUDED.email,
+		    webhook_url = EXCLUDED.webhook_url,
+		    webhook_secret = EXCLUDED.webhook_secret,
+		    quiet_hours_start = EXCLUDED.quiet_hours_start,
+		    quiet_hours_end = EXCLUDED.quiet_hours_end,
+		    timezone = EXCLUDED.timezone,
+		    digest_frequency = EXCLUDED.digest_frequency`
+
+	_, err := r.pool.Exec(ctx, query,
+		settings.UserID,
+		settings.Email,
+		settings.WebhookURL,
+		settings.WebhookSecret,
+		settings.QuietHoursStart,
+		settings.QuietHoursEnd,
+		settings.Timezone,
+		settings.DigestFrequency,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification settings: %w", err)
+	}
+	settings.WebhookSecretSet = settings.WebhookSecret != nil
+	return nil
+}
+
+// GetNotificationPreferences returns the preferences the user has saved, by
+// notification type
+func (r *PostgresRepositories) GetNotificationPreferences(ctx context.Context, userID string) ([]models.NotificationPreference, error) {
+	query := `
+		SELECT user_id, notification_type, in_app, email, webhook, delivery, respect_quiet_hours
+		FROM public.notification_preferences
+		WHERE user_id = $1
+		ORDER BY notification_type`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var preferences []models.NotificationPreference
+	for rows.Next() {
+		var p models.NotificationPreference
+		if err := rows.Scan(&p.UserID, &p.NotificationType, &p.InApp, &p.Email, &p.Webhook, &p.Delivery, &p.RespectQuietHours); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		preferences = append(preferences, p)
+	}
+
+	return preferences, nil
+}
+
+// UpsertNotificationPreference saves the user's preference for a
+// notification type
+func (r *PostgresRepositories) UpsertNotificationPreference(ctx context.Context, preference *models.NotificationPreference) error {
+	query := `
+		INSERT INTO public.notification_preferences
+		    (user_id, notification_type, in_app, email, webhook, delivery, respect_quiet_hours)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, notification_type) DO UPDATE SET
+		    in_app = EXCLUDED.in_app,
+		    email = EXCLUDED.email,
+		    webhook = EXCLUDED.webhook,
+		    delivery = EXCLUDED.delivery,
+		    respect_quiet_hours = EXCLUDED.respect_quiet_hours`
+
+	_, err := r.pool.Exec(ctx, query,
+		preference.UserID,
+		preference.NotificationType,
+		preference.InApp,
+		preference.Email,
+		preference.Webhook,
+		preference.Delivery,
+		preference.RespectQuietHours,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preference: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueNotificationDeliveries takes up to limit pending deliveries due by
+// now, oldest first, with their notifications and the users' settings. They
+// are leased until leaseUntil: if they are neither completed nor
+// rescheduled by then, they become due again. Deliveries claimed by another
+// dispatcher are skipped.
+func (r *PostgresRepositories) ClaimDueNotificationDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.NotificationDelivery, error) {
+	query := `
+		UPDATE public.notification_deliveries d
+		SET next_attempt_at = $2
+		FROM public.notifications n
+		JOIN auth.users u ON u.id = n.user_id
+		LEFT JOIN public.notification_settings s ON s.user_id = n.user_id
+		LEFT JOIN public.notification_preferences p ON p.user_id = n.user_id AND p.notification_type = n.type
+		WHERE n.id = d.notification_id
+		  AND d.id IN (
+			SELECT id FROM public.notification_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING d.id, d.notification_id, d.user_id, d.channel, d.status, d.attempts,
+		          d.next_attempt_at, d.last_error, d.sent_at, d.created_at, d.updated_at,
+		          n.title, n.message, n.type, n.metadata, n.created_at,
+		          COALESCE(u.email, ''), s.email, s.webhook_url, s.webhook_secret,
+		          to_char(s.quiet_hours_start, 'HH24:MI'), to_char(s.quiet_hours_end, 'HH24:MI'),
+		          COALESCE(s.timezone, 'UTC'), COALESCE(p.respect_quiet_hours, true)`
+
+	rows, err := r.pool.Query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.NotificationDelivery
+	for rows.Next() {
+		d := models.NotificationDelivery{
+			Notification: &models.Notification{},
+			Settings:     &models.NotificationSettings{},
+		}
+		err := rows.Scan(
+			&d.ID, &d.NotificationID, &d.UserID, &d.Channel, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.SentAt, &d.CreatedAt, &d.UpdatedAt,
+			&d.Notification.Title, &d.Notification.Message, &d.Notification.Type,
+			&d.Notification.Metadata, &d.Notification.CreatedAt,
+			&d.Settings.AccountEmail, &d.Settings.Email, &d.Settings.WebhookURL, &d.Settings.WebhookSecret,
+			&d.Settings.QuietHoursStart, &d.Settings.QuietHoursEnd,
+			&d.Settings.Timezone, &d.RespectQuietHours,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		d.Notification.ID = d.NotificationID
+		d.Notification.UserID = d.UserID
+		d.Settings.UserID = d.UserID
+		d.Settings.WebhookSecretSet = d.Settings.WebhookSecret != nil
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim notification deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// MarkNotificationDeliverySent records a successful attempt
+func (r *PostgresRepositories) MarkNotificationDeliverySent(ctx context.Context, id string, attempts int, sentAt time.Time) error {
+	query := `
+		UPDATE public.notification_deliveries
+		SET status = 'sent', attempts = $2, sent_at = $3, last_error = NULL
+		WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, attempts, sentAt); err != nil {
+		return fmt.Errorf("failed to mark notification delivery sent: %w", err)
+	}
+	return nil
+}
+
+// RescheduleNotificationDelivery puts a pending delivery back in the queue
+// until nextAttemptAt
+func (r *PostgresRepositories) RescheduleNotificationDelivery(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, lastError *string) error {
+	query := `
+		UPDATE public.notification_deliveries
+		SET attempts = $2, next_attempt_at = $3, last_error = $4
+		WHERE id = $1 AND status = 'pending'`
+
+	if _, err := r.pool.Exec(ctx, query, id, attempts, nextAttemptAt, lastError); err != nil {
+		return fmt.Errorf("failed to reschedule notification delivery: %w", err)
+	}
+	return nil
+}
+
+// MarkNotificationDeliveryFailed gives up on a delivery
+func (r *PostgresRepositories) MarkNotificationDeliveryFailed(ctx context.Context, id string, attempts int, lastError string) error {
+	query := `
+		UPDATE public.notification_deliveries
+		SET status = 'failed', attempts = $2, last_error = $3
+		WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, attempts, lastError); err != nil {
+		return fmt.Errorf("failed to mark notification delivery failed: %w", err)
+	}
+	return nil
+}
+
+// GetNotificationDeliveries returns the user's most recent deliveries with
+// their notifications, optionally only those with the given status
+func (r *PostgresRepositories) GetNotificationDeliveries(ctx context.Context, userID string, status models.NotificationDeliveryStatus, limit int) ([]models.NotificationDelivery, error) {
+	query := `
+		SELECT d.id, d.notification_id, d.user_id, d.channel, d.status, d.attempts,
+		       d.next_attempt_at, d.last_error, d.sent_at, d.created_at, d.updated_at,
+		       n.title, n.message, n.type, n.created_at
+		FROM public.notification_deliveries d
+		JOIN public.notifications n ON n.id = d.notification_id
+		WHERE d.user_id = $1
+		  AND ($2 = '' OR d.status = $2)
+		ORDER BY d.created_at DESC
+		LIMIT $3`
+
+	rows, err := r.pool.Query(ctx, query, userID, string(status), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.NotificationDelivery{}
+	for rows.Next() {
+		d := models.NotificationDelivery{Notification: &models.Notification{}}
+		err := rows.Scan(
+			&d.ID, &d.NotificationID, &d.UserID, &d.Channel, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.SentAt, &d.CreatedAt, &d.UpdatedAt,
+			&d.Notification.Title, &d.Notification.Message, &d.Notification.Type, &d.Notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		d.Notification.ID = d.NotificationID
+		d.Notification.UserID = d.UserID
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+// Interface compliance check
+var _ repositories.NotificationDeliveryRepository = (*PostgresRepositories)(nil)
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Notification Repository Implementation
+
+// CreateNotification stores a notification. When dedupeKey is set it is
+// recorded in the metadata and the notification is only created if the user
+// has none with the same key; the result reports whether one was created.
+func (r *PostgresRepositories) CreateNotification(ctx context.Context, notification *models.Notification, dedupeKey string) (bool, error) {
+	metadata := make(map[string]interface{}, len(notification.Metadata)+1)
+	for key, value := range notification.Metadata {
+		metadata[key] = value
+	}
+	if dedupeKey != "" {
+		metadata["dedupe_key"] = dedupeKey
+	}
+
+	query := `
+		INSERT INTO public.notifications (user_id, title, message, type, metadata)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, (metadata->>'dedupe_key')) WHERE metadata ? 'dedupe_key' DO NOTHING
+		RETURNING id, is_read, in_app, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		notification.UserID,
+		notification.Title,
+		notification.Message,
+		notification.Type,
+		metadata,
+	).Scan(&notification.ID, &notification.IsRead, &notification.InApp, &notification.CreatedAt, &notification.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	notification.Metadata = metadata
+	return true, nil
+}
+
+// Interface compliance check
+var _ repositories.NotificationRepository = (*PostgresRepositories)(nil)
@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Analytics Repository Implementation
+
+// pivotStatementTimeout bounds how long each pivot statement may run
+const pivotStatementTimeout = "5s"
+
+// pivotColumn is the SQL of a pivot dimension over transaction_lines l: its
+// key and label expressions and the join they need, if any. A label that is
+// an aggregate over the key's group is left out of the GROUP BY. Only these
+// fixed fragments are ever spliced into the query; all values are bound as
+// parameters.
+type pivotColumn struct {
+	key            string
+	label          string
+	join           string
+	aggregateLabel bool
+}
+
+var pivotColumns = map[models.PivotDimension]pivotColumn{
+	models.PivotDimensionCategory: {
+		key:   "COALESCE(l.category_id::text, '')",
+		label: "COALESCE(c.name, 'Uncategorized')",
+		join:  "LEFT JOIN public.categories c ON c.id = l.category_id",
+	},
+	models.PivotDimensionAccount: {
+		key:   "l.account_id::text",
+		label: "COALESCE(a.name, '')",
+		join:  "LEFT JOIN public.accounts a ON a.id = l.account_id",
+	},
+	models.PivotDimensionMerchant: {
+		key:   "COALESCE(l.merchant_id::text, '')",
+		label: "COALESCE(m.name, 'No merchant')",
+		join:  "LEFT JOIN public.merchants m ON m.id = l.merchant_id",
+	},
+	// Tags match case-insensitively, labelled with their most used spelling
+	models.PivotDimensionTag: {
+		key:            "COALESCE(lower(tag.name), '')",
+		label:          "COALESCE(MODE() WITHIN GROUP (ORDER BY tag.name), 'Untagged')",
+		join:           "LEFT JOIN LATERAL unnest(CASE WHEN cardinality(l.tags) > 0 THEN l.tags ELSE ARRAY[NULL::text] END) AS tag(name) ON true",
+		aggregateLabel: true,
+	},
+	models.PivotDimensionMonth: {
+		key:   "to_char(l.transaction_date, 'YYYY-MM')",
+		label: "to_char(l.transaction_date, 'FMMonth YYYY')",
+	},
+	models.PivotDimensionWeekday: {
+		key:   "EXTRACT(ISODOW FROM l.transaction_date)::int::text",
+		label: "to_char(l.transaction_date, 'FMDay')",
+	},
+}
+
+var pivotAggregates = map[models.PivotMeasure]string{
+	models.PivotMeasureSum:    "COALESCE(SUM(ABS(l.amount)), 0)::float8",
+	models.PivotMeasureCount:  "COUNT(*)::float8",
+	models.PivotMeasureAvg:    "COALESCE(AVG(ABS(l.amount)), 0)::float8",
+	models.PivotMeasureMedian: "COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY ABS(l.amount)), 0)::float8",
+}
+
+// pivotWhere builds the WHERE conditions and arguments of a pivot filter
+func pivotWhere(userID string, filter models.PivotFilter) (string, []interface{}) {
+	args := []interface{}{userID, filter.StartDate, filter.EndDate, filter.TransactionType}
+	conditions := []string{
+		"l.user_id = $1",
+		"l.transaction_date >= $2",
+		"l.transaction_date <= $3",
+		"l.transaction_type = $4",
+	}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if len(filter.AccountIDs) > 0 {
+		add("l.account_id::text = ANY($%d)", filter.AccountIDs)
+	}
+	if len(filter.CategoryIDs) > 0 {
+		add("l.category_id::text = ANY($%d)", filter.CategoryIDs)
+	}
+	if len(filter.MerchantIDs) > 0 {
+		add("l.merchant_id::text = ANY($%d)", filter.MerchantIDs)
+	}
+	if len(filter.Tags) > 0 {
+		add("EXISTS (SELECT 1 FROM unnest(l.tags) AS tag WHERE lower(tag) = ANY($%d::text[]))", lowerTags(filter.Tags))
+	}
+	if filter.MinAmount != nil {
+		add("ABS(l.amount) >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		add("ABS(l.amount) <= $%d", *filter.MaxAmount)
+	}
+
+	return strings.Join(conditions, "\n		  AND "), args
+}
+
+// RunPivotQuery aggregates the user's transaction lines by the query's
+// dimensions. The query must already be validated; it runs read-only with a
+// statement timeout and returns at most query.Limit rows.
+func (r *PostgresRepositories) RunPivotQuery(ctx context.Context, userID string, query models.PivotQuery) (*models.PivotResult, error) {
+	var selects, groupBy, orderBy, joins []string
+	for i, dimension := range query.Dimensions {
+		column, ok := pivotColumns[dimension]
+		if !ok {
+			return nil, fmt.Errorf("unsupported pivot dimension %q", dimension)
+		}
+		selects = append(selects, column.key, column.label)
+		orderBy = append(orderBy, fmt.Sprintf("%d, %d", 2*i+1, 2*i+2))
+		if column.aggregateLabel {
+			groupBy = append(groupBy, fmt.Sprintf("%d", 2*i+1))
+		} else {
+			groupBy = append(groupBy, fmt.Sprintf("%d, %d", 2*i+1, 2*i+2))
+		}
+		if column.join != "" {
+			joins = append(joins, column.join)
+		}
+	}
+	var aggregates []string
+	for _, measure := range query.Measures {
+		aggregate, ok := pivotAggregates[measure]
+		if !ok {
+			return nil, fmt.Errorf("unsupported pivot measure %q", measure)
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+	if len(aggregates) == 0 {
+		return nil, fmt.Errorf("a pivot query needs at least one measure")
+	}
+
+	where, args := pivotWhere(userID, query.Filter)
+	rowsQuery := fmt.Sprintf(`
+		SELECT %s, %s
+		FROM public.transaction_lines l
+		%s
+		WHERE %s
+		GROUP BY %s
+		ORDER BY %d DESC, %s
+		LIMIT $%d`,
+		strings.Join(selects, ", "), strings.Join(aggregates, ", "),
+		strings.Join(joins, "\n		"),
+		where,
+		strings.Join(groupBy, ", "),
+		len(selects)+1, strings.Join(orderBy, ", "),
+		len(args)+1)
+	totalsQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM public.transaction_lines l
+		WHERE %s`, strings.Join(aggregates, ", "), where)
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = '"+pivotStatementTimeout+"'"); err != nil {
+		return nil, fmt.Errorf("failed to set pivot timeout: %w", err)
+	}
+
+	result := &models.PivotResult{
+		UserID:     userID,
+		Dimensions: query.Dimensions,
+		Measures:   query.Measures,
+		StartDate:  query.Filter.StartDate,
+		EndDate:    query.Filter.EndDate,
+		Rows:       []models.PivotRow{},
+		Totals:     make(map[models.PivotMeasure]float64, len(query.Measures)),
+	}
+
+	// One row past the limit tells whether the result was cut short
+	rows, err := tx.Query(ctx, rowsQuery, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, pivotError(err)
+	}
+	keys := make([]string, len(query.Dimensions))
+	labels := make([]string, len(query.Dimensions))
+	values := make([]float64, len(query.Measures))
+	targets := make([]interface{}, 0, len(selects)+len(values))
+	for i := range query.Dimensions {
+		targets = append(targets, &keys[i], &labels[i])
+	}
+	for i := range values {
+		targets = append(targets, &values[i])
+	}
+	for rows.Next() {
+		if len(result.Rows) == query.Limit {
+			result.Truncated = true
+			break
+		}
+		if err := rows.Scan(targets...); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan pivot row: %w", err)
+		}
+		row := models.PivotRow{
+			Keys:     make(map[models.PivotDimension]string, len(keys)),
+			Labels:   make(map[models.PivotDimension]string, len(labels)),
+			Measures: make(map[models.PivotMeasure]float64, len(values)),
+		}
+		for i, dimension := range query.Dimensions {
+			row.Keys[dimension] = keys[i]
+			row.Labels[dimension] = labels[i]
+		}
+		for i, measure := range query.Measures {
+			row.Measures[measure] = values[i]
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, pivotError(err)
+	}
+
+	if err := tx.QueryRow(ctx, totalsQuery, args...).Scan(targets[2*len(query.Dimensions):]...); err != nil {
+		return nil, pivotError(err)
+	}
+	for i, measure := range query.Measures {
+		result.Totals[measure] = values[i]
+	}
+
+	result.GeneratedAt = time.Now()
+	return result, nil
+}
+
+// pivotError reports cancelled statements as repositories.ErrQueryTimeout
+func pivotError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "57014" {
+		return repositories.ErrQueryTimeout
+	}
+	return fmt.Errorf("failed to run pivot query: %w", err)
+}
+
+// Interface compliance check
+var _ repositories.AnalyticsRepository = (*PostgresRepositories)(nil)
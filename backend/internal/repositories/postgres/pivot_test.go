@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPivotWhere_MatchesTagsCaseInsensitively(t *testing.T) {
+	where, args := pivotWhere("user-1", models.PivotFilter{
+		StartDate:       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:         time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		TransactionType: models.TransactionTypeExpense,
+		Tags:            []string{"Trip", " WORK "},
+	})
+
+	assert.Contains(t, where, "lower(tag) = ANY($5::text[])")
+	assert.Equal(t, []string{"trip", "work"}, args[4])
+}
@@ -83,6 +83,7 @@ func (r *PostgresRepositories) GetBudgetsByUserID(ctx context.Context, userID st
 	query := `
 		SELECT b.id, b.user_id, b.category_id, b.name, b.amount, b.period, 
 		       b.start_date, b.end_date, b.description, b.is_active, b.created_at, b.updated_at,
+		       b.alert_thresholds, b.pace_alerts,
 		       c.name as category_name, c.color as category_color, c.icon as category_icon
 		FROM public.budgets b
 		LEFT JOIN public.categories c ON b.category_id = c.id
@@ -113,6 +114,8 @@ func (r *PostgresRepositories) GetBudgetsByUserID(ctx context.Context, userID st
 			&b.IsActive,
 			&b.CreatedAt,
 			&b.UpdatedAt,
+			&b.AlertThresholds,
+			&b.PaceAlerts,
 			&categoryName,
 			&categoryColor,
 			&categoryIcon,
@@ -135,21 +138,56 @@ func (r *PostgresRepositories) GetBudgetsByUserID(ctx context.Context, userID st
 	return budgets, nil
 }
 
+// GetUserIDsWithActiveBudgets returns every user with an active budget
+func (r *PostgresRepositories) GetUserIDsWithActiveBudgets(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT user_id FROM public.budgets WHERE is_active = true`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user ID: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return userIDs, nil
+}
+
 // Transaction Repository Implementation
-func (r *PostgresRepositories) GetTransactionsByUserID(ctx context.Context, userID string, limit, offset int) ([]models.Transaction, error) {
+// GetTransactionsByUserID returns a page of the user's transactions, newest
+// first, optionally restricted to those carrying the filter's tags.
+func (r *PostgresRepositories) GetTransactionsByUserID(ctx context.Context, userID string, filter models.TagFilter, limit, offset int) ([]models.Transaction, error) {
 	query := `
 		SELECT t.id, t.user_id, t.account_id, t.category_id, t.amount, t.transaction_type, 
-		       t.description, t.transaction_date, t.notes, t.transfer_id, t.created_at, t.updated_at,
+		       t.description, t.transaction_date, t.notes, t.transfer_id,
+		       COALESCE(t.is_cleared, false), COALESCE(t.is_locked, false), t.reconciliation_id,
+		       COALESCE(t.tags, '{}'), t.merchant_id, COALESCE(t.custom_fields, '{}'),
+		       t.created_at, t.updated_at,
 		       a.name as account_name, a.account_type,
-		       c.name as category_name, c.color as category_color
+		       c.name as category_name, c.color as category_color,
+		       m.name as merchant_name
 		FROM public.transactions t
 		LEFT JOIN public.accounts a ON t.account_id = a.id
 		LEFT JOIN public.categories c ON t.category_id = c.id
-		WHERE t.user_id = $1
+		LEFT JOIN public.merchants m ON t.merchant_id = m.id
+		WHERE t.user_id = $1 %s
 		ORDER BY t.transaction_date DESC, t.created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.pool.Query(ctx, query, userID, limit, offset)
+	args := []interface{}{userID, limit, offset}
+	tagCondition := ""
+	if len(filter.Tags) > 0 {
+		tagCondition = "AND " + tagFilterCondition(filter, 4)
+		args = append(args, lowerTags(filter.Tags))
+	}
+	query = fmt.Sprintf(query, tagCondition)
+
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -160,7 +198,7 @@ func (r *PostgresRepositories) GetTransactionsByUserID(ctx context.Context, user
 		var t models.Transaction
 		var accountName, categoryName *string
 		var accountType *models.AccountType
-		var categoryColor *string
+		var categoryColor, merchantName *string
 
 		err := rows.Scan(
 			&t.ID,
@@ -173,12 +211,19 @@ func (r *PostgresRepositories) GetTransactionsByUserID(ctx context.Context, user
 			&t.TransactionDate,
 			&t.Notes,
 			&t.TransferID,
+			&t.IsCleared,
+			&t.IsLocked,
+			&t.ReconciliationID,
+			&t.Tags,
+			&t.MerchantID,
+			&t.CustomFields,
 			&t.CreatedAt,
 			&t.UpdatedAt,
 			&accountName,
 			&accountType,
 			&categoryName,
 			&categoryColor,
+			&merchantName,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction: %w", err)
@@ -198,21 +243,34 @@ func (r *PostgresRepositories) GetTransactionsByUserID(ctx context.Context, user
 			}
 		}
 
+		if merchantName != nil {
+			t.Merchant = &models.Merchant{ID: *t.MerchantID, Name: *merchantName}
+		}
+
 		transactions = append(transactions, t)
 	}
 
+	if err := r.attachSplits(ctx, transactions); err != nil {
+		return nil, err
+	}
+
 	return transactions, nil
 }
 
 func (r *PostgresRepositories) GetTransactionsByDateRange(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.Transaction, error) {
 	query := `
 		SELECT t.id, t.user_id, t.account_id, t.category_id, t.amount, t.transaction_type, 
-		       t.description, t.transaction_date, t.notes, t.transfer_id, t.created_at, t.updated_at,
+		       t.description, t.transaction_date, t.notes, t.transfer_id,
+		       COALESCE(t.is_cleared, false), COALESCE(t.is_locked, false), t.reconciliation_id,
+		       COALESCE(t.tags, '{}'), t.merchant_id, COALESCE(t.custom_fields, '{}'),
+		       t.created_at, t.updated_at,
 		       a.name as account_name, a.account_type,
-		       c.name as category_name, c.color as category_color
+		       c.name as category_name, c.color as category_color,
+		       m.name as merchant_name
 		FROM public.transactions t
 		LEFT JOIN public.accounts a ON t.account_id = a.id
 		LEFT JOIN public.categories c ON t.category_id = c.id
+		LEFT JOIN public.merchants m ON t.merchant_id = m.id
 		WHERE t.user_id = $1 AND t.transaction_date >= $2 AND t.transaction_date <= $3
 		ORDER BY t.transaction_date DESC, t.created_at DESC`
 
@@ -227,7 +285,7 @@ func (r *PostgresRepositories) GetTransactionsByDateRange(ctx context.Context, u
 		var t models.Transaction
 		var accountName, categoryName *string
 		var accountType *models.AccountType
-		var categoryColor *string
+		var categoryColor, merchantName *string
 
 		err := rows.Scan(
 			&t.ID,
@@ -240,12 +298,19 @@ func (r *PostgresRepositories) GetTransactionsByDateRange(ctx context.Context, u
 			&t.TransactionDate,
 			&t.Notes,
 			&t.TransferID,
+			&t.IsCleared,
+			&t.IsLocked,
+			&t.ReconciliationID,
+			&t.Tags,
+			&t.MerchantID,
+			&t.CustomFields,
 			&t.CreatedAt,
 			&t.UpdatedAt,
 			&accountName,
 			&accountType,
 			&categoryName,
 			&categoryColor,
+			&merchantName,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction: %w", err)
@@ -265,34 +330,42 @@ func (r *PostgresRepositories) GetTransactionsByDateRange(ctx context.Context, u
 			}
 		}
 
+		if merchantName != nil {
+			t.Merchant = &models.Merchant{ID: *t.MerchantID, Name: *merchantName}
+		}
+
 		transactions = append(transactions, t)
 	}
 
+	if err := r.attachSplits(ctx, transactions); err != nil {
+		return nil, err
+	}
+
 	return transactions, nil
 }
 
 // Reports Repository Implementation
+// GetMonthlySummary reads the month from the maintained monthly totals,
+// which count split lines against their own categories and leave out
+// transfers.
 func (r *PostgresRepositories) GetMonthlySummary(ctx context.Context, userID string, month, year int) (*models.MonthlySummary, error) {
-	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, 0).Add(-time.Second)
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 
 	// Get category spending
 	categoryQuery := `
 		SELECT 
-			COALESCE(t.category_id, ''), 
+			COALESCE(a.category_id::text, ''), 
 			COALESCE(c.name, 'Uncategorized'),
-			SUM(ABS(t.amount)) as total_amount,
-			COUNT(*) as count
-		FROM public.transactions t
-		LEFT JOIN public.categories c ON t.category_id = c.id
-		WHERE t.user_id = $1 
-		  AND t.transaction_date >= $2 
-		  AND t.transaction_date <= $3
-		  AND t.transaction_type = 'expense'
-		GROUP BY t.category_id, c.name
-		ORDER BY total_amount DESC`
-
-	rows, err := r.pool.Query(ctx, categoryQuery, userID, startDate, endDate)
+			a.total_amount,
+			a.transaction_count
+		FROM public.monthly_category_totals a
+		LEFT JOIN public.categories c ON a.category_id = c.id
+		WHERE a.user_id = $1 
+		  AND a.month = $2
+		  AND a.transaction_type = 'expense'
+		ORDER BY a.total_amount DESC`
+
+	rows, err := r.pool.Query(ctx, categoryQuery, userID, monthStart)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get category spending: %w", err)
 	}
@@ -316,15 +389,14 @@ func (r *PostgresRepositories) GetMonthlySummary(ctx context.Context, userID str
 	// Get total income and expenses
 	totalQuery := `
 		SELECT 
-			SUM(CASE WHEN transaction_type = 'income' THEN amount ELSE 0 END) as total_income,
-			SUM(CASE WHEN transaction_type = 'expense' THEN ABS(amount) ELSE 0 END) as total_expenses
-		FROM public.transactions
+			COALESCE(SUM(CASE WHEN transaction_type = 'income' THEN net_amount ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN transaction_type = 'expense' THEN total_amount ELSE 0 END), 0) as total_expenses
+		FROM public.monthly_category_totals
 		WHERE user_id = $1 
-		  AND transaction_date >= $2 
-		  AND transaction_date <= $3`
+		  AND month = $2`
 
 	var totalIncome, totalExpenses float64
-	err = r.pool.QueryRow(ctx, totalQuery, userID, startDate, endDate).Scan(&totalIncome, &totalExpenses)
+	err = r.pool.QueryRow(ctx, totalQuery, userID, monthStart).Scan(&totalIncome, &totalExpenses)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get totals: %w", err)
 	}
@@ -343,50 +415,290 @@ func (r *PostgresRepositories) GetMonthlySummary(ctx context.Context, userID str
 	return summary, nil
 }
 
-func (r *PostgresRepositories) GetSpendingTrends(ctx context.Context, userID string, categoryID *string, months int) (*models.SpendingTrends, error) {
-	query := `
-		SELECT 
-			EXTRACT(MONTH FROM transaction_date) as month,
-			EXTRACT(YEAR FROM transaction_date) as year,
-			SUM(ABS(amount)) as amount
-		FROM public.transactions
-		WHERE user_id = $1 
-		  AND transaction_type = 'expense'
-		  AND transaction_date >= $2`
+// GetTagSummary breaks the month's expenses down by tag. Tags are grouped
+// case-insensitively under their most common spelling.
+func (r *PostgresRepositories) GetTagSummary(ctx context.Context, userID string, month, year int) (*models.TagSummary, error) {
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, 0).Add(-time.Second)
+
+	tagQuery := `
+		SELECT
+			MODE() WITHIN GROUP (ORDER BY tag) as tag,
+			SUM(ABS(l.amount)) as total_amount,
+			COUNT(DISTINCT l.transaction_id) as count
+		FROM public.transaction_lines l
+		CROSS JOIN LATERAL unnest(l.tags) AS tag
+		WHERE l.user_id = $1
+		  AND l.transaction_date >= $2
+		  AND l.transaction_date <= $3
+		  AND l.transaction_type = 'expense'
+		GROUP BY lower(tag)
+		ORDER BY total_amount DESC`
 
-	args := []interface{}{userID, time.Now().AddDate(0, -months, 0)}
+	rows, err := r.pool.Query(ctx, tagQuery, userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag spending: %w", err)
+	}
+	defer rows.Close()
 
-	if categoryID != nil {
-		query += " AND category_id = $3"
-		args = append(args, *categoryID)
+	tags := []models.TagSummaryItem{}
+	for rows.Next() {
+		var item models.TagSummaryItem
+		if err := rows.Scan(&item.Tag, &item.TotalAmount, &item.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag item: %w", err)
+		}
+		tags = append(tags, item)
 	}
 
-	query += ` GROUP BY EXTRACT(MONTH FROM transaction_date), EXTRACT(YEAR FROM transaction_date)
-		ORDER BY year, month`
+	totalQuery := `
+		SELECT
+			COALESCE(SUM(ABS(amount)), 0) as total_expenses,
+			COALESCE(SUM(CASE WHEN cardinality(tags) = 0 THEN ABS(amount) ELSE 0 END), 0) as untagged
+		FROM public.transaction_lines
+		WHERE user_id = $1
+		  AND transaction_date >= $2
+		  AND transaction_date <= $3
+		  AND transaction_type = 'expense'`
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	summary := &models.TagSummary{
+		UserID: userID,
+		Month:  month,
+		Year:   year,
+		Tags:   tags,
+	}
+	err = r.pool.QueryRow(ctx, totalQuery, userID, startDate, endDate).Scan(&summary.TotalExpenses, &summary.UntaggedAmount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get spending trends: %w", err)
+		return nil, fmt.Errorf("failed to get totals: %w", err)
 	}
-	defer rows.Close()
 
-	var trends []models.SpendingTrendItem
+	summary.GeneratedAt = time.Now()
+	return summary, nil
+}
+
+// trendBuckets maps a trend granularity to its date_trunc unit, the step
+// between buckets and the to_char format of bucket labels
+var trendBuckets = map[models.TrendGranularity]struct{ unit, step, label string }{
+	models.TrendGranularityWeekly:    {"week", "1 week", `IYYY-"W"IW`},
+	models.TrendGranularityMonthly:   {"month", "1 month", "YYYY-MM"},
+	models.TrendGranularityQuarterly: {"quarter", "3 months", `YYYY-"Q"Q`},
+	models.TrendGranularityYearly:    {"year", "1 year", "YYYY"},
+}
+
+func (r *PostgresRepositories) GetSpendingTrends(ctx context.Context, userID string, query models.SpendingTrendsQuery) (*models.SpendingTrends, error) {
+	bucket, ok := trendBuckets[query.Granularity]
+	if !ok {
+		return nil, fmt.Errorf("unsupported trend granularity %q", query.Granularity)
+	}
+	if query.Periods < 1 {
+		return nil, fmt.Errorf("periods must be at least 1, got %d", query.Periods)
+	}
+	endDate := query.EndDate
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+
+	bucketQuery := `
+		SELECT
+			bucket::date,
+			(bucket + $2::text::interval - interval '1 day')::date,
+			to_char(bucket, $4)
+		FROM generate_series(
+			date_trunc($1, $3::date::timestamp) - ($5::int - 1) * $2::text::interval,
+			date_trunc($1, $3::date::timestamp),
+			$2::text::interval
+		) AS bucket
+		ORDER BY bucket`
+
+	rows, err := r.pool.Query(ctx, bucketQuery, bucket.unit, bucket.step, endDate, bucket.label, query.Periods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trend periods: %w", err)
+	}
+	var periods []models.SpendingTrendItem
 	for rows.Next() {
 		var item models.SpendingTrendItem
-		err := rows.Scan(&item.Month, &item.Year, &item.Amount)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan trend item: %w", err)
+		if err := rows.Scan(&item.PeriodStart, &item.PeriodEnd, &item.Label); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan trend period: %w", err)
 		}
-		trends = append(trends, item)
+		item.Month = int(item.PeriodStart.Month())
+		item.Year = item.PeriodStart.Year()
+		periods = append(periods, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get trend periods: %w", err)
+	}
+	if len(periods) == 0 {
+		return nil, fmt.Errorf("no trend periods for %d %s buckets", query.Periods, query.Granularity)
 	}
 
-	return &models.SpendingTrends{
+	trends := &models.SpendingTrends{
 		UserID:      userID,
-		CategoryID:  categoryID,
-		Period:      "monthly",
-		Trends:      trends,
+		Period:      string(query.Granularity),
+		StartDate:   periods[0].PeriodStart,
+		EndDate:     periods[len(periods)-1].PeriodEnd,
 		GeneratedAt: time.Now(),
-	}, nil
+	}
+	zeroFilled := func() []models.SpendingTrendItem {
+		return append([]models.SpendingTrendItem(nil), periods...)
+	}
+	index := make(map[string]int, len(periods))
+	for i, period := range periods {
+		index[period.PeriodStart.Format("2006-01-02")] = i
+	}
+
+	// Requested categories keep their order; ids the user doesn't own are dropped
+	names := make(map[string]string)
+	var categoryIDs []string
+	if len(query.CategoryIDs) > 0 {
+		rows, err := r.pool.Query(ctx, `
+			SELECT id::text, name
+			FROM public.categories
+			WHERE user_id = $1 AND id::text = ANY($2)`, userID, query.CategoryIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trend categories: %w", err)
+		}
+		for rows.Next() {
+			var id, name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan trend category: %w", err)
+			}
+			names[id] = name
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to get trend categories: %w", err)
+		}
+		for _, id := range query.CategoryIDs {
+			if _, ok := names[id]; ok {
+				categoryIDs = append(categoryIDs, id)
+			}
+		}
+		trends.CategoryIDs = categoryIDs
+		if len(categoryIDs) == 1 {
+			trends.CategoryID = &categoryIDs[0]
+		}
+	}
+
+	// Buckets of whole months are summed from the maintained monthly totals;
+	// weeks need the individual lines
+	totalsQuery := `
+		SELECT
+			date_trunc($2, month::timestamp)::date AS period_start,
+			transaction_type,
+			COALESCE(category_id::text, '') AS category_id,
+			SUM(total_amount) AS amount,
+			SUM(line_count) AS count
+		FROM public.monthly_category_totals
+		WHERE user_id = $1
+		  AND month >= $3
+		  AND month <= $4
+		  AND ($5::text[] IS NULL OR category_id::text = ANY($5))
+		GROUP BY 1, 2, 3`
+	if query.Granularity == models.TrendGranularityWeekly {
+		totalsQuery = `
+			SELECT
+				date_trunc($2, transaction_date::timestamp)::date AS period_start,
+				transaction_type,
+				COALESCE(category_id::text, '') AS category_id,
+				SUM(ABS(amount)) AS amount,
+				COUNT(*) AS count
+			FROM public.transaction_lines
+			WHERE user_id = $1
+			  AND transaction_date >= $3
+			  AND transaction_date <= $4
+			  AND ($5::text[] IS NULL OR category_id::text = ANY($5))
+			GROUP BY 1, 2, 3`
+	}
+
+	var categoryFilter []string
+	if len(query.CategoryIDs) > 0 {
+		// Non-nil even when nothing matched, so foreign ids filter everything out
+		categoryFilter = append([]string{}, categoryIDs...)
+	}
+	rows, err = r.pool.Query(ctx, totalsQuery, userID, bucket.unit, trends.StartDate, trends.EndDate, categoryFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending trends: %w", err)
+	}
+	defer rows.Close()
+
+	type seriesKey struct {
+		categoryID      string
+		transactionType models.TransactionType
+	}
+	series := make(map[seriesKey][]models.SpendingTrendItem)
+	trends.Trends = zeroFilled()
+	if query.IncludeIncome {
+		trends.IncomeTrends = zeroFilled()
+	}
+	for rows.Next() {
+		var (
+			periodStart     time.Time
+			transactionType models.TransactionType
+			categoryID      string
+			amount          float64
+			count           int
+		)
+		if err := rows.Scan(&periodStart, &transactionType, &categoryID, &amount, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan trend item: %w", err)
+		}
+		i, ok := index[periodStart.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+
+		var overall []models.SpendingTrendItem
+		switch {
+		case transactionType == models.TransactionTypeExpense:
+			overall = trends.Trends
+		case transactionType == models.TransactionTypeIncome && query.IncludeIncome:
+			overall = trends.IncomeTrends
+		}
+		if overall != nil {
+			overall[i].Amount += amount
+			overall[i].Count += count
+		}
+
+		if _, requested := names[categoryID]; requested {
+			key := seriesKey{categoryID, transactionType}
+			if series[key] == nil {
+				series[key] = zeroFilled()
+			}
+			series[key][i].Amount += amount
+			series[key][i].Count += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get spending trends: %w", err)
+	}
+
+	// One series per requested category and type it has; a category without
+	// activity in the range still gets a zero expense series
+	for _, id := range categoryIDs {
+		expenses := series[seriesKey{id, models.TransactionTypeExpense}]
+		var income []models.SpendingTrendItem
+		if query.IncludeIncome {
+			income = series[seriesKey{id, models.TransactionTypeIncome}]
+		}
+		if expenses == nil && income == nil {
+			expenses = zeroFilled()
+		}
+		for _, entry := range []models.SpendingTrendSeries{
+			{CategoryID: id, CategoryName: names[id], TransactionType: models.TransactionTypeExpense, Trends: expenses},
+			{CategoryID: id, CategoryName: names[id], TransactionType: models.TransactionTypeIncome, Trends: income},
+		} {
+			if entry.Trends == nil {
+				continue
+			}
+			for _, item := range entry.Trends {
+				entry.Total += item.Amount
+			}
+			trends.Series = append(trends.Series, entry)
+		}
+	}
+
+	return trends, nil
 }
 
 func (r *PostgresRepositories) GetCashFlow(ctx context.Context, userID string, startDate, endDate time.Time) (*models.CashFlow, error) {
@@ -395,7 +707,7 @@ func (r *PostgresRepositories) GetCashFlow(ctx context.Context, userID string, s
 			DATE(transaction_date) as date,
 			SUM(CASE WHEN transaction_type = 'income' THEN amount ELSE 0 END) as income,
 			SUM(CASE WHEN transaction_type = 'expense' THEN ABS(amount) ELSE 0 END) as expenses
-		FROM public.transactions
+		FROM public.transaction_lines
 		WHERE user_id = $1 
 		  AND transaction_date >= $2 
 		  AND transaction_date <= $3
@@ -437,6 +749,43 @@ func (r *PostgresRepositories) GetCashFlow(ctx context.Context, userID string, s
 	}, nil
 }
 
+// GetCategoryTotals returns income and expense totals per category for a
+// date range, counting split lines against their own categories.
+func (r *PostgresRepositories) GetCategoryTotals(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.CategoryTotal, error) {
+	query := `
+		SELECT
+			COALESCE(l.category_id::text, ''),
+			COALESCE(c.name, 'Uncategorized'),
+			l.transaction_type,
+			SUM(ABS(l.amount)),
+			COUNT(DISTINCT l.transaction_id)
+		FROM public.transaction_lines l
+		LEFT JOIN public.categories c ON l.category_id = c.id
+		WHERE l.user_id = $1
+		  AND l.transaction_date >= $2
+		  AND l.transaction_date <= $3
+		GROUP BY l.category_id, c.name, l.transaction_type
+		ORDER BY l.transaction_type, SUM(ABS(l.amount)) DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []models.CategoryTotal
+	for rows.Next() {
+		var total models.CategoryTotal
+		err := rows.Scan(&total.CategoryID, &total.CategoryName, &total.TransactionType, &total.Amount, &total.Count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category total: %w", err)
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, nil
+}
+
 // Goal Repository Implementation
 func (r *PostgresRepositories) GetGoalsByUserID(ctx context.Context, userID string) ([]models.Goal, error) {
 	query := `
@@ -592,4 +941,5 @@ func (r *PostgresRepositories) Delete(ctx context.Context, id string) error {
 
 // Interface compliance check
 var _ repositories.ReportsRepository = (*PostgresRepositories)(nil)
+var _ repositories.BudgetAlertRepository = (*PostgresRepositories)(nil)
 var _ repositories.GoalRepository = (*PostgresRepositories)(nil)
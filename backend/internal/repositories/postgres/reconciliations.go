@@ -0,0 +1,324 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Reconciliation Repository Implementation
+
+// signedAmountSQL expresses a transaction's effect on its account balance.
+const signedAmountSQL = `CASE
+	WHEN transaction_type = 'income' THEN amount
+	WHEN transaction_type = 'expense' THEN -amount
+	ELSE amount
+END`
+
+func (r *PostgresRepositories) CreateReconciliation(ctx context.Context, reconciliation *models.Reconciliation) error {
+	query := `
+		INSERT INTO public.reconciliations (user_id, account_id, statement_date, statement_balance)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, status, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		reconciliation.UserID,
+		reconciliation.AccountID,
+		reconciliation.StatementDate,
+		reconciliation.StatementBalance,
+	).Scan(&reconciliation.ID, &reconciliation.Status, &reconciliation.CreatedAt, &reconciliation.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create reconciliation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) GetReconciliationByID(ctx context.Context, id string) (*models.Reconciliation, error) {
+	query := `
+		SELECT id, user_id, account_id, statement_date, statement_balance,
+		       COALESCE(cleared_balance, 0), COALESCE(difference, 0), COALESCE(cleared_count, 0),
+		       status, completed_at, created_at, updated_at
+		FROM public.reconciliations
+		WHERE id = $1`
+
+	rec := &models.Reconciliation{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&rec.ID,
+		&rec.UserID,
+		&rec.AccountID,
+		&rec.StatementDate,
+		&rec.StatementBalance,
+		&rec.ClearedBalance,
+		&rec.Difference,
+		&rec.ClearedCount,
+		&rec.Status,
+		&rec.CompletedAt,
+		&rec.CreatedAt,
+		&rec.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation by ID: %w", err)
+	}
+
+	return rec, nil
+}
+
+// GetReconciliationsByUserID returns the reconciliation history, newest
+// statement first, optionally restricted to one account.
+func (r *PostgresRepositories) GetReconciliationsByUserID(ctx context.Context, userID string, accountID *string) ([]models.Reconciliation, error) {
+	query := `
+		SELECT id, user_id, account_id, statement_date, statement_balance,
+		       COALESCE(cleared_balance, 0), COALESCE(difference, 0), COALESCE(cleared_count, 0),
+		       status, completed_at, created_at, updated_at
+		FROM public.reconciliations
+		WHERE user_id = $1`
+
+	args := []interface{}{userID}
+	if accountID != nil {
+		query += " AND account_id = $2"
+		args = append(args, *accountID)
+	}
+	query += " ORDER BY statement_date DESC, created_at DESC"
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliations: %w", err)
+	}
+	defer rows.Close()
+
+	var reconciliations []models.Reconciliation
+	for rows.Next() {
+		var rec models.Reconciliation
+		err := rows.Scan(
+			&rec.ID,
+			&rec.UserID,
+			&rec.AccountID,
+			&rec.StatementDate,
+			&rec.StatementBalance,
+			&rec.ClearedBalance,
+			&rec.Difference,
+			&rec.ClearedCount,
+			&rec.Status,
+			&rec.CompletedAt,
+			&rec.CreatedAt,
+			&rec.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation: %w", err)
+		}
+		reconciliations = append(reconciliations, rec)
+	}
+
+	return reconciliations, nil
+}
+
+// GetClearedBalance returns the account balance counting only cleared
+// transactions: the current balance minus the effect of everything uncleared.
+// The second value is the number of transactions cleared in the reconciliation.
+func (r *PostgresRepositories) GetClearedBalance(ctx context.Context, accountID, reconciliationID string) (float64, int, error) {
+	query := `
+		SELECT
+			a.balance - COALESCE((
+				SELECT SUM(` + signedAmountSQL + `)
+				FROM public.transactions
+				WHERE account_id = a.id AND NOT COALESCE(is_cleared, false)
+			), 0),
+			(SELECT COUNT(*) FROM public.transactions WHERE reconciliation_id = $2)
+		FROM public.accounts a
+		WHERE a.id = $1`
+
+	var balance float64
+	var count int
+	err := r.pool.QueryRow(ctx, query, accountID, reconciliationID).Scan(&balance, &count)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get cleared balance: %w", err)
+	}
+
+	return balance, count, nil
+}
+
+// GetReconciliationTransactions returns the unlocked transactions on the
+// account up to the statement date that are either uncleared or were cleared
+// in this reconciliation.
+func (r *PostgresRepositories) GetReconciliationTransactions(ctx context.Context, reconciliation *models.Reconciliation) ([]models.Transaction, error) {
+	query := `
+		SELECT id, user_id, account_id, category_id, amount, transaction_type, description,
+		       transaction_date, notes, transfer_id, COALESCE(is_cleared, false),
+		       COALESCE(is_locked, false), reconciliation_id, COALESCE(tags, '{}'),
+		       created_at, updated_at
+		FROM public.transactions
+		WHERE account_id = $1
+		  AND transaction_date <= $2
+		  AND NOT COALESCE(is_locked, false)
+		  AND (NOT COALESCE(is_cleared, false) OR reconciliation_id = $3)
+		ORDER BY transaction_date, created_at`
+
+	rows, err := r.pool.Query(ctx, query, reconciliation.AccountID, reconciliation.StatementDate, reconciliation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.AccountID,
+			&t.CategoryID,
+			&t.Amount,
+			&t.TransactionType,
+			&t.Description,
+			&t.TransactionDate,
+			&t.Notes,
+			&t.TransferID,
+			&t.IsCleared,
+			&t.IsLocked,
+			&t.ReconciliationID,
+			&t.Tags,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, nil
+}
+
+// SetTransactionsCleared marks or unmarks transactions as cleared within a
+// reconciliation. Only unlocked transactions on the reconciled account are
+// touched, and only those dated on or before the statement date are cleared;
+// the number of rows changed is returned.
+func (r *PostgresRepositories) SetTransactionsCleared(ctx context.Context, reconciliation *models.Reconciliation, transactionIDs []string, cleared bool) (int64, error) {
+	var query string
+	if cleared {
+		query = `
+			UPDATE public.transactions
+			SET is_cleared = true, cleared_at = NOW(), reconciliation_id = $3
+			WHERE id = ANY($1::uuid[]) AND account_id = $2
+			  AND transaction_date <= $4
+			  AND NOT COALESCE(is_locked, false)
+			  AND NOT COALESCE(is_cleared, false)`
+	} else {
+		query = `
+			UPDATE public.transactions
+			SET is_cleared = false, cleared_at = NULL, reconciliation_id = NULL
+			WHERE id = ANY($1::uuid[]) AND account_id = $2
+			  AND NOT COALESCE(is_locked, false)
+			  AND reconciliation_id = $3`
+	}
+
+	args := []interface{}{transactionIDs, reconciliation.AccountID, reconciliation.ID}
+	if cleared {
+		args = append(args, reconciliation.StatementDate)
+	}
+	result, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update cleared transactions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// CompleteReconciliation locks every transaction cleared in the reconciliation
+// and records the final balances.
+func (r *PostgresRepositories) CompleteReconciliation(ctx context.Context, reconciliation *models.Reconciliation) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `UPDATE public.transactions SET is_locked = true WHERE reconciliation_id = $1`, reconciliation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to lock reconciled transactions: %w", err)
+	}
+
+	query := `
+		UPDATE public.reconciliations
+		SET status = 'completed', cleared_balance = $2, difference = $3, cleared_count = $4,
+		    completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'in_progress'
+		RETURNING status, completed_at, updated_at`
+
+	err = tx.QueryRow(ctx, query,
+		reconciliation.ID,
+		reconciliation.ClearedBalance,
+		reconciliation.Difference,
+		reconciliation.ClearedCount,
+	).Scan(&reconciliation.Status, &reconciliation.CompletedAt, &reconciliation.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete reconciliation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit reconciliation: %w", err)
+	}
+
+	return nil
+}
+
+// CancelReconciliation abandons an open reconciliation and unclears the
+// transactions that were marked in it.
+func (r *PostgresRepositories) CancelReconciliation(ctx context.Context, id string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE public.transactions
+		SET is_cleared = false, cleared_at = NULL, reconciliation_id = NULL
+		WHERE reconciliation_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to clear reconciliation transactions: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE public.reconciliations
+		SET status = 'cancelled', updated_at = NOW()
+		WHERE id = $1 AND status = 'in_progress'`, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel reconciliation: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("reconciliation not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit reconciliation: %w", err)
+	}
+
+	return nil
+}
+
+// UnlockTransaction releases a reconciled transaction for editing. It stays
+// cleared so the reconciliation history is not rewritten.
+func (r *PostgresRepositories) UnlockTransaction(ctx context.Context, id string) error {
+	query := `UPDATE public.transactions SET is_locked = false, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to unlock transaction: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("transaction not found")
+	}
+
+	return nil
+}
+
+// Interface compliance check
+var _ repositories.ReconciliationRepository = (*PostgresRepositories)(nil)
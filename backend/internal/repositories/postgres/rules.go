@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Categorization Rule Repository Implementation
+
+// GetRulesByUserID returns the user's rules in evaluation order.
+func (r *PostgresRepositories) GetRulesByUserID(ctx context.Context, userID string) ([]models.CategorizationRule, error) {
+	query := `
+		SELECT id, user_id, name, priority, is_active, stop_processing, conditions, actions,
+		       created_at, updated_at
+		FROM public.categorization_rules
+		WHERE user_id = $1
+		ORDER BY priority, created_at`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categorization rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.CategorizationRule
+	for rows.Next() {
+		var rule models.CategorizationRule
+		err := rows.Scan(
+			&rule.ID,
+			&rule.UserID,
+			&rule.Name,
+			&rule.Priority,
+			&rule.IsActive,
+			&rule.StopProcessing,
+			&rule.Conditions,
+			&rule.Actions,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan categorization rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (r *PostgresRepositories) GetRuleByID(ctx context.Context, id string) (*models.CategorizationRule, error) {
+	query := `
+		SELECT id, user_id, name, priority, is_active, stop_processing, conditions, actions,
+		       created_at, updated_at
+		FROM public.categorization_rules
+		WHERE id = $1`
+
+	rule := &models.CategorizationRule{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.Name,
+		&rule.Priority,
+		&rule.IsActive,
+		&rule.StopProcessing,
+		&rule.Conditions,
+		&rule.Actions,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categorization rule by ID: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (r *PostgresRepositories) CreateRule(ctx context.Context, rule *models.CategorizationRule) error {
+	query := `
+		INSERT INTO public.categorization_rules (user_id, name, priority, is_active, stop_processing,
+		                                         conditions, actions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		rule.UserID,
+		rule.Name,
+		rule.Priority,
+		rule.IsActive,
+		rule.StopProcessing,
+		rule.Conditions,
+		rule.Actions,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create categorization rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) UpdateRule(ctx context.Context, rule *models.CategorizationRule) error {
+	query := `
+		UPDATE public.categorization_rules
+		SET name = $2, priority = $3, is_active = $4, stop_processing = $5,
+		    conditions = $6, actions = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		rule.ID,
+		rule.Name,
+		rule.Priority,
+		rule.IsActive,
+		rule.StopProcessing,
+		rule.Conditions,
+		rule.Actions,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update categorization rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) DeleteRule(ctx context.Context, id string) error {
+	query := `DELETE FROM public.categorization_rules WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete categorization rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("categorization rule not found")
+	}
+
+	return nil
+}
+
+// ApplyRuleChanges writes the rule-controlled fields (category, tags, notes)
+// of the given transactions in one database transaction. Locked transactions
+// are skipped; the number of rows updated is returned.
+func (r *PostgresRepositories) ApplyRuleChanges(ctx context.Context, userID string, transactions []models.Transaction) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE public.transactions
+		SET category_id = $2, tags = $3, notes = $4, updated_at = NOW()
+		WHERE id = $1 AND user_id = $5 AND NOT COALESCE(is_locked, false)`
+
+	var updated int64
+	for _, t := range transactions {
+		result, err := tx.Exec(ctx, query, t.ID, t.CategoryID, tagsParam(t.Tags), t.Notes, userID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to apply rule changes: %w", err)
+		}
+		updated += result.RowsAffected()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit rule changes: %w", err)
+	}
+
+	return updated, nil
+}
+
+// Interface compliance check
+var _ repositories.CategorizationRuleRepository = (*PostgresRepositories)(nil)
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Scheduled Transaction Repository Implementation
+
+const scheduledTransactionColumns = `
+	id, user_id, account_id, category_id, amount, transaction_type, description,
+	frequency, next_date, end_date, is_active, created_at, updated_at`
+
+func scanScheduledTransaction(row pgx.Row, s *models.ScheduledTransaction) error {
+	return row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.AccountID,
+		&s.CategoryID,
+		&s.Amount,
+		&s.TransactionType,
+		&s.Description,
+		&s.Frequency,
+		&s.NextDate,
+		&s.EndDate,
+		&s.IsActive,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+}
+
+// GetScheduledTransactionsByUserID returns the user's scheduled transactions
+// in date order, optionally only the active ones.
+func (r *PostgresRepositories) GetScheduledTransactionsByUserID(ctx context.Context, userID string, activeOnly bool) ([]models.ScheduledTransaction, error) {
+	query := `
+		SELECT ` + scheduledTransactionColumns + `
+		FROM public.scheduled_transactions
+		WHERE user_id = $1 AND (is_active OR NOT $2)
+		ORDER BY next_date, created_at`
+
+	rows, err := r.pool.Query(ctx, query, userID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var scheduled []models.ScheduledTransaction
+	for rows.Next() {
+		var s models.ScheduledTransaction
+		if err := scanScheduledTransaction(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled transaction: %w", err)
+		}
+		scheduled = append(scheduled, s)
+	}
+
+	return scheduled, nil
+}
+
+func (r *PostgresRepositories) GetScheduledTransactionByID(ctx context.Context, id string) (*models.ScheduledTransaction, error) {
+	query := `
+		SELECT ` + scheduledTransactionColumns + `
+		FROM public.scheduled_transactions
+		WHERE id = $1`
+
+	s := &models.ScheduledTransaction{}
+	if err := scanScheduledTransaction(r.pool.QueryRow(ctx, query, id), s); err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transaction by ID: %w", err)
+	}
+
+	return s, nil
+}
+
+func (r *PostgresRepositories) CreateScheduledTransaction(ctx context.Context, s *models.ScheduledTransaction) error {
+	query := `
+		INSERT INTO public.scheduled_transactions
+		    (user_id, account_id, category_id, amount, transaction_type, description,
+		     frequency, next_date, end_date, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		s.UserID,
+		s.AccountID,
+		s.CategoryID,
+		s.Amount,
+		s.TransactionType,
+		s.Description,
+		s.Frequency,
+		s.NextDate,
+		s.EndDate,
+		s.IsActive,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) UpdateScheduledTransaction(ctx context.Context, s *models.ScheduledTransaction) error {
+	query := `
+		UPDATE public.scheduled_transactions
+		SET account_id = $2, category_id = $3, amount = $4, transaction_type = $5,
+		    description = $6, frequency = $7, next_date = $8, end_date = $9,
+		    is_active = $10, updated_at = NOW()
+		WHERE id = $1 AND user_id = $11
+		RETURNING updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		s.ID,
+		s.AccountID,
+		s.CategoryID,
+		s.Amount,
+		s.TransactionType,
+		s.Description,
+		s.Frequency,
+		s.NextDate,
+		s.EndDate,
+		s.IsActive,
+		s.UserID,
+	).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) DeleteScheduledTransaction(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM public.scheduled_transactions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled transaction: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("scheduled transaction not found")
+	}
+
+	return nil
+}
+
+// Interface compliance check
+var _ repositories.ScheduledTransactionRepository = (*PostgresRepositories)(nil)
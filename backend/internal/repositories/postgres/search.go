@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Transaction Search Repository Implementation
+
+// SearchTransactions returns up to limit transactions matching the parsed
+// search, ordered by full-text relevance and then newest first. Results
+// continue after the cursor when one is given. Every user-supplied value is
+// bound as a parameter.
+func (r *PostgresRepositories) SearchTransactions(ctx context.Context, userID string, search *models.TransactionSearch, cursor *models.SearchCursor, limit int) ([]models.SearchHit, error) {
+	args := []interface{}{userID}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"t.user_id = $1"}
+	rank := "0::real"
+
+	if search.Text != "" {
+		tsquery := fmt.Sprintf("websearch_to_tsquery('english', %s)", param(search.Text))
+		rank = fmt.Sprintf("ts_rank_cd(t.search_vector, %s)", tsquery)
+		conditions = append(conditions, fmt.Sprintf("t.search_vector @@ %s", tsquery))
+	}
+	for _, merchant := range search.Merchants {
+		p := param(containsPattern(merchant))
+		conditions = append(conditions, fmt.Sprintf("(m.name ILIKE %s OR t.description ILIKE %s)", p, p))
+	}
+	for _, category := range search.Categories {
+		p := param(containsPattern(category))
+		conditions = append(conditions, fmt.Sprintf(`(c.name ILIKE %s OR EXISTS (
+			SELECT 1 FROM public.transaction_splits s
+			JOIN public.categories sc ON s.category_id = sc.id
+			WHERE s.transaction_id = t.id AND sc.name ILIKE %s))`, p, p))
+	}
+	for _, account := range search.Accounts {
+		conditions = append(conditions, fmt.Sprintf("a.name ILIKE %s", param(containsPattern(account))))
+	}
+	if len(search.Tags) > 0 {
+		filter := models.TagFilter{Tags: search.Tags, MatchAll: true}
+		conditions = append(conditions, tagFilterCondition(filter, len(args)+1))
+		args = append(args, lowerTags(search.Tags))
+	}
+	if search.TransactionType != nil {
+		conditions = append(conditions, fmt.Sprintf("t.transaction_type = %s", param(*search.TransactionType)))
+	}
+	if search.MinAmount != nil {
+		op := ">="
+		if search.MinExclusive {
+			op = ">"
+		}
+		conditions = append(conditions, fmt.Sprintf("t.amount %s %s", op, param(*search.MinAmount)))
+	}
+	if search.MaxAmount != nil {
+		op := "<="
+		if search.MaxExclusive {
+			op = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("t.amount %s %s", op, param(*search.MaxAmount)))
+	}
+	if search.After != nil {
+		conditions = append(conditions, fmt.Sprintf("t.transaction_date >= %s", param(*search.After)))
+	}
+	if search.Before != nil {
+		conditions = append(conditions, fmt.Sprintf("t.transaction_date < %s", param(*search.Before)))
+	}
+
+	after := ""
+	if cursor != nil {
+		after = fmt.Sprintf("WHERE (search_rank, transaction_date, id) < (%s::real, %s::date, %s::uuid)",
+			param(cursor.Rank), param(cursor.TransactionDate), param(cursor.ID))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT t.id, t.user_id, t.account_id, t.category_id, t.amount, t.transaction_type,
+			       t.description, t.transaction_date, t.notes, t.transfer_id,
+			       COALESCE(t.is_cleared, false) as is_cleared, COALESCE(t.is_locked, false) as is_locked,
+			       t.reconciliation_id, COALESCE(t.tags, '{}') as tags, t.merchant_id,
+			       COALESCE(t.custom_fields, '{}') as custom_fields,
+			       t.created_at, t.updated_at,
+			       a.name as account_name, a.account_type,
+			       c.name as category_name, c.color as category_color,
+			       m.name as merchant_name,
+			       %s as search_rank
+			FROM public.transactions t
+			LEFT JOIN public.accounts a ON t.account_id = a.id
+			LEFT JOIN public.categories c ON t.category_id = c.id
+			LEFT JOIN public.merchants m ON t.merchant_id = m.id
+			WHERE %s
+		) hits
+		%s
+		ORDER BY search_rank DESC, transaction_date DESC, id DESC
+		LIMIT %s`,
+		rank, strings.Join(conditions, "\n\t\t\t  AND "), after, param(limit))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	var ranks []float32
+	for rows.Next() {
+		var t models.Transaction
+		var accountName, categoryName *string
+		var accountType *models.AccountType
+		var categoryColor, merchantName *string
+		var rank float32
+
+		err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.AccountID,
+			&t.CategoryID,
+			&t.Amount,
+			&t.TransactionType,
+			&t.Description,
+			&t.TransactionDate,
+			&t.Notes,
+			&t.TransferID,
+			&t.IsCleared,
+			&t.IsLocked,
+			&t.ReconciliationID,
+			&t.Tags,
+			&t.MerchantID,
+			&t.CustomFields,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&accountName,
+			&accountType,
+			&categoryName,
+			&categoryColor,
+			&merchantName,
+			&rank,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		if accountName != nil {
+			t.Account = &models.Account{
+				Name:        *accountName,
+				AccountType: *accountType,
+			}
+		}
+
+		if categoryName != nil {
+			t.Category = &models.Category{
+				Name:  *categoryName,
+				Color: *categoryColor,
+			}
+		}
+
+		if merchantName != nil {
+			t.Merchant = &models.Merchant{ID: *t.MerchantID, Name: *merchantName}
+		}
+
+		transactions = append(transactions, t)
+		ranks = append(ranks, rank)
+	}
+
+	if err := r.attachSplits(ctx, transactions); err != nil {
+		return nil, err
+	}
+
+	hits := make([]models.SearchHit, len(transactions))
+	for i := range transactions {
+		hits[i] = models.SearchHit{Transaction: transactions[i], Rank: ranks[i]}
+	}
+
+	return hits, nil
+}
+
+// containsPattern builds an ILIKE pattern matching value anywhere, with the
+// LIKE wildcards in value escaped.
+func containsPattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return "%" + escaped + "%"
+}
+
+// Interface compliance check
+var _ repositories.TransactionSearchRepository = (*PostgresRepositories)(nil)
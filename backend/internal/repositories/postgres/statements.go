@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Financial Statements Repository Implementation
+
+// GetCategoriesByUserID returns all of the user's categories, inactive ones
+// included since past transactions may still be booked to them.
+func (r *PostgresRepositories) GetCategoriesByUserID(ctx context.Context, userID string) ([]models.Category, error) {
+	query := `
+		SELECT id, user_id, name, description, COALESCE(color, ''), icon, parent_id,
+		       COALESCE(is_active, true), created_at, updated_at
+		FROM public.categories
+		WHERE user_id = $1
+		ORDER BY name`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		var c models.Category
+		err := rows.Scan(
+			&c.ID,
+			&c.UserID,
+			&c.Name,
+			&c.Description,
+			&c.Color,
+			&c.Icon,
+			&c.ParentID,
+			&c.IsActive,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, nil
+}
+
+// GetCategoryByID returns a category, whichever user it belongs to
+func (r *PostgresRepositories) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) {
+	query := `
+		SELECT id, user_id, name, description, COALESCE(color, ''), icon, parent_id,
+		       COALESCE(is_active, true), created_at, updated_at
+		FROM public.categories
+		WHERE id = $1`
+
+	c := &models.Category{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&c.ID,
+		&c.UserID,
+		&c.Name,
+		&c.Description,
+		&c.Color,
+		&c.Icon,
+		&c.ParentID,
+		&c.IsActive,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category by ID: %w", err)
+	}
+
+	return c, nil
+}
+
+// GetAccountBalancesAsOf returns the user's accounts with their balances at
+// the end of the given day, worked back from the current balance by undoing
+// the transactions dated after it. Accounts opened later are left out, as are
+// closed accounts that held nothing on that day.
+func (r *PostgresRepositories) GetAccountBalancesAsOf(ctx context.Context, userID string, asOf time.Time) ([]models.Account, error) {
+	query := `
+		WITH later AS (
+			SELECT account_id,
+			       SUM(CASE
+			           WHEN transaction_type = 'income' THEN amount
+			           WHEN transaction_type = 'expense' THEN -amount
+			           ELSE amount
+			       END) AS change
+			FROM public.transactions
+			WHERE user_id = $1 AND transaction_date > $2
+			GROUP BY account_id
+		)
+		SELECT a.id, a.user_id, a.name, a.account_type,
+		       COALESCE(a.balance, 0) - COALESCE(l.change, 0) AS balance,
+		       a.description, COALESCE(a.currency, 'USD'),
+		       a.is_active, a.created_at, a.updated_at
+		FROM public.accounts a
+		LEFT JOIN later l ON l.account_id = a.id
+		WHERE a.user_id = $1
+		  AND a.created_at::date <= $2
+		  AND (a.is_active OR COALESCE(a.balance, 0) - COALESCE(l.change, 0) <> 0)
+		ORDER BY a.name`
+
+	rows, err := r.pool.Query(ctx, query, userID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account balances: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []models.Account
+	for rows.Next() {
+		var a models.Account
+		err := rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.Name,
+			&a.AccountType,
+			&a.Balance,
+			&a.Description,
+			&a.Currency,
+			&a.IsActive,
+			&a.CreatedAt,
+			&a.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account balance: %w", err)
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, nil
+}
+
+// Interface compliance check
+var _ repositories.FinancialStatementsRepository = (*PostgresRepositories)(nil)
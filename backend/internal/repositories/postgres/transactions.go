@@ -0,0 +1,461 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Transaction write operations and split lines
+
+func (r *PostgresRepositories) GetTransactionByID(ctx context.Context, id string) (*models.Transaction, error) {
+	query := `
+		SELECT t.id, t.user_id, t.account_id, t.category_id, t.amount, t.transaction_type,
+		       t.description, t.transaction_date, t.notes, t.transfer_id,
+		       t.currency, t.original_currency, t.exchange_rate,
+		       COALESCE(t.is_cleared, false), COALESCE(t.is_locked, false), t.reconciliation_id,
+		       COALESCE(t.tags, '{}'), t.merchant_id, COALESCE(t.custom_fields, '{}'),
+		       t.created_at, t.updated_at,
+		       a.name as account_name, a.account_type,
+		       c.name as category_name, c.color as category_color,
+		       m.name as merchant_name
+		FROM public.transactions t
+		LEFT JOIN public.accounts a ON t.account_id = a.id
+		LEFT JOIN public.categories c ON t.category_id = c.id
+		LEFT JOIN public.merchants m ON t.merchant_id = m.id
+		WHERE t.id = $1`
+
+	t := &models.Transaction{}
+	var accountName, categoryName *string
+	var accountType *models.AccountType
+	var categoryColor, merchantName *string
+
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.AccountID,
+		&t.CategoryID,
+		&t.Amount,
+		&t.TransactionType,
+		&t.Description,
+		&t.TransactionDate,
+		&t.Notes,
+		&t.TransferID,
+		&t.Currency,
+		&t.OriginalCurrency,
+		&t.ExchangeRate,
+		&t.IsCleared,
+		&t.IsLocked,
+		&t.ReconciliationID,
+		&t.Tags,
+		&t.MerchantID,
+		&t.CustomFields,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&accountName,
+		&accountType,
+		&categoryName,
+		&categoryColor,
+		&merchantName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction by ID: %w", err)
+	}
+
+	if accountName != nil {
+		t.Account = &models.Account{
+			Name:        *accountName,
+			AccountType: *accountType,
+		}
+	}
+
+	if categoryName != nil {
+		t.Category = &models.Category{
+			Name:  *categoryName,
+			Color: *categoryColor,
+		}
+	}
+
+	if merchantName != nil {
+		t.Merchant = &models.Merchant{ID: *t.MerchantID, Name: *merchantName}
+	}
+
+	t.Splits, err = r.GetTransactionSplits(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r *PostgresRepositories) GetTransactionSplits(ctx context.Context, transactionID string) ([]models.TransactionSplit, error) {
+	query := `
+		SELECT id, transaction_id, user_id, category_id, amount, description, created_at, updated_at
+		FROM public.transaction_splits
+		WHERE transaction_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := r.pool.Query(ctx, query, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction splits: %w", err)
+	}
+	defer rows.Close()
+
+	var splits []models.TransactionSplit
+	for rows.Next() {
+		var s models.TransactionSplit
+		err := rows.Scan(
+			&s.ID,
+			&s.TransactionID,
+			&s.UserID,
+			&s.CategoryID,
+			&s.Amount,
+			&s.Description,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction split: %w", err)
+		}
+		splits = append(splits, s)
+	}
+
+	return splits, nil
+}
+
+// attachSplits loads split lines for a page of transactions in one query.
+func (r *PostgresRepositories) attachSplits(ctx context.Context, transactions []models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(transactions))
+	index := make(map[string]int, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.ID
+		index[t.ID] = i
+	}
+
+	query := `
+		SELECT id, transaction_id, user_id, category_id, amount, description, created_at, updated_at
+		FROM public.transaction_splits
+		WHERE transaction_id = ANY($1::uuid[])
+		ORDER BY created_at, id`
+
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction splits: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.TransactionSplit
+		err := rows.Scan(
+			&s.ID,
+			&s.TransactionID,
+			&s.UserID,
+			&s.CategoryID,
+			&s.Amount,
+			&s.Description,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan transaction split: %w", err)
+		}
+		if i, ok := index[s.TransactionID]; ok {
+			transactions[i].Splits = append(transactions[i].Splits, s)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *PostgresRepositories) CreateTransaction(ctx context.Context, transaction *models.Transaction) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO public.transactions (user_id, account_id, category_id, amount, transaction_type,
+		                                 description, transaction_date, notes, tags, merchant_id,
+		                                 custom_fields)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`
+
+	err = tx.QueryRow(ctx, query,
+		transaction.UserID,
+		transaction.AccountID,
+		transaction.CategoryID,
+		transaction.Amount,
+		transaction.TransactionType,
+		transaction.Description,
+		transaction.TransactionDate,
+		transaction.Notes,
+		tagsParam(transaction.Tags),
+		transaction.MerchantID,
+		customFieldsParam(transaction.CustomFields),
+	).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if err := insertSplits(ctx, tx, transaction); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) UpdateTransaction(ctx context.Context, transaction *models.Transaction) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE public.transactions
+		SET account_id = $2, category_id = $3, amount = $4, transaction_type = $5,
+		    description = $6, transaction_date = $7, notes = $8, tags = $10,
+		    merchant_id = $11, custom_fields = $12, updated_at = NOW()
+		WHERE id = $1 AND user_id = $9
+		RETURNING updated_at`
+
+	err = tx.QueryRow(ctx, query,
+		transaction.ID,
+		transaction.AccountID,
+		transaction.CategoryID,
+		transaction.Amount,
+		transaction.TransactionType,
+		transaction.Description,
+		transaction.TransactionDate,
+		transaction.Notes,
+		transaction.UserID,
+		tagsParam(transaction.Tags),
+		transaction.MerchantID,
+		customFieldsParam(transaction.CustomFields),
+	).Scan(&transaction.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	// Split lines are replaced wholesale; the handler has already validated them
+	_, err = tx.Exec(ctx, `DELETE FROM public.transaction_splits WHERE transaction_id = $1`, transaction.ID)
+	if err != nil {
+		return fmt.Errorf("failed to clear transaction splits: %w", err)
+	}
+
+	if err := insertSplits(ctx, tx, transaction); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) DeleteTransaction(ctx context.Context, id string) error {
+	query := `DELETE FROM public.transactions WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("transaction not found")
+	}
+
+	return nil
+}
+
+// MergeTransactions saves the merged transaction and deletes its duplicate in
+// one database transaction.
+func (r *PostgresRepositories) MergeTransactions(ctx context.Context, keep *models.Transaction, duplicateID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := mergeTransactions(ctx, tx, keep, duplicateID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// mergeTransactions does the work of MergeTransactions inside tx. The
+// duplicate's receipt links move to the kept transaction before the
+// duplicate is deleted, since deleting it would cascade to them; a receipt
+// already linked to both keeps its existing link.
+func mergeTransactions(ctx context.Context, tx pgx.Tx, keep *models.Transaction, duplicateID string) error {
+	query := `
+		UPDATE public.transactions
+		SET category_id = $2, description = $3, notes = $4, tags = $6, merchant_id = $7,
+		    custom_fields = $8, updated_at = NOW()
+		WHERE id = $1 AND user_id = $5
+		RETURNING updated_at`
+
+	err := tx.QueryRow(ctx, query,
+		keep.ID,
+		keep.CategoryID,
+		keep.Description,
+		keep.Notes,
+		keep.UserID,
+		tagsParam(keep.Tags),
+		keep.MerchantID,
+		customFieldsParam(keep.CustomFields),
+	).Scan(&keep.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update merged transaction: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM public.transaction_splits WHERE transaction_id = $1`, keep.ID)
+	if err != nil {
+		return fmt.Errorf("failed to clear transaction splits: %w", err)
+	}
+
+	if err := insertSplits(ctx, tx, keep); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE public.attachment_links l
+		SET transaction_id = $1
+		WHERE l.transaction_id = $2
+		  AND NOT EXISTS (
+		    SELECT 1 FROM public.attachment_links k
+		    WHERE k.transaction_id = $1 AND k.attachment_id = l.attachment_id
+		  )`, keep.ID, duplicateID)
+	if err != nil {
+		return fmt.Errorf("failed to move attachment links: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM public.transactions WHERE id = $1 AND user_id = $2`, duplicateID, keep.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to delete duplicate transaction: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("duplicate transaction not found")
+	}
+
+	return nil
+}
+
+// customFieldsParam keeps a nil custom field map from being written as NULL.
+func customFieldsParam(fields map[string]interface{}) map[string]interface{} {
+	if fields == nil {
+		return map[string]interface{}{}
+	}
+	return fields
+}
+
+// tagFilterCondition returns the SQL condition restricting transactions
+// aliased t to the filter's tags, bound to parameter $n. The parameter must
+// hold the lowercased tags.
+func tagFilterCondition(filter models.TagFilter, n int) string {
+	if filter.MatchAll {
+		return fmt.Sprintf(`NOT EXISTS (
+		    SELECT 1 FROM unnest($%d::text[]) AS wanted
+		    WHERE wanted <> ALL (SELECT lower(tag) FROM unnest(t.tags) AS tag)
+		  )`, n)
+	}
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM unnest(t.tags) AS tag WHERE lower(tag) = ANY($%d::text[]))`, n)
+}
+
+func lowerTags(tags []string) []string {
+	lowered := make([]string, len(tags))
+	for i, tag := range tags {
+		lowered[i] = strings.ToLower(strings.TrimSpace(tag))
+	}
+	return lowered
+}
+
+// tagsParam keeps a nil tag list from being written as NULL.
+func tagsParam(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// insertSplits writes the split lines of a transaction inside an open database transaction.
+func insertSplits(ctx context.Context, tx pgx.Tx, transaction *models.Transaction) error {
+	query := `
+		INSERT INTO public.transaction_splits (transaction_id, user_id, category_id, amount, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	for i := range transaction.Splits {
+		split := &transaction.Splits[i]
+		split.TransactionID = transaction.ID
+		split.UserID = transaction.UserID
+
+		err := tx.QueryRow(ctx, query,
+			split.TransactionID,
+			split.UserID,
+			split.CategoryID,
+			split.Amount,
+			split.Description,
+		).Scan(&split.ID, &split.CreatedAt, &split.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create transaction split: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetCategorySpending returns expense totals per category for a date range,
+// counting split transactions against each of their split categories.
+// Uncategorized spending is keyed by the empty string.
+func (r *PostgresRepositories) GetCategorySpending(ctx context.Context, userID string, startDate, endDate time.Time) (map[string]float64, error) {
+	query := `
+		SELECT COALESCE(category_id::text, ''), SUM(ABS(amount))
+		FROM public.transaction_lines
+		WHERE user_id = $1
+		  AND transaction_date >= $2
+		  AND transaction_date <= $3
+		  AND transaction_type = 'expense'
+		GROUP BY category_id`
+
+	rows, err := r.pool.Query(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category spending: %w", err)
+	}
+	defer rows.Close()
+
+	spending := make(map[string]float64)
+	for rows.Next() {
+		var categoryID string
+		var amount float64
+		if err := rows.Scan(&categoryID, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan category spending: %w", err)
+		}
+		spending[categoryID] = amount
+	}
+
+	return spending, nil
+}
+
+// Interface compliance check
+var _ repositories.TransactionSplitRepository = (*PostgresRepositories)(nil)
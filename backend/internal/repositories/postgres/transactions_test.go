@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingTx is a pgx.Tx that records the statements run on it, reporting
+// one affected row for each
+type recordingTx struct {
+	pgx.Tx
+	statements []recordedStatement
+}
+
+type recordedStatement struct {
+	sql  string
+	args []interface{}
+}
+
+func (tx *recordingTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	tx.statements = append(tx.statements, recordedStatement{sql: sql, args: args})
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (tx *recordingTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	tx.statements = append(tx.statements, recordedStatement{sql: sql, args: args})
+	return recordedRow{}
+}
+
+// recordedRow scans the current time into every destination
+type recordedRow struct{}
+
+func (recordedRow) Scan(dest ...interface{}) error {
+	for _, d := range dest {
+		if t, ok := d.(*time.Time); ok {
+			*t = time.Now()
+		}
+	}
+	return nil
+}
+
+// statementIndex returns the index of the first statement containing all of
+// the fragments, or -1
+func (tx *recordingTx) statementIndex(fragments ...string) int {
+	for i, st := range tx.statements {
+		matched := true
+		for _, f := range fragments {
+			matched = matched && strings.Contains(st.sql, f)
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestMergeTransactions_MovesAttachmentLinks(t *testing.T) {
+	tx := &recordingTx{}
+	keep := &models.Transaction{ID: "txn-keep", UserID: "user-1"}
+
+	assert.NoError(t, mergeTransactions(context.Background(), tx, keep, "txn-dup"))
+
+	moved := tx.statementIndex("UPDATE public.attachment_links", "NOT EXISTS")
+	deleted := tx.statementIndex("DELETE FROM public.transactions")
+	if !assert.NotEqual(t, -1, moved, "attachment links are not moved") || !assert.NotEqual(t, -1, deleted) {
+		return
+	}
+	// Deleting the duplicate first would cascade to its links
+	assert.Less(t, moved, deleted)
+	assert.Equal(t, []interface{}{"txn-keep", "txn-dup"}, tx.statements[moved].args)
+}
@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Transfer Repository Implementation
+
+// CreateTransfer inserts both legs of a transfer in one database transaction.
+// The outgoing leg is stored as a negative amount and the incoming leg as a
+// positive amount, both tagged with the same transfer_id.
+func (r *PostgresRepositories) CreateTransfer(ctx context.Context, transfer *models.Transfer) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT gen_random_uuid()`).Scan(&transfer.TransferID); err != nil {
+		return fmt.Errorf("failed to generate transfer ID: %w", err)
+	}
+
+	outgoing, incoming := transferLegs(transfer)
+
+	query := `
+		INSERT INTO public.transactions (user_id, account_id, amount, transaction_type, description,
+		                                 transaction_date, notes, transfer_id, currency,
+		                                 original_currency, exchange_rate)
+		VALUES ($1, $2, $3, 'transfer', $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`
+
+	for _, leg := range []*models.Transaction{outgoing, incoming} {
+		err := tx.QueryRow(ctx, query,
+			leg.UserID,
+			leg.AccountID,
+			leg.Amount,
+			leg.Description,
+			leg.TransactionDate,
+			leg.Notes,
+			leg.TransferID,
+			leg.Currency,
+			leg.OriginalCurrency,
+			leg.ExchangeRate,
+		).Scan(&leg.ID, &leg.CreatedAt, &leg.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create transfer leg: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	transfer.OutgoingLeg = outgoing
+	transfer.IncomingLeg = incoming
+
+	return nil
+}
+
+func (r *PostgresRepositories) GetTransferByID(ctx context.Context, transferID string) (*models.Transfer, error) {
+	query := `
+		SELECT id, user_id, account_id, amount, transaction_type, description, transaction_date,
+		       notes, transfer_id, currency, original_currency, exchange_rate,
+		       COALESCE(is_cleared, false), COALESCE(is_locked, false), reconciliation_id,
+		       created_at, updated_at
+		FROM public.transactions
+		WHERE transfer_id = $1
+		ORDER BY amount`
+
+	rows, err := r.pool.Query(ctx, query, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer: %w", err)
+	}
+	defer rows.Close()
+
+	var legs []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.AccountID,
+			&t.Amount,
+			&t.TransactionType,
+			&t.Description,
+			&t.TransactionDate,
+			&t.Notes,
+			&t.TransferID,
+			&t.Currency,
+			&t.OriginalCurrency,
+			&t.ExchangeRate,
+			&t.IsCleared,
+			&t.IsLocked,
+			&t.ReconciliationID,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transfer leg: %w", err)
+		}
+		legs = append(legs, t)
+	}
+
+	if len(legs) != 2 || legs[0].Amount >= 0 || legs[1].Amount <= 0 {
+		return nil, repositories.ErrTransferNotFound
+	}
+
+	outgoing, incoming := &legs[0], &legs[1]
+	transfer := &models.Transfer{
+		TransferID:      transferID,
+		UserID:          outgoing.UserID,
+		FromAccountID:   outgoing.AccountID,
+		ToAccountID:     incoming.AccountID,
+		Amount:          -outgoing.Amount,
+		ConvertedAmount: incoming.Amount,
+		ExchangeRate:    1,
+		TransactionDate: outgoing.TransactionDate,
+		Description:     outgoing.Description,
+		Notes:           outgoing.Notes,
+		OutgoingLeg:     outgoing,
+		IncomingLeg:     incoming,
+	}
+	if outgoing.Currency != nil {
+		transfer.FromCurrency = *outgoing.Currency
+	}
+	if incoming.Currency != nil {
+		transfer.ToCurrency = *incoming.Currency
+	}
+	if incoming.ExchangeRate != nil {
+		transfer.ExchangeRate = *incoming.ExchangeRate
+	}
+
+	return transfer, nil
+}
+
+// UpdateTransfer rewrites both legs so they stay consistent with each other.
+func (r *PostgresRepositories) UpdateTransfer(ctx context.Context, transfer *models.Transfer) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	outgoing, incoming := transferLegs(transfer)
+	outgoing.ID = transfer.OutgoingLeg.ID
+	incoming.ID = transfer.IncomingLeg.ID
+
+	query := `
+		UPDATE public.transactions
+		SET account_id = $2, amount = $3, description = $4, transaction_date = $5, notes = $6,
+		    currency = $7, original_currency = $8, exchange_rate = $9, updated_at = NOW()
+		WHERE id = $1 AND transfer_id = $10 AND user_id = $11
+		RETURNING created_at, updated_at`
+
+	for _, leg := range []*models.Transaction{outgoing, incoming} {
+		err := tx.QueryRow(ctx, query,
+			leg.ID,
+			leg.AccountID,
+			leg.Amount,
+			leg.Description,
+			leg.TransactionDate,
+			leg.Notes,
+			leg.Currency,
+			leg.OriginalCurrency,
+			leg.ExchangeRate,
+			leg.TransferID,
+			leg.UserID,
+		).Scan(&leg.CreatedAt, &leg.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to update transfer leg: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	transfer.OutgoingLeg = outgoing
+	transfer.IncomingLeg = incoming
+
+	return nil
+}
+
+func (r *PostgresRepositories) DeleteTransfer(ctx context.Context, transferID string) error {
+	query := `DELETE FROM public.transactions WHERE transfer_id = $1`
+
+	result, err := r.pool.Exec(ctx, query, transferID)
+	if err != nil {
+		return fmt.Errorf("failed to delete transfer: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("transfer not found")
+	}
+
+	return nil
+}
+
+// GetLatestExchangeRate returns the most recent stored rate converting base into target.
+func (r *PostgresRepositories) GetLatestExchangeRate(ctx context.Context, baseCurrency, targetCurrency string) (float64, error) {
+	query := `SELECT rate FROM public.get_latest_exchange_rate($1, $2)`
+
+	var rate float64
+	err := r.pool.QueryRow(ctx, query, baseCurrency, targetCurrency).Scan(&rate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("no exchange rate available for %s to %s", baseCurrency, targetCurrency)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	return rate, nil
+}
+
+// transferLegs builds the two transaction rows that represent a transfer.
+func transferLegs(transfer *models.Transfer) (*models.Transaction, *models.Transaction) {
+	fromCurrency := transfer.FromCurrency
+	toCurrency := transfer.ToCurrency
+
+	outgoing := &models.Transaction{
+		UserID:          transfer.UserID,
+		AccountID:       transfer.FromAccountID,
+		Amount:          -transfer.Amount,
+		TransactionType: models.TransactionTypeTransfer,
+		Description:     transfer.Description,
+		TransactionDate: transfer.TransactionDate,
+		Notes:           transfer.Notes,
+		TransferID:      &transfer.TransferID,
+		Currency:        &fromCurrency,
+	}
+
+	incoming := &models.Transaction{
+		UserID:          transfer.UserID,
+		AccountID:       transfer.ToAccountID,
+		Amount:          transfer.ConvertedAmount,
+		TransactionType: models.TransactionTypeTransfer,
+		Description:     transfer.Description,
+		TransactionDate: transfer.TransactionDate,
+		Notes:           transfer.Notes,
+		TransferID:      &transfer.TransferID,
+		Currency:        &toCurrency,
+	}
+
+	// The applied rate is recorded on the incoming leg of a cross-currency transfer
+	if fromCurrency != toCurrency {
+		rate := transfer.ExchangeRate
+		incoming.OriginalCurrency = &fromCurrency
+		incoming.ExchangeRate = &rate
+	}
+
+	return outgoing, incoming
+}
+
+// Interface compliance check
+var _ repositories.TransferRepository = (*PostgresRepositories)(nil)
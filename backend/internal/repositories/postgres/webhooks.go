@@ -0,0 +1,344 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// Webhook Repository Implementation
+
+const webhookSubscriptionColumns = `
+	id, user_id, url, secret, event_types, description, is_active, created_at, updated_at`
+
+func scanWebhookSubscription(row pgx.Row, s *models.WebhookSubscription) error {
+	return row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.URL,
+		&s.Secret,
+		&s.EventTypes,
+		&s.Description,
+		&s.IsActive,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+}
+
+// webhookDeliveryColumns selects a delivery as d with its event as e
+const webhookDeliveryColumns = `
+	d.id, d.event_id, d.subscription_id, d.user_id, d.status, d.attempts,
+	d.next_attempt_at, d.last_response_status, d.last_error, d.delivered_at,
+	d.replay_of, d.created_at, d.updated_at,
+	e.event_type, e.data, e.created_at`
+
+func scanWebhookDelivery(row pgx.Row, d *models.WebhookDelivery, extra ...any) error {
+	d.Event = &models.WebhookEvent{}
+	dest := []any{
+		&d.ID, &d.EventID, &d.SubscriptionID, &d.UserID, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastResponseStatus, &d.LastError, &d.DeliveredAt,
+		&d.ReplayOf, &d.CreatedAt, &d.UpdatedAt,
+		&d.Event.EventType, &d.Event.Data, &d.Event.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	d.Event.ID = d.EventID
+	d.Event.UserID = d.UserID
+	return nil
+}
+
+// GetWebhookSubscriptionsByUserID returns the user's webhook subscriptions,
+// oldest first
+func (r *PostgresRepositories) GetWebhookSubscriptionsByUserID(ctx context.Context, userID string) ([]models.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM public.webhook_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []models.WebhookSubscription{}
+	for rows.Next() {
+		var s models.WebhookSubscription
+		if err := scanWebhookSubscription(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PostgresRepositories) GetWebhookSubscriptionByID(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM public.webhook_subscriptions
+		WHERE id = $1`
+
+	s := &models.WebhookSubscription{}
+	if err := scanWebhookSubscription(r.pool.QueryRow(ctx, query, id), s); err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription by ID: %w", err)
+	}
+
+	return s, nil
+}
+
+func (r *PostgresRepositories) CreateWebhookSubscription(ctx context.Context, s *models.WebhookSubscription) error {
+	query := `
+		INSERT INTO public.webhook_subscriptions (user_id, url, secret, event_types, description, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		s.UserID,
+		s.URL,
+		s.Secret,
+		s.EventTypes,
+		s.Description,
+		s.IsActive,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) UpdateWebhookSubscription(ctx context.Context, s *models.WebhookSubscription) error {
+	query := `
+		UPDATE public.webhook_subscriptions
+		SET url = $2, secret = $3, event_types = $4, description = $5, is_active = $6
+		WHERE id = $1 AND user_id = $7
+		RETURNING updated_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		s.ID,
+		s.URL,
+		s.Secret,
+		s.EventTypes,
+		s.Description,
+		s.IsActive,
+		s.UserID,
+	).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepositories) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM public.webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook subscription not found")
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries returns a subscription's most recent deliveries with
+// their events, optionally only those with the given status
+func (r *PostgresRepositories) GetWebhookDeliveries(ctx context.Context, subscriptionID string, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM public.webhook_deliveries d
+		JOIN public.webhook_events e ON e.id = d.event_id
+		WHERE d.subscription_id = $1
+		  AND ($2 = '' OR d.status = $2)
+		ORDER BY d.created_at DESC
+		LIMIT $3`
+
+	rows, err := r.pool.Query(ctx, query, subscriptionID, string(status), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &d); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+// GetWebhookDeliveryByID returns a delivery with its event and the log of
+// its attempts
+func (r *PostgresRepositories) GetWebhookDeliveryByID(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM public.webhook_deliveries d
+		JOIN public.webhook_events e ON e.id = d.event_id
+		WHERE d.id = $1`
+
+	d := &models.WebhookDelivery{}
+	if err := scanWebhookDelivery(r.pool.QueryRow(ctx, query, id), d); err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery by ID: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, delivery_id, attempt, response_status, error, duration_ms, attempted_at
+		FROM public.webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery attempts: %w", err)
+	}
+	defer rows.Close()
+
+	d.AttemptLog = []models.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var a models.WebhookDeliveryAttempt
+		err := rows.Scan(&a.ID, &a.DeliveryID, &a.Attempt, &a.ResponseStatus, &a.Error, &a.DurationMs, &a.AttemptedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery attempt: %w", err)
+		}
+		d.AttemptLog = append(d.AttemptLog, a)
+	}
+
+	return d, nil
+}
+
+// ReplayWebhookDelivery queues a new delivery of a delivery's event to the
+// same subscription, due now, and returns it
+func (r *PostgresRepositories) ReplayWebhookDelivery(ctx context.Context, id string, now time.Time) (*models.WebhookDelivery, error) {
+	query := `
+		WITH replay AS (
+			INSERT INTO public.webhook_deliveries (event_id, subscription_id, user_id, next_attempt_at, replay_of)
+			SELECT event_id, subscription_id, user_id, $2, id
+			FROM public.webhook_deliveries
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT ` + webhookDeliveryColumns + `
+		FROM replay d
+		JOIN public.webhook_events e ON e.id = d.event_id`
+
+	d := &models.WebhookDelivery{}
+	if err := scanWebhookDelivery(r.pool.QueryRow(ctx, query, id, now), d); err != nil {
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	return d, nil
+}
+
+// ClaimDueWebhookDeliveries takes up to limit pending deliveries to active
+// subscriptions due by now, oldest first, with their events and
+// subscriptions. They are leased until leaseUntil: if no attempt is recorded
+// by then, they become due again. Deliveries claimed by another dispatcher
+// are skipped.
+func (r *PostgresRepositories) ClaimDueWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE public.webhook_deliveries
+			SET next_attempt_at = $2
+			WHERE id IN (
+				SELECT d.id FROM public.webhook_deliveries d
+				JOIN public.webhook_subscriptions s ON s.id = d.subscription_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= $1 AND s.is_active
+				ORDER BY d.next_attempt_at
+				LIMIT $3
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + webhookDeliveryColumns + `,
+		       s.url, s.secret
+		FROM claimed d
+		JOIN public.webhook_events e ON e.id = d.event_id
+		JOIN public.webhook_subscriptions s ON s.id = d.subscription_id
+		ORDER BY d.created_at`
+
+	rows, err := r.pool.Query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		s := &models.WebhookSubscription{IsActive: true}
+		if err := scanWebhookDelivery(rows, &d, &s.URL, &s.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		s.ID = d.SubscriptionID
+		s.UserID = d.UserID
+		d.Subscription = s
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt logs an attempt at a delivery and moves the delivery
+// to status: sent, failed, or pending again until nextAttemptAt
+func (r *PostgresRepositories) RecordWebhookAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt, status models.WebhookDeliveryStatus, nextAttemptAt time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO public.webhook_delivery_attempts
+		    (delivery_id, attempt, response_status, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		attempt.DeliveryID,
+		attempt.Attempt,
+		attempt.ResponseStatus,
+		attempt.Error,
+		attempt.DurationMs,
+		attempt.AttemptedAt,
+	).Scan(&attempt.ID)
+	if err != nil {
+		return fmt.Errorf("failed to log webhook delivery attempt: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE public.webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4,
+		    last_response_status = $5, last_error = $6,
+		    delivered_at = CASE WHEN $2 = 'sent' THEN $7 ELSE delivered_at END
+		WHERE id = $1`,
+		attempt.DeliveryID,
+		string(status),
+		attempt.Attempt,
+		nextAttemptAt,
+		attempt.ResponseStatus,
+		attempt.Error,
+		attempt.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Interface compliance check
+var _ repositories.WebhookRepository = (*PostgresRepositories)(nil)
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// HighConfidenceThreshold is the confidence at or above which a prediction
+// counts as high confidence, matching the frontend categorizers.
+const HighConfidenceThreshold = 80
+
+// calibrationBucketWidth splits confidence into ten buckets: 0-9, ..., 90-100.
+const calibrationBucketWidth = 10
+
+// BuildCategorizationAccuracy fills the overall, per-source, per-category,
+// calibration and monthly trend figures of the report from reviewed outcomes.
+// When an outcome was correct but no actual category was recorded, the
+// predicted category is taken as the actual one.
+func BuildCategorizationAccuracy(report *models.CategorizationAccuracy, outcomes []models.CategorizationOutcome) {
+	report.Overall = summarizeOutcomes(outcomes)
+
+	bySource := make(map[string][]models.CategorizationOutcome)
+	for _, o := range outcomes {
+		bySource[o.Source] = append(bySource[o.Source], o)
+	}
+	report.BySource = make(map[string]models.AccuracySummary, len(bySource))
+	for source, group := range bySource {
+		report.BySource[source] = summarizeOutcomes(group)
+	}
+
+	report.Categories = categoryAccuracy(outcomes)
+	report.Calibration = calibrationBuckets(outcomes)
+	report.Trend = accuracyTrend(outcomes, report.StartDate, report.EndDate)
+}
+
+func summarizeOutcomes(outcomes []models.CategorizationOutcome) models.AccuracySummary {
+	var summary models.AccuracySummary
+	confidenceSum := 0
+
+	for _, o := range outcomes {
+		summary.Total++
+		confidenceSum += o.Confidence
+		if o.WasCorrect {
+			summary.Correct++
+		}
+		if o.Confidence >= HighConfidenceThreshold {
+			summary.HighConfidenceTotal++
+			if o.WasCorrect {
+				summary.HighConfidenceCorrect++
+			}
+		}
+	}
+
+	summary.Accuracy = ratioPercent(summary.Correct, summary.Total)
+	summary.HighConfidenceAccuracy = ratioPercent(summary.HighConfidenceCorrect, summary.HighConfidenceTotal)
+	if summary.Total > 0 {
+		summary.AverageConfidence = roundTo2(float64(confidenceSum) / float64(summary.Total))
+	}
+
+	return summary
+}
+
+func categoryAccuracy(outcomes []models.CategorizationOutcome) []models.CategoryAccuracy {
+	stats := make(map[string]*models.CategoryAccuracy)
+	get := func(id string, name *string) *models.CategoryAccuracy {
+		entry, ok := stats[id]
+		if !ok {
+			entry = &models.CategoryAccuracy{CategoryID: id}
+			stats[id] = entry
+		}
+		if entry.CategoryName == "" && name != nil {
+			entry.CategoryName = *name
+		}
+		return entry
+	}
+
+	for _, o := range outcomes {
+		predicted := stringValue(o.PredictedCategoryID)
+		actual := stringValue(o.ActualCategoryID)
+		actualName := o.ActualCategoryName
+		if actual == "" && o.WasCorrect {
+			actual, actualName = predicted, o.PredictedCategoryName
+		}
+
+		if predicted != "" {
+			entry := get(predicted, o.PredictedCategoryName)
+			entry.Predicted++
+			if o.WasCorrect {
+				entry.TruePositives++
+			}
+		}
+		if actual != "" {
+			get(actual, actualName).Actual++
+		}
+	}
+
+	result := make([]models.CategoryAccuracy, 0, len(stats))
+	for _, entry := range stats {
+		entry.Precision = ratioPercent(entry.TruePositives, entry.Predicted)
+		entry.Recall = ratioPercent(entry.TruePositives, entry.Actual)
+		if entry.Precision+entry.Recall > 0 {
+			entry.F1Score = roundTo2(2 * entry.Precision * entry.Recall / (entry.Precision + entry.Recall))
+		}
+		result = append(result, *entry)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Actual == result[j].Actual {
+			return result[i].CategoryID < result[j].CategoryID
+		}
+		return result[i].Actual > result[j].Actual
+	})
+
+	return result
+}
+
+func calibrationBuckets(outcomes []models.CategorizationOutcome) []models.CalibrationBucket {
+	count := 100 / calibrationBucketWidth
+	buckets := make([]models.CalibrationBucket, count)
+	correct := make([]int, count)
+	confidenceSums := make([]int, count)
+
+	for i := range buckets {
+		buckets[i].MinConfidence = i * calibrationBucketWidth
+		buckets[i].MaxConfidence = (i+1)*calibrationBucketWidth - 1
+	}
+	buckets[count-1].MaxConfidence = 100
+
+	for _, o := range outcomes {
+		i := o.Confidence / calibrationBucketWidth
+		if i >= count {
+			i = count - 1
+		}
+		if i < 0 {
+			i = 0
+		}
+		buckets[i].Count++
+		confidenceSums[i] += o.Confidence
+		if o.WasCorrect {
+			correct[i]++
+		}
+	}
+
+	for i := range buckets {
+		if buckets[i].Count > 0 {
+			buckets[i].AverageConfidence = roundTo2(float64(confidenceSums[i]) / float64(buckets[i].Count))
+			buckets[i].Accuracy = ratioPercent(correct[i], buckets[i].Count)
+		}
+	}
+
+	return buckets
+}
+
+// accuracyTrend returns one entry per calendar month from start to end,
+// including months without any reviewed predictions.
+func accuracyTrend(outcomes []models.CategorizationOutcome, start, end time.Time) []models.AccuracyTrendItem {
+	trend := []models.AccuracyTrendItem{}
+	index := make(map[[2]int]int)
+
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !month.After(last) {
+		index[[2]int{month.Year(), int(month.Month())}] = len(trend)
+		trend = append(trend, models.AccuracyTrendItem{Month: int(month.Month()), Year: month.Year()})
+		month = month.AddDate(0, 1, 0)
+	}
+
+	for _, o := range outcomes {
+		i, ok := index[[2]int{o.CreatedAt.Year(), int(o.CreatedAt.Month())}]
+		if !ok {
+			continue
+		}
+		trend[i].Total++
+		if o.WasCorrect {
+			trend[i].Correct++
+		}
+	}
+
+	for i := range trend {
+		trend[i].Accuracy = ratioPercent(trend[i].Correct, trend[i].Total)
+	}
+
+	return trend
+}
+
+func ratioPercent(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return roundTo2(float64(part) / float64(whole) * 100)
+}
+
+func roundTo2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func accuracyOutcome(source, predicted, actual string, confidence int, correct bool, month time.Month) models.CategorizationOutcome {
+	o := models.CategorizationOutcome{
+		Source:     source,
+		Confidence: confidence,
+		WasCorrect: correct,
+		CreatedAt:  time.Date(2024, month, 15, 0, 0, 0, 0, time.UTC),
+	}
+	if predicted != "" {
+		o.PredictedCategoryID = &predicted
+	}
+	if actual != "" {
+		o.ActualCategoryID = &actual
+	}
+	return o
+}
+
+func TestBuildCategorizationAccuracy(t *testing.T) {
+	outcomes := []models.CategorizationOutcome{
+		accuracyOutcome("ai", "food", "", 95, true, time.January), // actual inferred from prediction
+		accuracyOutcome("ai", "food", "food", 85, true, time.January),
+		accuracyOutcome("ai", "food", "fuel", 82, false, time.February),
+		accuracyOutcome("ml", "fuel", "fuel", 55, true, time.March),
+		accuracyOutcome("ml", "fuel", "rent", 40, false, time.March),
+	}
+
+	report := &models.CategorizationAccuracy{
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	}
+	BuildCategorizationAccuracy(report, outcomes)
+
+	assert.Equal(t, 5, report.Overall.Total)
+	assert.Equal(t, 3, report.Overall.Correct)
+	assert.Equal(t, 60.0, report.Overall.Accuracy)
+	assert.Equal(t, 3, report.Overall.HighConfidenceTotal)
+	assert.Equal(t, 66.67, report.Overall.HighConfidenceAccuracy)
+	assert.Equal(t, 71.4, report.Overall.AverageConfidence)
+
+	assert.Equal(t, 3, report.BySource["ai"].Total)
+	assert.Equal(t, 50.0, report.BySource["ml"].Accuracy)
+
+	byID := make(map[string]models.CategoryAccuracy)
+	for _, c := range report.Categories {
+		byID[c.CategoryID] = c
+	}
+	food := byID["food"]
+	assert.Equal(t, 3, food.Predicted)
+	assert.Equal(t, 2, food.Actual)
+	assert.Equal(t, 66.67, food.Precision)
+	assert.Equal(t, 100.0, food.Recall)
+	fuel := byID["fuel"]
+	assert.Equal(t, 50.0, fuel.Precision)
+	assert.Equal(t, 50.0, fuel.Recall)
+	assert.Equal(t, 50.0, fuel.F1Score)
+	assert.Equal(t, 0.0, byID["rent"].Recall)
+
+	assert.Len(t, report.Calibration, 10)
+	assert.Equal(t, 90, report.Calibration[9].MinConfidence)
+	assert.Equal(t, 100, report.Calibration[9].MaxConfidence)
+	assert.Equal(t, 1, report.Calibration[9].Count)
+	assert.Equal(t, 2, report.Calibration[8].Count)
+	assert.Equal(t, 50.0, report.Calibration[8].Accuracy)
+	assert.Equal(t, 0, report.Calibration[0].Count)
+
+	assert.Len(t, report.Trend, 4)
+	assert.Equal(t, 100.0, report.Trend[0].Accuracy)
+	assert.Equal(t, 0.0, report.Trend[1].Accuracy)
+	assert.Equal(t, 2, report.Trend[2].Total)
+	assert.Equal(t, 0, report.Trend[3].Total)
+}
+
+func TestBuildCategorizationAccuracy_NoOutcomes(t *testing.T) {
+	report := &models.CategorizationAccuracy{
+		StartDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+	BuildCategorizationAccuracy(report, nil)
+
+	assert.Equal(t, 0, report.Overall.Total)
+	assert.Equal(t, 0.0, report.Overall.Accuracy)
+	assert.Empty(t, report.Categories)
+	assert.Len(t, report.Trend, 1)
+}
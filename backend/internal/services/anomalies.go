@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+const (
+	// DefaultAnomalyThreshold is the robust z-score above which spending is
+	// flagged. 3.5 is the usual cut-off for median/MAD outlier detection.
+	DefaultAnomalyThreshold = 3.5
+
+	// anomalyBaselineDays is how far back a transaction's baseline reaches
+	anomalyBaselineDays = 365
+
+	// anomalyBaselineMonths is how many earlier months a category-month is
+	// compared with
+	anomalyBaselineMonths = 12
+
+	// minTransactionBaseline and minMonthBaseline are the fewest earlier
+	// transactions or months a baseline needs before anything is flagged
+	minTransactionBaseline = 5
+	minMonthBaseline       = 3
+
+	// seasonalTolerance lets a month be up to 25% above the same month last
+	// year without being flagged, so yearly peaks such as December gifts are
+	// not reported every year
+	seasonalTolerance = 1.25
+
+	// anomalyAlertLookbackDays is how many recent days the daily alert run
+	// checks, so that transactions imported late are still alerted on
+	anomalyAlertLookbackDays = 7
+)
+
+// AnomalyHistoryStart is the earliest date whose expenses are needed to
+// build the baselines for anomalies from startDate on.
+func AnomalyHistoryStart(startDate time.Time) time.Time {
+	return time.Date(startDate.Year(), startDate.Month()-anomalyBaselineMonths, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// robustScore is the robust z-score of value against a median and median
+// absolute deviation. The deviation is floored at 10% of the median (and at
+// one unit) so that a category with identical amounts does not flag every
+// small change.
+func robustScore(value, center, mad float64) float64 {
+	scale := math.Max(1.4826*mad, math.Max(0.1*center, 1))
+	return (value - center) / scale
+}
+
+func categoryKey(categoryID *string) string {
+	if categoryID == nil {
+		return ""
+	}
+	return *categoryID
+}
+
+// DetectTransactionAnomalies flags expense lines between startDate and
+// endDate, inclusive, that are far above the user's usual amount in the same
+// category over the preceding year. Only amounts above the baseline are
+// flagged. lines must include the baseline history.
+func DetectTransactionAnomalies(lines []models.ExpenseLine, startDate, endDate time.Time, threshold float64) []models.TransactionAnomaly {
+	byCategory := make(map[string][]models.ExpenseLine)
+	for _, line := range lines {
+		key := categoryKey(line.CategoryID)
+		byCategory[key] = append(byCategory[key], line)
+	}
+
+	startDate, endDate = truncateDay(startDate), truncateDay(endDate)
+	anomalies := []models.TransactionAnomaly{}
+	for _, categoryLines := range byCategory {
+		sort.SliceStable(categoryLines, func(i, j int) bool {
+			return categoryLines[i].TransactionDate.Before(categoryLines[j].TransactionDate)
+		})
+
+		for _, line := range categoryLines {
+			date := truncateDay(line.TransactionDate)
+			if date.Before(startDate) || date.After(endDate) {
+				continue
+			}
+
+			baselineStart := date.AddDate(0, 0, -anomalyBaselineDays)
+			var baseline []float64
+			for _, earlier := range categoryLines {
+				earlierDate := truncateDay(earlier.TransactionDate)
+				if !earlierDate.Before(date) {
+					break
+				}
+				if !earlierDate.Before(baselineStart) {
+					baseline = append(baseline, earlier.Amount)
+				}
+			}
+			if len(baseline) < minTransactionBaseline {
+				continue
+			}
+
+			center := median(baseline)
+			mad := medianAbsoluteDeviation(baseline, center)
+			score := robustScore(line.Amount, center, mad)
+			if score < threshold {
+				continue
+			}
+
+			anomalies = append(anomalies, models.TransactionAnomaly{
+				TransactionID:   line.TransactionID,
+				AccountID:       line.AccountID,
+				CategoryID:      line.CategoryID,
+				CategoryName:    line.CategoryName,
+				Description:     line.Description,
+				TransactionDate: date,
+				Amount:          line.Amount,
+				BaselineMedian:  roundTo2(center),
+				BaselineMAD:     roundTo2(mad),
+				SampleSize:      len(baseline),
+				Score:           roundTo2(score),
+			})
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].Score != anomalies[j].Score {
+			return anomalies[i].Score > anomalies[j].Score
+		}
+		return anomalies[i].TransactionID < anomalies[j].TransactionID
+	})
+	return anomalies
+}
+
+// monthIndex numbers months consecutively so that month arithmetic is
+// plain integer arithmetic
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// DetectCategoryMonthAnomalies flags category totals for the months from
+// startDate to endDate that are far above the category's totals in the
+// preceding twelve months, counting months without spending as zero from the
+// category's first expense on. A month that is no more than 25% above the
+// same month a year earlier is treated as seasonal and not flagged. The
+// month containing today is compared month-to-date.
+func DetectCategoryMonthAnomalies(lines []models.ExpenseLine, startDate, endDate, today time.Time, threshold float64) []models.CategoryMonthAnomaly {
+	type categoryTotals struct {
+		categoryID *string
+		name       string
+		first      int
+		totals     map[int]int64 // Cents by month index
+	}
+
+	categories := make(map[string]*categoryTotals)
+	for _, line := range lines {
+		key := categoryKey(line.CategoryID)
+		category, ok := categories[key]
+		if !ok {
+			category = &categoryTotals{
+				categoryID: line.CategoryID,
+				name:       line.CategoryName,
+				first:      math.MaxInt,
+				totals:     make(map[int]int64),
+			}
+			categories[key] = category
+		}
+		month := monthIndex(line.TransactionDate)
+		if month < category.first {
+			category.first = month
+		}
+		category.totals[month] += toCents(line.Amount)
+	}
+
+	current := monthIndex(today)
+	anomalies := []models.CategoryMonthAnomaly{}
+	for month := monthIndex(startDate); month <= monthIndex(endDate); month++ {
+		for _, category := range categories {
+			total := float64(category.totals[month]) / 100
+			if total == 0 {
+				continue
+			}
+
+			var baseline []float64
+			for earlier := month - anomalyBaselineMonths; earlier < month; earlier++ {
+				if earlier >= category.first {
+					baseline = append(baseline, float64(category.totals[earlier])/100)
+				}
+			}
+			if len(baseline) < minMonthBaseline {
+				continue
+			}
+
+			var lastYear *float64
+			if month-12 >= category.first {
+				value := float64(category.totals[month-12]) / 100
+				lastYear = &value
+				if value > 0 && total <= value*seasonalTolerance {
+					continue
+				}
+			}
+
+			center := median(baseline)
+			mad := medianAbsoluteDeviation(baseline, center)
+			score := robustScore(total, center, mad)
+			if score < threshold {
+				continue
+			}
+
+			anomalies = append(anomalies, models.CategoryMonthAnomaly{
+				CategoryID:        category.categoryID,
+				CategoryName:      category.name,
+				Month:             month%12 + 1,
+				Year:              month / 12,
+				Total:             total,
+				BaselineMedian:    roundTo2(center),
+				BaselineMAD:       roundTo2(mad),
+				MonthsOfHistory:   len(baseline),
+				SameMonthLastYear: lastYear,
+				Score:             roundTo2(score),
+				MonthToDate:       month == current,
+			})
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		a, b := anomalies[i], anomalies[j]
+		if a.Year != b.Year || a.Month != b.Month {
+			return a.Year*12+a.Month > b.Year*12+b.Month
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.CategoryName < b.CategoryName
+	})
+	return anomalies
+}
+
+// AnomalyStore is the data access the anomaly service needs.
+type AnomalyStore interface {
+	repositories.InsightsRepository
+	repositories.NotificationRepository
+	GetUserIDsWithActiveAccounts(ctx context.Context) ([]string, error)
+}
+
+// AnomalyService detects unusual spending and raises alerts for it.
+type AnomalyService struct {
+	store AnomalyStore
+}
+
+// NewAnomalyService creates an anomaly service
+func NewAnomalyService(store AnomalyStore) *AnomalyService {
+	return &AnomalyService{store: store}
+}
+
+// Detect finds the user's unusual transactions and category-months between
+// the dates, inclusive.
+func (s *AnomalyService) Detect(ctx context.Context, userID string, startDate, endDate, today time.Time, threshold float64) (*models.AnomalyReport, error) {
+	lines, err := s.store.GetExpenseLines(ctx, userID, AnomalyHistoryStart(startDate), endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AnomalyReport{
+		UserID:         userID,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		Threshold:      threshold,
+		Transactions:   DetectTransactionAnomalies(lines, startDate, endDate, threshold),
+		CategoryMonths: DetectCategoryMonthAnomalies(lines, startDate, endDate, today, threshold),
+		GeneratedAt:    time.Now(),
+	}, nil
+}
+
+// Notify raises a transaction_alert notification for each anomaly in the
+// report that has not been alerted on before, recording how many were
+// created in the report.
+func (s *AnomalyService) Notify(ctx context.Context, report *models.AnomalyReport) (int, error) {
+	created := 0
+	for _, anomaly := range report.Transactions {
+		description := "A transaction"
+		if anomaly.Description != nil && *anomaly.Description != "" {
+			description = *anomaly.Description
+		}
+		notification := &models.Notification{
+			UserID: report.UserID,
+			Title:  "Unusual Transaction",
+			Message: fmt.Sprintf("%s of %.2f in %s on %s is well above your usual %.2f",
+				description, anomaly.Amount, anomaly.CategoryName,
+				anomaly.TransactionDate.Format("Jan 2"), anomaly.BaselineMedian),
+			Type: models.NotificationTypeTransactionAlert,
+			Metadata: map[string]interface{}{
+				"anomaly":         "transaction",
+				"transaction_id":  anomaly.TransactionID,
+				"category_id":     anomaly.CategoryID,
+				"category_name":   anomaly.CategoryName,
+				"amount":          anomaly.Amount,
+				"baseline_median": anomaly.BaselineMedian,
+				"score":           anomaly.Score,
+			},
+		}
+		key := "anomaly:transaction:" + anomaly.TransactionID + ":" + categoryKey(anomaly.CategoryID)
+		ok, err := s.store.CreateNotification(ctx, notification, key)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+		}
+	}
+
+	for _, anomaly := range report.CategoryMonths {
+		period := time.Date(anomaly.Year, time.Month(anomaly.Month), 1, 0, 0, 0, 0, time.UTC)
+		notification := &models.Notification{
+			UserID: report.UserID,
+			Title:  fmt.Sprintf("Unusual Spending in %s", anomaly.CategoryName),
+			Message: fmt.Sprintf("You have spent %.2f on %s in %s, well above your usual %.2f a month",
+				anomaly.Total, anomaly.CategoryName, period.Format("January 2006"), anomaly.BaselineMedian),
+			Type: models.NotificationTypeTransactionAlert,
+			Metadata: map[string]interface{}{
+				"anomaly":         "category_month",
+				"category_id":     anomaly.CategoryID,
+				"category_name":   anomaly.CategoryName,
+				"month":           anomaly.Month,
+				"year":            anomaly.Year,
+				"total":           anomaly.Total,
+				"baseline_median": anomaly.BaselineMedian,
+				"score":           anomaly.Score,
+			},
+		}
+		key := fmt.Sprintf("anomaly:category-month:%s:%s", categoryKey(anomaly.CategoryID), period.Format("2006-01"))
+		ok, err := s.store.CreateNotification(ctx, notification, key)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+		}
+	}
+
+	report.NotificationsCreated += created
+	return created, nil
+}
+
+// NotifyAll checks the last week's spending of every user with an active
+// account and raises alerts for new anomalies. A failure for one user does
+// not stop the others; it returns how many notifications were created.
+func (s *AnomalyService) NotifyAll(ctx context.Context, today time.Time) (int, error) {
+	userIDs, err := s.store.GetUserIDsWithActiveAccounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	today = truncateDay(today)
+	startDate := today.AddDate(0, 0, -anomalyAlertLookbackDays+1)
+	created, failed := 0, 0
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return created, err
+		}
+		report, err := s.Detect(ctx, userID, startDate, today, today, DefaultAnomalyThreshold)
+		if err == nil {
+			var n int
+			n, err = s.Notify(ctx, report)
+			created += n
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to check spending anomalies for user %s: %v", userID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return created, fmt.Errorf("%d of %d anomaly checks failed", failed, len(userIDs))
+	}
+	return created, nil
+}
+
+// RunDailyAlerts checks every user's recent spending for anomalies right
+// away and then every morning UTC until the context is cancelled.
+func (s *AnomalyService) RunDailyAlerts(ctx context.Context) {
+	runDaily(ctx, 6, 0, func(now time.Time) {
+		created, err := s.NotifyAll(ctx, now)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Warning: Anomaly alert run incomplete: %v", err)
+		}
+		log.Printf("Raised %d spending anomaly alerts for %s", created, now.Format("2006-01-02"))
+	})
+}
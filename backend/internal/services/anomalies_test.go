@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func expenseLine(id, categoryID string, amount float64, date time.Time) models.ExpenseLine {
+	line := models.ExpenseLine{
+		TransactionID:   id,
+		AccountID:       "acc-1",
+		CategoryName:    "Uncategorized",
+		Amount:          amount,
+		TransactionDate: date,
+	}
+	if categoryID != "" {
+		line.CategoryID = &categoryID
+		line.CategoryName = categoryID
+	}
+	return line
+}
+
+func TestRobustScore(t *testing.T) {
+	assert.InDelta(t, 5.059, robustScore(80, 50, 4), 0.001)
+	// Identical amounts fall back to 10% of the median as the spread
+	assert.Equal(t, 5.0, robustScore(150, 100, 0))
+	assert.Equal(t, 4.0, robustScore(5, 1, 0))
+}
+
+func TestDetectTransactionAnomalies(t *testing.T) {
+	var lines []models.ExpenseLine
+	amounts := []float64{42, 55, 48, 61, 50, 39, 52}
+	for i, amount := range amounts {
+		lines = append(lines, expenseLine("g"+string(rune('a'+i)), "Groceries", amount, forecastDate(2026, 3, 1+i*4)))
+	}
+	lines = append(lines,
+		expenseLine("big", "Groceries", 240, forecastDate(2026, 4, 10)),
+		expenseLine("normal", "Groceries", 58, forecastDate(2026, 4, 12)),
+		// Too little history in the category to judge
+		expenseLine("tv", "Electronics", 900, forecastDate(2026, 4, 11)),
+		// Before the period, so only part of the baseline
+		expenseLine("early", "Groceries", 500, forecastDate(2026, 3, 30)),
+	)
+
+	anomalies := DetectTransactionAnomalies(lines, forecastDate(2026, 4, 1), forecastDate(2026, 4, 30), DefaultAnomalyThreshold)
+
+	if assert.Len(t, anomalies, 1) {
+		anomaly := anomalies[0]
+		assert.Equal(t, "big", anomaly.TransactionID)
+		assert.Equal(t, 8, anomaly.SampleSize)
+		assert.Equal(t, 51.0, anomaly.BaselineMedian)
+		assert.Greater(t, anomaly.Score, DefaultAnomalyThreshold)
+	}
+}
+
+func TestDetectCategoryMonthAnomalies(t *testing.T) {
+	var lines []models.ExpenseLine
+	// Dining out runs about 200 a month, then jumps in April 2026
+	for month := time.Month(1); month <= 12; month++ {
+		lines = append(lines, expenseLine("d", "Dining", 190+float64(month), forecastDate(2025, month, 15)))
+	}
+	lines = append(lines,
+		expenseLine("d", "Dining", 205, forecastDate(2026, 1, 15)),
+		expenseLine("d", "Dining", 198, forecastDate(2026, 2, 15)),
+		expenseLine("d", "Dining", 210, forecastDate(2026, 3, 15)),
+		expenseLine("d", "Dining", 450, forecastDate(2026, 4, 3)),
+		expenseLine("d", "Dining", 300, forecastDate(2026, 4, 20)),
+	)
+	// Gifts peak every December, so this December is seasonal
+	for month := time.Month(1); month <= 12; month++ {
+		amount := 20.0
+		if month == 12 {
+			amount = 600
+		}
+		lines = append(lines, expenseLine("g", "Gifts", amount, forecastDate(2024, month, 10)))
+		if month < 12 {
+			lines = append(lines, expenseLine("g", "Gifts", amount, forecastDate(2025, month, 10)))
+		}
+	}
+	lines = append(lines, expenseLine("g", "Gifts", 650, forecastDate(2025, 12, 10)))
+
+	anomalies := DetectCategoryMonthAnomalies(lines, forecastDate(2025, 12, 1), forecastDate(2026, 4, 30), forecastDate(2026, 4, 25), DefaultAnomalyThreshold)
+
+	if assert.Len(t, anomalies, 1) {
+		anomaly := anomalies[0]
+		assert.Equal(t, "Dining", anomaly.CategoryName)
+		assert.Equal(t, 4, anomaly.Month)
+		assert.Equal(t, 2026, anomaly.Year)
+		assert.Equal(t, 750.0, anomaly.Total)
+		assert.Equal(t, 12, anomaly.MonthsOfHistory)
+		assert.Equal(t, 194.0, *anomaly.SameMonthLastYear)
+		assert.True(t, anomaly.MonthToDate)
+	}
+}
+
+type fakeAnomalyStore struct {
+	lines         []models.ExpenseLine
+	notifications []models.Notification
+	keys          map[string]bool
+}
+
+func (f *fakeAnomalyStore) GetExpenseLines(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.ExpenseLine, error) {
+	return f.lines, nil
+}
+
+func (f *fakeAnomalyStore) CreateNotification(ctx context.Context, notification *models.Notification, dedupeKey string) (bool, error) {
+	if f.keys[dedupeKey] {
+		return false, nil
+	}
+	f.keys[dedupeKey] = true
+	f.notifications = append(f.notifications, *notification)
+	return true, nil
+}
+
+func (f *fakeAnomalyStore) GetUserIDsWithActiveAccounts(ctx context.Context) ([]string, error) {
+	return []string{"u-1"}, nil
+}
+
+func TestAnomalyService_NotifyAllDeduplicates(t *testing.T) {
+	store := &fakeAnomalyStore{keys: make(map[string]bool)}
+	for i := 0; i < 6; i++ {
+		store.lines = append(store.lines, expenseLine("c"+string(rune('a'+i)), "Coffee", 4.5, forecastDate(2026, 4, 1+i)))
+	}
+	store.lines = append(store.lines, expenseLine("espresso-machine", "Coffee", 350, forecastDate(2026, 4, 28)))
+	service := NewAnomalyService(store)
+	today := forecastDate(2026, 4, 30)
+
+	created, err := service.NotifyAll(context.Background(), today)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+	if assert.Len(t, store.notifications, 1) {
+		notification := store.notifications[0]
+		assert.Equal(t, models.NotificationTypeTransactionAlert, notification.Type)
+		assert.Equal(t, "u-1", notification.UserID)
+		assert.Equal(t, "espresso-machine", notification.Metadata["transaction_id"])
+	}
+
+	created, err = service.NotifyAll(context.Background(), today)
+	assert.NoError(t, err)
+	assert.Zero(t, created)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxAttachmentSize is the upload limit used when none is configured.
+const DefaultMaxAttachmentSize = 10 << 20
+
+// maxFileNameLength bounds stored file names.
+const maxFileNameLength = 255
+
+// allowedAttachmentTypes are the content types accepted for upload, keyed by
+// the type sniffed from the file contents.
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"text/plain":      true, // E-receipt bodies and OCR output
+}
+
+// DetectAttachmentType sniffs the content type of an upload from its
+// contents, ignoring whatever the client claimed, and rejects types that
+// are not allowed.
+func DetectAttachmentType(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("file is empty")
+	}
+
+	detected := http.DetectContentType(data)
+	contentType, _, err := mime.ParseMediaType(detected)
+	if err != nil {
+		contentType = detected
+	}
+
+	if !allowedAttachmentTypes[contentType] {
+		return "", fmt.Errorf("files of type %s are not allowed; upload a PDF, JPEG, PNG, GIF, WebP or plain text file", contentType)
+	}
+	return contentType, nil
+}
+
+// AttachmentHash returns the hex SHA-256 of the contents.
+func AttachmentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AttachmentStorageKey returns the object key for a user's file. Keys are
+// content-addressed, so re-uploading the same file reuses the same object.
+func AttachmentStorageKey(userID, hash string) string {
+	return fmt.Sprintf("attachments/%s/%s/%s", userID, hash[:2], hash)
+}
+
+// SanitizeFileName strips any directory part and control characters from a
+// client-supplied file name, falling back to "attachment".
+func SanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if runes := []rune(name); len(runes) > maxFileNameLength {
+		name = string(runes[:maxFileNameLength])
+	}
+	return name
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectAttachmentType(t *testing.T) {
+	contentType, err := DetectAttachmentType([]byte("%PDF-1.7\n..."))
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+
+	contentType, err = DetectAttachmentType([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	contentType, err = DetectAttachmentType([]byte("CORNER MARKET\nTOTAL 12.50\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", contentType)
+
+	_, err = DetectAttachmentType([]byte("<html><script>alert(1)</script></html>"))
+	assert.Error(t, err)
+
+	_, err = DetectAttachmentType([]byte("MZ\x90\x00\x03\x00\x00\x00"))
+	assert.Error(t, err)
+
+	_, err = DetectAttachmentType(nil)
+	assert.Error(t, err)
+}
+
+func TestAttachmentStorageKey(t *testing.T) {
+	hash := AttachmentHash([]byte("receipt"))
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, AttachmentHash([]byte("receipt")))
+	assert.Equal(t, "attachments/u-1/"+hash[:2]+"/"+hash, AttachmentStorageKey("u-1", hash))
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	assert.Equal(t, "receipt.pdf", SanitizeFileName("../../etc/receipt.pdf"))
+	assert.Equal(t, "scan.png", SanitizeFileName(`C:\Users\me\scan.png`))
+	assert.Equal(t, "a b.txt", SanitizeFileName(" a\x00 b\".txt "))
+	assert.Equal(t, "attachment", SanitizeFileName(""))
+	assert.Equal(t, "attachment", SanitizeFileName("/"))
+	assert.Len(t, []rune(SanitizeFileName(strings.Repeat("é", 300))), 255)
+}
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	key := "attachments/u-1/ab/abcdef"
+	assert.NoError(t, storage.Put(ctx, key, strings.NewReader("first")))
+	assert.NoError(t, storage.Put(ctx, key, strings.NewReader("second")))
+
+	reader, err := storage.Get(ctx, key)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	assert.Equal(t, "second", string(data))
+
+	assert.NoError(t, storage.Delete(ctx, key))
+	assert.NoError(t, storage.Delete(ctx, key))
+	_, err = storage.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	for _, bad := range []string{"../escape", "a/../../b", "/abs", "a//b", ""} {
+		assert.Error(t, storage.Put(ctx, bad, strings.NewReader("x")), bad)
+	}
+}
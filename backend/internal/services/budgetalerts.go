@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// budgetPaceMinElapsed is the share of a budget period that has to pass
+// before spending is projected, so a large purchase on the first day does
+// not raise a pace alert on its own
+const budgetPaceMinElapsed = 0.25
+
+// BudgetPeriodBounds returns the first and last day of the budget's period
+// containing date. Periods repeat from the budget's start date, with months
+// counted from its day of the month, and the last one ends on the budget's
+// end date. ok is false when date is outside the budget.
+func BudgetPeriodBounds(budget models.Budget, date time.Time) (start, end time.Time, ok bool) {
+	date = truncateDay(date)
+	anchor := truncateDay(budget.StartDate)
+	if date.Before(anchor) {
+		return time.Time{}, time.Time{}, false
+	}
+	if budget.EndDate != nil && date.After(truncateDay(*budget.EndDate)) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	var months int
+	switch budget.Period {
+	case models.BudgetPeriodWeekly:
+		days := int(date.Sub(anchor).Hours() / 24)
+		start = anchor.AddDate(0, 0, days/7*7)
+		end = start.AddDate(0, 0, 6)
+	case models.BudgetPeriodQuarterly:
+		months = 3
+	case models.BudgetPeriodYearly:
+		months = 12
+	default:
+		months = 1
+	}
+	if months > 0 {
+		n := (monthIndex(date) - monthIndex(anchor)) / months
+		start = addMonthsClamped(anchor, n*months)
+		if start.After(date) {
+			n--
+			start = addMonthsClamped(anchor, n*months)
+		}
+		end = addMonthsClamped(anchor, (n+1)*months).AddDate(0, 0, -1)
+	}
+
+	if budget.EndDate != nil && end.After(truncateDay(*budget.EndDate)) {
+		end = truncateDay(*budget.EndDate)
+	}
+	return start, end, true
+}
+
+// warningThreshold is the highest of the thresholds below 100%, the point
+// from which a budget is at risk. It is zero when there is none.
+func warningThreshold(thresholds []int) int {
+	warning := 0
+	for _, threshold := range thresholds {
+		if threshold < 100 && threshold > warning {
+			warning = threshold
+		}
+	}
+	return warning
+}
+
+// EvaluateBudget works out how the budget stands with spent in the period
+// from periodStart to periodEnd, as of today. Spending is projected to the
+// end of the period at its average daily rate once a quarter of a period in
+// progress has passed; a budget on pace to be exceeded is at risk if it has
+// pace alerts on.
+func EvaluateBudget(budget models.Budget, spent float64, periodStart, periodEnd, today time.Time) models.BudgetEvaluation {
+	periodStart, periodEnd, today = truncateDay(periodStart), truncateDay(periodEnd), truncateDay(today)
+	budgetCents, spentCents := toCents(budget.Amount), toCents(spent)
+
+	evaluation := models.BudgetEvaluation{
+		BudgetID:    budget.ID,
+		BudgetName:  budget.Name,
+		CategoryID:  budget.CategoryID,
+		Period:      budget.Period,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Budgeted:    budget.Amount,
+		Spent:       float64(spentCents) / 100,
+		Remaining:   float64(budgetCents-spentCents) / 100,
+		Status:      models.BudgetStatusUnderBudget,
+	}
+	if budget.Category != nil {
+		evaluation.CategoryName = budget.Category.Name
+	}
+	if budgetCents > 0 {
+		evaluation.PercentageUsed = roundTo2(float64(spentCents) / float64(budgetCents) * 100)
+	}
+
+	for _, threshold := range budget.AlertThresholds {
+		if spentCents*100 < int64(threshold)*budgetCents {
+			continue
+		}
+		if evaluation.ThresholdReached == nil || threshold > *evaluation.ThresholdReached {
+			reached := threshold
+			evaluation.ThresholdReached = &reached
+		}
+	}
+
+	inProgress := !today.Before(periodStart) && today.Before(periodEnd)
+	if inProgress && spentCents > 0 && spentCents <= budgetCents {
+		elapsed := int(today.Sub(periodStart).Hours()/24) + 1
+		length := int(periodEnd.Sub(periodStart).Hours()/24) + 1
+		if float64(elapsed) >= budgetPaceMinElapsed*float64(length) {
+			daily := float64(spentCents) / float64(elapsed)
+			projected := roundTo2(daily * float64(length) / 100)
+			evaluation.ProjectedAmount = &projected
+			if int64(daily*float64(length)) > budgetCents {
+				// The first day on which the running total passes the budget
+				day := int(float64(budgetCents)/daily) + 1
+				exceeds := periodStart.AddDate(0, 0, day-1)
+				evaluation.ProjectedExceedDate = &exceeds
+			}
+		}
+	}
+
+	warning := warningThreshold(budget.AlertThresholds)
+	switch {
+	case spentCents > budgetCents:
+		evaluation.Status = models.BudgetStatusOverBudget
+	case warning > 0 && spentCents*100 >= int64(warning)*budgetCents:
+		evaluation.Status = models.BudgetStatusAtRisk
+	case budget.PaceAlerts && evaluation.ProjectedExceedDate != nil:
+		evaluation.Status = models.BudgetStatusAtRisk
+	}
+	return evaluation
+}
+
+// BudgetAlert is a budget_alert notification with the key that keeps it
+// from being raised twice
+type BudgetAlert struct {
+	Notification models.Notification
+	DedupeKey    string
+}
+
+// BudgetAlerts builds the alerts due for a budget evaluation: one for the
+// highest threshold reached and, while the period is in progress, one for
+// being on pace to exceed the budget. Their dedupe keys include the period,
+// so each is raised once per budget period.
+func BudgetAlerts(budget models.Budget, evaluation models.BudgetEvaluation) []BudgetAlert {
+	metadata := func(alert string) map[string]interface{} {
+		return map[string]interface{}{
+			"alert":           alert,
+			"budget_id":       evaluation.BudgetID,
+			"budget_name":     evaluation.BudgetName,
+			"category_id":     evaluation.CategoryID,
+			"category_name":   evaluation.CategoryName,
+			"budgeted_amount": evaluation.Budgeted,
+			"spent_amount":    evaluation.Spent,
+			"percentage_used": evaluation.PercentageUsed,
+			"period_start":    evaluation.PeriodStart.Format("2006-01-02"),
+			"period_end":      evaluation.PeriodEnd.Format("2006-01-02"),
+		}
+	}
+	period := evaluation.PeriodStart.Format("2006-01-02")
+
+	var alerts []BudgetAlert
+	if threshold := evaluation.ThresholdReached; threshold != nil {
+		notification := models.Notification{
+			UserID: budget.UserID,
+			Title:  "Budget Warning",
+			Message: fmt.Sprintf("You have used %.1f%% of your \"%s\" budget (%.2f of %.2f)",
+				evaluation.PercentageUsed, evaluation.BudgetName, evaluation.Spent, evaluation.Budgeted),
+			Type:     models.NotificationTypeBudgetAlert,
+			Metadata: metadata("threshold"),
+		}
+		if *threshold >= 100 {
+			notification.Title = "Budget Exceeded!"
+			notification.Message = fmt.Sprintf("You have exceeded your \"%s\" budget by %.2f (%.1f%% used)",
+				evaluation.BudgetName, -evaluation.Remaining, evaluation.PercentageUsed)
+			if evaluation.Remaining == 0 {
+				notification.Title = "Budget Reached"
+				notification.Message = fmt.Sprintf("You have used all of your \"%s\" budget of %.2f",
+					evaluation.BudgetName, evaluation.Budgeted)
+			}
+		}
+		notification.Metadata["threshold"] = *threshold
+		alerts = append(alerts, BudgetAlert{
+			Notification: notification,
+			DedupeKey:    fmt.Sprintf("budget:%s:%s:threshold:%d", budget.ID, period, *threshold),
+		})
+	}
+
+	if budget.PaceAlerts && evaluation.ProjectedExceedDate != nil {
+		exceeds := *evaluation.ProjectedExceedDate
+		day := int(exceeds.Sub(evaluation.PeriodStart).Hours()/24) + 1
+		notification := models.Notification{
+			UserID: budget.UserID,
+			Title:  "Budget Pace Warning",
+			Message: fmt.Sprintf("At your current pace you will exceed your \"%s\" budget by %s (day %d of the %s period)",
+				evaluation.BudgetName, exceeds.Format("Jan 2"), day, evaluation.Period),
+			Type:     models.NotificationTypeBudgetAlert,
+			Metadata: metadata("pace"),
+		}
+		notification.Metadata["projected_amount"] = *evaluation.ProjectedAmount
+		notification.Metadata["projected_exceed_date"] = exceeds.Format("2006-01-02")
+		alerts = append(alerts, BudgetAlert{
+			Notification: notification,
+			DedupeKey:    fmt.Sprintf("budget:%s:%s:pace", budget.ID, period),
+		})
+	}
+	return alerts
+}
+
+// BudgetAlertStore is the data access the budget alert service needs.
+type BudgetAlertStore interface {
+	repositories.BudgetAlertRepository
+	repositories.NotificationRepository
+	GetCategorySpending(ctx context.Context, userID string, startDate, endDate time.Time) (map[string]float64, error)
+}
+
+// BudgetAlertService evaluates budgets and raises budget_alert
+// notifications for them.
+type BudgetAlertService struct {
+	store BudgetAlertStore
+}
+
+// NewBudgetAlertService creates a budget alert service
+func NewBudgetAlertService(store BudgetAlertStore) *BudgetAlertService {
+	return &BudgetAlertService{store: store}
+}
+
+// Evaluate reports how each of the user's active budgets stands in its
+// period containing today, counting spending up to today.
+func (s *BudgetAlertService) Evaluate(ctx context.Context, userID string, today time.Time) ([]models.Budget, []models.BudgetEvaluation, error) {
+	budgets, err := s.store.GetBudgetsByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	today = truncateDay(today)
+	type window struct{ start, end time.Time }
+	spending := make(map[window]map[string]float64)
+	var current []models.Budget
+	var evaluations []models.BudgetEvaluation
+	for _, budget := range budgets {
+		start, end, ok := BudgetPeriodBounds(budget, today)
+		if !ok {
+			continue
+		}
+		// Budgets sharing a period share one spending query
+		w := window{start, end}
+		if _, ok := spending[w]; !ok {
+			spending[w], err = s.store.GetCategorySpending(ctx, userID, start, today)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		current = append(current, budget)
+		evaluations = append(evaluations, EvaluateBudget(budget, spending[w][budget.CategoryID], start, end, today))
+	}
+	return current, evaluations, nil
+}
+
+// Check evaluates the user's budgets and raises the alerts not raised
+// before in the current periods, returning how many were created.
+func (s *BudgetAlertService) Check(ctx context.Context, userID string, today time.Time) (int, error) {
+	budgets, evaluations, err := s.Evaluate(ctx, userID, today)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for i, budget := range budgets {
+		for _, alert := range BudgetAlerts(budget, evaluations[i]) {
+			ok, err := s.store.CreateNotification(ctx, &alert.Notification, alert.DedupeKey)
+			if err != nil {
+				return created, err
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+	return created, nil
+}
+
+// CheckAll checks the budgets of every user with an active budget. A failure
+// for one user does not stop the others; it returns how many notifications
+// were created.
+func (s *BudgetAlertService) CheckAll(ctx context.Context, today time.Time) (int, error) {
+	userIDs, err := s.store.GetUserIDsWithActiveBudgets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sort.Strings(userIDs)
+	created, failed := 0, 0
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return created, err
+		}
+		n, err := s.Check(ctx, userID, today)
+		created += n
+		if err != nil {
+			log.Printf("Warning: Failed to check budgets for user %s: %v", userID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return created, fmt.Errorf("%d of %d budget checks failed", failed, len(userIDs))
+	}
+	return created, nil
+}
+
+// RunAlerts checks every user's budgets right away and then every morning
+// UTC, when pace projections move on, and checks a user's budgets as soon as
+// listen reports a change to their data. It runs until the context is
+// cancelled.
+func (s *BudgetAlertService) RunAlerts(ctx context.Context, listen ChangeListener) {
+	checkAll := func(now time.Time) {
+		created, err := s.CheckAll(ctx, now)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Warning: Budget alert run incomplete: %v", err)
+		}
+		log.Printf("Raised %d budget alerts for %s", created, now.Format("2006-01-02"))
+	}
+
+	go followChanges(ctx, "Budget alert listener", listen,
+		func(userID string) {
+			if _, err := s.Check(ctx, userID, time.Now().UTC()); err != nil && ctx.Err() == nil {
+				log.Printf("Warning: Failed to check budgets for user %s: %v", userID, err)
+			}
+		},
+		func() { checkAll(time.Now().UTC()) })
+	runDaily(ctx, 6, 30, checkAll)
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testBudget(period models.BudgetPeriod, amount float64, start time.Time) models.Budget {
+	return models.Budget{
+		ID:              "b-1",
+		UserID:          "u-1",
+		CategoryID:      "groceries",
+		Name:            "Groceries",
+		Amount:          amount,
+		Period:          period,
+		StartDate:       start,
+		IsActive:        true,
+		AlertThresholds: []int{50, 80, 100},
+		PaceAlerts:      true,
+	}
+}
+
+func TestBudgetPeriodBounds(t *testing.T) {
+	cases := []struct {
+		name       string
+		period     models.BudgetPeriod
+		start      time.Time
+		date       time.Time
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantInside bool
+	}{
+		{"calendar month", models.BudgetPeriodMonthly, forecastDate(2025, 1, 1), forecastDate(2026, 3, 18), forecastDate(2026, 3, 1), forecastDate(2026, 3, 31), true},
+		{"mid-month anchor", models.BudgetPeriodMonthly, forecastDate(2025, 1, 15), forecastDate(2026, 3, 10), forecastDate(2026, 2, 15), forecastDate(2026, 3, 14), true},
+		{"anchor on the 31st", models.BudgetPeriodMonthly, forecastDate(2026, 1, 31), forecastDate(2026, 3, 1), forecastDate(2026, 2, 28), forecastDate(2026, 3, 30), true},
+		{"weekly", models.BudgetPeriodWeekly, forecastDate(2026, 1, 5), forecastDate(2026, 1, 21), forecastDate(2026, 1, 19), forecastDate(2026, 1, 25), true},
+		{"quarterly", models.BudgetPeriodQuarterly, forecastDate(2025, 1, 1), forecastDate(2026, 5, 31), forecastDate(2026, 4, 1), forecastDate(2026, 6, 30), true},
+		{"yearly", models.BudgetPeriodYearly, forecastDate(2024, 7, 1), forecastDate(2026, 3, 1), forecastDate(2025, 7, 1), forecastDate(2026, 6, 30), true},
+		{"not started", models.BudgetPeriodMonthly, forecastDate(2026, 4, 1), forecastDate(2026, 3, 31), time.Time{}, time.Time{}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, ok := BudgetPeriodBounds(testBudget(tc.period, 100, tc.start), tc.date)
+			assert.Equal(t, tc.wantInside, ok)
+			assert.Equal(t, tc.wantStart, start)
+			assert.Equal(t, tc.wantEnd, end)
+		})
+	}
+
+	// The last period ends with the budget
+	budget := testBudget(models.BudgetPeriodMonthly, 100, forecastDate(2026, 1, 1))
+	endDate := forecastDate(2026, 3, 20)
+	budget.EndDate = &endDate
+	_, end, ok := BudgetPeriodBounds(budget, forecastDate(2026, 3, 5))
+	assert.True(t, ok)
+	assert.Equal(t, endDate, end)
+	_, _, ok = BudgetPeriodBounds(budget, forecastDate(2026, 3, 21))
+	assert.False(t, ok)
+}
+
+func TestEvaluateBudget(t *testing.T) {
+	budget := testBudget(models.BudgetPeriodMonthly, 300, forecastDate(2026, 1, 1))
+	start, end := forecastDate(2026, 4, 1), forecastDate(2026, 4, 30)
+
+	// 150 in the first 10 days is on pace for 450, passing 300 on day 21
+	evaluation := EvaluateBudget(budget, 150, start, end, forecastDate(2026, 4, 10))
+	assert.Equal(t, 50.0, evaluation.PercentageUsed)
+	assert.Equal(t, 50, *evaluation.ThresholdReached)
+	assert.Equal(t, 450.0, *evaluation.ProjectedAmount)
+	assert.Equal(t, forecastDate(2026, 4, 21), *evaluation.ProjectedExceedDate)
+	assert.Equal(t, models.BudgetStatusAtRisk, evaluation.Status)
+
+	// Too early in the period to project
+	evaluation = EvaluateBudget(budget, 150, start, end, forecastDate(2026, 4, 3))
+	assert.Nil(t, evaluation.ProjectedAmount)
+	assert.Equal(t, models.BudgetStatusUnderBudget, evaluation.Status)
+
+	// On track
+	evaluation = EvaluateBudget(budget, 100, start, end, forecastDate(2026, 4, 15))
+	assert.Equal(t, 200.0, *evaluation.ProjectedAmount)
+	assert.Nil(t, evaluation.ProjectedExceedDate)
+	assert.Nil(t, evaluation.ThresholdReached)
+	assert.Equal(t, models.BudgetStatusUnderBudget, evaluation.Status)
+
+	// The at-risk point follows the budget's highest threshold below 100%
+	evaluation = EvaluateBudget(budget, 255, start, end, forecastDate(2026, 4, 30))
+	assert.Equal(t, models.BudgetStatusAtRisk, evaluation.Status)
+	budget.AlertThresholds = []int{90, 100}
+	evaluation = EvaluateBudget(budget, 255, start, end, forecastDate(2026, 4, 30))
+	assert.Equal(t, models.BudgetStatusUnderBudget, evaluation.Status)
+	assert.Nil(t, evaluation.ProjectedAmount)
+
+	evaluation = EvaluateBudget(budget, 312.5, start, end, forecastDate(2026, 4, 20))
+	assert.Equal(t, 100, *evaluation.ThresholdReached)
+	assert.Equal(t, -12.5, evaluation.Remaining)
+	assert.Equal(t, models.BudgetStatusOverBudget, evaluation.Status)
+}
+
+func TestBudgetAlerts(t *testing.T) {
+	budget := testBudget(models.BudgetPeriodMonthly, 300, forecastDate(2026, 1, 1))
+	start, end := forecastDate(2026, 4, 1), forecastDate(2026, 4, 30)
+
+	alerts := BudgetAlerts(budget, EvaluateBudget(budget, 250, start, end, forecastDate(2026, 4, 10)))
+	if assert.Len(t, alerts, 2) {
+		assert.Equal(t, "budget:b-1:2026-04-01:threshold:80", alerts[0].DedupeKey)
+		assert.Equal(t, "Budget Warning", alerts[0].Notification.Title)
+		assert.Equal(t, models.NotificationTypeBudgetAlert, alerts[0].Notification.Type)
+		assert.Equal(t, "budget:b-1:2026-04-01:pace", alerts[1].DedupeKey)
+		assert.Equal(t, "2026-04-13", alerts[1].Notification.Metadata["projected_exceed_date"])
+	}
+
+	budget.PaceAlerts = false
+	alerts = BudgetAlerts(budget, EvaluateBudget(budget, 320, start, end, forecastDate(2026, 4, 10)))
+	if assert.Len(t, alerts, 1) {
+		assert.Equal(t, "Budget Exceeded!", alerts[0].Notification.Title)
+		assert.Equal(t, 100, alerts[0].Notification.Metadata["threshold"])
+	}
+}
+
+type fakeBudgetAlertStore struct {
+	budgets       []models.Budget
+	spending      map[string]float64
+	notifications []models.Notification
+	keys          map[string]bool
+}
+
+func (f *fakeBudgetAlertStore) GetBudgetsByUserID(ctx context.Context, userID string) ([]models.Budget, error) {
+	return f.budgets, nil
+}
+
+func (f *fakeBudgetAlertStore) GetUserIDsWithActiveBudgets(ctx context.Context) ([]string, error) {
+	return []string{"u-1"}, nil
+}
+
+func (f *fakeBudgetAlertStore) GetCategorySpending(ctx context.Context, userID string, startDate, endDate time.Time) (map[string]float64, error) {
+	return f.spending, nil
+}
+
+func (f *fakeBudgetAlertStore) CreateNotification(ctx context.Context, notification *models.Notification, dedupeKey string) (bool, error) {
+	if f.keys[dedupeKey] {
+		return false, nil
+	}
+	f.keys[dedupeKey] = true
+	f.notifications = append(f.notifications, *notification)
+	return true, nil
+}
+
+func TestBudgetAlertService_CheckAllDeduplicates(t *testing.T) {
+	store := &fakeBudgetAlertStore{
+		budgets:  []models.Budget{testBudget(models.BudgetPeriodMonthly, 300, forecastDate(2026, 1, 1))},
+		spending: map[string]float64{"groceries": 160},
+		keys:     make(map[string]bool),
+	}
+	store.budgets[0].PaceAlerts = false
+	service := NewBudgetAlertService(store)
+
+	created, err := service.CheckAll(context.Background(), forecastDate(2026, 4, 20))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	created, err = service.CheckAll(context.Background(), forecastDate(2026, 4, 21))
+	assert.NoError(t, err)
+	assert.Zero(t, created)
+
+	// Crossing the next threshold alerts again, as does the next period
+	store.spending["groceries"] = 250
+	created, _ = service.CheckAll(context.Background(), forecastDate(2026, 4, 22))
+	assert.Equal(t, 1, created)
+	created, _ = service.CheckAll(context.Background(), forecastDate(2026, 5, 22))
+	assert.Equal(t, 1, created)
+	assert.Len(t, store.notifications, 3)
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// ClassifierVersion is stored with every persisted model and prediction.
+const ClassifierVersion = "go-nb-1.0.0"
+
+// Minimum spread of the per-category log-amount distribution, so a category
+// trained on a single amount does not reject everything else outright.
+const minAmountStdDev = 0.35
+
+// CategoryClassifier is a per-user multinomial naive Bayes model over the
+// words of a transaction description, with each word weighted by its inverse
+// document frequency, combined with a log-normal model of the amount for each
+// category. It serializes to JSON for storage in user_ml_models.model_data
+// and is trained incrementally by adding examples.
+type CategoryClassifier struct {
+	Version        string                    `json:"version"`
+	DocCount       int                       `json:"doc_count"`
+	DocFrequency   map[string]int            `json:"doc_frequency"`
+	Categories     map[string]*CategoryStats `json:"categories"`
+	TrainedThrough *time.Time                `json:"trained_through,omitempty"`
+}
+
+// CategoryStats are the sufficient statistics the classifier keeps per category.
+type CategoryStats struct {
+	DocCount       int            `json:"doc_count"`
+	TokenCount     int            `json:"token_count"`
+	Tokens         map[string]int `json:"tokens"`
+	AmountLogSum   float64        `json:"amount_log_sum"`
+	AmountLogSqSum float64        `json:"amount_log_sq_sum"`
+}
+
+// CategoryPrediction is the classifier's answer for one transaction.
+// Confidence values are percentages in the range 0-100.
+type CategoryPrediction struct {
+	CategoryID    string                `json:"category_id"`
+	Confidence    int                   `json:"confidence"`
+	Alternatives  []CategoryAlternative `json:"alternatives"`
+	MatchedTokens []string              `json:"matched_tokens"`
+}
+
+// CategoryAlternative is a runner-up category with its confidence.
+type CategoryAlternative struct {
+	CategoryID string `json:"category_id"`
+	Confidence int    `json:"confidence"`
+}
+
+// NewCategoryClassifier returns an untrained classifier.
+func NewCategoryClassifier() *CategoryClassifier {
+	return &CategoryClassifier{
+		Version:      ClassifierVersion,
+		DocFrequency: make(map[string]int),
+		Categories:   make(map[string]*CategoryStats),
+	}
+}
+
+// Train folds the examples into the model and advances TrainedThrough to the
+// newest example, so later incremental runs only pick up newer feedback.
+func (m *CategoryClassifier) Train(examples []models.TrainingExample) {
+	for _, ex := range examples {
+		if ex.CategoryID == "" {
+			continue
+		}
+
+		tokens := descriptionTokens(ex.Description)
+		stats, ok := m.Categories[ex.CategoryID]
+		if !ok {
+			stats = &CategoryStats{Tokens: make(map[string]int)}
+			m.Categories[ex.CategoryID] = stats
+		}
+
+		seen := make(map[string]bool, len(tokens))
+		for _, token := range tokens {
+			stats.Tokens[token]++
+			stats.TokenCount++
+			if !seen[token] {
+				seen[token] = true
+				m.DocFrequency[token]++
+			}
+		}
+
+		logAmount := math.Log1p(math.Abs(ex.Amount))
+		stats.AmountLogSum += logAmount
+		stats.AmountLogSqSum += logAmount * logAmount
+		stats.DocCount++
+		m.DocCount++
+
+		if !ex.CreatedAt.IsZero() && (m.TrainedThrough == nil || ex.CreatedAt.After(*m.TrainedThrough)) {
+			createdAt := ex.CreatedAt
+			m.TrainedThrough = &createdAt
+		}
+	}
+}
+
+// Predict scores every known category and returns the most likely one with up
+// to three alternatives. It returns nil when the model has not been trained.
+func (m *CategoryClassifier) Predict(description string, amount float64) *CategoryPrediction {
+	if m.DocCount == 0 || len(m.Categories) == 0 {
+		return nil
+	}
+
+	tokens := descriptionTokens(description)
+	vocabulary := len(m.DocFrequency)
+	logAmount := math.Log1p(math.Abs(amount))
+
+	type scored struct {
+		categoryID string
+		score      float64
+	}
+	scores := make([]scored, 0, len(m.Categories))
+
+	for categoryID, stats := range m.Categories {
+		score := math.Log(float64(stats.DocCount) / float64(m.DocCount))
+
+		for _, token := range tokens {
+			// Laplace-smoothed likelihood, weighted by how distinctive the word is
+			likelihood := float64(stats.Tokens[token]+1) / float64(stats.TokenCount+vocabulary+1)
+			score += m.idf(token) * math.Log(likelihood)
+		}
+
+		mean := stats.AmountLogSum / float64(stats.DocCount)
+		variance := stats.AmountLogSqSum/float64(stats.DocCount) - mean*mean
+		stdDev := math.Max(math.Sqrt(math.Max(variance, 0)), minAmountStdDev)
+		z := (logAmount - mean) / stdDev
+		score += -0.5*z*z - math.Log(stdDev)
+
+		scores = append(scores, scored{categoryID, score})
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score == scores[j].score {
+			return scores[i].categoryID < scores[j].categoryID
+		}
+		return scores[i].score > scores[j].score
+	})
+
+	// Softmax turns the log scores into probabilities
+	best := scores[0].score
+	total := 0.0
+	probabilities := make([]float64, len(scores))
+	for i, s := range scores {
+		probabilities[i] = math.Exp(s.score - best)
+		total += probabilities[i]
+	}
+
+	prediction := &CategoryPrediction{
+		CategoryID:    scores[0].categoryID,
+		Confidence:    percentage(probabilities[0] / total),
+		Alternatives:  []CategoryAlternative{},
+		MatchedTokens: []string{},
+	}
+	for i := 1; i < len(scores) && i <= 3; i++ {
+		prediction.Alternatives = append(prediction.Alternatives, CategoryAlternative{
+			CategoryID: scores[i].categoryID,
+			Confidence: percentage(probabilities[i] / total),
+		})
+	}
+
+	winner := m.Categories[prediction.CategoryID]
+	for _, token := range tokens {
+		if winner.Tokens[token] > 0 {
+			prediction.MatchedTokens = append(prediction.MatchedTokens, token)
+		}
+	}
+
+	return prediction
+}
+
+// Evaluate returns the share of examples, as a percentage, whose category the
+// model currently predicts correctly.
+func (m *CategoryClassifier) Evaluate(examples []models.TrainingExample) float64 {
+	if len(examples) == 0 {
+		return 0
+	}
+
+	correct := 0
+	for _, ex := range examples {
+		if prediction := m.Predict(ex.Description, ex.Amount); prediction != nil && prediction.CategoryID == ex.CategoryID {
+			correct++
+		}
+	}
+
+	return math.Round(float64(correct)/float64(len(examples))*10000) / 100
+}
+
+// validationEvery is how often an example is held back for validation: one
+// in every validationEvery examples
+const validationEvery = 5
+
+// SplitValidation holds back every fifth example for validation, so accuracy
+// can be measured on examples the model was not trained on. Fewer than
+// validationEvery examples are all used for training.
+func SplitValidation(examples []models.TrainingExample) (training, validation []models.TrainingExample) {
+	for i, ex := range examples {
+		if i%validationEvery == validationEvery-1 {
+			validation = append(validation, ex)
+		} else {
+			training = append(training, ex)
+		}
+	}
+	return training, validation
+}
+
+// PatternCount is the number of distinct words the model has learned.
+func (m *CategoryClassifier) PatternCount() int {
+	return len(m.DocFrequency)
+}
+
+func (m *CategoryClassifier) idf(token string) float64 {
+	return math.Log(float64(m.DocCount+1)/float64(m.DocFrequency[token]+1)) + 1
+}
+
+func percentage(p float64) int {
+	return int(math.Round(p * 100))
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func classifierTrainingSet() []models.TrainingExample {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []models.TrainingExample{
+		{Description: "STARBUCKS STORE 1234", Amount: 5.75, CategoryID: "coffee", CreatedAt: base},
+		{Description: "Starbucks Coffee", Amount: 4.20, CategoryID: "coffee", CreatedAt: base.Add(time.Hour)},
+		{Description: "Blue Bottle Coffee", Amount: 6.50, CategoryID: "coffee", CreatedAt: base.Add(2 * time.Hour)},
+		{Description: "SHELL OIL 5521", Amount: 48.00, CategoryID: "fuel", CreatedAt: base.Add(3 * time.Hour)},
+		{Description: "Chevron Gas Station", Amount: 52.10, CategoryID: "fuel", CreatedAt: base.Add(4 * time.Hour)},
+		{Description: "Safeway Grocery Store", Amount: 86.30, CategoryID: "groceries", CreatedAt: base.Add(5 * time.Hour)},
+		{Description: "Whole Foods Market", Amount: 112.45, CategoryID: "groceries", CreatedAt: base.Add(6 * time.Hour)},
+	}
+}
+
+func TestCategoryClassifier_Untrained(t *testing.T) {
+	assert.Nil(t, NewCategoryClassifier().Predict("anything", 10))
+}
+
+func TestCategoryClassifier_PredictsFromDescription(t *testing.T) {
+	model := NewCategoryClassifier()
+	model.Train(classifierTrainingSet())
+
+	prediction := model.Predict("STARBUCKS #9981", 5.10)
+	assert.NotNil(t, prediction)
+	assert.Equal(t, "coffee", prediction.CategoryID)
+	assert.Greater(t, prediction.Confidence, 50)
+	assert.Contains(t, prediction.MatchedTokens, "starbucks")
+	assert.Len(t, prediction.Alternatives, 2)
+
+	total := prediction.Confidence
+	for _, alt := range prediction.Alternatives {
+		assert.LessOrEqual(t, alt.Confidence, prediction.Confidence)
+		total += alt.Confidence
+	}
+	assert.InDelta(t, 100, total, 2)
+
+	assert.Equal(t, "fuel", model.Predict("Shell Service Station", 45).CategoryID)
+}
+
+func TestCategoryClassifier_AmountBreaksTies(t *testing.T) {
+	model := NewCategoryClassifier()
+	model.Train(classifierTrainingSet())
+
+	// "store" appears in both coffee and groceries; the amount decides
+	assert.Equal(t, "groceries", model.Predict("Store", 95).CategoryID)
+	assert.Equal(t, "coffee", model.Predict("Store", 5).CategoryID)
+}
+
+func TestCategoryClassifier_IncrementalTraining(t *testing.T) {
+	model := NewCategoryClassifier()
+	model.Train(classifierTrainingSet())
+	assert.Equal(t, time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC), *model.TrainedThrough)
+
+	feedback := []models.TrainingExample{
+		{Description: "Netflix Subscription", Amount: 15.49, CategoryID: "streaming", CreatedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Description: "NETFLIX.COM", Amount: 15.49, CategoryID: "streaming", CreatedAt: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	assert.Equal(t, 0.0, model.Evaluate(feedback))
+
+	model.Train(feedback)
+
+	assert.Equal(t, 100.0, model.Evaluate(feedback))
+	assert.Equal(t, "streaming", model.Predict("Netflix", 15.49).CategoryID)
+	assert.Equal(t, time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC), *model.TrainedThrough)
+}
+
+func TestSplitValidation(t *testing.T) {
+	examples := make([]models.TrainingExample, 12)
+	for i := range examples {
+		examples[i].Amount = float64(i)
+	}
+
+	training, validation := SplitValidation(examples)
+
+	assert.Len(t, training, 10)
+	assert.Equal(t, []models.TrainingExample{{Amount: 4}, {Amount: 9}}, validation)
+
+	training, validation = SplitValidation(examples[:4])
+	assert.Len(t, training, 4)
+	assert.Empty(t, validation)
+}
+
+func TestCategoryClassifier_RoundTripsThroughJSON(t *testing.T) {
+	model := NewCategoryClassifier()
+	model.Train(classifierTrainingSet())
+
+	data, err := json.Marshal(model)
+	assert.NoError(t, err)
+
+	restored := NewCategoryClassifier()
+	assert.NoError(t, json.Unmarshal(data, restored))
+
+	assert.Equal(t, model.PatternCount(), restored.PatternCount())
+	assert.Equal(t, model.Predict("Chevron", 50), restored.Predict("Chevron", 50))
+}
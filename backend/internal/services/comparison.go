@@ -0,0 +1,176 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// Comparison presets accepted by ResolveComparisonPeriods
+const (
+	ComparisonPresetMonth      = "month"       // Month vs the month before
+	ComparisonPresetMonthYoY   = "month_yoy"   // Month vs the same month last year
+	ComparisonPresetQuarter    = "quarter"     // Quarter vs the quarter before
+	ComparisonPresetQuarterYoY = "quarter_yoy" // Quarter vs the same quarter last year
+	ComparisonPresetYTD        = "ytd"         // Year to date vs the same days last year
+	ComparisonPresetYear       = "year"        // Calendar year vs the year before
+)
+
+// DefaultComparisonMovers is how many top movers a comparison lists
+const DefaultComparisonMovers = 5
+
+// ResolveComparisonPeriods returns the current and previous periods of a
+// preset for the period containing anchor. Year-to-date compares January 1
+// to the anchor with the same days a year earlier.
+func ResolveComparisonPeriods(preset string, anchor time.Time) (models.ReportPeriod, models.ReportPeriod, error) {
+	anchor = truncateDay(anchor)
+	year, month := anchor.Year(), anchor.Month()
+	monthStart := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	quarterStart := time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	monthPeriod := func(start time.Time) models.ReportPeriod {
+		return models.ReportPeriod{Label: start.Format("January 2006"), StartDate: start, EndDate: start.AddDate(0, 1, -1)}
+	}
+	quarterPeriod := func(start time.Time) models.ReportPeriod {
+		label := fmt.Sprintf("Q%d %d", (int(start.Month())-1)/3+1, start.Year())
+		return models.ReportPeriod{Label: label, StartDate: start, EndDate: start.AddDate(0, 3, -1)}
+	}
+	yearPeriod := func(start time.Time) models.ReportPeriod {
+		return models.ReportPeriod{Label: start.Format("2006"), StartDate: start, EndDate: start.AddDate(1, 0, -1)}
+	}
+
+	switch preset {
+	case ComparisonPresetMonth:
+		return monthPeriod(monthStart), monthPeriod(monthStart.AddDate(0, -1, 0)), nil
+	case ComparisonPresetMonthYoY:
+		return monthPeriod(monthStart), monthPeriod(monthStart.AddDate(-1, 0, 0)), nil
+	case ComparisonPresetQuarter:
+		return quarterPeriod(quarterStart), quarterPeriod(quarterStart.AddDate(0, -3, 0)), nil
+	case ComparisonPresetQuarterYoY:
+		return quarterPeriod(quarterStart), quarterPeriod(quarterStart.AddDate(-1, 0, 0)), nil
+	case ComparisonPresetYTD:
+		current := models.ReportPeriod{Label: fmt.Sprintf("%d YTD", year), StartDate: yearStart, EndDate: anchor}
+		previous := models.ReportPeriod{
+			Label:     fmt.Sprintf("%d YTD", year-1),
+			StartDate: yearStart.AddDate(-1, 0, 0),
+			EndDate:   addMonthsClamped(anchor, -12),
+		}
+		return current, previous, nil
+	case ComparisonPresetYear:
+		return yearPeriod(yearStart), yearPeriod(yearStart.AddDate(-1, 0, 0)), nil
+	}
+
+	return models.ReportPeriod{}, models.ReportPeriod{}, fmt.Errorf("unknown comparison preset %q", preset)
+}
+
+// CompareValues compares a figure across two periods
+func CompareValues(current, previous float64) models.ComparisonValue {
+	value := models.ComparisonValue{
+		Current:  roundTo2(current),
+		Previous: roundTo2(previous),
+		Change:   float64(toCents(current)-toCents(previous)) / 100,
+	}
+	if previous != 0 {
+		percent := roundTo2(value.Change / math.Abs(previous) * 100)
+		value.ChangePercent = &percent
+	}
+	return value
+}
+
+// ComparePeriods builds a comparison of the category totals of two periods.
+// Categories appearing in either period are listed, largest current amount
+// first; the top movers are the expense categories whose spending rose or
+// fell the most, up to movers of each.
+func ComparePeriods(current, previous models.ReportPeriod, currentTotals, previousTotals []models.CategoryTotal, movers int) *models.PeriodComparison {
+	type key struct {
+		categoryID      string
+		transactionType models.TransactionType
+	}
+
+	byKey := make(map[key]*models.CategoryComparison)
+	var order []key
+	entry := func(total models.CategoryTotal) *models.CategoryComparison {
+		k := key{total.CategoryID, total.TransactionType}
+		comparison, ok := byKey[k]
+		if !ok {
+			comparison = &models.CategoryComparison{
+				CategoryID:      total.CategoryID,
+				CategoryName:    total.CategoryName,
+				TransactionType: total.TransactionType,
+			}
+			byKey[k] = comparison
+			order = append(order, k)
+		}
+		return comparison
+	}
+
+	totals := make(map[models.TransactionType][2]float64)
+	for _, total := range currentTotals {
+		comparison := entry(total)
+		comparison.Current += total.Amount
+		comparison.CurrentCount += total.Count
+		sums := totals[total.TransactionType]
+		sums[0] += total.Amount
+		totals[total.TransactionType] = sums
+	}
+	for _, total := range previousTotals {
+		comparison := entry(total)
+		comparison.Previous += total.Amount
+		comparison.PreviousCount += total.Count
+		sums := totals[total.TransactionType]
+		sums[1] += total.Amount
+		totals[total.TransactionType] = sums
+	}
+
+	income := totals[models.TransactionTypeIncome]
+	expenses := totals[models.TransactionTypeExpense]
+	report := &models.PeriodComparison{
+		Current:      current,
+		Previous:     previous,
+		Income:       CompareValues(income[0], income[1]),
+		Expenses:     CompareValues(expenses[0], expenses[1]),
+		Net:          CompareValues(income[0]-expenses[0], income[1]-expenses[1]),
+		Categories:   make([]models.CategoryComparison, 0, len(order)),
+		TopIncreases: []models.CategoryComparison{},
+		TopDecreases: []models.CategoryComparison{},
+		GeneratedAt:  time.Now(),
+	}
+
+	for _, k := range order {
+		comparison := byKey[k]
+		comparison.ComparisonValue = CompareValues(comparison.Current, comparison.Previous)
+		report.Categories = append(report.Categories, *comparison)
+	}
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		a, b := report.Categories[i], report.Categories[j]
+		if a.TransactionType != b.TransactionType {
+			return a.TransactionType == models.TransactionTypeIncome
+		}
+		if a.Current != b.Current {
+			return a.Current > b.Current
+		}
+		return a.Previous > b.Previous
+	})
+
+	var changed []models.CategoryComparison
+	for _, comparison := range report.Categories {
+		if comparison.TransactionType == models.TransactionTypeExpense && comparison.Change != 0 {
+			changed = append(changed, comparison)
+		}
+	}
+	sort.SliceStable(changed, func(i, j int) bool {
+		return changed[i].Change > changed[j].Change
+	})
+	for i := 0; i < len(changed) && len(report.TopIncreases) < movers && changed[i].Change > 0; i++ {
+		report.TopIncreases = append(report.TopIncreases, changed[i])
+	}
+	for i := len(changed) - 1; i >= 0 && len(report.TopDecreases) < movers && changed[i].Change < 0; i-- {
+		report.TopDecreases = append(report.TopDecreases, changed[i])
+	}
+
+	return report
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveComparisonPeriods(t *testing.T) {
+	anchor := forecastDate(2024, 2, 29)
+
+	current, previous, err := ResolveComparisonPeriods(ComparisonPresetMonth, anchor)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReportPeriod{Label: "February 2024", StartDate: forecastDate(2024, 2, 1), EndDate: forecastDate(2024, 2, 29)}, current)
+	assert.Equal(t, models.ReportPeriod{Label: "January 2024", StartDate: forecastDate(2024, 1, 1), EndDate: forecastDate(2024, 1, 31)}, previous)
+
+	_, previous, err = ResolveComparisonPeriods(ComparisonPresetMonthYoY, anchor)
+	assert.NoError(t, err)
+	assert.Equal(t, forecastDate(2023, 2, 28), previous.EndDate)
+
+	current, previous, err = ResolveComparisonPeriods(ComparisonPresetQuarter, forecastDate(2026, 1, 15))
+	assert.NoError(t, err)
+	assert.Equal(t, "Q1 2026", current.Label)
+	assert.Equal(t, forecastDate(2026, 3, 31), current.EndDate)
+	assert.Equal(t, models.ReportPeriod{Label: "Q4 2025", StartDate: forecastDate(2025, 10, 1), EndDate: forecastDate(2025, 12, 31)}, previous)
+
+	_, previous, err = ResolveComparisonPeriods(ComparisonPresetQuarterYoY, forecastDate(2026, 8, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReportPeriod{Label: "Q3 2025", StartDate: forecastDate(2025, 7, 1), EndDate: forecastDate(2025, 9, 30)}, previous)
+
+	current, previous, err = ResolveComparisonPeriods(ComparisonPresetYTD, anchor)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReportPeriod{Label: "2024 YTD", StartDate: forecastDate(2024, 1, 1), EndDate: anchor}, current)
+	assert.Equal(t, models.ReportPeriod{Label: "2023 YTD", StartDate: forecastDate(2023, 1, 1), EndDate: forecastDate(2023, 2, 28)}, previous)
+
+	_, _, err = ResolveComparisonPeriods("fortnight", anchor)
+	assert.Error(t, err)
+}
+
+func TestCompareValues(t *testing.T) {
+	value := CompareValues(150.10, 100)
+	assert.Equal(t, 50.1, value.Change)
+	assert.Equal(t, 50.1, *value.ChangePercent)
+
+	value = CompareValues(-50, -100)
+	assert.Equal(t, 50.0, value.Change)
+	assert.Equal(t, 50.0, *value.ChangePercent)
+
+	assert.Nil(t, CompareValues(20, 0).ChangePercent)
+}
+
+func TestComparePeriods(t *testing.T) {
+	current := []models.CategoryTotal{
+		{CategoryID: "salary", CategoryName: "Salary", TransactionType: models.TransactionTypeIncome, Amount: 5000, Count: 2},
+		{CategoryID: "dining", CategoryName: "Dining", TransactionType: models.TransactionTypeExpense, Amount: 400, Count: 10},
+		{CategoryID: "travel", CategoryName: "Travel", TransactionType: models.TransactionTypeExpense, Amount: 900, Count: 3},
+		{CategoryID: "", CategoryName: "Uncategorized", TransactionType: models.TransactionTypeExpense, Amount: 50, Count: 1},
+	}
+	previous := []models.CategoryTotal{
+		{CategoryID: "salary", CategoryName: "Salary", TransactionType: models.TransactionTypeIncome, Amount: 4800, Count: 2},
+		{CategoryID: "dining", CategoryName: "Dining", TransactionType: models.TransactionTypeExpense, Amount: 250, Count: 6},
+		{CategoryID: "rent", CategoryName: "Rent", TransactionType: models.TransactionTypeExpense, Amount: 1500, Count: 1},
+		{CategoryID: "", CategoryName: "Uncategorized", TransactionType: models.TransactionTypeExpense, Amount: 50, Count: 2},
+	}
+
+	report := ComparePeriods(models.ReportPeriod{Label: "now"}, models.ReportPeriod{Label: "before"}, current, previous, 2)
+
+	assert.Equal(t, 200.0, report.Income.Change)
+	assert.Equal(t, models.ComparisonValue{Current: 1350, Previous: 1800, Change: -450, ChangePercent: floatPtr(-25)}, report.Expenses)
+	assert.Equal(t, 3650.0, report.Net.Current)
+	assert.Equal(t, 650.0, report.Net.Change)
+
+	names := make([]string, len(report.Categories))
+	for i, category := range report.Categories {
+		names[i] = category.CategoryName
+	}
+	assert.Equal(t, []string{"Salary", "Travel", "Dining", "Uncategorized", "Rent"}, names)
+
+	travel := report.Categories[1]
+	assert.Nil(t, travel.ChangePercent)
+	assert.Equal(t, 3, travel.CurrentCount)
+	assert.Zero(t, travel.PreviousCount)
+
+	if assert.Len(t, report.TopIncreases, 2) {
+		assert.Equal(t, "Travel", report.TopIncreases[0].CategoryName)
+		assert.Equal(t, "Dining", report.TopIncreases[1].CategoryName)
+		assert.Equal(t, 60.0, *report.TopIncreases[1].ChangePercent)
+	}
+	if assert.Len(t, report.TopDecreases, 1) {
+		assert.Equal(t, "Rent", report.TopDecreases[0].CategoryName)
+		assert.Equal(t, -100.0, *report.TopDecreases[0].ChangePercent)
+	}
+}
@@ -0,0 +1,6 @@
+package services
+
+// ConvertAmount applies an exchange rate to an amount, rounding to cents.
+func ConvertAmount(amount, rate float64) float64 {
+	return float64(toCents(amount*rate)) / 100
+}
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertAmount(t *testing.T) {
+	assert.Equal(t, 100.0, ConvertAmount(100.0, 1))
+	assert.Equal(t, 92.35, ConvertAmount(100.0, 0.92345))
+	assert.Equal(t, 0.01, ConvertAmount(0.01, 0.999))
+}
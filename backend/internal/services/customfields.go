@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// MaxTagLength caps a single tag so free-form tags stay usable as labels.
+const MaxTagLength = 50
+
+// ValidateCustomFieldDefinition checks a definition before it is saved.
+// Enum fields need at least one option; other types must not have any.
+func ValidateCustomFieldDefinition(def *models.CustomFieldDefinition) error {
+	def.Name = strings.TrimSpace(def.Name)
+	if def.Name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+
+	switch def.FieldType {
+	case models.CustomFieldTypeEnum:
+		options := MergeTags(nil, def.Options)
+		if len(options) == 0 {
+			return fmt.Errorf("enum fields need at least one option")
+		}
+		def.Options = options
+	case models.CustomFieldTypeText, models.CustomFieldTypeNumber, models.CustomFieldTypeDate:
+		if len(def.Options) > 0 {
+			return fmt.Errorf("only enum fields can have options")
+		}
+		def.Options = []string{}
+	default:
+		return fmt.Errorf("field_type must be text, number, date or enum")
+	}
+
+	return nil
+}
+
+// ApplyCustomFields validates the submitted values against the user's
+// definitions and merges them into the existing ones. Values are keyed by
+// definition ID; a null value removes the field. Numbers are stored as
+// numbers, dates as YYYY-MM-DD and enum values as the option's spelling.
+func ApplyCustomFields(defs []models.CustomFieldDefinition, existing, submitted map[string]interface{}) (map[string]interface{}, error) {
+	byID := make(map[string]models.CustomFieldDefinition, len(defs))
+	for _, def := range defs {
+		byID[def.ID] = def
+	}
+
+	result := make(map[string]interface{}, len(existing)+len(submitted))
+	for id, value := range existing {
+		result[id] = value
+	}
+
+	for id, value := range submitted {
+		def, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown custom field %q", id)
+		}
+		if value == nil {
+			delete(result, id)
+			continue
+		}
+
+		normalized, err := normalizeCustomFieldValue(def, value)
+		if err != nil {
+			return nil, fmt.Errorf("custom field %q: %w", def.Name, err)
+		}
+		result[id] = normalized
+	}
+
+	return result, nil
+}
+
+func normalizeCustomFieldValue(def models.CustomFieldDefinition, value interface{}) (interface{}, error) {
+	switch def.FieldType {
+	case models.CustomFieldTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("must be a number")
+			}
+			return parsed, nil
+		}
+		return nil, fmt.Errorf("must be a number")
+
+	case models.CustomFieldTypeDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a date in YYYY-MM-DD format")
+		}
+		parsed, err := time.Parse("2006-01-02", strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("must be a date in YYYY-MM-DD format")
+		}
+		return parsed.Format("2006-01-02"), nil
+
+	case models.CustomFieldTypeEnum:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be one of: %s", strings.Join(def.Options, ", "))
+		}
+		for _, option := range def.Options {
+			if strings.EqualFold(option, strings.TrimSpace(text)) {
+				return option, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of: %s", strings.Join(def.Options, ", "))
+
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be text")
+		}
+		return text, nil
+	}
+}
+
+// NormalizeTags trims and deduplicates user-entered tags, rejecting tags
+// longer than MaxTagLength.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := MergeTags(nil, tags)
+	for _, tag := range normalized {
+		if len([]rune(tag)) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+		}
+	}
+	if normalized == nil {
+		normalized = []string{}
+	}
+	return normalized, nil
+}
+
+// MatchesTagFilter reports whether a transaction's tags satisfy the filter.
+// An empty filter matches everything.
+func MatchesTagFilter(tags []string, filter models.TagFilter) bool {
+	if len(filter.Tags) == 0 {
+		return true
+	}
+
+	have := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		have[strings.ToLower(tag)] = true
+	}
+
+	for _, wanted := range filter.Tags {
+		found := have[strings.ToLower(wanted)]
+		if found && !filter.MatchAll {
+			return true
+		}
+		if !found && filter.MatchAll {
+			return false
+		}
+	}
+	return filter.MatchAll
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func customFieldDefs() []models.CustomFieldDefinition {
+	return []models.CustomFieldDefinition{
+		{ID: "f-project", Name: "Project", FieldType: models.CustomFieldTypeText},
+		{ID: "f-miles", Name: "Miles", FieldType: models.CustomFieldTypeNumber},
+		{ID: "f-due", Name: "Due", FieldType: models.CustomFieldTypeDate},
+		{ID: "f-status", Name: "Status", FieldType: models.CustomFieldTypeEnum, Options: []string{"Pending", "Reimbursed"}},
+	}
+}
+
+func TestValidateCustomFieldDefinition(t *testing.T) {
+	enum := &models.CustomFieldDefinition{Name: " Status ", FieldType: models.CustomFieldTypeEnum, Options: []string{"a", "A", " b "}}
+	assert.NoError(t, ValidateCustomFieldDefinition(enum))
+	assert.Equal(t, "Status", enum.Name)
+	assert.Equal(t, []string{"a", "b"}, enum.Options)
+
+	assert.Error(t, ValidateCustomFieldDefinition(&models.CustomFieldDefinition{Name: "x", FieldType: models.CustomFieldTypeEnum}))
+	assert.Error(t, ValidateCustomFieldDefinition(&models.CustomFieldDefinition{Name: "x", FieldType: models.CustomFieldTypeText, Options: []string{"a"}}))
+	assert.Error(t, ValidateCustomFieldDefinition(&models.CustomFieldDefinition{Name: "x", FieldType: "color"}))
+	assert.Error(t, ValidateCustomFieldDefinition(&models.CustomFieldDefinition{Name: " ", FieldType: models.CustomFieldTypeText}))
+}
+
+func TestApplyCustomFields(t *testing.T) {
+	existing := map[string]interface{}{"f-project": "Kitchen", "f-miles": 12.0}
+	submitted := map[string]interface{}{
+		"f-miles":   "42.5",
+		"f-due":     "2024-03-09",
+		"f-status":  "reimbursed",
+		"f-project": nil,
+	}
+
+	result, err := ApplyCustomFields(customFieldDefs(), existing, submitted)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"f-miles":  42.5,
+		"f-due":    "2024-03-09",
+		"f-status": "Reimbursed",
+	}, result)
+
+	// The existing values are left untouched
+	assert.Equal(t, "Kitchen", existing["f-project"])
+}
+
+func TestApplyCustomFields_Invalid(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"f-unknown": "x"},
+		{"f-miles": "many"},
+		{"f-miles": true},
+		{"f-due": "09/03/2024"},
+		{"f-status": "Rejected"},
+		{"f-project": 5.0},
+	}
+
+	for _, submitted := range cases {
+		_, err := ApplyCustomFields(customFieldDefs(), nil, submitted)
+		assert.Error(t, err, "%v", submitted)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Travel ", "travel", "work", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Travel", "work"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, tags)
+
+	_, err = NormalizeTags([]string{strings.Repeat("x", MaxTagLength+1)})
+	assert.Error(t, err)
+}
+
+func TestMatchesTagFilter(t *testing.T) {
+	tags := []string{"Travel", "work"}
+
+	assert.True(t, MatchesTagFilter(tags, models.TagFilter{}))
+	assert.True(t, MatchesTagFilter(tags, models.TagFilter{Tags: []string{"travel", "food"}}))
+	assert.False(t, MatchesTagFilter(tags, models.TagFilter{Tags: []string{"travel", "food"}, MatchAll: true}))
+	assert.True(t, MatchesTagFilter(tags, models.TagFilter{Tags: []string{"WORK", "travel"}, MatchAll: true}))
+	assert.False(t, MatchesTagFilter(nil, models.TagFilter{Tags: []string{"work"}}))
+}
@@ -0,0 +1,461 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+const (
+	// digestSendHour is the local hour digests go out, the morning after
+	// their period ends
+	digestSendHour = 8
+	// digestMaxAttempts is how many times a digest is tried, at most once
+	// per digestRetryAfter
+	digestMaxAttempts = 5
+	digestRetryAfter  = time.Hour
+	digestPollEvery   = 15 * time.Minute
+	// digestNotificationLimit caps the held notifications listed in one
+	// digest; the rest wait for the next
+	digestNotificationLimit = 50
+	digestTopCategories     = 5
+)
+
+//go:embed templates/digest.txt templates/digest.html
+var digestTemplateFiles embed.FS
+
+var digestTemplateFuncs = map[string]interface{}{
+	"amount":  formatAmount,
+	"percent": func(value float64) string { return strconv.FormatFloat(math.Round(value), 'f', 0, 64) + "%" },
+	"date":    func(t time.Time) string { return t.Format("Jan 2") },
+	"change":  formatChange,
+}
+
+var (
+	digestTextTemplate = texttemplate.Must(texttemplate.New("digest.txt").Funcs(digestTemplateFuncs).ParseFS(digestTemplateFiles, "templates/digest.txt"))
+	digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(digestTemplateFuncs).ParseFS(digestTemplateFiles, "templates/digest.html"))
+)
+
+// formatAmount formats an amount with two decimals and thousands separators
+func formatAmount(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := strconv.FormatFloat(amount, 'f', 2, 64)
+	whole, cents := digits[:len(digits)-3], digits[len(digits)-3:]
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + cents
+}
+
+// formatChange describes how a figure moved from the previous period
+func formatChange(value models.ComparisonValue) string {
+	switch {
+	case value.Change == 0:
+		return "no change"
+	case value.ChangePercent == nil:
+		return "new"
+	case *value.ChangePercent > 0:
+		return "up " + strconv.FormatFloat(*value.ChangePercent, 'f', 1, 64) + "%"
+	default:
+		return "down " + strconv.FormatFloat(-*value.ChangePercent, 'f', 1, 64) + "%"
+	}
+}
+
+// dateOf returns t's calendar date in its location as a UTC midnight, the
+// form dates are stored and compared in
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// digestPeriodLabel labels a digest period, "March 2026" or "Mar 2 - Mar 8, 2026"
+func digestPeriodLabel(frequency models.DigestFrequency, start, end time.Time) string {
+	if frequency == models.DigestFrequencyMonthly {
+		return start.Format("January 2006")
+	}
+	return start.Format("Jan 2") + " - " + end.Format("Jan 2, 2006")
+}
+
+// DigestPeriod returns the latest digest period due by now and when it
+// became due. Weekly periods run Monday to Sunday and monthly ones over the
+// calendar month, in loc; each is due at digestSendHour on the day after it
+// ends. ok is false when digests are off.
+func DigestPeriod(frequency models.DigestFrequency, now time.Time, loc *time.Location) (period models.ReportPeriod, dueAt time.Time, ok bool) {
+	local := now.In(loc)
+	var next, start time.Time // next is the day after the period
+	switch frequency {
+	case models.DigestFrequencyWeekly:
+		next = time.Date(local.Year(), local.Month(), local.Day()-(int(local.Weekday())+6)%7, 0, 0, 0, 0, loc)
+		if local.Before(time.Date(next.Year(), next.Month(), next.Day(), digestSendHour, 0, 0, 0, loc)) {
+			next = next.AddDate(0, 0, -7)
+		}
+		start = next.AddDate(0, 0, -7)
+	case models.DigestFrequencyMonthly:
+		next = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		if local.Before(time.Date(next.Year(), next.Month(), 1, digestSendHour, 0, 0, 0, loc)) {
+			next = next.AddDate(0, -1, 0)
+		}
+		start = next.AddDate(0, -1, 0)
+	default:
+		return models.ReportPeriod{}, time.Time{}, false
+	}
+
+	end := next.AddDate(0, 0, -1)
+	period = models.ReportPeriod{
+		Label:     digestPeriodLabel(frequency, start, end),
+		StartDate: dateOf(start),
+		EndDate:   dateOf(end),
+	}
+	return period, time.Date(next.Year(), next.Month(), next.Day(), digestSendHour, 0, 0, 0, loc), true
+}
+
+// previousDigestPeriod returns the period before a digest period
+func previousDigestPeriod(frequency models.DigestFrequency, period models.ReportPeriod) models.ReportPeriod {
+	var start time.Time
+	if frequency == models.DigestFrequencyMonthly {
+		start = period.StartDate.AddDate(0, -1, 0)
+	} else {
+		start = period.StartDate.AddDate(0, 0, -7)
+	}
+	end := period.StartDate.AddDate(0, 0, -1)
+	return models.ReportPeriod{
+		Label:     digestPeriodLabel(frequency, start, end),
+		StartDate: start,
+		EndDate:   end,
+	}
+}
+
+// digestView is the data the digest templates render
+type digestView struct {
+	Digest        *models.NotificationDigest
+	Title         string
+	PreviousLabel string // "previous week" or "previous month"
+	NextLabel     string // "next week" or "next month"
+	TopCategories []models.CategoryComparison
+}
+
+// RenderDigestMail renders the digest as an email to the given address
+func RenderDigestMail(digest *models.NotificationDigest, to string) (Mail, error) {
+	unit := "week"
+	if digest.Frequency == models.DigestFrequencyMonthly {
+		unit = "month"
+	}
+	view := digestView{
+		Digest:        digest,
+		Title:         fmt.Sprintf("Your %s summary", digest.Frequency),
+		PreviousLabel: "previous " + unit,
+		NextLabel:     "next " + unit,
+	}
+	for _, category := range digest.Spending.Categories {
+		if category.TransactionType == models.TransactionTypeExpense && category.Current > 0 && len(view.TopCategories) < digestTopCategories {
+			view.TopCategories = append(view.TopCategories, category)
+		}
+	}
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, view); err != nil {
+		return Mail{}, fmt.Errorf("failed to render digest text: %w", err)
+	}
+	if err := digestHTMLTemplate.Execute(&html, view); err != nil {
+		return Mail{}, fmt.Errorf("failed to render digest HTML: %w", err)
+	}
+	return Mail{
+		To:      to,
+		Subject: view.Title + ": " + digest.Period.Label,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// DigestStore is the data access the digest service needs
+type DigestStore interface {
+	BudgetAlertStore
+	repositories.NotificationDigestRepository
+	GetCategoryTotals(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.CategoryTotal, error)
+	GetCategoriesByUserID(ctx context.Context, userID string) ([]models.Category, error)
+	GetGoalsByUserID(ctx context.Context, userID string) ([]models.Goal, error)
+	GetScheduledTransactionsByUserID(ctx context.Context, userID string, activeOnly bool) ([]models.ScheduledTransaction, error)
+}
+
+// DigestService composes weekly and monthly digests and emails them to the
+// users who asked for them
+type DigestService struct {
+	store   DigestStore
+	budgets *BudgetAlertService
+	mailer  MailSender
+}
+
+// NewDigestService creates a digest service sending through mailer. A
+// service without a mailer can only generate digests.
+func NewDigestService(store DigestStore, mailer MailSender) *DigestService {
+	return &DigestService{store: store, budgets: NewBudgetAlertService(store), mailer: mailer}
+}
+
+// Generate composes the user's digest for the period as of now, in their
+// timezone: budgets as they stand today and bills due over the coming
+// period.
+func (s *DigestService) Generate(ctx context.Context, userID string, frequency models.DigestFrequency, period models.ReportPeriod, timezone string, now time.Time) (*models.NotificationDigest, error) {
+	today := dateOf(now.In(loadTimezone(timezone)))
+
+	previous := previousDigestPeriod(frequency, period)
+	currentTotals, err := s.store.GetCategoryTotals(ctx, userID, period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	previousTotals, err := s.store.GetCategoryTotals(ctx, userID, previous.StartDate, previous.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	spending := ComparePeriods(period, previous, currentTotals, previousTotals, digestTopCategories)
+	spending.UserID = userID
+
+	_, budgets, err := s.budgets.Evaluate(ctx, userID, today)
+	if err != nil {
+		return nil, err
+	}
+	goals, err := s.digestGoals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	until := today.AddDate(0, 0, 6)
+	if frequency == models.DigestFrequencyMonthly {
+		until = today.AddDate(0, 1, -1)
+	}
+	bills, err := s.upcomingBills(ctx, userID, today, until)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := s.store.GetDigestNotificationDeliveries(ctx, userID, digestNotificationLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if budgets == nil {
+		budgets = []models.BudgetEvaluation{}
+	}
+	return &models.NotificationDigest{
+		UserID:        userID,
+		Frequency:     frequency,
+		Period:        period,
+		Spending:      spending,
+		Budgets:       budgets,
+		Goals:         goals,
+		UpcomingBills: bills,
+		Notifications: notifications,
+		GeneratedAt:   now,
+	}, nil
+}
+
+// digestGoals lists the user's goals in progress, closest to done first
+func (s *DigestService) digestGoals(ctx context.Context, userID string) ([]models.DigestGoal, error) {
+	goals, err := s.store.GetGoalsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.DigestGoal{}
+	for _, goal := range goals {
+		if !goal.IsActive || goal.IsCompleted {
+			continue
+		}
+		percent := 0.0
+		if goal.TargetAmount > 0 {
+			percent = roundTo2(goal.CurrentAmount / goal.TargetAmount * 100)
+		}
+		result = append(result, models.DigestGoal{
+			GoalID:          goal.ID,
+			Name:            goal.Name,
+			TargetAmount:    goal.TargetAmount,
+			CurrentAmount:   goal.CurrentAmount,
+			PercentComplete: percent,
+			TargetDate:      goal.TargetDate,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].PercentComplete > result[j].PercentComplete
+	})
+	return result, nil
+}
+
+// upcomingBills lists the scheduled expenses falling due from from to to,
+// soonest first
+func (s *DigestService) upcomingBills(ctx context.Context, userID string, from, to time.Time) ([]models.DigestBill, error) {
+	scheduled, err := s.store.GetScheduledTransactionsByUserID(ctx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	bills := []models.DigestBill{}
+	var categoryNames map[string]string
+	for _, st := range scheduled {
+		if st.TransactionType != models.TransactionTypeExpense {
+			continue
+		}
+		dates := ScheduledOccurrences(st, from, to)
+		if len(dates) == 0 {
+			continue
+		}
+
+		if categoryNames == nil {
+			categories, err := s.store.GetCategoriesByUserID(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			categoryNames = make(map[string]string, len(categories))
+			for _, category := range categories {
+				categoryNames[category.ID] = category.Name
+			}
+		}
+		categoryName := ""
+		if st.CategoryID != nil {
+			categoryName = categoryNames[*st.CategoryID]
+		}
+		description := categoryName
+		if st.Description != nil && *st.Description != "" {
+			description = *st.Description
+		}
+		if description == "" {
+			description = "Scheduled payment"
+		}
+
+		for _, date := range dates {
+			bills = append(bills, models.DigestBill{
+				ScheduledTransactionID: st.ID,
+				Description:            description,
+				CategoryName:           categoryName,
+				Amount:                 st.Amount,
+				DueDate:                date,
+			})
+		}
+	}
+	sort.SliceStable(bills, func(i, j int) bool {
+		if !bills[i].DueDate.Equal(bills[j].DueDate) {
+			return bills[i].DueDate.Before(bills[j].DueDate)
+		}
+		return bills[i].Amount > bills[j].Amount
+	})
+	return bills, nil
+}
+
+// SendDue emails the user's latest digest if it is due and has not gone out
+// yet, reporting whether it was sent. The notifications held for the digest
+// are marked sent with it.
+func (s *DigestService) SendDue(ctx context.Context, settings models.NotificationSettings, now time.Time) (bool, error) {
+	period, _, ok := DigestPeriod(settings.DigestFrequency, now, loadTimezone(settings.Timezone))
+	if !ok {
+		return false, nil
+	}
+	to := settings.AccountEmail
+	if settings.Email != nil && *settings.Email != "" {
+		to = *settings.Email
+	}
+	if to == "" {
+		return false, nil
+	}
+
+	id, ok, err := s.store.ClaimNotificationDigest(ctx, settings.UserID, settings.DigestFrequency,
+		period.StartDate, period.EndDate, now, now.Add(-digestRetryAfter), digestMaxAttempts)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	fail := func(err error) (bool, error) {
+		if markErr := s.store.MarkNotificationDigestFailed(ctx, id, err.Error()); markErr != nil {
+			log.Printf("Warning: Failed to record digest failure for user %s: %v", settings.UserID, markErr)
+		}
+		return false, err
+	}
+	digest, err := s.Generate(ctx, settings.UserID, settings.DigestFrequency, period, settings.Timezone, now)
+	if err != nil {
+		return fail(err)
+	}
+	mail, err := RenderDigestMail(digest, to)
+	if err != nil {
+		return fail(err)
+	}
+	if err := s.mailer.SendMail(ctx, mail); err != nil {
+		return fail(err)
+	}
+
+	ids := make([]string, len(digest.Notifications))
+	for i, delivery := range digest.Notifications {
+		ids[i] = delivery.ID
+	}
+	if err := s.store.MarkDigestDeliveriesSent(ctx, ids, now); err != nil {
+		return true, err
+	}
+	return true, s.store.MarkNotificationDigestSent(ctx, id, now)
+}
+
+// SendAllDue sends every due digest. A failure for one user does not stop
+// the others; it returns how many digests were sent.
+func (s *DigestService) SendAllDue(ctx context.Context, now time.Time) (int, error) {
+	recipients, err := s.store.GetDigestRecipients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent, failed := 0, 0
+	for _, settings := range recipients {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		ok, err := s.SendDue(ctx, settings, now)
+		if ok {
+			sent++
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to send digest to user %s: %v", settings.UserID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return sent, fmt.Errorf("%d of %d digests failed", failed, len(recipients))
+	}
+	return sent, nil
+}
+
+// RunDigests sends due digests right away and then every few minutes, so
+// each goes out shortly after its send time in the user's timezone. It runs
+// until the context is cancelled.
+func (s *DigestService) RunDigests(ctx context.Context) {
+	ticker := time.NewTicker(digestPollEvery)
+	defer ticker.Stop()
+	for {
+		sent, err := s.SendAllDue(ctx, time.Now().UTC())
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Warning: Digest run incomplete: %v", err)
+		}
+		if sent > 0 {
+			log.Printf("Sent %d notification digests", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestPeriod(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Monday 2026-03-09, 07:30 in Berlin: last week's digest is not due yet
+	period, dueAt, ok := DigestPeriod(models.DigestFrequencyWeekly, time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC), berlin)
+	assert.True(t, ok)
+	assert.Equal(t, forecastDate(2026, 2, 23), period.StartDate)
+	assert.Equal(t, forecastDate(2026, 3, 1), period.EndDate)
+	assert.Equal(t, "Feb 23 - Mar 1, 2026", period.Label)
+	assert.True(t, dueAt.Equal(time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)))
+
+	// From 08:00 it is
+	period, dueAt, _ = DigestPeriod(models.DigestFrequencyWeekly, time.Date(2026, 3, 9, 7, 0, 0, 0, time.UTC), berlin)
+	assert.Equal(t, forecastDate(2026, 3, 2), period.StartDate)
+	assert.Equal(t, forecastDate(2026, 3, 8), period.EndDate)
+	assert.True(t, dueAt.Equal(time.Date(2026, 3, 9, 7, 0, 0, 0, time.UTC)))
+
+	// Sunday night is still in the week being summarized
+	period, _, _ = DigestPeriod(models.DigestFrequencyWeekly, time.Date(2026, 3, 15, 22, 0, 0, 0, time.UTC), berlin)
+	assert.Equal(t, forecastDate(2026, 3, 2), period.StartDate)
+
+	// Monthly digests cover the previous calendar month
+	period, _, _ = DigestPeriod(models.DigestFrequencyMonthly, time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), time.UTC)
+	assert.Equal(t, forecastDate(2026, 2, 1), period.StartDate)
+	assert.Equal(t, forecastDate(2026, 2, 28), period.EndDate)
+	assert.Equal(t, "February 2026", period.Label)
+	period, _, _ = DigestPeriod(models.DigestFrequencyMonthly, time.Date(2026, 3, 1, 7, 59, 0, 0, time.UTC), time.UTC)
+	assert.Equal(t, forecastDate(2026, 1, 1), period.StartDate)
+
+	_, _, ok = DigestPeriod(models.DigestFrequencyOff, time.Now(), time.UTC)
+	assert.False(t, ok)
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "0.00", formatAmount(0))
+	assert.Equal(t, "999.50", formatAmount(999.5))
+	assert.Equal(t, "1,234.56", formatAmount(1234.56))
+	assert.Equal(t, "1,234,567.00", formatAmount(1234567))
+	assert.Equal(t, "-12,000.10", formatAmount(-12000.1))
+}
+
+func TestFormatChange(t *testing.T) {
+	assert.Equal(t, "up 25.0%", formatChange(CompareValues(125, 100)))
+	assert.Equal(t, "down 10.0%", formatChange(CompareValues(90, 100)))
+	assert.Equal(t, "new", formatChange(CompareValues(40, 0)))
+	assert.Equal(t, "no change", formatChange(CompareValues(0, 0)))
+}
+
+type digestRecord struct {
+	id        string
+	status    string
+	attempts  int
+	claimedAt time.Time
+	lastError string
+}
+
+// fakeDigestStore serves a digest's data and keeps the digest log in memory
+type fakeDigestStore struct {
+	*fakeBudgetAlertStore
+	totals        map[time.Time][]models.CategoryTotal
+	goals         []models.Goal
+	scheduled     []models.ScheduledTransaction
+	held          []models.NotificationDelivery
+	recipients    []models.NotificationSettings
+	digests       map[string]*digestRecord
+	deliveriesOut []string
+}
+
+func newFakeDigestStore() *fakeDigestStore {
+	return &fakeDigestStore{
+		fakeBudgetAlertStore: &fakeBudgetAlertStore{keys: make(map[string]bool)},
+		totals:               make(map[time.Time][]models.CategoryTotal),
+		digests:              make(map[string]*digestRecord),
+	}
+}
+
+func (f *fakeDigestStore) GetCategoryTotals(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.CategoryTotal, error) {
+	return f.totals[startDate], nil
+}
+
+func (f *fakeDigestStore) GetCategoriesByUserID(ctx context.Context, userID string) ([]models.Category, error) {
+	return []models.Category{{ID: "rent", Name: "Rent"}, {ID: "utilities", Name: "Utilities"}}, nil
+}
+
+func (f *fakeDigestStore) GetGoalsByUserID(ctx context.Context, userID string) ([]models.Goal, error) {
+	return f.goals, nil
+}
+
+func (f *fakeDigestStore) GetScheduledTransactionsByUserID(ctx context.Context, userID string, activeOnly bool) ([]models.ScheduledTransaction, error) {
+	return f.scheduled, nil
+}
+
+func (f *fakeDigestStore) GetDigestRecipients(ctx context.Context) ([]models.NotificationSettings, error) {
+	return f.recipients, nil
+}
+
+func (f *fakeDigestStore) ClaimNotificationDigest(ctx context.Context, userID string, frequency models.DigestFrequency, periodStart, periodEnd, now, retryBefore time.Time, maxAttempts int) (string, bool, error) {
+	key := userID + ":" + string(frequency) + ":" + periodStart.Format("2006-01-02")
+	record, ok := f.digests[key]
+	if !ok {
+		f.digests[key] = &digestRecord{id: key, status: "sending", attempts: 1, claimedAt: now}
+		return key, true, nil
+	}
+	if record.status == "sent" || record.attempts >= maxAttempts || record.claimedAt.After(retryBefore) {
+		return "", false, nil
+	}
+	record.status, record.claimedAt = "sending", now
+	record.attempts++
+	return key, true, nil
+}
+
+func (f *fakeDigestStore) MarkNotificationDigestSent(ctx context.Context, id string, sentAt time.Time) error {
+	f.digests[id].status = "sent"
+	return nil
+}
+
+func (f *fakeDigestStore) MarkNotificationDigestFailed(ctx context.Context, id string, lastError string) error {
+	f.digests[id].status, f.digests[id].lastError = "failed", lastError
+	return nil
+}
+
+func (f *fakeDigestStore) GetDigestNotificationDeliveries(ctx context.Context, userID string, limit int) ([]models.NotificationDelivery, error) {
+	return f.held, nil
+}
+
+func (f *fakeDigestStore) MarkDigestDeliveriesSent(ctx context.Context, ids []string, sentAt time.Time) error {
+	f.deliveriesOut = append(f.deliveriesOut, ids...)
+	f.held = nil
+	return nil
+}
+
+// failingMailer fails every send
+type failingMailer struct{ err error }
+
+func (m failingMailer) SendMail(ctx context.Context, mail Mail) error { return m.err }
+
+func testDigestStore() *fakeDigestStore {
+	store := newFakeDigestStore()
+	// Week of Mar 2 and the week before
+	store.totals[forecastDate(2026, 3, 2)] = []models.CategoryTotal{
+		{CategoryID: "groceries", CategoryName: "Groceries", TransactionType: models.TransactionTypeExpense, Amount: 150, Count: 4},
+		{CategoryID: "dining", CategoryName: "Dining & Drinks", TransactionType: models.TransactionTypeExpense, Amount: 1250, Count: 2},
+		{CategoryID: "salary", CategoryName: "Salary", TransactionType: models.TransactionTypeIncome, Amount: 3000, Count: 1},
+	}
+	store.totals[forecastDate(2026, 2, 23)] = []models.CategoryTotal{
+		{CategoryID: "groceries", CategoryName: "Groceries", TransactionType: models.TransactionTypeExpense, Amount: 200, Count: 5},
+	}
+	store.budgets = []models.Budget{testBudget(models.BudgetPeriodMonthly, 400, forecastDate(2026, 1, 1))}
+	store.spending = map[string]float64{"groceries": 350}
+	store.goals = []models.Goal{
+		{ID: "g-1", Name: "Holiday", TargetAmount: 2000, CurrentAmount: 500, IsActive: true},
+		{ID: "g-2", Name: "Emergency fund", TargetAmount: 1000, CurrentAmount: 900, IsActive: true},
+		{ID: "g-3", Name: "Bike", TargetAmount: 800, CurrentAmount: 800, IsActive: true, IsCompleted: true},
+	}
+	store.scheduled = []models.ScheduledTransaction{
+		{ID: "s-1", CategoryID: strPtr("rent"), Amount: 950, TransactionType: models.TransactionTypeExpense,
+			Frequency: models.ScheduleFrequencyMonthly, NextDate: forecastDate(2026, 3, 12), IsActive: true},
+		{ID: "s-2", CategoryID: strPtr("utilities"), Description: strPtr("Electricity"), Amount: 60, TransactionType: models.TransactionTypeExpense,
+			Frequency: models.ScheduleFrequencyMonthly, NextDate: forecastDate(2026, 3, 10), IsActive: true},
+		{ID: "s-3", Amount: 3000, TransactionType: models.TransactionTypeIncome,
+			Frequency: models.ScheduleFrequencyMonthly, NextDate: forecastDate(2026, 3, 11), IsActive: true},
+		{ID: "s-4", Amount: 15, TransactionType: models.TransactionTypeExpense,
+			Frequency: models.ScheduleFrequencyMonthly, NextDate: forecastDate(2026, 3, 20), IsActive: true},
+	}
+	store.held = []models.NotificationDelivery{{
+		ID:      "d-1",
+		Channel: models.NotificationChannelEmail,
+		Status:  models.NotificationDeliveryInDigest,
+		Notification: &models.Notification{
+			Title:   "Goal milestone",
+			Message: "Emergency fund is 90% <funded>",
+		},
+	}}
+	store.recipients = []models.NotificationSettings{{
+		UserID:          "u-1",
+		AccountEmail:    "jo@example.com",
+		Timezone:        "UTC",
+		DigestFrequency: models.DigestFrequencyWeekly,
+	}}
+	return store
+}
+
+func TestDigestService_Generate(t *testing.T) {
+	store := testDigestStore()
+	service := NewDigestService(store, nil)
+	now := time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)
+	period, _, _ := DigestPeriod(models.DigestFrequencyWeekly, now, time.UTC)
+
+	digest, err := service.Generate(context.Background(), "u-1", models.DigestFrequencyWeekly, period, "UTC", now)
+	assert.NoError(t, err)
+
+	assert.Equal(t, forecastDate(2026, 2, 23), digest.Spending.Previous.StartDate)
+	assert.Equal(t, 1400.0, digest.Spending.Expenses.Current)
+	assert.Equal(t, 200.0, digest.Spending.Expenses.Previous)
+	assert.Equal(t, 3000.0, digest.Spending.Income.Current)
+
+	if assert.Len(t, digest.Budgets, 1) {
+		assert.Equal(t, 350.0, digest.Budgets[0].Spent)
+		assert.Equal(t, models.BudgetStatusAtRisk, digest.Budgets[0].Status)
+	}
+
+	// Goals in progress, closest to done first
+	if assert.Len(t, digest.Goals, 2) {
+		assert.Equal(t, "Emergency fund", digest.Goals[0].Name)
+		assert.Equal(t, 90.0, digest.Goals[0].PercentComplete)
+		assert.Equal(t, 25.0, digest.Goals[1].PercentComplete)
+	}
+
+	// Expenses due in the coming week, named by description or category
+	if assert.Len(t, digest.UpcomingBills, 2) {
+		assert.Equal(t, "Electricity", digest.UpcomingBills[0].Description)
+		assert.Equal(t, forecastDate(2026, 3, 10), digest.UpcomingBills[0].DueDate)
+		assert.Equal(t, "Rent", digest.UpcomingBills[1].Description)
+		assert.Equal(t, "Rent", digest.UpcomingBills[1].CategoryName)
+	}
+	assert.Len(t, digest.Notifications, 1)
+
+	// A monthly digest looks a month ahead
+	period, _, _ = DigestPeriod(models.DigestFrequencyMonthly, now, time.UTC)
+	digest, err = service.Generate(context.Background(), "u-1", models.DigestFrequencyMonthly, period, "UTC", now)
+	assert.NoError(t, err)
+	assert.Len(t, digest.UpcomingBills, 3)
+	assert.Equal(t, "Scheduled payment", digest.UpcomingBills[2].Description)
+}
+
+func TestRenderDigestMail(t *testing.T) {
+	store := testDigestStore()
+	now := time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)
+	period, _, _ := DigestPeriod(models.DigestFrequencyWeekly, now, time.UTC)
+	digest, err := NewDigestService(store, nil).Generate(context.Background(), "u-1", models.DigestFrequencyWeekly, period, "UTC", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mail, err := RenderDigestMail(digest, "jo@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "jo@example.com", mail.To)
+	assert.Equal(t, "Your weekly summary: Mar 2 - Mar 8, 2026", mail.Subject)
+
+	assert.Contains(t, mail.Text, "Spent 1,400.00 (up 600.0% on the previous week)")
+	assert.Contains(t, mail.Text, "  - Dining & Drinks: 1,250.00 (new)")
+	assert.Contains(t, mail.Text, "  - Groceries: 350.00 of 400.00 (88%), at risk")
+	assert.Contains(t, mail.Text, "  - Emergency fund: 900.00 of 1,000.00 (90%)")
+	assert.Contains(t, mail.Text, "  - Mar 10: Electricity, 60.00")
+	assert.Contains(t, mail.Text, "  - Goal milestone: Emergency fund is 90% <funded>")
+	assert.NotContains(t, mail.Text, "\n\n\n")
+
+	assert.Contains(t, mail.HTML, "Dining &amp; Drinks")
+	assert.Contains(t, mail.HTML, "Emergency fund is 90% &lt;funded&gt;")
+	assert.Contains(t, mail.HTML, "#b26a00") // at risk
+
+	// Empty sections say so
+	digest.Budgets, digest.Goals, digest.UpcomingBills, digest.Notifications = nil, nil, nil, nil
+	mail, err = RenderDigestMail(digest, "jo@example.com")
+	assert.NoError(t, err)
+	assert.Contains(t, mail.Text, "No active budgets.")
+	assert.Contains(t, mail.Text, "Nothing due in the next week.")
+	assert.NotContains(t, mail.Text, "NOTIFICATIONS")
+	assert.Contains(t, mail.HTML, "No goals in progress.")
+}
+
+func TestDigestService_SendAllDue(t *testing.T) {
+	ctx := context.Background()
+	server := startTestSMTPServer(t)
+	store := testDigestStore()
+	service := NewDigestService(store, server.mailer(t))
+
+	now := time.Date(2026, 3, 9, 8, 15, 0, 0, time.UTC)
+	sent, err := service.SendAllDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []string{"d-1"}, store.deliveriesOut)
+	assert.Equal(t, "sent", store.digests["u-1:weekly:2026-03-02"].status)
+
+	messages := server.received()
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	header, parts := readTestMessage(t, messages[0].Data)
+	assert.Equal(t, []string{"jo@example.com"}, messages[0].To)
+	assert.Contains(t, header.Get("Subject"), "Mar 2 - Mar 8, 2026")
+	assert.Contains(t, parts["text/plain"], "UPCOMING BILLS")
+	assert.Contains(t, parts["text/html"], "<h2")
+
+	// Sent once per period
+	sent, _ = service.SendAllDue(ctx, now.Add(digestPollEvery))
+	assert.Zero(t, sent)
+	assert.Len(t, server.received(), 1)
+}
+
+func TestDigestService_SendDueRetries(t *testing.T) {
+	ctx := context.Background()
+	store := testDigestStore()
+	service := NewDigestService(store, failingMailer{err: errors.New("connection refused")})
+	settings := store.recipients[0]
+	now := time.Date(2026, 3, 9, 8, 15, 0, 0, time.UTC)
+
+	ok, err := service.SendDue(ctx, settings, now)
+	assert.False(t, ok)
+	assert.Error(t, err)
+	record := store.digests["u-1:weekly:2026-03-02"]
+	assert.Equal(t, "failed", record.status)
+	assert.Equal(t, "connection refused", record.lastError)
+	assert.Empty(t, store.deliveriesOut)
+
+	// Retried after a while, up to the attempt limit
+	ok, err = service.SendDue(ctx, settings, now.Add(digestPollEvery))
+	assert.False(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, record.attempts)
+
+	for i := 1; i <= digestMaxAttempts; i++ {
+		_, _ = service.SendDue(ctx, settings, now.Add(time.Duration(i)*digestRetryAfter))
+	}
+	assert.Equal(t, digestMaxAttempts, record.attempts)
+
+	// No address, no digest
+	settings.AccountEmail = ""
+	ok, err = service.SendDue(ctx, settings, now.AddDate(0, 0, 7))
+	assert.False(t, ok)
+	assert.NoError(t, err)
+	_, claimed := store.digests["u-1:weekly:2026-03-09"]
+	assert.False(t, claimed)
+}
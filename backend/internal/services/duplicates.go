@@ -0,0 +1,262 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// Default duplicate detection settings
+const (
+	DefaultDuplicateWindowDays = 3
+	DefaultDuplicateMinScore   = 0.7
+)
+
+// DuplicateOptions controls how aggressively duplicates are matched.
+// WindowDays is the largest gap between transaction dates that still counts
+// as a duplicate; MinScore drops weaker candidates.
+type DuplicateOptions struct {
+	WindowDays int
+	MinScore   float64
+}
+
+// DuplicatePair is a pair of likely duplicate transactions. Original is the
+// transaction entered first and is the suggested one to keep.
+type DuplicatePair struct {
+	Original              models.Transaction `json:"original"`
+	Duplicate             models.Transaction `json:"duplicate"`
+	Score                 float64            `json:"score"`
+	DescriptionSimilarity float64            `json:"description_similarity"`
+	DaysApart             int                `json:"days_apart"`
+}
+
+// FindDuplicates returns candidate duplicate pairs, best match first.
+// Transactions only pair up when they share the account, type and amount and
+// fall within the date window; the score blends description similarity (70%)
+// with date proximity (30%). Transfer legs are never reported.
+func FindDuplicates(transactions []models.Transaction, opts DuplicateOptions) []DuplicatePair {
+	if opts.WindowDays < 0 {
+		opts.WindowDays = 0
+	}
+
+	type groupKey struct {
+		accountID       string
+		transactionType models.TransactionType
+		cents           int64
+	}
+
+	groups := make(map[groupKey][]models.Transaction)
+	for _, t := range transactions {
+		if t.TransferID != nil || t.TransactionType == models.TransactionTypeTransfer {
+			continue
+		}
+		key := groupKey{t.AccountID, t.TransactionType, toCents(t.Amount)}
+		groups[key] = append(groups[key], t)
+	}
+
+	pairs := []DuplicatePair{}
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].TransactionDate.Equal(group[j].TransactionDate) {
+				return group[i].CreatedAt.Before(group[j].CreatedAt)
+			}
+			return group[i].TransactionDate.Before(group[j].TransactionDate)
+		})
+
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				days := int(group[j].TransactionDate.Sub(group[i].TransactionDate).Hours() / 24)
+				if days > opts.WindowDays {
+					break
+				}
+
+				similarity := DescriptionSimilarity(stringValue(group[i].Description), stringValue(group[j].Description))
+				score := 0.7*similarity + 0.3*(1-float64(days)/float64(opts.WindowDays+1))
+				score = float64(int64(score*1000+0.5)) / 1000
+				if score < opts.MinScore {
+					continue
+				}
+
+				original, duplicate := group[i], group[j]
+				if duplicate.CreatedAt.Before(original.CreatedAt) {
+					original, duplicate = duplicate, original
+				}
+
+				pairs = append(pairs, DuplicatePair{
+					Original:              original,
+					Duplicate:             duplicate,
+					Score:                 score,
+					DescriptionSimilarity: float64(int64(similarity*1000+0.5)) / 1000,
+					DaysApart:             days,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Score == pairs[j].Score {
+			return pairs[i].Original.TransactionDate.After(pairs[j].Original.TransactionDate)
+		}
+		return pairs[i].Score > pairs[j].Score
+	})
+
+	return pairs
+}
+
+// DescriptionSimilarity scores two transaction descriptions between 0 and 1.
+// Descriptions are normalized first (case, punctuation and reference numbers
+// are ignored), then compared both by edit distance and by shared words so
+// that reordered bank descriptions still match.
+func DescriptionSimilarity(a, b string) float64 {
+	tokensA := descriptionTokens(a)
+	tokensB := descriptionTokens(b)
+
+	if len(tokensA) == 0 && len(tokensB) == 0 {
+		// Nothing to compare; neither confirms nor rules out a duplicate
+		return 0.5
+	}
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+
+	edit := levenshteinRatio(strings.Join(tokensA, " "), strings.Join(tokensB, " "))
+
+	setA := make(map[string]bool, len(tokensA))
+	for _, token := range tokensA {
+		setA[token] = true
+	}
+	setB := make(map[string]bool, len(tokensB))
+	for _, token := range tokensB {
+		setB[token] = true
+	}
+	shared := 0
+	for token := range setA {
+		if setB[token] {
+			shared++
+		}
+	}
+
+	// Banks often truncate descriptions, so containment counts as much as
+	// overall overlap: average the Jaccard index with the overlap coefficient
+	jaccard := float64(shared) / float64(len(setA)+len(setB)-shared)
+	overlap := float64(shared) / float64(min(len(setA), len(setB)))
+	words := (jaccard + overlap) / 2
+
+	if words > edit {
+		return words
+	}
+	return edit
+}
+
+// MergeDuplicate folds the duplicate into the transaction being kept. Notes
+// and tags from both are retained, and the kept transaction inherits the
+// duplicate's description, merchant, custom field values, category or split
+// lines where it has none of its own.
+func MergeDuplicate(keep *models.Transaction, duplicate models.Transaction) {
+	keepNotes := strings.TrimSpace(stringValue(keep.Notes))
+	duplicateNotes := strings.TrimSpace(stringValue(duplicate.Notes))
+	switch {
+	case duplicateNotes == "" || duplicateNotes == keepNotes:
+	case keepNotes == "":
+		keep.Notes = &duplicateNotes
+	default:
+		merged := keepNotes + "\n" + duplicateNotes
+		keep.Notes = &merged
+	}
+
+	keep.Tags = MergeTags(keep.Tags, duplicate.Tags)
+
+	if strings.TrimSpace(stringValue(keep.Description)) == "" && duplicate.Description != nil {
+		keep.Description = duplicate.Description
+	}
+
+	if keep.MerchantID == nil {
+		keep.MerchantID = duplicate.MerchantID
+	}
+
+	for id, value := range duplicate.CustomFields {
+		if _, ok := keep.CustomFields[id]; ok {
+			continue
+		}
+		if keep.CustomFields == nil {
+			keep.CustomFields = make(map[string]interface{})
+		}
+		keep.CustomFields[id] = value
+	}
+
+	if keep.CategoryID != nil || len(keep.Splits) > 0 {
+		return
+	}
+
+	// Split lines only carry over when they still balance against the kept amount
+	if len(duplicate.Splits) > 0 && toCents(duplicate.Amount) == toCents(keep.Amount) {
+		keep.Splits = make([]models.TransactionSplit, 0, len(duplicate.Splits))
+		for _, split := range duplicate.Splits {
+			keep.Splits = append(keep.Splits, models.TransactionSplit{
+				CategoryID:  split.CategoryID,
+				Amount:      split.Amount,
+				Description: split.Description,
+			})
+		}
+		return
+	}
+
+	keep.CategoryID = duplicate.CategoryID
+}
+
+// descriptionTokens lowercases a description and splits it into words,
+// dropping tokens that contain digits such as card or reference numbers.
+func descriptionTokens(description string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if strings.IndexFunc(field, unicode.IsDigit) >= 0 {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// levenshteinRatio returns 1 minus the edit distance normalized by the longer string.
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func duplicateTestTransaction(id, accountID string, amount float64, day int, description string) models.Transaction {
+	date := time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC)
+	return models.Transaction{
+		ID:              id,
+		AccountID:       accountID,
+		Amount:          amount,
+		TransactionType: models.TransactionTypeExpense,
+		Description:     &description,
+		TransactionDate: date,
+		CreatedAt:       date.Add(time.Duration(len(id)) * time.Hour),
+	}
+}
+
+func TestDescriptionSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, DescriptionSimilarity("STARBUCKS #1234", "Starbucks 5678"))
+	assert.Equal(t, 1.0, DescriptionSimilarity("Amazon Marketplace", "marketplace amazon"))
+	assert.Greater(t, DescriptionSimilarity("Whole Foods Market", "Whole Foods Mkt"), 0.7)
+	assert.Less(t, DescriptionSimilarity("Shell Gas", "Netflix"), 0.3)
+	assert.Equal(t, 0.0, DescriptionSimilarity("Netflix", ""))
+	assert.Equal(t, 0.5, DescriptionSimilarity("", ""))
+}
+
+func TestFindDuplicates_MatchesWithinWindow(t *testing.T) {
+	transactions := []models.Transaction{
+		duplicateTestTransaction("a", "acct-1", 25.40, 10, "WHOLE FOODS #102"),
+		duplicateTestTransaction("bb", "acct-1", 25.40, 11, "Whole Foods Market"),
+		duplicateTestTransaction("c", "acct-1", 25.40, 20, "Whole Foods Market"),  // outside window
+		duplicateTestTransaction("d", "acct-2", 25.40, 10, "Whole Foods Market"),  // other account
+		duplicateTestTransaction("e", "acct-1", 25.41, 10, "Whole Foods Market"),  // other amount
+		duplicateTestTransaction("f", "acct-1", 25.40, 12, "Chevron Gas Station"), // unrelated description
+	}
+
+	pairs := FindDuplicates(transactions, DuplicateOptions{WindowDays: 3, MinScore: DefaultDuplicateMinScore})
+
+	assert.Len(t, pairs, 1)
+	assert.Equal(t, "a", pairs[0].Original.ID)
+	assert.Equal(t, "bb", pairs[0].Duplicate.ID)
+	assert.Equal(t, 1, pairs[0].DaysApart)
+	assert.GreaterOrEqual(t, pairs[0].Score, DefaultDuplicateMinScore)
+}
+
+func TestFindDuplicates_IgnoresTransfers(t *testing.T) {
+	transferID := "transfer-1"
+	a := duplicateTestTransaction("a", "acct-1", 100, 5, "Transfer to savings")
+	b := duplicateTestTransaction("b", "acct-1", 100, 5, "Transfer to savings")
+	a.TransferID = &transferID
+	b.TransactionType = models.TransactionTypeTransfer
+
+	assert.Empty(t, FindDuplicates([]models.Transaction{a, b}, DuplicateOptions{WindowDays: 3}))
+}
+
+func TestFindDuplicates_SameDayScoresHigher(t *testing.T) {
+	transactions := []models.Transaction{
+		duplicateTestTransaction("a", "acct-1", 9.99, 1, "Netflix"),
+		duplicateTestTransaction("bb", "acct-1", 9.99, 1, "Netflix"),
+		duplicateTestTransaction("ccc", "acct-1", 9.99, 4, "Netflix"),
+	}
+
+	pairs := FindDuplicates(transactions, DuplicateOptions{WindowDays: 3})
+
+	assert.Len(t, pairs, 3)
+	assert.Equal(t, 1.0, pairs[0].Score)
+	assert.Equal(t, 0, pairs[0].DaysApart)
+	assert.Less(t, pairs[2].Score, pairs[0].Score)
+}
+
+func TestMergeDuplicate_PreservesNotesAndCategory(t *testing.T) {
+	keepNotes := "paid by card"
+	dupNotes := "reimbursable"
+	category := "cat-groceries"
+
+	keep := duplicateTestTransaction("a", "acct-1", 40, 1, "Grocer")
+	keep.Notes = &keepNotes
+	duplicate := duplicateTestTransaction("b", "acct-1", 40, 2, "Grocer")
+	duplicate.Notes = &dupNotes
+	duplicate.CategoryID = &category
+
+	MergeDuplicate(&keep, duplicate)
+
+	assert.Equal(t, "paid by card\nreimbursable", *keep.Notes)
+	assert.Equal(t, &category, keep.CategoryID)
+}
+
+func TestMergeDuplicate_KeepsOwnCategory(t *testing.T) {
+	own := "cat-dining"
+	other := "cat-groceries"
+
+	keep := duplicateTestTransaction("a", "acct-1", 40, 1, "Grocer")
+	keep.CategoryID = &own
+	duplicate := duplicateTestTransaction("b", "acct-1", 40, 2, "Grocer")
+	duplicate.CategoryID = &other
+	duplicate.Notes = keep.Notes
+
+	MergeDuplicate(&keep, duplicate)
+
+	assert.Equal(t, "cat-dining", *keep.CategoryID)
+	assert.Nil(t, keep.Notes)
+}
+
+func TestMergeDuplicate_CopiesSplits(t *testing.T) {
+	food, home := "cat-food", "cat-home"
+	keep := duplicateTestTransaction("a", "acct-1", 100, 1, "Target")
+	duplicate := duplicateTestTransaction("b", "acct-1", 100, 1, "Target")
+	duplicate.Splits = []models.TransactionSplit{
+		{ID: "s1", TransactionID: "b", CategoryID: &food, Amount: 60},
+		{ID: "s2", TransactionID: "b", CategoryID: &home, Amount: 40},
+	}
+
+	MergeDuplicate(&keep, duplicate)
+
+	assert.Len(t, keep.Splits, 2)
+	assert.Empty(t, keep.Splits[0].ID)
+	assert.Equal(t, &food, keep.Splits[0].CategoryID)
+	assert.NoError(t, ValidateSplits(keep.Amount, keep.Splits))
+}
+
+func TestMergeDuplicate_CombinesCustomFields(t *testing.T) {
+	keep := duplicateTestTransaction("a", "acct-1", 40, 1, "Grocer")
+	keep.CustomFields = map[string]interface{}{"f-project": "Kitchen"}
+	duplicate := duplicateTestTransaction("b", "acct-1", 40, 2, "Grocer")
+	duplicate.CustomFields = map[string]interface{}{"f-project": "Garden", "f-miles": 12.0}
+
+	MergeDuplicate(&keep, duplicate)
+
+	assert.Equal(t, map[string]interface{}{"f-project": "Kitchen", "f-miles": 12.0}, keep.CustomFields)
+}
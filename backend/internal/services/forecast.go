@@ -0,0 +1,511 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+const (
+	// MinForecastDays and MaxForecastDays bound the forecast horizon
+	MinForecastDays = 30
+	MaxForecastDays = 365
+
+	// RecurringHistoryDays is how much history recurring detection looks at
+	RecurringHistoryDays = 365
+
+	// discretionaryWindowDays is the lookback for average discretionary spend;
+	// newer accounts average over at least discretionaryMinDays
+	discretionaryWindowDays = 90
+	discretionaryMinDays    = 14
+
+	// maxAmountDeviation is the largest median absolute deviation, relative to
+	// the median amount, a series can have and still count as recurring
+	maxAmountDeviation = 0.25
+)
+
+// frequencyGaps are the day gaps accepted between occurrences of each
+// detectable frequency.
+var frequencyGaps = []struct {
+	frequency models.ScheduleFrequency
+	min, max  int
+}{
+	{models.ScheduleFrequencyWeekly, 6, 8},
+	{models.ScheduleFrequencyBiweekly, 13, 16},
+	{models.ScheduleFrequencyMonthly, 27, 33},
+	{models.ScheduleFrequencyQuarterly, 85, 95},
+	{models.ScheduleFrequencyYearly, 355, 375},
+}
+
+// ForecastInput is what a cash-flow forecast is projected from.
+type ForecastInput struct {
+	Accounts  []models.Account
+	History   []models.Transaction // At least the last RecurringHistoryDays
+	Scheduled []models.ScheduledTransaction
+	Today     time.Time // Balances are as of the end of this day
+	Days      int
+}
+
+// ValidScheduleFrequency reports whether f is a known schedule frequency.
+func ValidScheduleFrequency(f models.ScheduleFrequency) bool {
+	if f == models.ScheduleFrequencyOnce {
+		return true
+	}
+	for _, gap := range frequencyGaps {
+		if gap.frequency == f {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduleOccurrence returns the nth occurrence (0 is the anchor itself) of
+// a schedule. Months are added from the anchor rather than step by step and
+// clamped to the month end, so a schedule on the 31st falls on Feb 28 and
+// then Mar 31.
+func ScheduleOccurrence(anchor time.Time, frequency models.ScheduleFrequency, n int) time.Time {
+	switch frequency {
+	case models.ScheduleFrequencyWeekly:
+		return anchor.AddDate(0, 0, 7*n)
+	case models.ScheduleFrequencyBiweekly:
+		return anchor.AddDate(0, 0, 14*n)
+	case models.ScheduleFrequencyMonthly:
+		return addMonthsClamped(anchor, n)
+	case models.ScheduleFrequencyQuarterly:
+		return addMonthsClamped(anchor, 3*n)
+	case models.ScheduleFrequencyYearly:
+		return addMonthsClamped(anchor, 12*n)
+	}
+	return anchor
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, t.Location())
+}
+
+// ScheduledOccurrences lists the dates a scheduled transaction falls on
+// between from and to, inclusive. Repeating schedules whose next date has
+// passed without being advanced continue from their next due date.
+func ScheduledOccurrences(s models.ScheduledTransaction, from, to time.Time) []time.Time {
+	if !s.IsActive {
+		return nil
+	}
+	anchor := truncateDay(s.NextDate)
+	if s.EndDate != nil && truncateDay(*s.EndDate).Before(to) {
+		to = truncateDay(*s.EndDate)
+	}
+
+	var dates []time.Time
+	for n := 0; ; n++ {
+		date := ScheduleOccurrence(anchor, s.Frequency, n)
+		if date.After(to) {
+			break
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+		if s.Frequency == models.ScheduleFrequencyOnce || !ValidScheduleFrequency(s.Frequency) {
+			break
+		}
+	}
+	return dates
+}
+
+// recurringGroup collects the transactions that may form a recurring series
+type recurringGroup struct {
+	accountID       string
+	transactionType models.TransactionType
+	merchantID      *string
+	description     string
+	transactions    []models.Transaction
+}
+
+// DetectRecurring finds repeating transactions in the history: at least
+// three (two for yearly) on the same account, of the same type and with the
+// same merchant, spaced at a consistent weekly to yearly interval and with
+// consistent amounts. Only series still running as of today are returned,
+// along with the IDs of every transaction belonging to them.
+func DetectRecurring(history []models.Transaction, today time.Time) ([]models.RecurringPattern, map[string]bool) {
+	today = truncateDay(today)
+	groups := make(map[string]*recurringGroup)
+	var keys []string
+
+	for _, t := range history {
+		key, description := recurringKey(t)
+		if key == "" {
+			continue
+		}
+		direction := "+"
+		if t.TransactionType == models.TransactionTypeTransfer && t.Amount < 0 {
+			direction = "-"
+		}
+		groupKey := t.AccountID + "|" + string(t.TransactionType) + direction + "|" + key
+		group, ok := groups[groupKey]
+		if !ok {
+			group = &recurringGroup{
+				accountID:       t.AccountID,
+				transactionType: t.TransactionType,
+				merchantID:      t.MerchantID,
+				description:     description,
+			}
+			groups[groupKey] = group
+			keys = append(keys, groupKey)
+		}
+		group.transactions = append(group.transactions, t)
+	}
+	sort.Strings(keys)
+
+	var patterns []models.RecurringPattern
+	members := make(map[string]bool)
+	for _, key := range keys {
+		pattern, ok := detectSeries(groups[key], today)
+		if !ok {
+			continue
+		}
+		for _, t := range groups[key].transactions {
+			members[t.ID] = true
+		}
+		patterns = append(patterns, *pattern)
+	}
+
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].NextDate.Before(patterns[j].NextDate)
+	})
+	return patterns, members
+}
+
+// recurringKey identifies the merchant of a transaction, returning the key
+// and a display name. Transactions without either are never recurring.
+func recurringKey(t models.Transaction) (string, string) {
+	description := ""
+	if t.Merchant != nil && t.Merchant.Name != "" {
+		description = t.Merchant.Name
+	} else if t.Description != nil {
+		description = CleanMerchantDescription(*t.Description)
+	}
+
+	if t.MerchantID != nil {
+		return "m:" + *t.MerchantID, description
+	}
+	if key := MerchantKey(description); key != "" {
+		return "d:" + key, description
+	}
+	return "", ""
+}
+
+func detectSeries(group *recurringGroup, today time.Time) (*models.RecurringPattern, bool) {
+	transactions := group.transactions
+	if len(transactions) < 2 {
+		return nil, false
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].TransactionDate.Before(transactions[j].TransactionDate)
+	})
+
+	var gaps []float64
+	for i := 1; i < len(transactions); i++ {
+		gap := truncateDay(transactions[i].TransactionDate).Sub(truncateDay(transactions[i-1].TransactionDate))
+		gaps = append(gaps, math.Round(gap.Hours()/24))
+	}
+
+	typical := median(gaps)
+	frequency, minGap, maxGap, ok := classifyGap(typical)
+	if !ok {
+		return nil, false
+	}
+	if len(transactions) < 3 && frequency != models.ScheduleFrequencyYearly {
+		return nil, false
+	}
+
+	// Allow the odd early or late payment, but most gaps must fit the cadence
+	consistent := 0
+	for _, gap := range gaps {
+		if int(gap) >= minGap && int(gap) <= maxGap {
+			consistent++
+		}
+	}
+	if consistent*3 < len(gaps)*2 {
+		return nil, false
+	}
+
+	amounts := make([]float64, len(transactions))
+	for i, t := range transactions {
+		amounts[i] = t.Amount
+	}
+	typicalAmount := median(amounts)
+	if typicalAmount == 0 || medianAbsoluteDeviation(amounts, typicalAmount)/math.Abs(typicalAmount) > maxAmountDeviation {
+		return nil, false
+	}
+
+	last := truncateDay(transactions[len(transactions)-1].TransactionDate)
+	if today.Sub(last).Hours()/24 > typical*1.5 {
+		return nil, false
+	}
+
+	pattern := &models.RecurringPattern{
+		AccountID:       group.accountID,
+		Description:     group.description,
+		MerchantID:      group.merchantID,
+		TransactionType: group.transactionType,
+		Amount:          roundTo2(signedAmount(group.transactionType, typicalAmount)),
+		Frequency:       frequency,
+		Occurrences:     len(transactions),
+		LastDate:        last,
+		NextDate:        ScheduleOccurrence(last, frequency, 1),
+	}
+	return pattern, true
+}
+
+func classifyGap(days float64) (models.ScheduleFrequency, int, int, bool) {
+	for _, gap := range frequencyGaps {
+		if days >= float64(gap.min) && days <= float64(gap.max) {
+			return gap.frequency, gap.min, gap.max, true
+		}
+	}
+	return "", 0, 0, false
+}
+
+// signedAmount is the effect of an amount of the given type on the account
+// balance, matching the balance trigger: income adds, expenses subtract and
+// transfers carry their own sign.
+func signedAmount(transactionType models.TransactionType, amount float64) float64 {
+	switch transactionType {
+	case models.TransactionTypeIncome:
+		return math.Abs(amount)
+	case models.TransactionTypeExpense:
+		return -math.Abs(amount)
+	}
+	return amount
+}
+
+// ForecastCashFlow projects each account's end-of-day balance for the days
+// after today from its current balance, the scheduled transactions, the
+// detected recurring transactions and the account's average discretionary
+// spend. Recurring series that a scheduled transaction already covers are
+// left out so they are not counted twice. Checking accounts projected below
+// zero are flagged.
+func ForecastCashFlow(input ForecastInput) *models.CashFlowForecast {
+	today := truncateDay(input.Today)
+	start := today.AddDate(0, 0, 1)
+	end := today.AddDate(0, 0, input.Days)
+
+	accounts := make(map[string]bool, len(input.Accounts))
+	for _, account := range input.Accounts {
+		accounts[account.ID] = true
+	}
+
+	patterns, members := DetectRecurring(input.History, today)
+
+	forecast := &models.CashFlowForecast{
+		StartDate:         start,
+		EndDate:           end,
+		Days:              input.Days,
+		Accounts:          []models.AccountForecast{},
+		Events:            []models.ForecastEvent{},
+		RecurringPatterns: []models.RecurringPattern{},
+		NegativeAccounts:  []string{},
+		GeneratedAt:       time.Now(),
+	}
+
+	for _, s := range input.Scheduled {
+		if !accounts[s.AccountID] {
+			continue
+		}
+		description := ""
+		if s.Description != nil {
+			description = *s.Description
+		}
+		for _, date := range ScheduledOccurrences(s, start, end) {
+			forecast.Events = append(forecast.Events, models.ForecastEvent{
+				Date:        date,
+				AccountID:   s.AccountID,
+				Description: description,
+				Amount:      signedAmount(s.TransactionType, s.Amount),
+				Source:      "scheduled",
+			})
+		}
+	}
+
+	for _, pattern := range patterns {
+		if !accounts[pattern.AccountID] || coveredBySchedule(pattern, input.Scheduled) {
+			continue
+		}
+		forecast.RecurringPatterns = append(forecast.RecurringPatterns, pattern)
+		for n := 1; ; n++ {
+			date := ScheduleOccurrence(pattern.LastDate, pattern.Frequency, n)
+			if date.After(end) {
+				break
+			}
+			// A payment that is due but has not posted yet is expected first thing
+			if date.Before(start) {
+				date = start
+			}
+			forecast.Events = append(forecast.Events, models.ForecastEvent{
+				Date:        date,
+				AccountID:   pattern.AccountID,
+				Description: pattern.Description,
+				Amount:      pattern.Amount,
+				Source:      "recurring",
+			})
+		}
+	}
+
+	sort.SliceStable(forecast.Events, func(i, j int) bool {
+		a, b := forecast.Events[i], forecast.Events[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.AccountID < b.AccountID
+	})
+
+	for _, account := range input.Accounts {
+		daily := DailyDiscretionarySpend(account, input.History, members, today)
+		accountForecast := projectAccount(account, forecast.Events, daily, start, input.Days)
+		if accountForecast.FirstNegativeDate != nil {
+			forecast.NegativeAccounts = append(forecast.NegativeAccounts, account.ID)
+		}
+		forecast.Accounts = append(forecast.Accounts, accountForecast)
+	}
+
+	return forecast
+}
+
+// coveredBySchedule reports whether a scheduled transaction on the same
+// account already describes the recurring series, either by name or by
+// frequency and an amount within 10%.
+func coveredBySchedule(pattern models.RecurringPattern, scheduled []models.ScheduledTransaction) bool {
+	key := MerchantKey(pattern.Description)
+	for _, s := range scheduled {
+		if !s.IsActive || s.AccountID != pattern.AccountID || s.TransactionType != pattern.TransactionType {
+			continue
+		}
+		if s.Description != nil && key != "" && MerchantKey(CleanMerchantDescription(*s.Description)) == key {
+			return true
+		}
+		if s.Frequency == pattern.Frequency && math.Abs(s.Amount-math.Abs(pattern.Amount)) <= 0.1*math.Abs(pattern.Amount) {
+			return true
+		}
+	}
+	return false
+}
+
+// DailyDiscretionarySpend averages the account's expenses over the last 90
+// days, leaving out those belonging to recurring series. Accounts with a
+// shorter history average over the days since their first activity, but at
+// least two weeks.
+func DailyDiscretionarySpend(account models.Account, history []models.Transaction, recurring map[string]bool, today time.Time) float64 {
+	today = truncateDay(today)
+	windowStart := today.AddDate(0, 0, -discretionaryWindowDays+1)
+
+	firstActivity := truncateDay(account.CreatedAt)
+	var spent int64
+	for _, t := range history {
+		if t.AccountID != account.ID {
+			continue
+		}
+		date := truncateDay(t.TransactionDate)
+		if date.Before(firstActivity) {
+			firstActivity = date
+		}
+		if t.TransactionType != models.TransactionTypeExpense || recurring[t.ID] {
+			continue
+		}
+		if date.Before(windowStart) || date.After(today) {
+			continue
+		}
+		spent += toCents(math.Abs(t.Amount))
+	}
+
+	if !account.CreatedAt.IsZero() && firstActivity.After(windowStart) {
+		windowStart = firstActivity
+	}
+	days := int(today.Sub(windowStart).Hours()/24) + 1
+	if days < discretionaryMinDays {
+		days = discretionaryMinDays
+	}
+
+	return roundTo2(float64(spent) / 100 / float64(days))
+}
+
+func projectAccount(account models.Account, events []models.ForecastEvent, daily float64, start time.Time, days int) models.AccountForecast {
+	changes := make(map[time.Time]int64)
+	for _, event := range events {
+		if event.AccountID == account.ID {
+			changes[event.Date] += toCents(event.Amount)
+		}
+	}
+
+	checking := account.AccountType == models.AccountTypeChecking
+	balance := toCents(account.Balance)
+	result := models.AccountForecast{
+		AccountID:          account.ID,
+		AccountName:        account.Name,
+		AccountType:        account.AccountType,
+		StartingBalance:    account.Balance,
+		DailyDiscretionary: daily,
+		LowestBalance:      account.Balance,
+		LowestBalanceDate:  start.AddDate(0, 0, -1),
+		Days:               make([]models.ForecastDay, 0, days),
+	}
+
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i)
+		change := changes[date] - toCents(daily)
+		balance += change
+
+		day := models.ForecastDay{
+			Date:    date,
+			Balance: float64(balance) / 100,
+			Change:  float64(change) / 100,
+		}
+		if checking && balance < 0 {
+			day.IsNegative = true
+			result.NegativeDays++
+			if result.FirstNegativeDate == nil {
+				first := date
+				result.FirstNegativeDate = &first
+			}
+		}
+		if day.Balance < result.LowestBalance {
+			result.LowestBalance = day.Balance
+			result.LowestBalanceDate = date
+		}
+		result.Days = append(result.Days, day)
+	}
+
+	result.EndingBalance = float64(balance) / 100
+	return result
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func medianAbsoluteDeviation(values []float64, center float64) float64 {
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+	}
+	return median(deviations)
+}
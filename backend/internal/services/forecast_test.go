@@ -0,0 +1,182 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func forecastDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func forecastTransaction(id, accountID string, transactionType models.TransactionType, amount float64, description string, date time.Time) models.Transaction {
+	return models.Transaction{
+		ID:              id,
+		AccountID:       accountID,
+		TransactionType: transactionType,
+		Amount:          amount,
+		Description:     &description,
+		TransactionDate: date,
+	}
+}
+
+func TestScheduleOccurrence_ClampsToMonthEnd(t *testing.T) {
+	anchor := forecastDate(2026, 1, 31)
+
+	assert.Equal(t, forecastDate(2026, 2, 28), ScheduleOccurrence(anchor, models.ScheduleFrequencyMonthly, 1))
+	assert.Equal(t, forecastDate(2026, 3, 31), ScheduleOccurrence(anchor, models.ScheduleFrequencyMonthly, 2))
+	assert.Equal(t, forecastDate(2026, 4, 30), ScheduleOccurrence(anchor, models.ScheduleFrequencyQuarterly, 1))
+	assert.Equal(t, forecastDate(2027, 2, 28), ScheduleOccurrence(forecastDate(2024, 2, 29), models.ScheduleFrequencyYearly, 3))
+	assert.Equal(t, forecastDate(2026, 2, 14), ScheduleOccurrence(anchor, models.ScheduleFrequencyBiweekly, 1))
+}
+
+func TestScheduledOccurrences(t *testing.T) {
+	end := forecastDate(2026, 3, 20)
+	scheduled := models.ScheduledTransaction{
+		Frequency: models.ScheduleFrequencyWeekly,
+		NextDate:  forecastDate(2026, 2, 25),
+		EndDate:   &end,
+		IsActive:  true,
+	}
+
+	dates := ScheduledOccurrences(scheduled, forecastDate(2026, 3, 1), forecastDate(2026, 6, 1))
+	assert.Equal(t, []time.Time{
+		forecastDate(2026, 3, 4),
+		forecastDate(2026, 3, 11),
+		forecastDate(2026, 3, 18),
+	}, dates)
+
+	once := models.ScheduledTransaction{
+		Frequency: models.ScheduleFrequencyOnce,
+		NextDate:  forecastDate(2026, 4, 1),
+		IsActive:  true,
+	}
+	assert.Equal(t, []time.Time{forecastDate(2026, 4, 1)}, ScheduledOccurrences(once, forecastDate(2026, 3, 1), forecastDate(2026, 6, 1)))
+	assert.Empty(t, ScheduledOccurrences(once, forecastDate(2026, 4, 2), forecastDate(2026, 6, 1)))
+
+	once.IsActive = false
+	assert.Empty(t, ScheduledOccurrences(once, forecastDate(2026, 3, 1), forecastDate(2026, 6, 1)))
+}
+
+func TestDetectRecurring(t *testing.T) {
+	today := forecastDate(2026, 5, 10)
+	history := []models.Transaction{
+		// Monthly rent with one payment a few days late
+		forecastTransaction("r1", "chk", models.TransactionTypeExpense, 1200, "RENT PAYMENT", forecastDate(2026, 2, 1)),
+		forecastTransaction("r2", "chk", models.TransactionTypeExpense, 1200, "RENT PAYMENT", forecastDate(2026, 3, 4)),
+		forecastTransaction("r3", "chk", models.TransactionTypeExpense, 1200, "RENT PAYMENT", forecastDate(2026, 4, 1)),
+		forecastTransaction("r4", "chk", models.TransactionTypeExpense, 1200, "RENT PAYMENT", forecastDate(2026, 5, 1)),
+		// Biweekly pay with small variations
+		forecastTransaction("p1", "chk", models.TransactionTypeIncome, 2000, "ACME PAYROLL", forecastDate(2026, 4, 3)),
+		forecastTransaction("p2", "chk", models.TransactionTypeIncome, 2050, "ACME PAYROLL", forecastDate(2026, 4, 17)),
+		forecastTransaction("p3", "chk", models.TransactionTypeIncome, 2000, "ACME PAYROLL", forecastDate(2026, 5, 1)),
+		// Regular groceries with amounts too different to be recurring
+		forecastTransaction("g1", "chk", models.TransactionTypeExpense, 40, "GROCER", forecastDate(2026, 4, 12)),
+		forecastTransaction("g2", "chk", models.TransactionTypeExpense, 160, "GROCER", forecastDate(2026, 4, 19)),
+		forecastTransaction("g3", "chk", models.TransactionTypeExpense, 85, "GROCER", forecastDate(2026, 4, 26)),
+		forecastTransaction("g4", "chk", models.TransactionTypeExpense, 15, "GROCER", forecastDate(2026, 5, 3)),
+		// A subscription that was cancelled
+		forecastTransaction("s1", "chk", models.TransactionTypeExpense, 9.99, "STREAMCO", forecastDate(2025, 11, 5)),
+		forecastTransaction("s2", "chk", models.TransactionTypeExpense, 9.99, "STREAMCO", forecastDate(2025, 12, 5)),
+		forecastTransaction("s3", "chk", models.TransactionTypeExpense, 9.99, "STREAMCO", forecastDate(2026, 1, 5)),
+	}
+
+	patterns, members := DetectRecurring(history, today)
+
+	if assert.Len(t, patterns, 2) {
+		pay := patterns[0]
+		assert.Equal(t, "Acme Payroll", pay.Description)
+		assert.Equal(t, models.ScheduleFrequencyBiweekly, pay.Frequency)
+		assert.Equal(t, 2000.0, pay.Amount)
+		assert.Equal(t, forecastDate(2026, 5, 15), pay.NextDate)
+
+		rent := patterns[1]
+		assert.Equal(t, models.ScheduleFrequencyMonthly, rent.Frequency)
+		assert.Equal(t, -1200.0, rent.Amount)
+		assert.Equal(t, 4, rent.Occurrences)
+		assert.Equal(t, forecastDate(2026, 6, 1), rent.NextDate)
+	}
+	assert.True(t, members["r2"])
+	assert.True(t, members["p1"])
+	assert.False(t, members["g1"])
+	assert.False(t, members["s1"])
+}
+
+func TestDailyDiscretionarySpend(t *testing.T) {
+	today := forecastDate(2026, 5, 10)
+	account := models.Account{ID: "chk", CreatedAt: forecastDate(2025, 1, 1)}
+	history := []models.Transaction{
+		forecastTransaction("a", "chk", models.TransactionTypeExpense, 600, "Groceries", forecastDate(2026, 4, 1)),
+		forecastTransaction("b", "chk", models.TransactionTypeExpense, 300, "Dining", forecastDate(2026, 5, 1)),
+		forecastTransaction("rent", "chk", models.TransactionTypeExpense, 1200, "Rent", forecastDate(2026, 5, 1)),
+		forecastTransaction("old", "chk", models.TransactionTypeExpense, 5000, "Holiday", forecastDate(2025, 12, 1)),
+		forecastTransaction("pay", "chk", models.TransactionTypeIncome, 2000, "Pay", forecastDate(2026, 5, 1)),
+		forecastTransaction("other", "sav", models.TransactionTypeExpense, 999, "Other", forecastDate(2026, 5, 1)),
+	}
+	recurring := map[string]bool{"rent": true}
+
+	assert.Equal(t, 10.0, DailyDiscretionarySpend(account, history, recurring, today))
+
+	// A new account averages over its own history, but at least two weeks
+	account.CreatedAt = forecastDate(2026, 5, 5)
+	recent := []models.Transaction{
+		forecastTransaction("c", "chk", models.TransactionTypeExpense, 140, "Groceries", forecastDate(2026, 5, 6)),
+	}
+	assert.Equal(t, 10.0, DailyDiscretionarySpend(account, recent, nil, today))
+}
+
+func TestForecastCashFlow(t *testing.T) {
+	today := forecastDate(2026, 5, 10)
+	rentNote := "Rent"
+	history := []models.Transaction{
+		forecastTransaction("r1", "chk", models.TransactionTypeExpense, 1200, "RENT PAYMENT", forecastDate(2026, 3, 8)),
+		forecastTransaction("r2", "chk", models.TransactionTypeExpense, 1200, "RENT PAYMENT", forecastDate(2026, 4, 8)),
+		forecastTransaction("r3", "chk", models.TransactionTypeExpense, 1200, "RENT PAYMENT", forecastDate(2026, 5, 8)),
+		forecastTransaction("n1", "chk", models.TransactionTypeExpense, 12, "NETFLIX", forecastDate(2026, 3, 9)),
+		forecastTransaction("n2", "chk", models.TransactionTypeExpense, 12, "NETFLIX", forecastDate(2026, 4, 9)),
+		forecastTransaction("n3", "chk", models.TransactionTypeExpense, 12, "NETFLIX", forecastDate(2026, 5, 9)),
+	}
+	input := ForecastInput{
+		Accounts: []models.Account{
+			{ID: "chk", Name: "Checking", AccountType: models.AccountTypeChecking, Balance: 1000, CreatedAt: forecastDate(2025, 1, 1)},
+			{ID: "sav", Name: "Savings", AccountType: models.AccountTypeSavings, Balance: 100, CreatedAt: forecastDate(2025, 1, 1)},
+		},
+		History: history,
+		Scheduled: []models.ScheduledTransaction{
+			// Rent is scheduled explicitly, so the detected series is not counted again
+			{AccountID: "chk", TransactionType: models.TransactionTypeExpense, Amount: 1250, Description: &rentNote,
+				Frequency: models.ScheduleFrequencyMonthly, NextDate: forecastDate(2026, 6, 8), IsActive: true},
+			{AccountID: "sav", TransactionType: models.TransactionTypeExpense, Amount: 150,
+				Frequency: models.ScheduleFrequencyOnce, NextDate: forecastDate(2026, 5, 20), IsActive: true},
+		},
+		Today: today,
+		Days:  30,
+	}
+
+	forecast := ForecastCashFlow(input)
+
+	assert.Equal(t, forecastDate(2026, 5, 11), forecast.StartDate)
+	assert.Equal(t, forecastDate(2026, 6, 9), forecast.EndDate)
+	if assert.Len(t, forecast.RecurringPatterns, 1) {
+		assert.Equal(t, "Netflix", forecast.RecurringPatterns[0].Description)
+	}
+	assert.Len(t, forecast.Events, 3)
+	assert.Equal(t, []string{"chk"}, forecast.NegativeAccounts)
+
+	checking := forecast.Accounts[0]
+	assert.Len(t, checking.Days, 30)
+	assert.Zero(t, checking.DailyDiscretionary)
+	assert.Equal(t, 1000.0, checking.Days[27].Balance)
+	assert.Equal(t, forecastDate(2026, 6, 8), *checking.FirstNegativeDate)
+	assert.Equal(t, 2, checking.NegativeDays)
+	assert.Equal(t, -262.0, checking.EndingBalance)
+	assert.Equal(t, -262.0, checking.LowestBalance)
+
+	savings := forecast.Accounts[1]
+	assert.Nil(t, savings.FirstNegativeDate)
+	assert.False(t, savings.Days[9].IsNegative)
+	assert.Equal(t, -50.0, savings.EndingBalance)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runDaily calls run right away and then every day at the given UTC time
+// until the context is cancelled.
+func runDaily(ctx context.Context, hour, minute int, run func(now time.Time)) {
+	for {
+		now := time.Now().UTC()
+		run(now)
+		if ctx.Err() != nil {
+			return
+		}
+
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, time.UTC)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// ChangeListener delivers the ID of each user whose data changes, blocking
+// until the context is cancelled or it fails
+type ChangeListener func(ctx context.Context, onChange func(userID string)) error
+
+// followChanges runs listen until the context is cancelled, restarting it
+// with backoff when it fails. Changes may be missed while it is down, so
+// onRestart is called before each restart.
+func followChanges(ctx context.Context, name string, listen ChangeListener, onChange func(userID string), onRestart func()) {
+	retry := time.Second
+	for {
+		started := time.Now()
+		err := listen(ctx, onChange)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Warning: %s stopped: %v", name, err)
+
+		if time.Since(started) > time.Minute {
+			retry = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry < time.Minute {
+			retry *= 2
+		}
+		onRestart()
+	}
+}
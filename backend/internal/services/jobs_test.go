@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunDaily_RunsOnceThenStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		runDaily(ctx, 0, 5, func(now time.Time) {
+			runs++
+			cancel()
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runDaily did not return after cancellation")
+	}
+	assert.Equal(t, 1, runs)
+}
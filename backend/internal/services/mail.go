@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/config"
+)
+
+// smtpTimeout bounds a whole SMTP session when the context has no earlier
+// deadline
+const smtpTimeout = 30 * time.Second
+
+// Mail is an email to a single recipient. HTML is optional; when set, the
+// message carries both versions.
+type Mail struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// MailSender sends email. Errors that retrying cannot fix, such as a
+// rejected recipient, are permanent.
+type MailSender interface {
+	SendMail(ctx context.Context, mail Mail) error
+}
+
+// SMTPMailer is a MailSender on an SMTP server. It uses implicit TLS on port
+// 465 and STARTTLS elsewhere when the server offers it, and authenticates
+// when a username is set.
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer creates a mailer sending from the given address through
+// host:port
+func NewSMTPMailer(host, port, username, password, from string) (*SMTPMailer, error) {
+	if host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	if _, err := netmail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+	return &SMTPMailer{host: host, port: port, username: username, password: password, from: from}, nil
+}
+
+// NewMailSenderFromConfig creates the configured mail sender, or returns nil
+// when email is not set up.
+func NewMailSenderFromConfig(cfg *config.Config) (MailSender, error) {
+	if cfg.SMTPHost == "" {
+		return nil, nil
+	}
+	mailer, err := NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	if err != nil {
+		return nil, err
+	}
+	return mailer, nil
+}
+
+// SendMail sends the mail in a new SMTP session
+func (m *SMTPMailer) SendMail(ctx context.Context, mail Mail) error {
+	from, _ := netmail.ParseAddress(m.from)
+	to, err := netmail.ParseAddress(mail.To)
+	if err != nil {
+		return permanent(fmt.Errorf("invalid recipient address %q: %w", mail.To, err))
+	}
+	message, err := buildMessage(from, to, mail, time.Now())
+	if err != nil {
+		return permanent(err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > smtpTimeout {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+	addr := net.JoinHostPort(m.host, m.port)
+	var conn net.Conn
+	if m.port == "465" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: m.host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return smtpError("greeting", err)
+	}
+	defer client.Close()
+
+	if m.port != "465" {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+				return smtpError("STARTTLS", err)
+			}
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return smtpError("AUTH", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return smtpError("MAIL FROM", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return smtpError("RCPT TO", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return smtpError("DATA", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return smtpError("DATA", err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpError("DATA", err)
+	}
+	return client.Quit()
+}
+
+// smtpError describes a failed SMTP command. Rejections with a 5xx reply are
+// permanent.
+func smtpError(command string, err error) error {
+	err = fmt.Errorf("SMTP %s failed: %w", command, err)
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return permanent(err)
+	}
+	return err
+}
+
+// buildMessage renders the mail as a MIME message: plain text alone, or
+// multipart/alternative with the HTML version last as the preferred one.
+func buildMessage(from, to *netmail.Address, mail Mail, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", mail.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	header("MIME-Version", "1.0")
+
+	if mail.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, mail.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", mail.Text},
+		{"text/html; charset=utf-8", mail.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable writes body quoted-printable encoded with CRLF line
+// endings
+func writeQuotedPrintable(w io.Writer, body string) error {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID returns a unique Message-ID in the sender's domain
+func messageID(from *netmail.Address) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
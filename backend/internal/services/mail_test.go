@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSMTPMessage is a message received by the SMTP stand-in
+type testSMTPMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// testSMTPServer is a local SMTP stand-in speaking just enough of the
+// protocol for SMTPMailer. It rejects recipients in reject with a 550.
+type testSMTPServer struct {
+	listener net.Listener
+	reject   map[string]bool
+
+	mu       sync.Mutex
+	messages []testSMTPMessage
+}
+
+func startTestSMTPServer(t *testing.T, reject ...string) *testSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testSMTPServer{listener: listener, reject: make(map[string]bool)}
+	for _, address := range reject {
+		s.reject[address] = true
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+// mailer returns an SMTPMailer sending through the stand-in
+func (s *testSMTPServer) mailer(t *testing.T) *SMTPMailer {
+	host, port, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailer, err := NewSMTPMailer(host, port, "", "", "Personal Finance <alerts@example.com>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mailer
+}
+
+func (s *testSMTPServer) received() []testSMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]testSMTPMessage(nil), s.messages...)
+}
+
+func (s *testSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP test")
+	var msg testSMTPMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			msg = testSMTPMessage{From: strings.Trim(strings.Fields(line[len("MAIL FROM:"):])[0], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			to := strings.Trim(line[len("RCPT TO:"):], "<> ")
+			if s.reject[to] {
+				reply("550 No such user")
+				continue
+			}
+			msg.To = append(msg.To, to)
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// readTestMessage parses a received message, returning its headers and its
+// decoded body parts by content type
+func readTestMessage(t *testing.T, data string) (netmail.Header, map[string]string) {
+	t.Helper()
+	msg, err := netmail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := make(map[string]string)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[mediaType] = string(body)
+		return msg.Header, parts
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		assert.Equal(t, "quoted-printable", part.Header.Get("Content-Transfer-Encoding"))
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[partType] = string(body)
+	}
+	return msg.Header, parts
+}
+
+func TestSMTPMailer_SendMail(t *testing.T) {
+	server := startTestSMTPServer(t)
+	mailer := server.mailer(t)
+
+	err := mailer.SendMail(context.Background(), Mail{
+		To:      "Jo Doe <jo@example.com>",
+		Subject: "Budget alert: Café €50",
+		Text:    "You have used 80% of your budget.\nKeep an eye on it.",
+		HTML:    "<p>You have used <b>80%</b> of your budget.</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := server.received()
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	assert.Equal(t, "alerts@example.com", messages[0].From)
+	assert.Equal(t, []string{"jo@example.com"}, messages[0].To)
+
+	header, parts := readTestMessage(t, messages[0].Data)
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Budget alert: Café €50", subject)
+	assert.Equal(t, `"Jo Doe" <jo@example.com>`, header.Get("To"))
+	assert.Contains(t, header.Get("Message-ID"), "@example.com>")
+	assert.Equal(t, "You have used 80% of your budget.\r\nKeep an eye on it.", parts["text/plain"])
+	assert.Equal(t, "<p>You have used <b>80%</b> of your budget.</p>", parts["text/html"])
+}
+
+func TestSMTPMailer_SendMail_TextOnly(t *testing.T) {
+	server := startTestSMTPServer(t)
+
+	err := server.mailer(t).SendMail(context.Background(), Mail{
+		To:      "jo@example.com",
+		Subject: "Hello",
+		Text:    "Plain text only",
+	})
+	assert.NoError(t, err)
+
+	messages := server.received()
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	_, parts := readTestMessage(t, messages[0].Data)
+	assert.Equal(t, map[string]string{"text/plain": "Plain text only\r\n"}, parts)
+}
+
+func TestSMTPMailer_SendMail_Errors(t *testing.T) {
+	server := startTestSMTPServer(t, "gone@example.com")
+	mailer := server.mailer(t)
+	ctx := context.Background()
+
+	// A rejected recipient is permanent
+	err := mailer.SendMail(ctx, Mail{To: "gone@example.com", Subject: "Hi", Text: "Hi"})
+	assert.Error(t, err)
+	assert.True(t, IsPermanent(err))
+
+	// So is an address that cannot be parsed, which is never sent
+	err = mailer.SendMail(ctx, Mail{To: "not an address", Subject: "Hi", Text: "Hi"})
+	assert.True(t, IsPermanent(err))
+	assert.Empty(t, server.received())
+
+	// A server that cannot be reached is worth retrying
+	addr := server.listener.Addr().String()
+	server.listener.Close()
+	host, port, _ := net.SplitHostPort(addr)
+	down, err := NewSMTPMailer(host, port, "", "", "alerts@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = down.SendMail(ctx, Mail{To: "jo@example.com", Subject: "Hi", Text: "Hi"})
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+}
+
+func TestNewSMTPMailer_Validation(t *testing.T) {
+	_, err := NewSMTPMailer("", "587", "", "", "alerts@example.com")
+	assert.Error(t, err)
+	_, err = NewSMTPMailer("smtp.example.com", "587", "", "", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// Merchant match sources, from most to least specific
+const (
+	MerchantSourceAlias   = "alias"
+	MerchantSourceLibrary = "library"
+	MerchantSourceCleaned = "cleaned"
+)
+
+// MerchantMatch is the canonical merchant resolved from a raw description.
+// MerchantID is only set when a user alias pointed at an existing merchant.
+type MerchantMatch struct {
+	Name       string  `json:"name"`
+	MerchantID *string `json:"merchant_id,omitempty"`
+	Source     string  `json:"source"`
+}
+
+type merchantPattern struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// merchantLibrary maps common bank description fragments to canonical
+// merchant names. More specific entries come first (Uber Eats before Uber).
+var merchantLibrary = []merchantPattern{
+	{"Amazon Prime", regexp.MustCompile(`(?i)\b(amzn|amazon)\s*prime\b`)},
+	{"Amazon", regexp.MustCompile(`(?i)\b(amzn|amazon)(\s*(mktp|mktplace|marketplace|\.com|com|digital|retail))?\b`)},
+	{"Whole Foods", regexp.MustCompile(`(?i)\bwhole\s*(foods|fds)\b|\bwholefds\b`)},
+	{"Uber Eats", regexp.MustCompile(`(?i)\buber\s*\*?\s*eats\b`)},
+	{"Uber", regexp.MustCompile(`(?i)\buber\b`)},
+	{"Lyft", regexp.MustCompile(`(?i)\blyft\b`)},
+	{"DoorDash", regexp.MustCompile(`(?i)\bdoordash\b|\bdd\s*\*`)},
+	{"Grubhub", regexp.MustCompile(`(?i)\bgrubhub\b`)},
+	{"Starbucks", regexp.MustCompile(`(?i)\bstarbucks\b|\bsbux\b`)},
+	{"McDonald's", regexp.MustCompile(`(?i)\bmc\s*donald'?s\b`)},
+	{"Chipotle", regexp.MustCompile(`(?i)\bchipotle\b`)},
+	{"Netflix", regexp.MustCompile(`(?i)\bnetflix\b`)},
+	{"Spotify", regexp.MustCompile(`(?i)\bspotify\b`)},
+	{"Hulu", regexp.MustCompile(`(?i)\bhulu\b`)},
+	{"Disney+", regexp.MustCompile(`(?i)\bdisney\s*(plus|\+)`)},
+	{"Apple", regexp.MustCompile(`(?i)\bapple\.com\b|\bitunes\b|\bapple\s+(store|services)\b`)},
+	{"Google", regexp.MustCompile(`(?i)\bgoogle\s*\*|\bgoogle\s+(play|storage|cloud)\b`)},
+	{"Microsoft", regexp.MustCompile(`(?i)\bmicrosoft\b|\bmsft\s*\*`)},
+	{"Walmart", regexp.MustCompile(`(?i)\bwal-?mart\b|\bwm\s+supercenter\b`)},
+	{"Target", regexp.MustCompile(`(?i)\btarget\b`)},
+	{"Costco", regexp.MustCompile(`(?i)\bcostco\b`)},
+	{"Trader Joe's", regexp.MustCompile(`(?i)\btrader\s*joe'?s?\b`)},
+	{"Kroger", regexp.MustCompile(`(?i)\bkroger\b`)},
+	{"Safeway", regexp.MustCompile(`(?i)\bsafeway\b`)},
+	{"The Home Depot", regexp.MustCompile(`(?i)\bhome\s*depot\b`)},
+	{"Lowe's", regexp.MustCompile(`(?i)\blowe'?s\b`)},
+	{"IKEA", regexp.MustCompile(`(?i)\bikea\b`)},
+	{"Best Buy", regexp.MustCompile(`(?i)\bbest\s*buy\b`)},
+	{"CVS Pharmacy", regexp.MustCompile(`(?i)\bcvs\b`)},
+	{"Walgreens", regexp.MustCompile(`(?i)\bwalgreens?\b`)},
+	{"Shell", regexp.MustCompile(`(?i)\bshell\s+(oil|service)\b|^shell\b`)},
+	{"Chevron", regexp.MustCompile(`(?i)\bchevron\b`)},
+	{"ExxonMobil", regexp.MustCompile(`(?i)\bexxon(\s*mobil)?\b|\bmobil\b`)},
+	{"BP", regexp.MustCompile(`(?i)^bp\b|\bbp\s*#`)},
+	{"Airbnb", regexp.MustCompile(`(?i)\bairbnb\b`)},
+	{"Delta Air Lines", regexp.MustCompile(`(?i)\bdelta\s+air\b`)},
+	{"United Airlines", regexp.MustCompile(`(?i)\bunited\s+(air|airlines)\b`)},
+	{"Comcast Xfinity", regexp.MustCompile(`(?i)\bcomcast\b|\bxfinity\b`)},
+	{"Verizon", regexp.MustCompile(`(?i)\bverizon\b|\bvzwrlss\b`)},
+	{"AT&T", regexp.MustCompile(`(?i)\bat\s*&\s*t\b|\batt\s*\*`)},
+	{"Venmo", regexp.MustCompile(`(?i)\bvenmo\b`)},
+}
+
+// Prefixes banks and payment processors put in front of the merchant name
+var descriptionNoisePrefix = regexp.MustCompile(`(?i)^(((pos|debit|credit|card|checkcard|check card|purchase|recurring|payment|ach|visa|mc|pre-?auth|authorized|withdrawal|online|web|pmt)\b|(paypal|sq|tst|sp)\s*\*)[\s*:#-]*)+`)
+
+// Store numbers, reference codes, dates and long digit runs
+var descriptionNoiseToken = regexp.MustCompile(`(?i)(\*\s*\S+|#\s*\S+|\b\d{1,2}/\d{1,2}(/\d{2,4})?\b|\b\w*\d{3,}\w*\b|\bx{2,}\d*\b)`)
+
+// Trailing two-letter state or country codes left after a city name
+var descriptionTrailingRegion = regexp.MustCompile(`(?i)\s+(us|usa|[a-z]{2})$`)
+
+// MerchantAliasMatcher is a compiled user alias.
+type MerchantAliasMatcher struct {
+	alias   models.MerchantAlias
+	pattern *regexp.Regexp
+}
+
+// MerchantNormalizer resolves raw descriptions into canonical merchants
+// using the user's aliases first and the built-in library second.
+type MerchantNormalizer struct {
+	aliases []MerchantAliasMatcher
+}
+
+// NewMerchantNormalizer compiles the user's aliases. Aliases whose pattern
+// fails to compile are skipped.
+func NewMerchantNormalizer(aliases []models.MerchantAlias) *MerchantNormalizer {
+	n := &MerchantNormalizer{}
+	for _, alias := range aliases {
+		pattern, err := CompileMerchantAlias(alias)
+		if err != nil {
+			continue
+		}
+		n.aliases = append(n.aliases, MerchantAliasMatcher{alias: alias, pattern: pattern})
+	}
+	return n
+}
+
+// CompileMerchantAlias turns an alias into a case-insensitive matcher.
+// "contains" and "exact" aliases match literally; "regex" aliases are
+// used as written.
+func CompileMerchantAlias(alias models.MerchantAlias) (*regexp.Regexp, error) {
+	switch alias.MatchType {
+	case models.MerchantMatchExact:
+		return regexp.Compile(`(?i)^\s*` + regexp.QuoteMeta(strings.TrimSpace(alias.Pattern)) + `\s*$`)
+	case models.MerchantMatchRegex:
+		return regexp.Compile(`(?i)` + alias.Pattern)
+	default:
+		return regexp.Compile(`(?i)` + regexp.QuoteMeta(strings.TrimSpace(alias.Pattern)))
+	}
+}
+
+// Normalize returns the canonical merchant for a description, or nil when
+// nothing recognizable is left after cleaning.
+func (n *MerchantNormalizer) Normalize(description string) *MerchantMatch {
+	if strings.TrimSpace(description) == "" {
+		return nil
+	}
+
+	for _, matcher := range n.aliases {
+		if matcher.pattern.MatchString(description) {
+			merchantID := matcher.alias.MerchantID
+			return &MerchantMatch{
+				Name:       matcher.alias.MerchantName,
+				MerchantID: &merchantID,
+				Source:     MerchantSourceAlias,
+			}
+		}
+	}
+
+	cleaned := CleanMerchantDescription(description)
+
+	for _, entry := range merchantLibrary {
+		if entry.pattern.MatchString(description) || entry.pattern.MatchString(cleaned) {
+			return &MerchantMatch{Name: entry.name, Source: MerchantSourceLibrary}
+		}
+	}
+
+	if cleaned == "" {
+		return nil
+	}
+	return &MerchantMatch{Name: cleaned, Source: MerchantSourceCleaned}
+}
+
+// CleanMerchantDescription strips processor prefixes, store numbers,
+// reference codes and dates from a bank description and title-cases what
+// remains, e.g. "POS 1234 JOES PIZZA #22 BROOKLYN NY" becomes
+// "Joes Pizza Brooklyn".
+func CleanMerchantDescription(description string) string {
+	cleaned := strings.TrimSpace(description)
+	cleaned = descriptionNoisePrefix.ReplaceAllString(cleaned, "")
+	cleaned = descriptionNoiseToken.ReplaceAllString(cleaned, " ")
+	cleaned = descriptionNoisePrefix.ReplaceAllString(strings.TrimSpace(cleaned), "")
+	cleaned = strings.Join(strings.FieldsFunc(cleaned, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '*' || r == '#' || r == ','
+	}), " ")
+
+	// Only strip a region code when there is still a name in front of it
+	if strings.Count(cleaned, " ") >= 2 {
+		cleaned = descriptionTrailingRegion.ReplaceAllString(cleaned, "")
+	}
+
+	return titleCase(strings.Trim(cleaned, " -.:"))
+}
+
+// MerchantKey is the case- and punctuation-insensitive key under which
+// merchants are deduplicated.
+func MerchantKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func titleCase(s string) string {
+	words := strings.Fields(strings.ToLower(s))
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
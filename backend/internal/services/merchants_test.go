@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanMerchantDescription(t *testing.T) {
+	cases := map[string]string{
+		"POS 1234 JOES PIZZA #22 BROOKLYN NY": "Joes Pizza Brooklyn",
+		"SQ *BLUE BOTTLE COFFEE":              "Blue Bottle Coffee",
+		"CHECKCARD 0412 CORNER DELI 12/04":    "Corner Deli",
+		"TST* THE GRILL HOUSE 000123":         "The Grill House",
+		"Local Bakery":                        "Local Bakery",
+		"1234567":                             "",
+	}
+
+	for raw, want := range cases {
+		assert.Equal(t, want, CleanMerchantDescription(raw), raw)
+	}
+}
+
+func TestMerchantNormalizer_Library(t *testing.T) {
+	n := NewMerchantNormalizer(nil)
+
+	cases := map[string]string{
+		"POS 1234 AMZN MKTP US*2K4": "Amazon",
+		"Amazon.com*MK1LS0":         "Amazon",
+		"AMAZON PRIME*2K3L":         "Amazon Prime",
+		"UBER *EATS PENDING":        "Uber Eats",
+		"UBER *TRIP HELP.UBER.COM":  "Uber",
+		"PAYPAL *NETFLIX.COM":       "Netflix",
+		"STARBUCKS STORE 00123":     "Starbucks",
+		"WHOLEFDS MKT 10234":        "Whole Foods",
+		"WM SUPERCENTER #1234":      "Walmart",
+		"SHELL OIL 57442650600":     "Shell",
+	}
+
+	for raw, want := range cases {
+		match := n.Normalize(raw)
+		if assert.NotNil(t, match, raw) {
+			assert.Equal(t, want, match.Name, raw)
+			assert.Equal(t, MerchantSourceLibrary, match.Source, raw)
+		}
+	}
+}
+
+func TestMerchantNormalizer_AliasesWin(t *testing.T) {
+	aliases := []models.MerchantAlias{
+		{MerchantID: "m-gym", MerchantName: "City Gym", Pattern: "CTY GYM", MatchType: models.MerchantMatchContains},
+		{MerchantID: "m-amzn", MerchantName: "Amazon Business", Pattern: `amzn\s+biz`, MatchType: models.MerchantMatchRegex},
+		{MerchantID: "m-bad", MerchantName: "Broken", Pattern: "(", MatchType: models.MerchantMatchRegex},
+	}
+	n := NewMerchantNormalizer(aliases)
+
+	match := n.Normalize("ACH CTY GYM MEMBERSHIP")
+	assert.Equal(t, "City Gym", match.Name)
+	assert.Equal(t, "m-gym", *match.MerchantID)
+	assert.Equal(t, MerchantSourceAlias, match.Source)
+
+	assert.Equal(t, "Amazon Business", n.Normalize("AMZN BIZ 9981").Name)
+	assert.Equal(t, "Amazon", n.Normalize("AMZN MKTP").Name)
+}
+
+func TestMerchantNormalizer_FallsBackToCleaned(t *testing.T) {
+	n := NewMerchantNormalizer(nil)
+
+	match := n.Normalize("DEBIT CARD PURCHASE MAPLE STREET BOOKS")
+	assert.Equal(t, "Maple Street Books", match.Name)
+	assert.Equal(t, MerchantSourceCleaned, match.Source)
+
+	assert.Nil(t, n.Normalize("   "))
+	assert.Nil(t, n.Normalize("#00012345"))
+}
+
+func TestMerchantKey(t *testing.T) {
+	assert.Equal(t, "mcdonalds", MerchantKey("McDonald's"))
+	assert.Equal(t, MerchantKey("Trader Joe's"), MerchantKey("TRADER JOES"))
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// IsLiabilityAccount reports whether balances of the account type are owed
+// rather than owned.
+func IsLiabilityAccount(accountType models.AccountType) bool {
+	return accountType == models.AccountTypeCreditCard || accountType == models.AccountTypeLoan
+}
+
+// owedCents is what a liability account with the given balance owes, in
+// cents. Card balances move with their transactions, so spending makes them
+// negative and a positive balance (after an overpayment or refund) is owed
+// to the user, lowering the liabilities. Loans are recorded as the principal
+// outstanding, so their balance is what is owed.
+func owedCents(accountType models.AccountType, balance float64) int64 {
+	if accountType == models.AccountTypeLoan {
+		return toCents(balance)
+	}
+	return -toCents(balance)
+}
+
+// ComputeNetWorth totals the accounts in the base currency. rates maps each
+// other currency to the rate converting it into the base currency; accounts
+// in a currency without a rate are left out and reported.
+//
+// Liability accounts count what they owe by owedCents, so a credit card at
+// -500 and a loan at 500 both owe 500. Asset balances count as they are, so
+// an overdrawn checking account lowers the assets.
+func ComputeNetWorth(accounts []models.Account, baseCurrency string, rates map[string]float64) *models.NetWorthSnapshot {
+	snapshot := &models.NetWorthSnapshot{BaseCurrency: baseCurrency}
+
+	byType := make(map[models.AccountType]*models.NetWorthBreakdownItem)
+	unconverted := make(map[string]bool)
+	var assets, liabilities int64
+
+	for _, account := range accounts {
+		balance := account.Balance
+		if account.Currency != "" && account.Currency != baseCurrency {
+			rate, ok := rates[account.Currency]
+			if !ok {
+				unconverted[account.Currency] = true
+				continue
+			}
+			balance = ConvertAmount(balance, rate)
+		}
+
+		item, ok := byType[account.AccountType]
+		if !ok {
+			item = &models.NetWorthBreakdownItem{
+				AccountType: account.AccountType,
+				IsLiability: IsLiabilityAccount(account.AccountType),
+			}
+			byType[account.AccountType] = item
+		}
+		item.AccountCount++
+
+		if item.IsLiability {
+			owed := owedCents(account.AccountType, balance)
+			liabilities += owed
+			item.Amount = float64(toCents(item.Amount)+owed) / 100
+		} else {
+			assets += toCents(balance)
+			item.Amount = float64(toCents(item.Amount)+toCents(balance)) / 100
+		}
+	}
+
+	for _, item := range byType {
+		snapshot.Breakdown = append(snapshot.Breakdown, *item)
+	}
+	sort.Slice(snapshot.Breakdown, func(i, j int) bool {
+		a, b := snapshot.Breakdown[i], snapshot.Breakdown[j]
+		if a.IsLiability != b.IsLiability {
+			return !a.IsLiability
+		}
+		return a.AccountType < b.AccountType
+	})
+
+	for currency := range unconverted {
+		snapshot.UnconvertedCurrencies = append(snapshot.UnconvertedCurrencies, currency)
+	}
+	sort.Strings(snapshot.UnconvertedCurrencies)
+
+	snapshot.TotalAssets = float64(assets) / 100
+	snapshot.TotalLiabilities = float64(liabilities) / 100
+	snapshot.NetWorth = float64(assets-liabilities) / 100
+	return snapshot
+}
+
+// NetWorthChange returns the change from the first to the last snapshot and
+// the percentage change relative to the magnitude of the first, which is nil
+// when the first net worth is zero.
+func NetWorthChange(snapshots []models.NetWorthSnapshot) (float64, *float64) {
+	if len(snapshots) < 2 {
+		return 0, nil
+	}
+
+	first := snapshots[0].NetWorth
+	change := roundTo2(snapshots[len(snapshots)-1].NetWorth - first)
+	if first == 0 {
+		return change, nil
+	}
+	percent := roundTo2(change / math.Abs(first) * 100)
+	return change, &percent
+}
+
+// NetWorthStore is the data access the net worth service needs.
+type NetWorthStore interface {
+	repositories.NetWorthRepository
+	GetLatestExchangeRate(ctx context.Context, baseCurrency, targetCurrency string) (float64, error)
+}
+
+// NetWorthService computes net worth from live balances and records the
+// daily snapshots.
+type NetWorthService struct {
+	store NetWorthStore
+}
+
+// NewNetWorthService creates a net worth service
+func NewNetWorthService(store NetWorthStore) *NetWorthService {
+	return &NetWorthService{store: store}
+}
+
+// Current computes the user's net worth from their account balances now.
+func (s *NetWorthService) Current(ctx context.Context, userID string, today time.Time) (*models.NetWorthSnapshot, error) {
+	accounts, err := s.store.GetActiveAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	baseCurrency, err := s.store.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := ComputeNetWorth(accounts, baseCurrency, accountRates(ctx, s.store, accounts, baseCurrency))
+	snapshot.UserID = userID
+	snapshot.SnapshotDate = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	return snapshot, nil
+}
+
+// exchangeRateSource looks up the latest stored exchange rates
+type exchangeRateSource interface {
+	GetLatestExchangeRate(ctx context.Context, baseCurrency, targetCurrency string) (float64, error)
+}
+
+// accountRates finds the rate converting each account currency into the base
+// currency, trying the inverse of the opposite rate when there is no direct
+// one. Currencies without either are missing from the map.
+func accountRates(ctx context.Context, source exchangeRateSource, accounts []models.Account, baseCurrency string) map[string]float64 {
+	rates := make(map[string]float64)
+	tried := make(map[string]bool)
+	for _, account := range accounts {
+		currency := account.Currency
+		if currency == "" || currency == baseCurrency || tried[currency] {
+			continue
+		}
+		tried[currency] = true
+
+		if rate, err := source.GetLatestExchangeRate(ctx, currency, baseCurrency); err == nil && rate > 0 {
+			rates[currency] = rate
+		} else if rate, err := source.GetLatestExchangeRate(ctx, baseCurrency, currency); err == nil && rate > 0 {
+			rates[currency] = 1 / rate
+		}
+	}
+	return rates
+}
+
+// Snapshot computes and stores today's snapshot for the user.
+func (s *NetWorthService) Snapshot(ctx context.Context, userID string, today time.Time) (*models.NetWorthSnapshot, error) {
+	snapshot, err := s.Current(ctx, userID, today)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.UpsertNetWorthSnapshot(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// SnapshotAll stores today's snapshot for every user with an active
+// account. A failure for one user does not stop the others; it returns how
+// many snapshots were written.
+func (s *NetWorthService) SnapshotAll(ctx context.Context, today time.Time) (int, error) {
+	userIDs, err := s.store.GetUserIDsWithActiveAccounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		if _, err := s.Snapshot(ctx, userID, today); err != nil {
+			log.Printf("Warning: Failed to record net worth snapshot for user %s: %v", userID, err)
+			continue
+		}
+		written++
+	}
+
+	if written < len(userIDs) {
+		return written, fmt.Errorf("%d of %d net worth snapshots failed", len(userIDs)-written, len(userIDs))
+	}
+	return written, nil
+}
+
+// RunDailySnapshots records snapshots for all users right away and then
+// shortly after every midnight UTC until the context is cancelled. Running
+// it again on the same day overwrites that day's snapshots.
+func (s *NetWorthService) RunDailySnapshots(ctx context.Context) {
+	runDaily(ctx, 0, 5, func(now time.Time) {
+		written, err := s.SnapshotAll(ctx, now)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Warning: Net worth snapshot run incomplete: %v", err)
+		}
+		log.Printf("Recorded %d net worth snapshots for %s", written, now.Format("2006-01-02"))
+	})
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeNetWorth(t *testing.T) {
+	accounts := []models.Account{
+		{AccountType: models.AccountTypeChecking, Balance: 2500, Currency: "USD"},
+		{AccountType: models.AccountTypeChecking, Balance: -100, Currency: "USD"},
+		{AccountType: models.AccountTypeSavings, Balance: 1000, Currency: "EUR"},
+		{AccountType: models.AccountTypeCreditCard, Balance: -450.25, Currency: "USD"},
+		{AccountType: models.AccountTypeLoan, Balance: 5000, Currency: "USD"},
+		{AccountType: models.AccountTypeInvestment, Balance: 900, Currency: "JPY"},
+	}
+
+	snapshot := ComputeNetWorth(accounts, "USD", map[string]float64{"EUR": 1.1})
+
+	assert.Equal(t, 3500.0, snapshot.TotalAssets)
+	assert.Equal(t, 5450.25, snapshot.TotalLiabilities)
+	assert.Equal(t, -1950.25, snapshot.NetWorth)
+	assert.Equal(t, []string{"JPY"}, snapshot.UnconvertedCurrencies)
+	assert.Equal(t, []models.NetWorthBreakdownItem{
+		{AccountType: models.AccountTypeChecking, Amount: 2400, AccountCount: 2},
+		{AccountType: models.AccountTypeSavings, Amount: 1100, AccountCount: 1},
+		{AccountType: models.AccountTypeCreditCard, IsLiability: true, Amount: 450.25, AccountCount: 1},
+		{AccountType: models.AccountTypeLoan, IsLiability: true, Amount: 5000, AccountCount: 1},
+	}, snapshot.Breakdown)
+}
+
+func TestComputeNetWorth_LiabilitySigns(t *testing.T) {
+	accounts := []models.Account{
+		{AccountType: models.AccountTypeChecking, Balance: 1000, Currency: "USD"},
+		// An overpaid card is owed to the user
+		{AccountType: models.AccountTypeCreditCard, Balance: 75, Currency: "USD"},
+		{AccountType: models.AccountTypeCreditCard, Balance: -300, Currency: "USD"},
+		// Loans are recorded as the principal outstanding
+		{AccountType: models.AccountTypeLoan, Balance: 2000, Currency: "USD"},
+	}
+
+	snapshot := ComputeNetWorth(accounts, "USD", nil)
+
+	assert.Equal(t, 1000.0, snapshot.TotalAssets)
+	assert.Equal(t, 2225.0, snapshot.TotalLiabilities)
+	assert.Equal(t, -1225.0, snapshot.NetWorth)
+	assert.Equal(t, []models.NetWorthBreakdownItem{
+		{AccountType: models.AccountTypeChecking, Amount: 1000, AccountCount: 1},
+		{AccountType: models.AccountTypeCreditCard, IsLiability: true, Amount: 225, AccountCount: 2},
+		{AccountType: models.AccountTypeLoan, IsLiability: true, Amount: 2000, AccountCount: 1},
+	}, snapshot.Breakdown)
+}
+
+func TestNetWorthChange(t *testing.T) {
+	change, percent := NetWorthChange([]models.NetWorthSnapshot{{NetWorth: -1000}, {NetWorth: 0}, {NetWorth: 500}})
+	assert.Equal(t, 1500.0, change)
+	assert.Equal(t, 150.0, *percent)
+
+	change, percent = NetWorthChange([]models.NetWorthSnapshot{{NetWorth: 0}, {NetWorth: 10}})
+	assert.Equal(t, 10.0, change)
+	assert.Nil(t, percent)
+
+	change, percent = NetWorthChange(nil)
+	assert.Zero(t, change)
+	assert.Nil(t, percent)
+}
+
+type fakeNetWorthStore struct {
+	accounts  map[string][]models.Account
+	rates     map[string]float64 // "FROM/TO" -> rate
+	snapshots []models.NetWorthSnapshot
+}
+
+func (f *fakeNetWorthStore) GetActiveAccountsByUserID(ctx context.Context, userID string) ([]models.Account, error) {
+	if userID == "broken" {
+		return nil, fmt.Errorf("boom")
+	}
+	return f.accounts[userID], nil
+}
+
+func (f *fakeNetWorthStore) GetBaseCurrency(ctx context.Context, userID string) (string, error) {
+	return "USD", nil
+}
+
+func (f *fakeNetWorthStore) GetUserIDsWithActiveAccounts(ctx context.Context) ([]string, error) {
+	return []string{"u-1", "broken"}, nil
+}
+
+func (f *fakeNetWorthStore) UpsertNetWorthSnapshot(ctx context.Context, snapshot *models.NetWorthSnapshot) error {
+	f.snapshots = append(f.snapshots, *snapshot)
+	return nil
+}
+
+func (f *fakeNetWorthStore) GetNetWorthSnapshots(ctx context.Context, userID string, from, to time.Time) ([]models.NetWorthSnapshot, error) {
+	return f.snapshots, nil
+}
+
+func (f *fakeNetWorthStore) GetLatestExchangeRate(ctx context.Context, from, to string) (float64, error) {
+	if rate, ok := f.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+	return 0, fmt.Errorf("no rate")
+}
+
+func TestNetWorthService_SnapshotAll(t *testing.T) {
+	store := &fakeNetWorthStore{
+		accounts: map[string][]models.Account{
+			"u-1": {
+				{AccountType: models.AccountTypeChecking, Balance: 100, Currency: "USD"},
+				{AccountType: models.AccountTypeSavings, Balance: 100, Currency: "GBP"},
+			},
+		},
+		// Only the USD to GBP rate is known, so GBP converts through its inverse
+		rates: map[string]float64{"USD/GBP": 0.8},
+	}
+	service := NewNetWorthService(store)
+
+	written, err := service.SnapshotAll(context.Background(), time.Date(2026, 5, 1, 23, 30, 0, 0, time.UTC))
+	assert.Error(t, err)
+	assert.Equal(t, 1, written)
+
+	if assert.Len(t, store.snapshots, 1) {
+		snapshot := store.snapshots[0]
+		assert.Equal(t, "u-1", snapshot.UserID)
+		assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), snapshot.SnapshotDate)
+		assert.Equal(t, 225.0, snapshot.NetWorth)
+		assert.Empty(t, snapshot.UnconvertedCurrencies)
+	}
+}
@@ -0,0 +1,552 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	netmail "net/mail"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+const (
+	// maxDeliveryAttempts is how many times a delivery is tried before it is
+	// given up on
+	maxDeliveryAttempts = 8
+	// deliveryRetryBase is the wait after the first failed attempt; it
+	// doubles with each further failure up to deliveryRetryMax
+	deliveryRetryBase = time.Minute
+	deliveryRetryMax  = 6 * time.Hour
+	// deliveryLease is how long a claimed delivery is held by its dispatcher
+	// before another may take it
+	deliveryLease       = 5 * time.Minute
+	deliverySendTimeout = 30 * time.Second
+	deliveryBatchSize   = 50
+	deliveryPollEvery   = 15 * time.Second
+)
+
+// permanentError marks a delivery failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent marks err as not worth retrying
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or an error it wraps, is not worth
+// retrying
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// deliveryBackoff is the wait before retrying after the given number of
+// failed attempts
+func deliveryBackoff(attempts int) time.Duration {
+	wait := deliveryRetryBase
+	for i := 1; i < attempts && wait < deliveryRetryMax; i++ {
+		wait *= 2
+	}
+	if wait > deliveryRetryMax {
+		wait = deliveryRetryMax
+	}
+	return wait
+}
+
+// giveUpDelivery reports whether a delivery that failed with err on its
+// attempts-th attempt should be marked failed rather than retried
+func giveUpDelivery(err error, attempts int) bool {
+	return IsPermanent(err) || attempts >= maxDeliveryAttempts
+}
+
+// dispatchBatch claims a batch of deliveries due by now and attempts each,
+// returning how many were claimed and how many were delivered. It stops at
+// the first error recording an outcome.
+func dispatchBatch[D any](ctx context.Context, now time.Time,
+	claim func(ctx context.Context, now, leaseUntil time.Time, limit int) ([]D, error),
+	deliver func(ctx context.Context, delivery D) (bool, error)) (claimed, sent int, err error) {
+	deliveries, err := claim(ctx, now, now.Add(deliveryLease), deliveryBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, delivery := range deliveries {
+		ok, err := deliver(ctx, delivery)
+		if err != nil {
+			return len(deliveries), sent, err
+		}
+		if ok {
+			sent++
+		}
+	}
+	return len(deliveries), sent, nil
+}
+
+// runDispatchLoop calls dispatch every few seconds until the context is
+// cancelled, draining the queue a batch at a time. kind names what is
+// delivered in log lines.
+func runDispatchLoop(ctx context.Context, kind string, dispatch func(ctx context.Context, now time.Time) (claimed, sent int, err error)) {
+	ticker := time.NewTicker(deliveryPollEvery)
+	defer ticker.Stop()
+	for {
+		for {
+			claimed, sent, err := dispatch(ctx, time.Now().UTC())
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Printf("Warning: %s delivery failed: %v", kind, err)
+				break
+			}
+			if sent > 0 {
+				log.Printf("Delivered %d %s deliveries", sent, strings.ToLower(kind))
+			}
+			if claimed < deliveryBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// NotificationSender delivers a notification over one channel
+type NotificationSender interface {
+	Send(ctx context.Context, delivery models.NotificationDelivery) error
+}
+
+// EmailNotificationSender delivers notifications by email, to the user's
+// notification address or else their account address
+type EmailNotificationSender struct {
+	mailer MailSender
+}
+
+// NewEmailNotificationSender creates an email sender using mailer
+func NewEmailNotificationSender(mailer MailSender) *EmailNotificationSender {
+	return &EmailNotificationSender{mailer: mailer}
+}
+
+// Send emails the delivery's notification
+func (s *EmailNotificationSender) Send(ctx context.Context, delivery models.NotificationDelivery) error {
+	to := delivery.Settings.AccountEmail
+	if delivery.Settings.Email != nil && *delivery.Settings.Email != "" {
+		to = *delivery.Settings.Email
+	}
+	if to == "" {
+		return permanent(errors.New("no email address to deliver to"))
+	}
+
+	n := delivery.Notification
+	return s.mailer.SendMail(ctx, Mail{
+		To:      to,
+		Subject: n.Title,
+		Text:    n.Message + "\n",
+		HTML: "<!DOCTYPE html>\n<html><body>\n<h2>" + html.EscapeString(n.Title) + "</h2>\n<p>" +
+			strings.ReplaceAll(html.EscapeString(n.Message), "\n", "<br>\n") + "</p>\n</body></html>\n",
+	})
+}
+
+// Webhook request headers. The signature is an HMAC-SHA256 of the timestamp,
+// a dot and the body, keyed with the webhook secret; receivers should check
+// it and reject stale timestamps.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookEventHeader     = "X-Webhook-Event"
+)
+
+// errBlockedWebhookAddress is returned for webhooks pointing at this host or
+// its private network
+var errBlockedWebhookAddress = errors.New("webhook address is not publicly routable")
+
+// blockedWebhookAddr reports whether a webhook must not be sent to addr:
+// loopback, private, link-local, multicast and unspecified addresses all
+// reach this host or its network rather than the user's receiver
+func blockedWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast()
+}
+
+// guardWebhookDial is the dialer Control hook of the webhook client. It
+// runs on the resolved address of every connection, so host names that
+// resolve, or later re-resolve, to a blocked address are refused too.
+func guardWebhookDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return permanent(err)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || blockedWebhookAddr(addr) {
+		return permanent(fmt.Errorf("%w: %s", errBlockedWebhookAddress, host))
+	}
+	return nil
+}
+
+// NewWebhookHTTPClient returns the client webhooks are sent with. It only
+// connects to publicly routable addresses, bypasses any proxy so the check
+// sees the real destination, and does not follow redirects.
+func NewWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   guardWebhookDial,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   deliverySendTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// validateWebhookURL checks that raw is an http or https URL whose host is
+// not a blocked address. Host names are checked again when connecting.
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid webhook URL %q, use an http or https URL", raw)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("invalid webhook URL %q, %w", raw, errBlockedWebhookAddress)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && blockedWebhookAddr(addr) {
+		return fmt.Errorf("invalid webhook URL %q, %w", raw, errBlockedWebhookAddress)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the signature header value for a webhook body
+// sent at timestamp (Unix seconds)
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PostWebhook POSTs a JSON body to url as the given event, signing it when
+// secret is set. Responses other than 2xx are errors; those that retrying
+// cannot fix (redirects, which are not followed, and 4xx other than 408 and
+// 429) are permanent. It returns the response status, or zero when there was
+// none.
+func PostWebhook(ctx context.Context, client *http.Client, url, secret, event, deliveryID string, body []byte, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, permanent(fmt.Errorf("invalid webhook request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PersonalFinance-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	if secret != "" {
+		timestamp := now.Unix()
+		req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(secret, timestamp, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	err = fmt.Errorf("webhook responded with %s", resp.Status)
+	if resp.StatusCode >= 300 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return resp.StatusCode, permanent(err)
+	}
+	return resp.StatusCode, err
+}
+
+// webhookNotificationPayload is the body of a notification webhook
+type webhookNotificationPayload struct {
+	Event        string               `json:"event"`
+	DeliveryID   string               `json:"delivery_id"`
+	Notification *models.Notification `json:"notification"`
+}
+
+// WebhookNotificationSender delivers notifications by POSTing them as JSON
+// to the user's webhook URL
+type WebhookNotificationSender struct {
+	client *http.Client
+}
+
+// NewWebhookNotificationSender creates a webhook sender using client, or
+// NewWebhookHTTPClient when it is nil
+func NewWebhookNotificationSender(client *http.Client) *WebhookNotificationSender {
+	if client == nil {
+		client = NewWebhookHTTPClient()
+	}
+	return &WebhookNotificationSender{client: client}
+}
+
+// Send posts the delivery's notification to the user's webhook
+func (s *WebhookNotificationSender) Send(ctx context.Context, delivery models.NotificationDelivery) error {
+	if delivery.Settings.WebhookURL == nil || *delivery.Settings.WebhookURL == "" {
+		return permanent(errors.New("no webhook URL to deliver to"))
+	}
+
+	body, err := json.Marshal(webhookNotificationPayload{
+		Event:        "notification.created",
+		DeliveryID:   delivery.ID,
+		Notification: delivery.Notification,
+	})
+	if err != nil {
+		return permanent(err)
+	}
+	secret := ""
+	if delivery.Settings.WebhookSecret != nil {
+		secret = *delivery.Settings.WebhookSecret
+	}
+	_, err = PostWebhook(ctx, s.client, *delivery.Settings.WebhookURL, secret, "notification.created", delivery.ID, body, time.Now())
+	return err
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight
+func parseClock(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// loadTimezone returns the named location, or UTC when it is unknown
+func loadTimezone(name string) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// QuietUntil reports whether now falls in the user's quiet hours and, if so,
+// when they end. Quiet hours are local times in the user's timezone; a
+// window ending before it starts runs over midnight.
+func QuietUntil(settings models.NotificationSettings, now time.Time) (time.Time, bool) {
+	if settings.QuietHoursStart == nil || settings.QuietHoursEnd == nil {
+		return time.Time{}, false
+	}
+	start, ok1 := parseClock(*settings.QuietHoursStart)
+	end, ok2 := parseClock(*settings.QuietHoursEnd)
+	if !ok1 || !ok2 || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(loadTimezone(settings.Timezone))
+	current := local.Hour()*60 + local.Minute()
+	endsAt := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, end/60, end%60, 0, 0, local.Location())
+	}
+	switch {
+	case start < end && current >= start && current < end:
+		return endsAt(0), true
+	case start > end && current >= start:
+		return endsAt(1), true
+	case start > end && current < end:
+		return endsAt(0), true
+	}
+	return time.Time{}, false
+}
+
+// NotificationDeliveryStore is the data access the notification dispatcher
+// needs
+type NotificationDeliveryStore interface {
+	ClaimDueNotificationDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.NotificationDelivery, error)
+	MarkNotificationDeliverySent(ctx context.Context, id string, attempts int, sentAt time.Time) error
+	RescheduleNotificationDelivery(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, lastError *string) error
+	MarkNotificationDeliveryFailed(ctx context.Context, id string, attempts int, lastError string) error
+}
+
+// NotificationDispatcher works through the notification delivery queue,
+// sending each due delivery over its channel. Failed attempts are retried
+// with exponential backoff until maxDeliveryAttempts; deliveries due during
+// the user's quiet hours wait for them to end.
+type NotificationDispatcher struct {
+	store   NotificationDeliveryStore
+	senders map[models.NotificationChannel]NotificationSender
+}
+
+// NewNotificationDispatcher creates a dispatcher with a sender per channel.
+// Deliveries on a channel without a sender fail.
+func NewNotificationDispatcher(store NotificationDeliveryStore, senders map[models.NotificationChannel]NotificationSender) *NotificationDispatcher {
+	return &NotificationDispatcher{store: store, senders: senders}
+}
+
+// DispatchDue claims a batch of deliveries due by now and attempts them,
+// returning how many were claimed and how many were sent
+func (d *NotificationDispatcher) DispatchDue(ctx context.Context, now time.Time) (claimed, sent int, err error) {
+	return dispatchBatch(ctx, now, d.store.ClaimDueNotificationDeliveries,
+		func(ctx context.Context, delivery models.NotificationDelivery) (bool, error) {
+			return d.deliver(ctx, delivery, now)
+		})
+}
+
+// deliver attempts one delivery and records the outcome, reporting whether
+// it was sent
+func (d *NotificationDispatcher) deliver(ctx context.Context, delivery models.NotificationDelivery, now time.Time) (bool, error) {
+	if delivery.RespectQuietHours {
+		if until, quiet := QuietUntil(*delivery.Settings, now); quiet {
+			return false, d.store.RescheduleNotificationDelivery(ctx, delivery.ID, delivery.Attempts, until, delivery.LastError)
+		}
+	}
+
+	attempts := delivery.Attempts + 1
+	sender, ok := d.senders[delivery.Channel]
+	if !ok {
+		return false, d.store.MarkNotificationDeliveryFailed(ctx, delivery.ID, attempts,
+			fmt.Sprintf("%s delivery is not configured", delivery.Channel))
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, deliverySendTimeout)
+	err := sender.Send(sendCtx, delivery)
+	cancel()
+	if err == nil {
+		return true, d.store.MarkNotificationDeliverySent(ctx, delivery.ID, attempts, now)
+	}
+	if ctx.Err() != nil {
+		// Shutting down: the lease runs out and the delivery is retried
+		return false, ctx.Err()
+	}
+
+	message := err.Error()
+	if giveUpDelivery(err, attempts) {
+		return false, d.store.MarkNotificationDeliveryFailed(ctx, delivery.ID, attempts, message)
+	}
+	return false, d.store.RescheduleNotificationDelivery(ctx, delivery.ID, attempts, now.Add(deliveryBackoff(attempts)), &message)
+}
+
+// Run dispatches due deliveries every few seconds until the context is
+// cancelled, draining the queue a batch at a time
+func (d *NotificationDispatcher) Run(ctx context.Context) {
+	runDispatchLoop(ctx, "Notification", d.DispatchDue)
+}
+
+// notificationTypes lists every notification type, in display order
+var notificationTypes = []models.NotificationType{
+	models.NotificationTypeBudgetAlert,
+	models.NotificationTypeGoalMilestone,
+	models.NotificationTypeTransactionAlert,
+	models.NotificationTypeInfo,
+}
+
+// DefaultNotificationPreference is the preference of a type the user has
+// not set: shown in-app only, immediately
+func DefaultNotificationPreference(userID string, notificationType models.NotificationType) models.NotificationPreference {
+	return models.NotificationPreference{
+		UserID:            userID,
+		NotificationType:  notificationType,
+		InApp:             true,
+		Delivery:          models.NotificationDeliveryImmediate,
+		RespectQuietHours: true,
+	}
+}
+
+// CompleteNotificationPreferences returns a preference for every
+// notification type, the saved one where there is one and the default
+// otherwise
+func CompleteNotificationPreferences(userID string, saved []models.NotificationPreference) []models.NotificationPreference {
+	byType := make(map[models.NotificationType]models.NotificationPreference, len(saved))
+	for _, p := range saved {
+		byType[p.NotificationType] = p
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(notificationTypes))
+	for _, t := range notificationTypes {
+		if p, ok := byType[t]; ok {
+			preferences = append(preferences, p)
+		} else {
+			preferences = append(preferences, DefaultNotificationPreference(userID, t))
+		}
+	}
+	return preferences
+}
+
+// ValidateNotificationSettings checks the user's channel settings
+func ValidateNotificationSettings(settings models.NotificationSettings) error {
+	if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "" {
+		return fmt.Errorf("unknown timezone %q", settings.Timezone)
+	}
+	switch settings.DigestFrequency {
+	case models.DigestFrequencyOff, models.DigestFrequencyWeekly, models.DigestFrequencyMonthly:
+	default:
+		return fmt.Errorf("invalid digest frequency %q, use off, weekly or monthly", settings.DigestFrequency)
+	}
+	if (settings.QuietHoursStart == nil) != (settings.QuietHoursEnd == nil) {
+		return errors.New("quiet hours need both a start and an end")
+	}
+	for _, clock := range []*string{settings.QuietHoursStart, settings.QuietHoursEnd} {
+		if clock == nil {
+			continue
+		}
+		if _, ok := parseClock(*clock); !ok {
+			return fmt.Errorf("invalid quiet hours time %q, use HH:MM", *clock)
+		}
+	}
+	if settings.Email != nil {
+		if _, err := netmail.ParseAddress(*settings.Email); err != nil {
+			return fmt.Errorf("invalid email address %q", *settings.Email)
+		}
+	}
+	if settings.WebhookURL != nil {
+		if err := validateWebhookURL(*settings.WebhookURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNotificationPreferences checks that the preferences can be
+// delivered with the user's settings
+func ValidateNotificationPreferences(settings models.NotificationSettings, preferences []models.NotificationPreference) error {
+	for _, p := range preferences {
+		if p.Webhook && settings.WebhookURL == nil {
+			return fmt.Errorf("%s webhook delivery needs a webhook URL", p.NotificationType)
+		}
+		if p.Email && settings.AccountEmail == "" && settings.Email == nil {
+			return fmt.Errorf("%s email delivery needs an email address", p.NotificationType)
+		}
+		if p.Email && p.Delivery == models.NotificationDeliveryDigest && settings.DigestFrequency == models.DigestFrequencyOff {
+			return fmt.Errorf("%s digest delivery needs weekly or monthly digests turned on", p.NotificationType)
+		}
+	}
+	return nil
+}
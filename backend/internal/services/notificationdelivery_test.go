@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeQueue is an in-memory delivery queue keyed by delivery ID, shared by
+// the notification and webhook store fakes
+type fakeQueue[D any] struct {
+	deliveries map[string]*D
+	// due reports whether a delivery can be claimed at now; lease holds a
+	// claimed delivery until the given time
+	due   func(d *D, now time.Time) bool
+	lease func(d *D, until time.Time)
+}
+
+func newFakeQueue[D any](id func(D) string, due func(*D, time.Time) bool, lease func(*D, time.Time), deliveries ...D) fakeQueue[D] {
+	q := fakeQueue[D]{deliveries: make(map[string]*D), due: due, lease: lease}
+	for i := range deliveries {
+		d := deliveries[i]
+		q.deliveries[id(d)] = &d
+	}
+	return q
+}
+
+func (q fakeQueue[D]) claim(now, leaseUntil time.Time, limit int) []D {
+	var due []D
+	for _, d := range q.deliveries {
+		if q.due(d, now) && len(due) < limit {
+			q.lease(d, leaseUntil)
+			due = append(due, *d)
+		}
+	}
+	return due
+}
+
+// fakeDeliveryStore is an in-memory notification delivery queue
+type fakeDeliveryStore struct {
+	fakeQueue[models.NotificationDelivery]
+}
+
+func newFakeDeliveryStore(deliveries ...models.NotificationDelivery) *fakeDeliveryStore {
+	for i := range deliveries {
+		if deliveries[i].Status == "" {
+			deliveries[i].Status = models.NotificationDeliveryPending
+		}
+	}
+	return &fakeDeliveryStore{newFakeQueue(
+		func(d models.NotificationDelivery) string { return d.ID },
+		func(d *models.NotificationDelivery, now time.Time) bool {
+			return d.Status == models.NotificationDeliveryPending && !d.NextAttemptAt.After(now)
+		},
+		func(d *models.NotificationDelivery, until time.Time) { d.NextAttemptAt = until },
+		deliveries...,
+	)}
+}
+
+func (s *fakeDeliveryStore) ClaimDueNotificationDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.NotificationDelivery, error) {
+	return s.claim(now, leaseUntil, limit), nil
+}
+
+func (s *fakeDeliveryStore) MarkNotificationDeliverySent(ctx context.Context, id string, attempts int, sentAt time.Time) error {
+	d := s.deliveries[id]
+	d.Status, d.Attempts, d.SentAt, d.LastError = models.NotificationDeliverySent, attempts, &sentAt, nil
+	return nil
+}
+
+func (s *fakeDeliveryStore) RescheduleNotificationDelivery(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, lastError *string) error {
+	d := s.deliveries[id]
+	d.Attempts, d.NextAttemptAt, d.LastError = attempts, nextAttemptAt, lastError
+	return nil
+}
+
+func (s *fakeDeliveryStore) MarkNotificationDeliveryFailed(ctx context.Context, id string, attempts int, lastError string) error {
+	d := s.deliveries[id]
+	d.Status, d.Attempts, d.LastError = models.NotificationDeliveryFailed, attempts, &lastError
+	return nil
+}
+
+// fakeSender fails with the queued errors, then succeeds
+type fakeSender struct {
+	errs []error
+	sent []string
+}
+
+func (s *fakeSender) Send(ctx context.Context, delivery models.NotificationDelivery) error {
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+	s.sent = append(s.sent, delivery.ID)
+	return nil
+}
+
+func testDelivery(id string, channel models.NotificationChannel, due time.Time) models.NotificationDelivery {
+	return models.NotificationDelivery{
+		ID:             id,
+		NotificationID: "n-" + id,
+		UserID:         "user-1",
+		Channel:        channel,
+		NextAttemptAt:  due,
+		Notification: &models.Notification{
+			ID:      "n-" + id,
+			UserID:  "user-1",
+			Title:   "Budget alert: Groceries",
+			Message: "You have used 80% of your Groceries budget.",
+			Type:    models.NotificationTypeBudgetAlert,
+		},
+		Settings:          &models.NotificationSettings{UserID: "user-1", Timezone: "UTC", AccountEmail: "jo@example.com"},
+		RespectQuietHours: true,
+	}
+}
+
+func TestDeliveryBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, deliveryBackoff(1))
+	assert.Equal(t, 2*time.Minute, deliveryBackoff(2))
+	assert.Equal(t, 64*time.Minute, deliveryBackoff(7))
+	assert.Equal(t, deliveryRetryMax, deliveryBackoff(20))
+}
+
+func TestNotificationDispatcher_RetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeDeliveryStore(testDelivery("d1", models.NotificationChannelEmail, now))
+	sender := &fakeSender{errs: []error{errors.New("connection refused"), errors.New("connection refused")}}
+	dispatcher := NewNotificationDispatcher(store, map[models.NotificationChannel]NotificationSender{
+		models.NotificationChannelEmail: sender,
+	})
+
+	claimed, sent, err := dispatcher.DispatchDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	assert.Equal(t, 0, sent)
+	d := store.deliveries["d1"]
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, now.Add(time.Minute), d.NextAttemptAt)
+	assert.Equal(t, "connection refused", *d.LastError)
+
+	// Not due again until the backoff has passed
+	claimed, _, _ = dispatcher.DispatchDue(ctx, now.Add(30*time.Second))
+	assert.Equal(t, 0, claimed)
+
+	now = now.Add(time.Minute)
+	_, _, _ = dispatcher.DispatchDue(ctx, now)
+	assert.Equal(t, 2, d.Attempts)
+	assert.Equal(t, now.Add(2*time.Minute), d.NextAttemptAt)
+
+	now = now.Add(2 * time.Minute)
+	_, sent, _ = dispatcher.DispatchDue(ctx, now)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, models.NotificationDeliverySent, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Equal(t, now, *d.SentAt)
+	assert.Nil(t, d.LastError)
+	assert.Equal(t, []string{"d1"}, sender.sent)
+}
+
+func TestNotificationDispatcher_GivesUp(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Permanent failures are not retried
+	store := newFakeDeliveryStore(testDelivery("d1", models.NotificationChannelEmail, now))
+	sender := &fakeSender{errs: []error{permanent(errors.New("mailbox unavailable"))}}
+	dispatcher := NewNotificationDispatcher(store, map[models.NotificationChannel]NotificationSender{
+		models.NotificationChannelEmail: sender,
+	})
+	_, _, err := dispatcher.DispatchDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, models.NotificationDeliveryFailed, store.deliveries["d1"].Status)
+	assert.Equal(t, 1, store.deliveries["d1"].Attempts)
+
+	// Neither is the last allowed attempt
+	last := testDelivery("d2", models.NotificationChannelEmail, now)
+	last.Attempts = maxDeliveryAttempts - 1
+	store = newFakeDeliveryStore(last)
+	sender = &fakeSender{errs: []error{errors.New("timeout")}}
+	dispatcher = NewNotificationDispatcher(store, map[models.NotificationChannel]NotificationSender{
+		models.NotificationChannelEmail: sender,
+	})
+	_, _, _ = dispatcher.DispatchDue(ctx, now)
+	assert.Equal(t, models.NotificationDeliveryFailed, store.deliveries["d2"].Status)
+	assert.Equal(t, maxDeliveryAttempts, store.deliveries["d2"].Attempts)
+	assert.Equal(t, "timeout", *store.deliveries["d2"].LastError)
+
+	// A channel without a sender fails
+	store = newFakeDeliveryStore(testDelivery("d3", models.NotificationChannelEmail, now))
+	dispatcher = NewNotificationDispatcher(store, map[models.NotificationChannel]NotificationSender{})
+	_, _, _ = dispatcher.DispatchDue(ctx, now)
+	assert.Equal(t, models.NotificationDeliveryFailed, store.deliveries["d3"].Status)
+	assert.Equal(t, "email delivery is not configured", *store.deliveries["d3"].LastError)
+}
+
+func TestNotificationDispatcher_QuietHours(t *testing.T) {
+	ctx := context.Background()
+	// 23:30 in Berlin (UTC+2 in summer)
+	now := time.Date(2026, 7, 1, 21, 30, 0, 0, time.UTC)
+	quiet := testDelivery("d1", models.NotificationChannelWebhook, now)
+	quiet.Settings.Timezone = "Europe/Berlin"
+	quiet.Settings.QuietHoursStart = strPtr("22:00")
+	quiet.Settings.QuietHoursEnd = strPtr("07:00")
+	urgent := quiet
+	urgent.ID = "d2"
+	urgent.RespectQuietHours = false
+
+	store := newFakeDeliveryStore(quiet, urgent)
+	sender := &fakeSender{}
+	dispatcher := NewNotificationDispatcher(store, map[models.NotificationChannel]NotificationSender{
+		models.NotificationChannelWebhook: sender,
+	})
+
+	claimed, sent, err := dispatcher.DispatchDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []string{"d2"}, sender.sent)
+
+	// Held until 07:00 Berlin time without using up an attempt
+	held := store.deliveries["d1"]
+	assert.Equal(t, models.NotificationDeliveryPending, held.Status)
+	assert.Equal(t, 0, held.Attempts)
+	assert.True(t, held.NextAttemptAt.Equal(time.Date(2026, 7, 2, 5, 0, 0, 0, time.UTC)))
+}
+
+func TestQuietUntil(t *testing.T) {
+	settings := models.NotificationSettings{Timezone: "UTC", QuietHoursStart: strPtr("22:00"), QuietHoursEnd: strPtr("07:00")}
+	at := func(hour, minute int) time.Time { return time.Date(2026, 3, 10, hour, minute, 0, 0, time.UTC) }
+
+	until, quiet := QuietUntil(settings, at(23, 0))
+	assert.True(t, quiet)
+	assert.Equal(t, time.Date(2026, 3, 11, 7, 0, 0, 0, time.UTC), until)
+
+	until, quiet = QuietUntil(settings, at(6, 59))
+	assert.True(t, quiet)
+	assert.Equal(t, at(7, 0), until)
+
+	_, quiet = QuietUntil(settings, at(7, 0))
+	assert.False(t, quiet)
+	_, quiet = QuietUntil(settings, at(21, 59))
+	assert.False(t, quiet)
+
+	// A window within one day
+	settings.QuietHoursStart, settings.QuietHoursEnd = strPtr("12:00"), strPtr("13:30")
+	until, quiet = QuietUntil(settings, at(12, 15))
+	assert.True(t, quiet)
+	assert.Equal(t, at(13, 30), until)
+	_, quiet = QuietUntil(settings, at(14, 0))
+	assert.False(t, quiet)
+
+	// No quiet hours
+	_, quiet = QuietUntil(models.NotificationSettings{Timezone: "UTC"}, at(23, 0))
+	assert.False(t, quiet)
+}
+
+func TestWebhookNotificationSender(t *testing.T) {
+	var got struct {
+		header http.Header
+		body   []byte
+	}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.header = r.Header.Clone()
+		got.body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	sender := NewWebhookNotificationSender(server.Client())
+	delivery := testDelivery("d1", models.NotificationChannelWebhook, time.Now())
+	delivery.Settings.WebhookURL = &server.URL
+	delivery.Settings.WebhookSecret = strPtr("s3cret")
+
+	assert.NoError(t, sender.Send(ctx, delivery))
+	assert.Equal(t, "application/json", got.header.Get("Content-Type"))
+	assert.Equal(t, "notification.created", got.header.Get(WebhookEventHeader))
+	assert.Equal(t, "d1", got.header.Get(WebhookDeliveryHeader))
+	timestamp, err := strconv.ParseInt(got.header.Get(WebhookTimestampHeader), 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, SignWebhookPayload("s3cret", timestamp, got.body), got.header.Get(WebhookSignatureHeader))
+
+	var payload map[string]interface{}
+	assert.NoError(t, json.Unmarshal(got.body, &payload))
+	assert.Equal(t, "notification.created", payload["event"])
+	assert.Equal(t, "Budget alert: Groceries", payload["notification"].(map[string]interface{})["title"])
+
+	// Server errors and rate limiting are retried, other client errors are not
+	status = http.StatusServiceUnavailable
+	err = sender.Send(ctx, delivery)
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	status = http.StatusTooManyRequests
+	assert.False(t, IsPermanent(sender.Send(ctx, delivery)))
+	status = http.StatusGone
+	assert.True(t, IsPermanent(sender.Send(ctx, delivery)))
+
+	// Without a secret there is no signature
+	status = http.StatusOK
+	delivery.Settings.WebhookSecret = nil
+	assert.NoError(t, sender.Send(ctx, delivery))
+	assert.Empty(t, got.header.Get(WebhookSignatureHeader))
+
+	delivery.Settings.WebhookURL = nil
+	assert.True(t, IsPermanent(sender.Send(ctx, delivery)))
+}
+
+func TestWebhookHTTPClient_RefusesLocalAddresses(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	_, err := PostWebhook(context.Background(), NewWebhookHTTPClient(), server.URL, "", "test", "d1", []byte("{}"), time.Now())
+
+	assert.ErrorIs(t, err, errBlockedWebhookAddress)
+	assert.True(t, IsPermanent(err))
+	assert.Equal(t, 0, hits)
+}
+
+func TestWebhookHTTPClient_DoesNotFollowRedirects(t *testing.T) {
+	client := NewWebhookHTTPClient()
+	// The guard is covered above; let this test reach the local server
+	client.Transport.(*http.Transport).DialContext = nil
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		http.Redirect(w, r, "http://127.0.0.1:1/admin", http.StatusFound)
+	}))
+	defer server.Close()
+
+	status, err := PostWebhook(context.Background(), client, server.URL, "", "test", "d1", []byte("{}"), time.Now())
+
+	assert.Equal(t, http.StatusFound, status)
+	assert.True(t, IsPermanent(err))
+	assert.Equal(t, 1, hits)
+}
+
+func TestBlockedWebhookAddr(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1",
+		"169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0", "::", "::ffff:127.0.0.1", "224.0.0.1"} {
+		assert.True(t, blockedWebhookAddr(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"93.184.216.34", "2606:4700::1111"} {
+		assert.False(t, blockedWebhookAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// HMAC-SHA256 of "1700000000.{}" keyed with "secret"
+	assert.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		SignWebhookPayload("secret", 1700000000, []byte("{}")))
+}
+
+func TestEmailNotificationSender(t *testing.T) {
+	server := startTestSMTPServer(t)
+	sender := NewEmailNotificationSender(server.mailer(t))
+	ctx := context.Background()
+
+	delivery := testDelivery("d1", models.NotificationChannelEmail, time.Now())
+	delivery.Notification.Message = "You have used 80% of your <Groceries> budget."
+	assert.NoError(t, sender.Send(ctx, delivery))
+
+	// The notification address wins over the account address
+	delivery.Settings.Email = strPtr("alerts@jo.example")
+	assert.NoError(t, sender.Send(ctx, delivery))
+
+	messages := server.received()
+	if !assert.Len(t, messages, 2) {
+		return
+	}
+	assert.Equal(t, []string{"jo@example.com"}, messages[0].To)
+	assert.Equal(t, []string{"alerts@jo.example"}, messages[1].To)
+	_, parts := readTestMessage(t, messages[0].Data)
+	assert.Contains(t, parts["text/plain"], "<Groceries>")
+	assert.Contains(t, parts["text/html"], "&lt;Groceries&gt;")
+
+	delivery.Settings.Email, delivery.Settings.AccountEmail = nil, ""
+	assert.True(t, IsPermanent(sender.Send(ctx, delivery)))
+}
+
+func TestCompleteNotificationPreferences(t *testing.T) {
+	saved := []models.NotificationPreference{{
+		UserID:           "user-1",
+		NotificationType: models.NotificationTypeGoalMilestone,
+		Email:            true,
+		Delivery:         models.NotificationDeliveryDigest,
+	}}
+
+	preferences := CompleteNotificationPreferences("user-1", saved)
+	assert.Len(t, preferences, 4)
+	assert.Equal(t, DefaultNotificationPreference("user-1", models.NotificationTypeBudgetAlert), preferences[0])
+	assert.Equal(t, saved[0], preferences[1])
+	assert.True(t, preferences[3].InApp)
+	assert.Equal(t, models.NotificationDeliveryImmediate, preferences[3].Delivery)
+}
+
+func TestValidateNotificationSettings(t *testing.T) {
+	valid := models.NotificationSettings{
+		Timezone:        "America/New_York",
+		DigestFrequency: models.DigestFrequencyOff,
+		Email:           strPtr("jo@example.com"),
+		WebhookURL:      strPtr("https://hooks.example.com/finance"),
+		QuietHoursStart: strPtr("22:00"),
+		QuietHoursEnd:   strPtr("07:30"),
+	}
+	assert.NoError(t, ValidateNotificationSettings(valid))
+
+	for name, change := range map[string]func(s *models.NotificationSettings){
+		"timezone":      func(s *models.NotificationSettings) { s.Timezone = "Mars/Olympus" },
+		"half window":   func(s *models.NotificationSettings) { s.QuietHoursEnd = nil },
+		"clock":         func(s *models.NotificationSettings) { s.QuietHoursStart = strPtr("25:00") },
+		"email":         func(s *models.NotificationSettings) { s.Email = strPtr("nope") },
+		"webhook":       func(s *models.NotificationSettings) { s.WebhookURL = strPtr("ftp://example.com") },
+		"relative hook": func(s *models.NotificationSettings) { s.WebhookURL = strPtr("/hooks") },
+		"local hook":    func(s *models.NotificationSettings) { s.WebhookURL = strPtr("http://localhost:8080/hooks") },
+		"private hook":  func(s *models.NotificationSettings) { s.WebhookURL = strPtr("http://10.0.0.7/hooks") },
+		"metadata hook": func(s *models.NotificationSettings) { s.WebhookURL = strPtr("http://169.254.169.254/latest") },
+		"digest":        func(s *models.NotificationSettings) { s.DigestFrequency = "daily" },
+	} {
+		settings := valid
+		change(&settings)
+		assert.Error(t, ValidateNotificationSettings(settings), name)
+	}
+
+	// Channels need somewhere to deliver to
+	preferences := []models.NotificationPreference{{NotificationType: models.NotificationTypeInfo, Webhook: true}}
+	assert.Error(t, ValidateNotificationPreferences(models.NotificationSettings{}, preferences))
+	assert.NoError(t, ValidateNotificationPreferences(valid, preferences))
+
+	// Email held for the digest needs digests on
+	preferences = []models.NotificationPreference{{NotificationType: models.NotificationTypeInfo, Email: true, Delivery: models.NotificationDeliveryDigest}}
+	assert.Error(t, ValidateNotificationPreferences(valid, preferences))
+	valid.DigestFrequency = models.DigestFrequencyWeekly
+	assert.NoError(t, ValidateNotificationPreferences(valid, preferences))
+}
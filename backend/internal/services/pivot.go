@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// Limits on pivot queries, keeping ad-hoc aggregations cheap to run
+const (
+	MaxPivotDimensions = 3
+	DefaultPivotLimit  = 100
+	MaxPivotLimit      = 1000
+	MaxPivotRangeDays  = 3 * 366
+	MaxPivotFilterIDs  = 50 // Per id or tag list
+)
+
+var pivotDimensions = map[models.PivotDimension]bool{
+	models.PivotDimensionCategory: true,
+	models.PivotDimensionAccount:  true,
+	models.PivotDimensionMerchant: true,
+	models.PivotDimensionTag:      true,
+	models.PivotDimensionMonth:    true,
+	models.PivotDimensionWeekday:  true,
+}
+
+var pivotMeasures = map[models.PivotMeasure]bool{
+	models.PivotMeasureSum:    true,
+	models.PivotMeasureCount:  true,
+	models.PivotMeasureAvg:    true,
+	models.PivotMeasureMedian: true,
+}
+
+// NormalizePivotQuery checks a pivot query against the supported dimensions,
+// measures and limits, filling in the defaults: the sum measure, expense
+// lines and DefaultPivotLimit rows. Dimensions and measures may not repeat.
+func NormalizePivotQuery(query *models.PivotQuery) error {
+	if len(query.Dimensions) == 0 {
+		return fmt.Errorf("at least one dimension is required")
+	}
+	if len(query.Dimensions) > MaxPivotDimensions {
+		return fmt.Errorf("at most %d dimensions are allowed", MaxPivotDimensions)
+	}
+	seenDimensions := make(map[models.PivotDimension]bool)
+	for _, dimension := range query.Dimensions {
+		if !pivotDimensions[dimension] {
+			return fmt.Errorf("unknown dimension %q; use %s", dimension, pivotNames(pivotDimensions))
+		}
+		if seenDimensions[dimension] {
+			return fmt.Errorf("dimension %q is repeated", dimension)
+		}
+		seenDimensions[dimension] = true
+	}
+
+	if len(query.Measures) == 0 {
+		query.Measures = []models.PivotMeasure{models.PivotMeasureSum}
+	}
+	seenMeasures := make(map[models.PivotMeasure]bool)
+	for _, measure := range query.Measures {
+		if !pivotMeasures[measure] {
+			return fmt.Errorf("unknown measure %q; use %s", measure, pivotNames(pivotMeasures))
+		}
+		if seenMeasures[measure] {
+			return fmt.Errorf("measure %q is repeated", measure)
+		}
+		seenMeasures[measure] = true
+	}
+
+	if query.Limit == 0 {
+		query.Limit = DefaultPivotLimit
+	}
+	if query.Limit < 1 || query.Limit > MaxPivotLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxPivotLimit)
+	}
+
+	filter := &query.Filter
+	if filter.StartDate.IsZero() || filter.EndDate.IsZero() {
+		return fmt.Errorf("start and end dates are required")
+	}
+	if filter.EndDate.Before(filter.StartDate) {
+		return fmt.Errorf("end date must not be before start date")
+	}
+	if days := int(filter.EndDate.Sub(filter.StartDate).Hours() / 24); days > MaxPivotRangeDays {
+		return fmt.Errorf("date range cannot exceed %d days", MaxPivotRangeDays)
+	}
+
+	switch filter.TransactionType {
+	case "":
+		filter.TransactionType = models.TransactionTypeExpense
+	case models.TransactionTypeExpense, models.TransactionTypeIncome:
+	default:
+		return fmt.Errorf("transaction type must be income or expense")
+	}
+
+	for name, list := range map[string][]string{
+		"account_ids":  filter.AccountIDs,
+		"category_ids": filter.CategoryIDs,
+		"merchant_ids": filter.MerchantIDs,
+		"tags":         filter.Tags,
+	} {
+		if len(list) > MaxPivotFilterIDs {
+			return fmt.Errorf("at most %d %s are allowed", MaxPivotFilterIDs, name)
+		}
+	}
+
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MaxAmount < *filter.MinAmount {
+		return fmt.Errorf("max amount must not be below min amount")
+	}
+
+	return nil
+}
+
+// pivotNames lists the supported names in order for error messages
+func pivotNames[K ~string](supported map[K]bool) string {
+	names := make([]string, 0, len(supported))
+	for name := range supported {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePivotQuery(t *testing.T) {
+	valid := func() models.PivotQuery {
+		return models.PivotQuery{
+			Dimensions: []models.PivotDimension{models.PivotDimensionCategory, models.PivotDimensionMonth},
+			Filter: models.PivotFilter{
+				StartDate: forecastDate(2026, 1, 1),
+				EndDate:   forecastDate(2026, 6, 30),
+			},
+		}
+	}
+
+	query := valid()
+	assert.NoError(t, NormalizePivotQuery(&query))
+	assert.Equal(t, []models.PivotMeasure{models.PivotMeasureSum}, query.Measures)
+	assert.Equal(t, DefaultPivotLimit, query.Limit)
+	assert.Equal(t, models.TransactionTypeExpense, query.Filter.TransactionType)
+
+	cases := map[string]func(q *models.PivotQuery){
+		"no dimensions":      func(q *models.PivotQuery) { q.Dimensions = nil },
+		"unknown dimension":  func(q *models.PivotQuery) { q.Dimensions = []models.PivotDimension{"description"} },
+		"repeated dimension": func(q *models.PivotQuery) { q.Dimensions = []models.PivotDimension{"tag", "tag"} },
+		"too many dimensions": func(q *models.PivotQuery) {
+			q.Dimensions = []models.PivotDimension{"tag", "month", "weekday", "account"}
+		},
+		"unknown measure": func(q *models.PivotQuery) { q.Measures = []models.PivotMeasure{"max; DROP TABLE"} },
+		"limit too high":  func(q *models.PivotQuery) { q.Limit = MaxPivotLimit + 1 },
+		"reversed dates":  func(q *models.PivotQuery) { q.Filter.EndDate = forecastDate(2025, 12, 31) },
+		"range too long":  func(q *models.PivotQuery) { q.Filter.StartDate = forecastDate(2022, 1, 1) },
+		"transfer lines":  func(q *models.PivotQuery) { q.Filter.TransactionType = models.TransactionTypeTransfer },
+		"too many tags":   func(q *models.PivotQuery) { q.Filter.Tags = make([]string, MaxPivotFilterIDs+1) },
+		"inverted amounts": func(q *models.PivotQuery) {
+			q.Filter.MinAmount, q.Filter.MaxAmount = floatPtr(50), floatPtr(10)
+		},
+	}
+	for name, mutate := range cases {
+		query := valid()
+		mutate(&query)
+		assert.Error(t, NormalizePivotQuery(&query), name)
+	}
+
+	err := NormalizePivotQuery(&models.PivotQuery{
+		Dimensions: []models.PivotDimension{"payee"},
+		Filter:     valid().Filter,
+	})
+	assert.EqualError(t, err, `unknown dimension "payee"; use account, category, merchant, month, tag, weekday`)
+}
@@ -0,0 +1,560 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// MaxReceiptTextLength bounds the receipt text accepted by the parser.
+const MaxReceiptTextLength = 100000
+
+// ReceiptLocale controls how ambiguous dates and grouped numbers in receipt
+// text are read.
+type ReceiptLocale struct {
+	DayFirst      bool // 03/04/2026 is 3 April rather than March 4
+	SpaceGrouping bool // Thousands may be grouped with spaces, as in "1 234,56"
+}
+
+var (
+	localeTagRegex = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2}))?$`)
+
+	// Regions that write the month before the day
+	monthFirstRegions = map[string]bool{"US": true, "PH": true}
+
+	// Languages that group thousands with spaces
+	spaceGroupingLanguages = map[string]bool{
+		"fr": true, "sv": true, "nb": true, "no": true, "fi": true, "pl": true, "cs": true, "ru": true,
+	}
+)
+
+// ParseReceiptLocale maps a language tag such as en-US or de-DE to a receipt
+// locale. The empty tag means en-US, and English without a region is read
+// the American way.
+func ParseReceiptLocale(tag string) (ReceiptLocale, error) {
+	if tag == "" {
+		tag = "en-US"
+	}
+
+	match := localeTagRegex.FindStringSubmatch(tag)
+	if match == nil {
+		return ReceiptLocale{}, fmt.Errorf("locale must be a language tag such as en-US or de-DE")
+	}
+	language, region := strings.ToLower(match[1]), strings.ToUpper(match[2])
+	if region == "" && language == "en" {
+		region = "US"
+	}
+
+	return ReceiptLocale{
+		DayFirst:      !monthFirstRegions[region],
+		SpaceGrouping: spaceGroupingLanguages[language],
+	}, nil
+}
+
+var (
+	// Amounts always carry two decimals, which settles whether "," or "." is
+	// the decimal separator. A trailing minus marks a discount.
+	receiptAmountRegex       = regexp.MustCompile(`(-\s?)?([$€£¥])?\s?(\d{1,3}(?:[,.'’]\d{3})+|\d+)[.,](\d{2})(-)?(?:\D|$)`)
+	receiptSpacedAmountRegex = regexp.MustCompile(`(-\s?)?([$€£¥])?\s?(\d{1,3}(?:[,.'’ \x{a0}\x{202f}]\d{3})+|\d+)[.,](\d{2})(-)?(?:\D|$)`)
+	receiptPercentRegex      = regexp.MustCompile(`\d+(?:[.,]\d+)?\s?%`)
+	receiptQuantityRegex     = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s?(?:kg|lbs?|pcs?|st)?\s?(?:x|×|@)\s?[$€£]?\s?(\d+[.,]\d{2})?(?:\s?/\s?(?:kg|lbs?|ea))?`)
+	receiptSKURegex          = regexp.MustCompile(`\b\d{6,}\b`)
+
+	isoDateRegex          = regexp.MustCompile(`\b(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})\b`)
+	numericDateRegex      = regexp.MustCompile(`\b(\d{1,2})[-/.](\d{1,2})[-/.](\d{4}|\d{2})\b`)
+	dayMonthNameDateRegex = regexp.MustCompile(`\b(\d{1,2})\.?\s+(\p{L}+)\.?,?\s+(\d{4})\b`)
+	monthNameDayDateRegex = regexp.MustCompile(`\b(\p{L}+)\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+
+	receiptFromRegex     = regexp.MustCompile(`(?i)^from:\s*(.*)$`)
+	receiptEmailRegex    = regexp.MustCompile(`<?[\w.+-]+@([\w-]+)(?:\.[\w-]+)+>?`)
+	receiptHeaderSkip    = regexp.MustCompile(`(?i)^(receipt|invoice|welcome|thank|order|tel|phone|fax|www\.|http|subject:|to:|date:|sent:|cc:|store\s*#|cashier|register)`)
+	receiptDateKeyword   = regexp.MustCompile(`(?i)\b(date|datum|fecha)\b`)
+	receiptHTMLRegex     = regexp.MustCompile(`(?i)<(html|body|br|p|div|table|tr|td)\b`)
+	receiptHTMLBreaks    = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h\d|table)>`)
+	receiptHTMLCells     = regexp.MustCompile(`(?i)</t[dh]>`)
+	receiptHTMLBlocks    = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)>`)
+	receiptHTMLTags      = regexp.MustCompile(`<[^>]*>`)
+	receiptCurrencyCodes = regexp.MustCompile(`\b(USD|EUR|GBP|CAD|AUD|CHF|JPY)\b`)
+
+	receiptIgnoreRegex    = regexp.MustCompile(`(?i)\b(change|cash|tendered|tend|visa|mastercard|amex|american express|discover|card|debit|credit|payment|paid|auth|approval|bezahlt|gegeben|rückgeld|wechselgeld|rendu|espèces|especes|carte|cambio|efectivo|tarjeta|you saved|savings|saved)\b`)
+	receiptSubtotalRegex  = regexp.MustCompile(`(?i)(\bsub\s*-?\s*total|\bzwischensumme|\bsous[\s-]total|\btotal\s+ht\b|\bbefore\s+tax|\bnet\s+amount|\bnetto\b)`)
+	receiptInclusiveRegex = regexp.MustCompile(`(?i)\b(incl|inkl|including|inc|ttc)\b`)
+	receiptTaxRegex       = regexp.MustCompile(`(?i)\b(tax|taxes|vat|gst|hst|pst|qst|mwst|ust|tva|iva|igv)\b`)
+	receiptTotalRegex     = regexp.MustCompile(`(?i)\b(total|amount\s+due|balance\s+due|to\s+pay|summe|gesamt|gesamtbetrag|zu\s+zahlen|montant|importe|a\s+pagar)\b`)
+	receiptTipRegex       = regexp.MustCompile(`(?i)\b(tip|gratuity|trinkgeld|pourboire|propina)\b`)
+	receiptDiscountRegex  = regexp.MustCompile(`(?i)\b(discount|coupon|promo|rabatt|remise|descuento|markdown)\b`)
+)
+
+// receiptMonths maps English, German, French and Spanish month names and
+// abbreviations to months.
+var receiptMonths = map[string]time.Month{
+	"january": 1, "jan": 1, "januar": 1, "janvier": 1, "janv": 1, "enero": 1, "ene": 1,
+	"february": 2, "feb": 2, "februar": 2, "février": 2, "fevrier": 2, "févr": 2, "fevr": 2, "febrero": 2,
+	"march": 3, "mar": 3, "märz": 3, "maerz": 3, "mär": 3, "mrz": 3, "mars": 3, "marzo": 3,
+	"april": 4, "apr": 4, "avril": 4, "avr": 4, "abril": 4, "abr": 4,
+	"may": 5, "mai": 5, "mayo": 5,
+	"june": 6, "jun": 6, "juni": 6, "juin": 6, "junio": 6,
+	"july": 7, "jul": 7, "juli": 7, "juillet": 7, "juil": 7, "julio": 7,
+	"august": 8, "aug": 8, "août": 8, "aout": 8, "agosto": 8, "ago": 8,
+	"september": 9, "sep": 9, "sept": 9, "septembre": 9, "septiembre": 9, "setiembre": 9,
+	"october": 10, "oct": 10, "oktober": 10, "okt": 10, "octobre": 10, "octubre": 10,
+	"november": 11, "nov": 11, "novembre": 11, "noviembre": 11,
+	"december": 12, "dec": 12, "dezember": 12, "dez": 12, "décembre": 12, "decembre": 12, "déc": 12, "diciembre": 12, "dic": 12,
+}
+
+// receiptAmount is an amount found on a line together with the text in
+// front of it.
+type receiptAmount struct {
+	value  float64
+	prefix string
+}
+
+// ParseReceipt extracts the merchant, date, totals and line items from
+// receipt text, which may be OCR output or the plain or HTML body of an
+// e-receipt email. It never fails on unrecognized content; anything that
+// could not be read is reported in Warnings.
+func ParseReceipt(text string, locale ReceiptLocale) (*models.ParsedReceipt, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("receipt text is empty")
+	}
+	if len(text) > MaxReceiptTextLength {
+		return nil, fmt.Errorf("receipt text cannot be longer than %d characters", MaxReceiptTextLength)
+	}
+
+	lines := receiptLines(text)
+	receipt := &models.ParsedReceipt{LineItems: []models.ReceiptLineItem{}, Warnings: []string{}}
+
+	receipt.Merchant = receiptMerchant(lines)
+	receipt.Date = receiptDate(lines, locale)
+	receipt.Currency = receiptCurrency(text)
+
+	var totals, taxes, taxTotals []float64
+	totalSeen := false
+	for _, line := range lines {
+		amount, ok := lastReceiptAmount(line, locale)
+		if !ok {
+			continue
+		}
+		label := amount.prefix
+
+		switch {
+		case receiptIgnoreRegex.MatchString(label):
+		case receiptSubtotalRegex.MatchString(label):
+			value := math.Abs(amount.value)
+			receipt.Subtotal = &value
+		case receiptTotalRegex.MatchString(label) && receiptInclusiveRegex.MatchString(label):
+			totals = append(totals, math.Abs(amount.value))
+			totalSeen = true
+		case receiptTaxRegex.MatchString(label):
+			if receiptTotalRegex.MatchString(label) {
+				taxTotals = append(taxTotals, math.Abs(amount.value))
+			} else {
+				taxes = append(taxes, math.Abs(amount.value))
+			}
+		case receiptTotalRegex.MatchString(label):
+			totals = append(totals, math.Abs(amount.value))
+			totalSeen = true
+		case totalSeen || amount.value == 0:
+			// Lines after the total belong to the payment section
+		case receiptTipRegex.MatchString(label):
+			receipt.LineItems = append(receipt.LineItems, models.ReceiptLineItem{Description: "Tip", Amount: amount.value})
+		case amount.value < 0 || receiptDiscountRegex.MatchString(label):
+			if len(receipt.LineItems) == 0 {
+				receipt.Warnings = append(receipt.Warnings, "A discount appears before any item and was ignored")
+				continue
+			}
+			last := &receipt.LineItems[len(receipt.LineItems)-1]
+			last.Amount = roundTo2(last.Amount - math.Abs(amount.value))
+		default:
+			if item, ok := receiptLineItem(label, amount.value, locale); ok {
+				receipt.LineItems = append(receipt.LineItems, item)
+			}
+		}
+	}
+
+	switch {
+	case len(taxTotals) > 0:
+		tax := maxFloat(taxTotals)
+		receipt.Tax = &tax
+	case len(taxes) > 0:
+		var tax float64
+		for _, t := range taxes {
+			tax += t
+		}
+		tax = roundTo2(tax)
+		receipt.Tax = &tax
+	}
+
+	if len(totals) > 0 {
+		total := maxFloat(totals)
+		receipt.Total = &total
+	} else {
+		var total float64
+		switch {
+		case receipt.Subtotal != nil:
+			total = *receipt.Subtotal
+			receipt.Warnings = append(receipt.Warnings, "No total found; using the subtotal plus tax")
+		case len(receipt.LineItems) > 0:
+			total = sumLineItems(receipt.LineItems)
+			receipt.Warnings = append(receipt.Warnings, "No total found; using the sum of the line items plus tax")
+		}
+		if receipt.Tax != nil {
+			total += *receipt.Tax
+		}
+		if total > 0 {
+			total = roundTo2(total)
+			receipt.Total = &total
+		} else {
+			receipt.Warnings = append(receipt.Warnings, "No total found")
+		}
+	}
+
+	if receipt.Merchant == nil {
+		receipt.Warnings = append(receipt.Warnings, "No merchant found")
+	}
+	if receipt.Date == nil {
+		receipt.Warnings = append(receipt.Warnings, "No date found")
+	}
+	if len(receipt.LineItems) > 0 && receipt.Total != nil {
+		if _, ok := receiptSplits(receipt); !ok {
+			receipt.Warnings = append(receipt.Warnings, fmt.Sprintf(
+				"Line items add up to %.2f but the total is %.2f; the draft is not split",
+				sumLineItems(receipt.LineItems), *receipt.Total))
+		}
+	}
+
+	return receipt, nil
+}
+
+// ReceiptDraft builds an unsaved expense from a parsed receipt. The line
+// items become split lines, with tax as its own line when the items exclude
+// it, as long as they add up to the total. The date defaults to today.
+func ReceiptDraft(receipt *models.ParsedReceipt, today time.Time) models.Transaction {
+	draft := models.Transaction{
+		TransactionType: models.TransactionTypeExpense,
+		Description:     receipt.Merchant,
+		TransactionDate: time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC),
+		Currency:        receipt.Currency,
+	}
+	if receipt.Date != nil {
+		draft.TransactionDate = *receipt.Date
+	}
+	if receipt.Total != nil {
+		draft.Amount = *receipt.Total
+	}
+	if splits, ok := receiptSplits(receipt); ok {
+		draft.Splits = splits
+	}
+	return draft
+}
+
+// receiptSplits returns the split lines for the receipt and whether the
+// items reconcile with the total. A single reconciled line yields no splits.
+func receiptSplits(receipt *models.ParsedReceipt) ([]models.TransactionSplit, bool) {
+	if receipt.Total == nil || len(receipt.LineItems) == 0 {
+		return nil, false
+	}
+
+	var splits []models.TransactionSplit
+	for _, item := range receipt.LineItems {
+		if item.Amount <= 0 {
+			return nil, false
+		}
+		description := item.Description
+		splits = append(splits, models.TransactionSplit{Amount: item.Amount, Description: &description})
+	}
+
+	items := toCents(sumLineItems(receipt.LineItems))
+	total := toCents(*receipt.Total)
+	switch {
+	case items == total:
+	case receipt.Tax != nil && *receipt.Tax > 0 && items+toCents(*receipt.Tax) == total:
+		description := "Tax"
+		splits = append(splits, models.TransactionSplit{Amount: *receipt.Tax, Description: &description})
+	default:
+		return nil, false
+	}
+
+	if len(splits) < 2 {
+		return nil, true
+	}
+	return splits, true
+}
+
+// receiptLines turns the text into trimmed, non-empty lines, converting HTML
+// e-receipts to text first.
+func receiptLines(text string) []string {
+	if receiptHTMLRegex.MatchString(text) {
+		text = receiptHTMLBlocks.ReplaceAllString(text, "")
+		text = receiptHTMLBreaks.ReplaceAllString(text, "\n")
+		text = receiptHTMLCells.ReplaceAllString(text, " ")
+		text = receiptHTMLTags.ReplaceAllString(text, "")
+		text = html.UnescapeString(text)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		line = strings.Join(strings.FieldsFunc(line, func(r rune) bool {
+			return r == '\t' || r == ' '
+		}), " ")
+		line = strings.TrimFunc(line, func(r rune) bool {
+			return unicode.IsSpace(r) && r != ' '
+		})
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// receiptMerchant takes the sender of an e-receipt email, or else the first
+// plausible name among the header lines.
+func receiptMerchant(lines []string) *string {
+	for _, line := range lines {
+		match := receiptFromRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		name := strings.Trim(receiptEmailRegex.ReplaceAllString(match[1], ""), ` "'`)
+		if name == "" {
+			if email := receiptEmailRegex.FindStringSubmatch(match[1]); email != nil {
+				name = titleCase(email[1])
+			}
+		}
+		if name != "" {
+			return &name
+		}
+	}
+
+	for i, line := range lines {
+		if i >= 6 {
+			break
+		}
+		if receiptHeaderSkip.MatchString(line) || strings.Contains(line, "@") {
+			continue
+		}
+		if _, ok := lastReceiptAmount(line, ReceiptLocale{}); ok {
+			continue
+		}
+		if findReceiptDate(line, ReceiptLocale{}) != nil {
+			continue
+		}
+
+		letters := 0
+		for _, r := range line {
+			if unicode.IsLetter(r) {
+				letters++
+			}
+		}
+		if letters < 3 || letters*2 < len([]rune(line)) {
+			continue
+		}
+
+		name := CleanMerchantDescription(line)
+		if name == "" {
+			name = line
+		}
+		return &name
+	}
+
+	return nil
+}
+
+// receiptDate prefers a date on a line labelled as the date and otherwise
+// takes the first date in the text.
+func receiptDate(lines []string, locale ReceiptLocale) *time.Time {
+	var first *time.Time
+	for _, line := range lines {
+		date := findReceiptDate(line, locale)
+		if date == nil {
+			continue
+		}
+		if receiptDateKeyword.MatchString(line) {
+			return date
+		}
+		if first == nil {
+			first = date
+		}
+	}
+	return first
+}
+
+// findReceiptDate returns the first plausible date on the line.
+func findReceiptDate(line string, locale ReceiptLocale) *time.Time {
+	if match := isoDateRegex.FindStringSubmatch(line); match != nil {
+		if date := receiptCalendarDate(match[1], match[2], match[3]); date != nil {
+			return date
+		}
+	}
+
+	if match := dayMonthNameDateRegex.FindStringSubmatch(line); match != nil {
+		if month, ok := receiptMonths[strings.ToLower(match[2])]; ok {
+			if date := receiptCalendarDate(match[3], strconv.Itoa(int(month)), match[1]); date != nil {
+				return date
+			}
+		}
+	}
+	if match := monthNameDayDateRegex.FindStringSubmatch(line); match != nil {
+		if month, ok := receiptMonths[strings.ToLower(match[1])]; ok {
+			if date := receiptCalendarDate(match[3], strconv.Itoa(int(month)), match[2]); date != nil {
+				return date
+			}
+		}
+	}
+
+	if match := numericDateRegex.FindStringSubmatch(line); match != nil {
+		first, _ := strconv.Atoi(match[1])
+		second, _ := strconv.Atoi(match[2])
+		year := match[3]
+		if len(year) == 2 {
+			year = "20" + year
+		}
+
+		dayFirst := locale.DayFirst
+		if first > 12 {
+			dayFirst = true
+		} else if second > 12 {
+			dayFirst = false
+		}
+		if dayFirst {
+			return receiptCalendarDate(year, match[2], match[1])
+		}
+		return receiptCalendarDate(year, match[1], match[2])
+	}
+
+	return nil
+}
+
+// receiptCalendarDate validates the parts and returns the date, or nil when
+// it does not exist or is implausible for a receipt.
+func receiptCalendarDate(yearText, monthText, dayText string) *time.Time {
+	year, _ := strconv.Atoi(yearText)
+	month, _ := strconv.Atoi(monthText)
+	day, _ := strconv.Atoi(dayText)
+	if year < 1990 || year > 2100 || month < 1 || month > 12 || day < 1 {
+		return nil
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return nil
+	}
+	return &date
+}
+
+// receiptCurrency detects an ISO code or currency symbol in the text.
+func receiptCurrency(text string) *string {
+	if match := receiptCurrencyCodes.FindString(text); match != "" {
+		return &match
+	}
+
+	symbols := map[string]string{"€": "EUR", "£": "GBP", "¥": "JPY", "$": "USD"}
+	for _, symbol := range []string{"€", "£", "¥", "$"} {
+		if strings.Contains(text, symbol) {
+			code := symbols[symbol]
+			return &code
+		}
+	}
+	return nil
+}
+
+// lastReceiptAmount finds the rightmost amount on the line, ignoring dates
+// and percentages, and returns it with the text in front of it.
+func lastReceiptAmount(line string, locale ReceiptLocale) (receiptAmount, bool) {
+	cleaned := isoDateRegex.ReplaceAllString(line, " ")
+	cleaned = numericDateRegex.ReplaceAllString(cleaned, " ")
+	cleaned = receiptPercentRegex.ReplaceAllString(cleaned, " ")
+
+	pattern := receiptAmountRegex
+	if locale.SpaceGrouping {
+		pattern = receiptSpacedAmountRegex
+	}
+
+	matches := pattern.FindAllStringSubmatchIndex(cleaned, -1)
+	if len(matches) == 0 {
+		return receiptAmount{}, false
+	}
+	m := matches[len(matches)-1]
+
+	integer := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, cleaned[m[6]:m[7]])
+	value, err := strconv.ParseFloat(integer+"."+cleaned[m[8]:m[9]], 64)
+	if err != nil {
+		return receiptAmount{}, false
+	}
+	if m[2] >= 0 || m[10] >= 0 {
+		value = -value
+	}
+
+	return receiptAmount{value: value, prefix: strings.TrimSpace(cleaned[:m[0]])}, true
+}
+
+// receiptLineItem builds an item from the text in front of its amount,
+// reading a quantity and unit price such as "2 @ 0.59" when present.
+func receiptLineItem(label string, amount float64, locale ReceiptLocale) (models.ReceiptLineItem, bool) {
+	item := models.ReceiptLineItem{Amount: amount}
+
+	if match := receiptQuantityRegex.FindStringSubmatchIndex(label); match != nil {
+		if quantity, err := strconv.ParseFloat(strings.Replace(label[match[2]:match[3]], ",", ".", 1), 64); err == nil && quantity > 0 {
+			item.Quantity = &quantity
+			if match[4] >= 0 {
+				if unit, ok := lastReceiptAmount(label[match[4]:match[5]], locale); ok {
+					unitPrice := unit.value
+					item.UnitPrice = &unitPrice
+				}
+			}
+			label = label[:match[0]] + " " + label[match[1]:]
+		}
+	}
+
+	label = receiptSKURegex.ReplaceAllString(label, " ")
+	label = strings.Trim(strings.Join(strings.Fields(label), " "), " .:-*$€£")
+
+	hasLetter, hasLower := false, false
+	for _, r := range label {
+		if unicode.IsLetter(r) {
+			hasLetter = true
+			if unicode.IsLower(r) {
+				hasLower = true
+			}
+		}
+	}
+	if !hasLetter {
+		return item, false
+	}
+	if !hasLower {
+		label = titleCase(label)
+	}
+
+	item.Description = label
+	return item, true
+}
+
+func sumLineItems(items []models.ReceiptLineItem) float64 {
+	var cents int64
+	for _, item := range items {
+		cents += toCents(item.Amount)
+	}
+	return float64(cents) / 100
+}
+
+func maxFloat(values []float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v > result {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustLocale(t *testing.T, tag string) ReceiptLocale {
+	locale, err := ParseReceiptLocale(tag)
+	assert.NoError(t, err)
+	return locale
+}
+
+func TestParseReceiptLocale(t *testing.T) {
+	assert.Equal(t, ReceiptLocale{DayFirst: false}, mustLocale(t, ""))
+	assert.Equal(t, ReceiptLocale{DayFirst: false}, mustLocale(t, "en"))
+	assert.Equal(t, ReceiptLocale{DayFirst: true}, mustLocale(t, "en-GB"))
+	assert.Equal(t, ReceiptLocale{DayFirst: true}, mustLocale(t, "de_DE"))
+	assert.Equal(t, ReceiptLocale{DayFirst: true, SpaceGrouping: true}, mustLocale(t, "fr-FR"))
+
+	_, err := ParseReceiptLocale("english please")
+	assert.Error(t, err)
+}
+
+func TestParseReceipt_USGrocery(t *testing.T) {
+	text := `
+CORNER MARKET #0423
+123 MAIN ST, SPRINGFIELD IL
+TEL 555-201-3344
+03/04/2026  14:32
+
+BANANAS 2 @ 0.59        1.18 F
+012345678901 MILK 2%    3.49 F
+COFFEE BEANS           12.99 F
+  COUPON                1.00-
+SUBTOTAL               16.66
+TAX 8.25%               1.07
+TOTAL                  17.73
+VISA TEND              17.73
+CHANGE DUE              0.00
+`
+	receipt, err := ParseReceipt(text, mustLocale(t, "en-US"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Corner Market", *receipt.Merchant)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), *receipt.Date)
+	assert.Nil(t, receipt.Currency)
+	assert.Equal(t, 16.66, *receipt.Subtotal)
+	assert.Equal(t, 1.07, *receipt.Tax)
+	assert.Equal(t, 17.73, *receipt.Total)
+	assert.Empty(t, receipt.Warnings)
+
+	if assert.Len(t, receipt.LineItems, 3) {
+		assert.Equal(t, "Bananas", receipt.LineItems[0].Description)
+		assert.Equal(t, 2.0, *receipt.LineItems[0].Quantity)
+		assert.Equal(t, 0.59, *receipt.LineItems[0].UnitPrice)
+		assert.Equal(t, 1.18, receipt.LineItems[0].Amount)
+		assert.Equal(t, "Milk", receipt.LineItems[1].Description)
+		assert.Equal(t, "Coffee Beans", receipt.LineItems[2].Description)
+		assert.Equal(t, 11.99, receipt.LineItems[2].Amount) // Coupon applied
+	}
+
+	draft := ReceiptDraft(receipt, time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC))
+	assert.Equal(t, 17.73, draft.Amount)
+	assert.Equal(t, "Corner Market", *draft.Description)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), draft.TransactionDate)
+	if assert.Len(t, draft.Splits, 4) {
+		assert.Equal(t, "Tax", *draft.Splits[3].Description)
+		assert.Equal(t, 1.07, draft.Splits[3].Amount)
+	}
+	assert.NoError(t, ValidateSplits(draft.Amount, draft.Splits))
+}
+
+func TestParseReceipt_GermanVATIncluded(t *testing.T) {
+	text := `
+Bäckerei Müller
+Hauptstraße 5, 10115 Berlin
+Datum: 03.04.2026 08:15
+
+Brötchen 4 x 0,45        1,80
+Kaffee                   3,20
+Kuchen                   1.234,00
+SUMME EUR            1.239,00
+Gegeben Bar          1.240,00
+Rückgeld                 1,00
+enthaltene MwSt 7%      81,06
+`
+	receipt, err := ParseReceipt(text, mustLocale(t, "de-DE"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Bäckerei Müller", *receipt.Merchant)
+	assert.Equal(t, time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC), *receipt.Date)
+	assert.Equal(t, "EUR", *receipt.Currency)
+	assert.Equal(t, 1239.0, *receipt.Total)
+	assert.Equal(t, 81.06, *receipt.Tax)
+	assert.Len(t, receipt.LineItems, 3)
+	assert.Equal(t, 4.0, *receipt.LineItems[0].Quantity)
+	assert.Equal(t, 0.45, *receipt.LineItems[0].UnitPrice)
+
+	// Prices include VAT, so the items alone make up the total
+	draft := ReceiptDraft(receipt, time.Now())
+	assert.Len(t, draft.Splits, 3)
+	assert.NoError(t, ValidateSplits(draft.Amount, draft.Splits))
+}
+
+func TestParseReceipt_FrenchSpaceGrouping(t *testing.T) {
+	text := "LIBRAIRIE DU PORT\n12 janv. 2026\nLivre rare 1 250,00\nMarque-page 2,50\nTOTAL TTC 1 252,50 €\n"
+
+	receipt, err := ParseReceipt(text, mustLocale(t, "fr-FR"))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC), *receipt.Date)
+	assert.Equal(t, 1252.5, *receipt.Total)
+	assert.Equal(t, "EUR", *receipt.Currency)
+	assert.Len(t, receipt.LineItems, 2)
+	assert.Equal(t, 1250.0, receipt.LineItems[0].Amount)
+}
+
+func TestParseReceipt_HTMLEmail(t *testing.T) {
+	text := `From: "Coffee Roasters Co" <orders@coffeeroasters.example>
+Subject: Your receipt
+Date: Tue, 14 Jan 2026 10:00:00 -0500
+
+<html><head><style>td { color: red }</style></head><body>
+<table>
+<tr><td>House Blend 1lb</td><td>$16.00</td></tr>
+<tr><td>Shipping</td><td>$5.00</td></tr>
+<tr><td>Sales tax</td><td>$1.32</td></tr>
+<tr><td><b>Order total</b></td><td>$22.32</td></tr>
+</table>
+<p>Thanks &amp; enjoy!</p></body></html>`
+
+	receipt, err := ParseReceipt(text, ReceiptLocale{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Coffee Roasters Co", *receipt.Merchant)
+	assert.Equal(t, time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC), *receipt.Date)
+	assert.Equal(t, "USD", *receipt.Currency)
+	assert.Equal(t, 22.32, *receipt.Total)
+	assert.Equal(t, 1.32, *receipt.Tax)
+	assert.Len(t, receipt.LineItems, 2)
+
+	draft := ReceiptDraft(receipt, time.Now())
+	assert.Len(t, draft.Splits, 3)
+}
+
+func TestParseReceipt_SenderWithoutDisplayName(t *testing.T) {
+	receipt, err := ParseReceipt("From: receipts@bigstore.example\nTotal: 9.99", ReceiptLocale{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bigstore", *receipt.Merchant)
+}
+
+func TestParseReceipt_AmbiguousDates(t *testing.T) {
+	us := mustLocale(t, "en-US")
+	gb := mustLocale(t, "en-GB")
+
+	assert.Equal(t, time.Month(3), findReceiptDate("03/04/2026", us).Month())
+	assert.Equal(t, time.Month(4), findReceiptDate("03/04/2026", gb).Month())
+	// An impossible month settles the order regardless of locale
+	assert.Equal(t, time.Month(12), findReceiptDate("25/12/26", us).Month())
+	assert.Equal(t, time.Month(12), findReceiptDate("12/25/26", gb).Month())
+	assert.Nil(t, findReceiptDate("31/02/2026", gb))
+	assert.Equal(t, 2026, findReceiptDate("2026-02-28", us).Year())
+	assert.Equal(t, time.Month(3), findReceiptDate("March 5th, 2026", us).Month())
+}
+
+func TestParseReceipt_Mismatch(t *testing.T) {
+	text := "DINER\nBurger 10.00\nFries 3.00\nTOTAL 20.00\n"
+
+	receipt, err := ParseReceipt(text, ReceiptLocale{})
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, *receipt.Total)
+	assert.Contains(t, receipt.Warnings, "No date found")
+	assert.Contains(t, receipt.Warnings, "Line items add up to 13.00 but the total is 20.00; the draft is not split")
+	assert.Empty(t, ReceiptDraft(receipt, time.Now()).Splits)
+}
+
+func TestParseReceipt_NoTotal(t *testing.T) {
+	receipt, err := ParseReceipt("Widget 4.00\nGadget 6.00\nTax 0.80", ReceiptLocale{})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.8, *receipt.Total)
+	assert.Contains(t, receipt.Warnings, "No total found; using the sum of the line items plus tax")
+
+	_, err = ParseReceipt("   ", ReceiptLocale{})
+	assert.Error(t, err)
+}
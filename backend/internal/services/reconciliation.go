@@ -0,0 +1,8 @@
+package services
+
+// ReconciliationDifference returns how far the cleared balance is from the
+// statement balance, rounded to cents. A reconciliation can only be completed
+// once this reaches zero.
+func ReconciliationDifference(statementBalance, clearedBalance float64) float64 {
+	return float64(toCents(statementBalance)-toCents(clearedBalance)) / 100
+}
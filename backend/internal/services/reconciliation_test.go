@@ -0,0 +1,14 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciliationDifference(t *testing.T) {
+	assert.Equal(t, 0.0, ReconciliationDifference(1234.56, 1234.56))
+	assert.Equal(t, 0.0, ReconciliationDifference(0.3, 0.1+0.2))
+	assert.Equal(t, 25.5, ReconciliationDifference(1000.0, 974.5))
+	assert.Equal(t, -10.01, ReconciliationDifference(-50.0, -39.99))
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redisTimeout bounds each command when the context has no earlier deadline
+const redisTimeout = 2 * time.Second
+
+// redisMaxIdle is how many idle connections a RedisCache keeps
+const redisMaxIdle = 8
+
+// RedisCache is a ReportCacheBackend on a Redis-compatible server, speaking
+// RESP over TCP. Entries are set with an expiry and counters without one, so
+// the server should evict with a volatile-* maxmemory policy to keep the
+// counters.
+type RedisCache struct {
+	addr     string
+	username string
+	password string
+	db       int
+	tls      *tls.Config
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisCache creates a backend for redis://[user:password@]host[:port][/db]
+// or rediss:// for TLS. Connections are opened on first use.
+func NewRedisCache(rawURL string) (*RedisCache, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid redis URL %q", rawURL)
+	}
+
+	cache := &RedisCache{addr: parsed.Host, idle: make(chan *redisConn, redisMaxIdle)}
+	switch parsed.Scheme {
+	case "redis":
+	case "rediss":
+		cache.tls = &tls.Config{ServerName: parsed.Hostname()}
+	default:
+		return nil, fmt.Errorf("redis URL must use redis:// or rediss://")
+	}
+	if parsed.Port() == "" {
+		cache.addr = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if parsed.User != nil {
+		cache.password, _ = parsed.User.Password()
+		cache.username = parsed.User.Username()
+		if cache.password == "" {
+			// redis://secret@host passes only a password
+			cache.password, cache.username = cache.username, ""
+		}
+	}
+	if path := strings.Trim(parsed.Path, "/"); path != "" {
+		if cache.db, err = strconv.Atoi(path); err != nil || cache.db < 0 {
+			return nil, fmt.Errorf("invalid redis database %q", path)
+		}
+	}
+	return cache, nil
+}
+
+// Name identifies the backend in cache stats
+func (r *RedisCache) Name() string {
+	return "redis"
+}
+
+// Get returns the value stored under key
+func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected redis reply %v to GET", reply)
+	}
+	return value, true, nil
+}
+
+// Set stores value under key for ttl
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := r.do(ctx, "SET", key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+// Incr increments the counter under key
+func (r *RedisCache) Incr(ctx context.Context, key string) (int64, error) {
+	reply, err := r.do(ctx, "INCR", key)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected redis reply %v to INCR", reply)
+	}
+	return value, nil
+}
+
+// Close closes the idle connections
+func (r *RedisCache) Close() {
+	for {
+		select {
+		case conn := <-r.idle:
+			conn.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// do runs one command on an idle or new connection. A connection that failed
+// mid-command is closed rather than reused.
+func (r *RedisCache) do(ctx context.Context, args ...string) (interface{}, error) {
+	var conn *redisConn
+	select {
+	case conn = <-r.idle:
+	default:
+		var err error
+		if conn, err = r.dial(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := conn.command(ctx, args...)
+	var serverErr redisError
+	if err != nil && !errors.As(err, &serverErr) {
+		conn.conn.Close()
+		return nil, err
+	}
+
+	select {
+	case r.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+	return reply, err
+}
+
+// dial opens a connection, authenticating and selecting the database
+func (r *RedisCache) dial(ctx context.Context) (*redisConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	raw, err := dialer.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	if r.tls != nil {
+		tlsConn := tls.Client(raw, r.tls)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			raw.Close()
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		raw = tlsConn
+	}
+
+	conn := &redisConn{conn: raw, reader: bufio.NewReader(raw)}
+	var setup [][]string
+	if r.password != "" {
+		if r.username != "" {
+			setup = append(setup, []string{"AUTH", r.username, r.password})
+		} else {
+			setup = append(setup, []string{"AUTH", r.password})
+		}
+	}
+	if r.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	for _, args := range setup {
+		if _, err := conn.command(ctx, args...); err != nil {
+			raw.Close()
+			return nil, fmt.Errorf("failed to set up redis connection: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// redisError is an error reply from the server. The connection stays usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// command writes a command as an array of bulk strings and reads its reply
+func (c *redisConn) command(ctx context.Context, args ...string) (interface{}, error) {
+	deadline := time.Now().Add(redisTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return readRedisReply(c.reader)
+}
+
+// readRedisReply parses one RESP reply: simple strings as string, integers
+// as int64, bulk strings as []byte, arrays as []interface{} and nil bulk
+// strings or arrays as nil.
+func readRedisReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis bulk length %q", body)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis array length %q", body)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readRedisReply(reader); err != nil {
+				var serverErr redisError
+				if !errors.As(err, &serverErr) {
+					return nil, err
+				}
+				items[i] = serverErr
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unknown redis reply type %q", kind)
+}
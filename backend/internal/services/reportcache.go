@@ -0,0 +1,319 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/config"
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// ReportCacheBackend stores cached report bodies. Counters changed with Incr
+// must outlive every entry written after them: they are never evicted.
+type ReportCacheBackend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Incr(ctx context.Context, key string) (int64, error)
+	Name() string
+}
+
+// ReportCache caches rendered reports per user. Every key carries the user's
+// generation counter; invalidating a user bumps it, so none of their earlier
+// entries is read again and they age out of the backend. A nil cache is a
+// disabled one.
+type ReportCache struct {
+	backend ReportCacheBackend
+	ttl     time.Duration
+
+	mu            sync.Mutex
+	counts        map[string]*models.ReportCacheCounts
+	invalidations int64
+	errors        int64
+}
+
+// NewReportCache creates a report cache keeping entries for ttl
+func NewReportCache(backend ReportCacheBackend, ttl time.Duration) *ReportCache {
+	return &ReportCache{
+		backend: backend,
+		ttl:     ttl,
+		counts:  make(map[string]*models.ReportCacheCounts),
+	}
+}
+
+// NewReportCacheFromConfig creates the configured report cache, or returns
+// nil when caching is turned off.
+func NewReportCacheFromConfig(cfg *config.Config) (*ReportCache, error) {
+	switch cfg.ReportCacheBackend {
+	case "none", "off", "":
+		return nil, nil
+	case "memory":
+		return NewReportCache(NewLRUCache(int(cfg.ReportCacheSize)), cfg.ReportCacheTTL), nil
+	case "redis":
+		backend, err := NewRedisCache(cfg.ReportCacheRedisURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewReportCache(backend, cfg.ReportCacheTTL), nil
+	}
+	return nil, fmt.Errorf("unknown report cache backend %q", cfg.ReportCacheBackend)
+}
+
+// epochKey is the key of the counter expiring every user's reports at once
+const epochKey = "report-cache:epoch"
+
+// generationKey is the key of the user's generation counter
+func generationKey(userID string) string {
+	return "report-cache:generation:" + userID
+}
+
+// counter reads a generation counter, which is zero until first incremented
+func (c *ReportCache) counter(ctx context.Context, key string) (int64, error) {
+	raw, ok, err := c.backend.Get(ctx, key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return strconv.ParseInt(string(raw), 10, 64)
+}
+
+// Lookup returns the cached body of the report for the parameters. On a miss
+// it returns the key to Store the freshly built report under; the key pins
+// the generations seen now, so a report built while the user's data changed
+// is never served afterwards.
+func (c *ReportCache) Lookup(ctx context.Context, userID, report, params string) ([]byte, string, bool) {
+	if c == nil {
+		return nil, "", false
+	}
+
+	epoch, err := c.counter(ctx, epochKey)
+	if err != nil {
+		c.fail("read epoch", err)
+		return nil, "", false
+	}
+	generation, err := c.counter(ctx, generationKey(userID))
+	if err != nil {
+		c.fail("read generation", err)
+		return nil, "", false
+	}
+
+	sum := sha256.Sum256([]byte(report + "\x00" + params))
+	key := fmt.Sprintf("report-cache:%s:%d.%d:%s", userID, epoch, generation, hex.EncodeToString(sum[:]))
+
+	body, hit, err := c.backend.Get(ctx, key)
+	if err != nil {
+		c.fail("read report", err)
+		hit = false
+	}
+	c.count(report, hit)
+	if hit {
+		return body, key, true
+	}
+	return nil, key, false
+}
+
+// Store caches a report body under a key from Lookup
+func (c *ReportCache) Store(ctx context.Context, key string, body []byte) {
+	if c == nil || key == "" {
+		return
+	}
+	if err := c.backend.Set(ctx, key, body, c.ttl); err != nil {
+		c.fail("store report", err)
+	}
+}
+
+// Invalidate drops every cached report of the user
+func (c *ReportCache) Invalidate(ctx context.Context, userID string) {
+	c.bump(ctx, generationKey(userID))
+}
+
+// InvalidateAll drops every cached report of every user
+func (c *ReportCache) InvalidateAll(ctx context.Context) {
+	c.bump(ctx, epochKey)
+}
+
+func (c *ReportCache) bump(ctx context.Context, key string) {
+	if c == nil {
+		return
+	}
+	if _, err := c.backend.Incr(ctx, key); err != nil {
+		c.fail("invalidate", err)
+		return
+	}
+	c.mu.Lock()
+	c.invalidations++
+	c.mu.Unlock()
+}
+
+// Stats returns the hit and miss counts since the cache was created
+func (c *ReportCache) Stats() models.ReportCacheStats {
+	if c == nil {
+		return models.ReportCacheStats{Backend: "none", Reports: map[string]models.ReportCacheCounts{}}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := models.ReportCacheStats{
+		Backend:       c.backend.Name(),
+		Invalidations: c.invalidations,
+		Errors:        c.errors,
+		Reports:       make(map[string]models.ReportCacheCounts, len(c.counts)),
+	}
+	for report, counts := range c.counts {
+		entry := *counts
+		entry.HitRate = hitRate(entry.Hits, entry.Misses)
+		stats.Reports[report] = entry
+		stats.Hits += entry.Hits
+		stats.Misses += entry.Misses
+	}
+	stats.HitRate = hitRate(stats.Hits, stats.Misses)
+	return stats
+}
+
+func (c *ReportCache) count(report string, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts, ok := c.counts[report]
+	if !ok {
+		counts = &models.ReportCacheCounts{}
+		c.counts[report] = counts
+	}
+	if hit {
+		counts.Hits++
+	} else {
+		counts.Misses++
+	}
+}
+
+func (c *ReportCache) fail(action string, err error) {
+	log.Printf("Warning: Report cache failed to %s: %v", action, err)
+	c.mu.Lock()
+	c.errors++
+	c.mu.Unlock()
+}
+
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return roundTo2(float64(hits) / float64(hits+misses) * 100)
+}
+
+// RunInvalidationListener drops a user's cached reports whenever the
+// database reports a change to their data, until the context is cancelled.
+// Every cached report is dropped whenever the listener restarts after a
+// failure, as changes may have been missed.
+func (c *ReportCache) RunInvalidationListener(ctx context.Context, listen ChangeListener) {
+	if c == nil {
+		return
+	}
+
+	followChanges(ctx, "Report cache invalidation listener", listen,
+		func(userID string) { c.Invalidate(ctx, userID) },
+		func() { c.InvalidateAll(ctx) })
+}
+
+// LRUCache is an in-process ReportCacheBackend holding up to a fixed number
+// of entries, evicting the least recently used first.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // Front is the most recently used
+	counters map[string]int64
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache creates an in-process cache of up to capacity entries
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		counters: make(map[string]int64),
+		now:      time.Now,
+	}
+}
+
+// Name identifies the backend in cache stats
+func (l *LRUCache) Name() string {
+	return "memory"
+}
+
+// Get returns an unexpired entry or counter
+func (l *LRUCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if counter, ok := l.counters[key]; ok {
+		return []byte(strconv.FormatInt(counter, 10)), true, nil
+	}
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if !l.now().Before(entry.expires) {
+		l.order.Remove(element)
+		delete(l.entries, key)
+		return nil, false, nil
+	}
+	l.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores an entry, evicting the least recently used ones over capacity
+func (l *LRUCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := l.now().Add(ttl)
+	if element, ok := l.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Incr increments a counter, which is kept apart from the entries and never
+// evicted
+func (l *LRUCache) Incr(ctx context.Context, key string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.counters[key]++
+	return l.counters[key], nil
+}
+
+// Len returns how many entries are held
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
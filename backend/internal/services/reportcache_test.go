@@ -0,0 +1,164 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	now := forecastDate(2026, 5, 1)
+	cache := NewLRUCache(2)
+	cache.now = func() time.Time { return now }
+
+	assert.NoError(t, cache.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.NoError(t, cache.Set(ctx, "b", []byte("2"), time.Minute))
+	_, ok, _ := cache.Get(ctx, "a")
+	assert.True(t, ok)
+
+	// b is now the least recently used
+	assert.NoError(t, cache.Set(ctx, "c", []byte("3"), time.Minute))
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, cache.Len())
+
+	now = now.Add(time.Minute)
+	_, ok, _ = cache.Get(ctx, "a")
+	assert.False(t, ok)
+
+	// Counters are kept apart and never evicted
+	for i := 0; i < 3; i++ {
+		_, _ = cache.Incr(ctx, "counter")
+	}
+	assert.NoError(t, cache.Set(ctx, "d", []byte("4"), time.Minute))
+	assert.NoError(t, cache.Set(ctx, "e", []byte("5"), time.Minute))
+	value, ok, _ := cache.Get(ctx, "counter")
+	assert.True(t, ok)
+	assert.Equal(t, "3", string(value))
+}
+
+func TestReportCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewReportCache(NewLRUCache(100), time.Minute)
+
+	_, key, hit := cache.Lookup(ctx, "u-1", "monthly-summary", "2026-05")
+	assert.False(t, hit)
+	cache.Store(ctx, key, []byte(`{"total":1}`))
+
+	body, _, hit := cache.Lookup(ctx, "u-1", "monthly-summary", "2026-05")
+	assert.True(t, hit)
+	assert.Equal(t, `{"total":1}`, string(body))
+
+	_, _, hit = cache.Lookup(ctx, "u-1", "monthly-summary", "2026-04")
+	assert.False(t, hit)
+	_, _, hit = cache.Lookup(ctx, "u-2", "monthly-summary", "2026-05")
+	assert.False(t, hit)
+
+	// A report built before an invalidation is not served after it
+	_, staleKey, _ := cache.Lookup(ctx, "u-2", "cash-flow", "")
+	cache.Invalidate(ctx, "u-2")
+	cache.Store(ctx, staleKey, []byte(`{}`))
+	_, _, hit = cache.Lookup(ctx, "u-2", "cash-flow", "")
+	assert.False(t, hit)
+
+	cache.Invalidate(ctx, "u-1")
+	_, _, hit = cache.Lookup(ctx, "u-1", "monthly-summary", "2026-05")
+	assert.False(t, hit)
+
+	stats := cache.Stats()
+	assert.Equal(t, "memory", stats.Backend)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(6), stats.Misses)
+	assert.Equal(t, 14.29, stats.HitRate)
+	assert.Equal(t, int64(2), stats.Invalidations)
+	assert.Equal(t, int64(1), stats.Reports["monthly-summary"].Hits)
+	assert.Equal(t, int64(2), stats.Reports["cash-flow"].Misses)
+}
+
+func TestReportCache_InvalidateAll(t *testing.T) {
+	ctx := context.Background()
+	cache := NewReportCache(NewLRUCache(100), time.Minute)
+
+	_, key, _ := cache.Lookup(ctx, "u-1", "summary", "")
+	cache.Store(ctx, key, []byte(`{}`))
+	cache.InvalidateAll(ctx)
+	_, _, hit := cache.Lookup(ctx, "u-1", "summary", "")
+	assert.False(t, hit)
+}
+
+func TestReportCache_Disabled(t *testing.T) {
+	var cache *ReportCache
+	_, key, hit := cache.Lookup(context.Background(), "u-1", "summary", "")
+	assert.False(t, hit)
+	cache.Store(context.Background(), key, []byte(`{}`))
+	cache.Invalidate(context.Background(), "u-1")
+	assert.Equal(t, "none", cache.Stats().Backend)
+}
+
+func TestReportCache_RunInvalidationListener(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cache := NewReportCache(NewLRUCache(100), time.Minute)
+	_, key, _ := cache.Lookup(ctx, "u-1", "summary", "")
+	cache.Store(ctx, key, []byte(`{}`))
+
+	cache.RunInvalidationListener(ctx, func(ctx context.Context, onChange func(userID string)) error {
+		onChange("u-1")
+		cancel()
+		return errors.New("connection closed")
+	})
+
+	_, _, hit := cache.Lookup(context.Background(), "u-1", "summary", "")
+	assert.False(t, hit)
+}
+
+func TestNewRedisCache(t *testing.T) {
+	cache, err := NewRedisCache("redis://:secret@cache.internal/2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "cache.internal:6379", cache.addr)
+		assert.Equal(t, "secret", cache.password)
+		assert.Empty(t, cache.username)
+		assert.Equal(t, 2, cache.db)
+		assert.Nil(t, cache.tls)
+	}
+
+	cache, err = NewRedisCache("rediss://reports:pw@cache.internal:6380")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "reports", cache.username)
+		assert.NotNil(t, cache.tls)
+	}
+
+	_, err = NewRedisCache("http://cache.internal")
+	assert.Error(t, err)
+	_, err = NewRedisCache("redis://cache.internal/x")
+	assert.Error(t, err)
+}
+
+func TestReadRedisReply(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("+OK\r\n:42\r\n$5\r\nhello\r\n$-1\r\n-ERR wrong type\r\n*2\r\n$1\r\na\r\n:1\r\n"))
+
+	reply, err := readRedisReply(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", reply)
+
+	reply, _ = readRedisReply(reader)
+	assert.Equal(t, int64(42), reply)
+
+	reply, _ = readRedisReply(reader)
+	assert.Equal(t, []byte("hello"), reply)
+
+	reply, err = readRedisReply(reader)
+	assert.NoError(t, err)
+	assert.Nil(t, reply)
+
+	_, err = readRedisReply(reader)
+	assert.EqualError(t, err, "redis: ERR wrong type")
+
+	reply, _ = readRedisReply(reader)
+	assert.Equal(t, []interface{}{[]byte("a"), int64(1)}, reply)
+}
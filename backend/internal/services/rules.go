@@ -0,0 +1,270 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// RuleEngine evaluates a user's categorization rules against transactions.
+type RuleEngine struct {
+	rules    []models.CategorizationRule
+	patterns map[string]*regexp.Regexp
+}
+
+// RuleChange describes how applying the rules changes one transaction.
+type RuleChange struct {
+	TransactionID string             `json:"transaction_id"`
+	Description   *string            `json:"description,omitempty"`
+	MatchedRules  []string           `json:"matched_rules"`
+	Before        RuleChangeSnapshot `json:"before"`
+	After         RuleChangeSnapshot `json:"after"`
+}
+
+// RuleChangeSnapshot holds the rule-controlled fields of a transaction
+type RuleChangeSnapshot struct {
+	CategoryID *string  `json:"category_id"`
+	Tags       []string `json:"tags"`
+	Notes      *string  `json:"notes"`
+}
+
+// ValidateRule checks that a rule has at least one condition and one action
+// and that its conditions are well formed.
+func ValidateRule(rule *models.CategorizationRule) error {
+	cond := rule.Conditions
+
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("rule name is required")
+	}
+
+	if cond.DescriptionPattern == nil && cond.MinAmount == nil && cond.MaxAmount == nil &&
+		len(cond.AccountIDs) == 0 && len(cond.Weekdays) == 0 && cond.TransactionType == nil {
+		return fmt.Errorf("rule must have at least one condition")
+	}
+
+	if cond.DescriptionPattern != nil {
+		if _, err := compilePattern(*cond.DescriptionPattern); err != nil {
+			return fmt.Errorf("invalid description pattern: %w", err)
+		}
+	}
+
+	if cond.MinAmount != nil && cond.MaxAmount != nil && *cond.MinAmount > *cond.MaxAmount {
+		return fmt.Errorf("min_amount cannot be greater than max_amount")
+	}
+
+	for _, day := range cond.Weekdays {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("weekday %d out of range, use 0 (Sunday) through 6 (Saturday)", day)
+		}
+	}
+
+	if cond.TransactionType != nil && *cond.TransactionType == models.TransactionTypeTransfer {
+		return fmt.Errorf("rules cannot match transfers")
+	}
+
+	actions := rule.Actions
+	if actions.CategoryID == nil && len(actions.Tags) == 0 && actions.Notes == nil {
+		return fmt.Errorf("rule must have at least one action")
+	}
+
+	return nil
+}
+
+// NewRuleEngine compiles the active rules, ordered by priority and then age.
+func NewRuleEngine(rules []models.CategorizationRule) (*RuleEngine, error) {
+	engine := &RuleEngine{
+		patterns: make(map[string]*regexp.Regexp),
+	}
+
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+		if rule.Conditions.DescriptionPattern != nil {
+			pattern, err := compilePattern(*rule.Conditions.DescriptionPattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid description pattern: %w", rule.Name, err)
+			}
+			engine.patterns[rule.ID] = pattern
+		}
+		engine.rules = append(engine.rules, rule)
+	}
+
+	sort.SliceStable(engine.rules, func(i, j int) bool {
+		if engine.rules[i].Priority == engine.rules[j].Priority {
+			return engine.rules[i].CreatedAt.Before(engine.rules[j].CreatedAt)
+		}
+		return engine.rules[i].Priority < engine.rules[j].Priority
+	})
+
+	return engine, nil
+}
+
+// Apply runs the rules against a transaction and returns the IDs of the rules
+// that matched. The first matching rule to set a category or notes wins;
+// tags from every matching rule are added. Existing categories and notes are
+// only replaced when overwrite is set. Split transactions keep their
+// categories on the split lines, and transfers are never touched.
+func (e *RuleEngine) Apply(transaction *models.Transaction, overwrite bool) []string {
+	if transaction.TransferID != nil || transaction.TransactionType == models.TransactionTypeTransfer {
+		return nil
+	}
+
+	categorySet := transaction.CategoryID != nil && !overwrite
+	notesSet := transaction.Notes != nil && strings.TrimSpace(*transaction.Notes) != "" && !overwrite
+
+	var matched []string
+	for _, rule := range e.rules {
+		if !e.matches(rule, transaction) {
+			continue
+		}
+		matched = append(matched, rule.ID)
+
+		if rule.Actions.CategoryID != nil && !categorySet && len(transaction.Splits) == 0 {
+			transaction.CategoryID = rule.Actions.CategoryID
+			categorySet = true
+		}
+		if rule.Actions.Notes != nil && !notesSet {
+			transaction.Notes = rule.Actions.Notes
+			notesSet = true
+		}
+		transaction.Tags = MergeTags(transaction.Tags, rule.Actions.Tags)
+
+		if rule.StopProcessing {
+			break
+		}
+	}
+
+	return matched
+}
+
+// Preview applies the rules to copies of the transactions and reports the
+// ones that would change, leaving the originals untouched.
+func (e *RuleEngine) Preview(transactions []models.Transaction, overwrite bool) ([]RuleChange, []models.Transaction) {
+	changes := []RuleChange{}
+	var updated []models.Transaction
+
+	for _, original := range transactions {
+		if original.IsLocked {
+			continue
+		}
+
+		candidate := original
+		candidate.Tags = append([]string(nil), original.Tags...)
+
+		matched := e.Apply(&candidate, overwrite)
+		if len(matched) == 0 || !ruleFieldsChanged(original, candidate) {
+			continue
+		}
+
+		changes = append(changes, RuleChange{
+			TransactionID: original.ID,
+			Description:   original.Description,
+			MatchedRules:  matched,
+			Before:        ruleSnapshot(original),
+			After:         ruleSnapshot(candidate),
+		})
+		updated = append(updated, candidate)
+	}
+
+	return changes, updated
+}
+
+func (e *RuleEngine) matches(rule models.CategorizationRule, t *models.Transaction) bool {
+	cond := rule.Conditions
+
+	if cond.TransactionType != nil && *cond.TransactionType != t.TransactionType {
+		return false
+	}
+	if cond.MinAmount != nil && t.Amount < *cond.MinAmount {
+		return false
+	}
+	if cond.MaxAmount != nil && t.Amount > *cond.MaxAmount {
+		return false
+	}
+	if len(cond.AccountIDs) > 0 && !containsString(cond.AccountIDs, t.AccountID) {
+		return false
+	}
+	if len(cond.Weekdays) > 0 {
+		found := false
+		for _, day := range cond.Weekdays {
+			if day == t.TransactionDate.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if pattern, ok := e.patterns[rule.ID]; ok {
+		if t.Description == nil || !pattern.MatchString(*t.Description) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MergeTags returns the union of two tag lists, keeping the original order
+// and dropping case-insensitive duplicates.
+func MergeTags(existing, added []string) []string {
+	if len(added) == 0 {
+		return existing
+	}
+
+	seen := make(map[string]bool, len(existing)+len(added))
+	merged := make([]string, 0, len(existing)+len(added))
+	for _, tag := range append(append([]string(nil), existing...), added...) {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, tag)
+	}
+	return merged
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+func ruleFieldsChanged(before, after models.Transaction) bool {
+	if stringValue(before.CategoryID) != stringValue(after.CategoryID) ||
+		stringValue(before.Notes) != stringValue(after.Notes) ||
+		len(before.Tags) != len(after.Tags) {
+		return true
+	}
+	for i := range before.Tags {
+		if before.Tags[i] != after.Tags[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleSnapshot(t models.Transaction) RuleChangeSnapshot {
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return RuleChangeSnapshot{
+		CategoryID: t.CategoryID,
+		Tags:       tags,
+		Notes:      t.Notes,
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string     { return &s }
+func floatPtr(f float64) *float64 { return &f }
+
+func ruleTestTransaction(description string, amount float64, date time.Time) models.Transaction {
+	return models.Transaction{
+		ID:              "txn-" + description,
+		AccountID:       "acct-1",
+		Amount:          amount,
+		TransactionType: models.TransactionTypeExpense,
+		Description:     &description,
+		TransactionDate: date,
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := &models.CategorizationRule{
+		Name:       "Coffee",
+		Conditions: models.RuleConditions{DescriptionPattern: strPtr("starbucks|peet")},
+		Actions:    models.RuleActions{CategoryID: strPtr("cat-coffee")},
+	}
+	assert.NoError(t, ValidateRule(valid))
+
+	noCondition := &models.CategorizationRule{Name: "x", Actions: valid.Actions}
+	assert.ErrorContains(t, ValidateRule(noCondition), "at least one condition")
+
+	noAction := &models.CategorizationRule{Name: "x", Conditions: valid.Conditions}
+	assert.ErrorContains(t, ValidateRule(noAction), "at least one action")
+
+	badPattern := &models.CategorizationRule{
+		Name:       "x",
+		Conditions: models.RuleConditions{DescriptionPattern: strPtr("(unclosed")},
+		Actions:    valid.Actions,
+	}
+	assert.ErrorContains(t, ValidateRule(badPattern), "invalid description pattern")
+
+	badRange := &models.CategorizationRule{
+		Name:       "x",
+		Conditions: models.RuleConditions{MinAmount: floatPtr(50), MaxAmount: floatPtr(10)},
+		Actions:    valid.Actions,
+	}
+	assert.Error(t, ValidateRule(badRange))
+
+	badWeekday := &models.CategorizationRule{
+		Name:       "x",
+		Conditions: models.RuleConditions{Weekdays: []time.Weekday{7}},
+		Actions:    valid.Actions,
+	}
+	assert.Error(t, ValidateRule(badWeekday))
+}
+
+func TestRuleEngine_PriorityAndConditions(t *testing.T) {
+	saturday := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	rules := []models.CategorizationRule{
+		{
+			ID: "weekend-dining", Name: "Weekend dining", Priority: 10, IsActive: true,
+			Conditions: models.RuleConditions{
+				DescriptionPattern: strPtr(`restaurant|grill`),
+				Weekdays:           []time.Weekday{time.Saturday, time.Sunday},
+			},
+			Actions: models.RuleActions{CategoryID: strPtr("cat-leisure"), Tags: []string{"weekend"}},
+		},
+		{
+			ID: "dining", Name: "Dining", Priority: 20, IsActive: true,
+			Conditions: models.RuleConditions{DescriptionPattern: strPtr(`restaurant|grill`)},
+			Actions:    models.RuleActions{CategoryID: strPtr("cat-dining"), Tags: []string{"food"}},
+		},
+		{
+			ID: "large", Name: "Large", Priority: 30, IsActive: true,
+			Conditions: models.RuleConditions{MinAmount: floatPtr(100)},
+			Actions:    models.RuleActions{Tags: []string{"Large"}, Notes: strPtr("review")},
+		},
+		{
+			ID: "inactive", Name: "Inactive", Priority: 1, IsActive: false,
+			Conditions: models.RuleConditions{MinAmount: floatPtr(0)},
+			Actions:    models.RuleActions{CategoryID: strPtr("cat-never")},
+		},
+	}
+
+	engine, err := NewRuleEngine(rules)
+	assert.NoError(t, err)
+
+	weekend := ruleTestTransaction("Harbor GRILL", 120, saturday)
+	matched := engine.Apply(&weekend, false)
+	assert.Equal(t, []string{"weekend-dining", "dining", "large"}, matched)
+	assert.Equal(t, "cat-leisure", *weekend.CategoryID)
+	assert.Equal(t, []string{"weekend", "food", "Large"}, weekend.Tags)
+	assert.Equal(t, "review", *weekend.Notes)
+
+	weekday := ruleTestTransaction("Corner Restaurant", 30, monday)
+	engine.Apply(&weekday, false)
+	assert.Equal(t, "cat-dining", *weekday.CategoryID)
+	assert.Equal(t, []string{"food"}, weekday.Tags)
+	assert.Nil(t, weekday.Notes)
+}
+
+func TestRuleEngine_StopProcessingAndOverwrite(t *testing.T) {
+	rules := []models.CategorizationRule{
+		{
+			ID: "first", Name: "First", Priority: 1, IsActive: true, StopProcessing: true,
+			Conditions: models.RuleConditions{AccountIDs: []string{"acct-1"}},
+			Actions:    models.RuleActions{CategoryID: strPtr("cat-a")},
+		},
+		{
+			ID: "second", Name: "Second", Priority: 2, IsActive: true,
+			Conditions: models.RuleConditions{AccountIDs: []string{"acct-1"}},
+			Actions:    models.RuleActions{Tags: []string{"never"}},
+		},
+	}
+	engine, err := NewRuleEngine(rules)
+	assert.NoError(t, err)
+
+	txn := ruleTestTransaction("anything", 5, time.Now())
+	txn.CategoryID = strPtr("cat-manual")
+
+	assert.Equal(t, []string{"first"}, engine.Apply(&txn, false))
+	assert.Equal(t, "cat-manual", *txn.CategoryID)
+	assert.Empty(t, txn.Tags)
+
+	engine.Apply(&txn, true)
+	assert.Equal(t, "cat-a", *txn.CategoryID)
+}
+
+func TestRuleEngine_PreviewReportsOnlyChanges(t *testing.T) {
+	rules := []models.CategorizationRule{{
+		ID: "fuel", Name: "Fuel", IsActive: true,
+		Conditions: models.RuleConditions{DescriptionPattern: strPtr(`shell|chevron`)},
+		Actions:    models.RuleActions{CategoryID: strPtr("cat-fuel")},
+	}}
+	engine, err := NewRuleEngine(rules)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	already := ruleTestTransaction("Chevron 0042", 40, now)
+	already.CategoryID = strPtr("cat-fuel")
+	locked := ruleTestTransaction("Shell Oil", 35, now)
+	locked.IsLocked = true
+	transactions := []models.Transaction{
+		ruleTestTransaction("SHELL 1234", 50, now),
+		already,
+		locked,
+		ruleTestTransaction("Grocery", 20, now),
+	}
+
+	changes, updated := engine.Preview(transactions, false)
+
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "txn-SHELL 1234", changes[0].TransactionID)
+	assert.Nil(t, changes[0].Before.CategoryID)
+	assert.Equal(t, "cat-fuel", *changes[0].After.CategoryID)
+	assert.Len(t, updated, 1)
+	assert.Nil(t, transactions[0].CategoryID)
+}
+
+func TestMergeTags(t *testing.T) {
+	assert.Equal(t, []string{"a", "B", "c"}, MergeTags([]string{"a", "B"}, []string{"b", " c ", ""}))
+	assert.Nil(t, MergeTags(nil, nil))
+}
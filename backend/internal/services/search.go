@@ -0,0 +1,238 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// MaxSearchQueryLength bounds the raw query string accepted by the parser.
+const MaxSearchQueryLength = 500
+
+// searchToken is one whitespace-separated piece of a query. Quoted is set
+// when the whole token was a quoted phrase.
+type searchToken struct {
+	text   string
+	quoted bool
+}
+
+// ParseSearchQuery parses the transaction search language:
+//
+//	merchant:amazon category:groceries account:checking tag:trip type:expense
+//	amount>50 amount<=100 amount:25 amount:10..20
+//	after:2026-01-01 before:2026-02-01 on:2026-01-15
+//	"exact phrase" plain words -excluded
+//
+// Values may be quoted (merchant:"whole foods"). after: is inclusive and
+// before: is exclusive. Unknown keys and everything else are matched as
+// full text against descriptions and notes.
+func ParseSearchQuery(query string) (*models.TransactionSearch, error) {
+	if len(query) > MaxSearchQueryLength {
+		return nil, fmt.Errorf("query cannot be longer than %d characters", MaxSearchQueryLength)
+	}
+
+	tokens, err := tokenizeSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	search := &models.TransactionSearch{}
+	var text []string
+
+	for _, token := range tokens {
+		if token.quoted {
+			text = append(text, `"`+token.text+`"`)
+			continue
+		}
+
+		if lower := strings.ToLower(token.text); strings.HasPrefix(lower, "amount") &&
+			len(lower) > len("amount") && strings.ContainsRune("<>=:", rune(lower[len("amount")])) {
+			if err := parseAmountFilter(search, token.text[len("amount"):]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(token.text, ":")
+		if !found || value == "" {
+			text = append(text, token.text)
+			continue
+		}
+		value = strings.Trim(value, `"`)
+
+		switch strings.ToLower(key) {
+		case "merchant":
+			search.Merchants = append(search.Merchants, value)
+		case "category":
+			search.Categories = append(search.Categories, value)
+		case "account":
+			search.Accounts = append(search.Accounts, value)
+		case "tag":
+			search.Tags = append(search.Tags, value)
+		case "type":
+			transactionType := models.TransactionType(strings.ToLower(value))
+			switch transactionType {
+			case models.TransactionTypeIncome, models.TransactionTypeExpense, models.TransactionTypeTransfer:
+				search.TransactionType = &transactionType
+			default:
+				return nil, fmt.Errorf("type must be income, expense or transfer")
+			}
+		case "after", "before", "on":
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: dates must use YYYY-MM-DD", key)
+			}
+			switch strings.ToLower(key) {
+			case "after":
+				search.After = &date
+			case "before":
+				search.Before = &date
+			default:
+				next := date.AddDate(0, 0, 1)
+				search.After, search.Before = &date, &next
+			}
+		default:
+			text = append(text, token.text)
+		}
+	}
+
+	search.Text = strings.Join(text, " ")
+	if search.Text == "" && len(search.Merchants) == 0 && len(search.Categories) == 0 &&
+		len(search.Accounts) == 0 && len(search.Tags) == 0 && search.TransactionType == nil &&
+		search.MinAmount == nil && search.MaxAmount == nil && search.After == nil && search.Before == nil {
+		return nil, fmt.Errorf("query is empty")
+	}
+
+	return search, nil
+}
+
+// parseAmountFilter handles the part after "amount": >50, >=50, <50, <=50,
+// =50, :50 and :10..20.
+func parseAmountFilter(search *models.TransactionSearch, expr string) error {
+	parse := func(raw string) (float64, error) {
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("amount: %q is not a valid amount", raw)
+		}
+		return value, nil
+	}
+
+	var op string
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", ":"} {
+		if strings.HasPrefix(expr, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return fmt.Errorf("amount filters look like amount>50, amount<=100 or amount:10..20")
+	}
+	rest := expr[len(op):]
+
+	if op == ":" {
+		if lowText, highText, isRange := strings.Cut(rest, ".."); isRange {
+			low, err := parse(lowText)
+			if err != nil {
+				return err
+			}
+			high, err := parse(highText)
+			if err != nil {
+				return err
+			}
+			if high < low {
+				return fmt.Errorf("amount: range end must not be below its start")
+			}
+			search.MinAmount, search.MaxAmount = &low, &high
+			search.MinExclusive, search.MaxExclusive = false, false
+			return nil
+		}
+		op = "="
+	}
+
+	value, err := parse(rest)
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case ">", ">=":
+		search.MinAmount = &value
+		search.MinExclusive = op == ">"
+	case "<", "<=":
+		search.MaxAmount = &value
+		search.MaxExclusive = op == "<"
+	default:
+		search.MinAmount, search.MaxAmount = &value, &value
+		search.MinExclusive, search.MaxExclusive = false, false
+	}
+	return nil
+}
+
+// tokenizeSearchQuery splits on whitespace outside double quotes.
+func tokenizeSearchQuery(query string) ([]searchToken, error) {
+	var tokens []searchToken
+	var current strings.Builder
+	inQuote := false
+	quotedStart := false
+
+	flush := func() {
+		text := current.String()
+		current.Reset()
+		if quotedStart && strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) && len(text) >= 2 {
+			phrase := strings.TrimSpace(text[1 : len(text)-1])
+			if phrase != "" {
+				tokens = append(tokens, searchToken{text: phrase, quoted: true})
+			}
+		} else if text != "" {
+			tokens = append(tokens, searchToken{text: text})
+		}
+		quotedStart = false
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if current.Len() == 0 {
+				quotedStart = true
+			}
+			inQuote = !inQuote
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuote:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// EncodeSearchCursor serializes a cursor into an opaque URL-safe string.
+func EncodeSearchCursor(cursor models.SearchCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeSearchCursor parses a cursor produced by EncodeSearchCursor.
+func DecodeSearchCursor(encoded string) (*models.SearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	var cursor models.SearchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	search, err := ParseSearchQuery(`merchant:amazon amount>50 category:groceries after:2026-01-01 tag:trip "exact phrase" coffee`)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"amazon"}, search.Merchants)
+	assert.Equal(t, []string{"groceries"}, search.Categories)
+	assert.Equal(t, []string{"trip"}, search.Tags)
+	assert.Equal(t, 50.0, *search.MinAmount)
+	assert.True(t, search.MinExclusive)
+	assert.Nil(t, search.MaxAmount)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *search.After)
+	assert.Equal(t, `"exact phrase" coffee`, search.Text)
+}
+
+func TestParseSearchQuery_QuotedValuesAndRanges(t *testing.T) {
+	search, err := ParseSearchQuery(`merchant:"whole foods" amount:10..20.5 type:Expense on:2026-03-04 note:x -refund`)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"whole foods"}, search.Merchants)
+	assert.Equal(t, 10.0, *search.MinAmount)
+	assert.Equal(t, 20.5, *search.MaxAmount)
+	assert.False(t, search.MinExclusive || search.MaxExclusive)
+	assert.Equal(t, models.TransactionTypeExpense, *search.TransactionType)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), *search.After)
+	assert.Equal(t, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), *search.Before)
+	// Unknown keys and exclusions are left to the full-text query
+	assert.Equal(t, "note:x -refund", search.Text)
+}
+
+func TestParseSearchQuery_AmountOperators(t *testing.T) {
+	search, err := ParseSearchQuery("amount<=100 amount>=5")
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, *search.MinAmount)
+	assert.Equal(t, 100.0, *search.MaxAmount)
+	assert.False(t, search.MinExclusive || search.MaxExclusive)
+
+	search, err = ParseSearchQuery("amount=12.34")
+	assert.NoError(t, err)
+	assert.Equal(t, 12.34, *search.MinAmount)
+	assert.Equal(t, 12.34, *search.MaxAmount)
+
+	// Words that merely start with "amount" are text
+	search, err = ParseSearchQuery("amounts")
+	assert.NoError(t, err)
+	assert.Equal(t, "amounts", search.Text)
+}
+
+func TestParseSearchQuery_Errors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"   ",
+		"amount>abc",
+		"amount>-5",
+		"amount:20..10",
+		"after:01/02/2026",
+		"type:refund",
+		`"unterminated`,
+	} {
+		_, err := ParseSearchQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	cursor := models.SearchCursor{
+		Rank:            0.0607927,
+		TransactionDate: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		ID:              "txn-1",
+	}
+
+	decoded, err := DecodeSearchCursor(EncodeSearchCursor(cursor))
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, *decoded)
+
+	_, err = DecodeSearchCursor("not a cursor")
+	assert.Error(t, err)
+}
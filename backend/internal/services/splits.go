@@ -0,0 +1,41 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// ValidateSplits checks that split lines are well formed and add up to the
+// parent transaction amount. An empty slice means the transaction is not split.
+func ValidateSplits(amount float64, splits []models.TransactionSplit) error {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	if len(splits) < 2 {
+		return fmt.Errorf("a split transaction needs at least two lines")
+	}
+
+	var total int64
+	for i, split := range splits {
+		if split.Amount <= 0 {
+			return fmt.Errorf("split line %d: amount must be greater than 0", i+1)
+		}
+		total += toCents(split.Amount)
+	}
+
+	if total != toCents(amount) {
+		return fmt.Errorf("split amounts total %.2f but transaction amount is %.2f",
+			float64(total)/100, amount)
+	}
+
+	return nil
+}
+
+// toCents converts a currency amount to integer cents so that sums can be
+// compared without floating point drift.
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSplits_NoSplits(t *testing.T) {
+	assert.NoError(t, ValidateSplits(42.50, nil))
+}
+
+func TestValidateSplits_MatchingTotal(t *testing.T) {
+	splits := []models.TransactionSplit{
+		{Amount: 0.1},
+		{Amount: 0.2},
+		{Amount: 99.7},
+	}
+
+	assert.NoError(t, ValidateSplits(100.0, splits))
+}
+
+func TestValidateSplits_MismatchedTotal(t *testing.T) {
+	splits := []models.TransactionSplit{
+		{Amount: 60.0},
+		{Amount: 30.0},
+	}
+
+	err := ValidateSplits(100.0, splits)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "split amounts total 90.00")
+}
+
+func TestValidateSplits_SingleLine(t *testing.T) {
+	splits := []models.TransactionSplit{{Amount: 100.0}}
+
+	assert.Error(t, ValidateSplits(100.0, splits))
+}
+
+func TestValidateSplits_NonPositiveLine(t *testing.T) {
+	splits := []models.TransactionSplit{
+		{Amount: 110.0},
+		{Amount: -10.0},
+	}
+
+	err := ValidateSplits(100.0, splits)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "split line 2")
+}
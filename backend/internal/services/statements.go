@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/personal-finance-management/backend/internal/repositories"
+)
+
+// BuildIncomeStatement arranges the category totals of a period under the
+// category hierarchy. A parent category is listed whenever one of its
+// subcategories has activity, with Total covering the whole subtree; totals
+// of categories missing from categories are listed at the top level.
+func BuildIncomeStatement(categories []models.Category, totals []models.CategoryTotal, startDate, endDate time.Time) *models.IncomeStatement {
+	byID := make(map[string]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	var revenue, expenses []models.CategoryTotal
+	for _, total := range totals {
+		switch total.TransactionType {
+		case models.TransactionTypeIncome:
+			revenue = append(revenue, total)
+		case models.TransactionTypeExpense:
+			expenses = append(expenses, total)
+		}
+	}
+
+	statement := &models.IncomeStatement{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Revenue:     buildStatementSection(byID, revenue),
+		Expenses:    buildStatementSection(byID, expenses),
+		GeneratedAt: time.Now(),
+	}
+	statement.NetIncome = float64(toCents(statement.Revenue.Total)-toCents(statement.Expenses.Total)) / 100
+	if statement.Revenue.Total != 0 {
+		margin := roundTo2(statement.NetIncome / statement.Revenue.Total * 100)
+		statement.NetMargin = &margin
+	}
+	return statement
+}
+
+// statementNode is a category being placed on an income statement
+type statementNode struct {
+	line     models.StatementLine
+	cents    int64
+	children []*statementNode
+}
+
+// buildStatementSection builds the category tree of one side of an income
+// statement
+func buildStatementSection(categories map[string]models.Category, totals []models.CategoryTotal) models.StatementSection {
+	nodes := make(map[string]*statementNode)
+	placing := make(map[string]bool)
+	var roots []*statementNode
+
+	// node returns the category's node, adding it and its ancestors to the
+	// tree first. A parent already being placed would close a loop, so the
+	// category is made a root instead.
+	var node func(id, name string) *statementNode
+	node = func(id, name string) *statementNode {
+		if n, ok := nodes[id]; ok {
+			return n
+		}
+		n := &statementNode{line: models.StatementLine{CategoryID: id, CategoryName: name}}
+		nodes[id] = n
+
+		category, known := categories[id]
+		if known {
+			n.line.CategoryName = category.Name
+		}
+		if known && category.ParentID != nil && !placing[*category.ParentID] {
+			if parent, ok := categories[*category.ParentID]; ok {
+				placing[id] = true
+				p := node(parent.ID, parent.Name)
+				delete(placing, id)
+				p.children = append(p.children, n)
+				return n
+			}
+		}
+		roots = append(roots, n)
+		return n
+	}
+
+	for _, total := range totals {
+		name := total.CategoryName
+		if total.CategoryID == "" && name == "" {
+			name = "Uncategorized"
+		}
+		n := node(total.CategoryID, name)
+		n.cents += toCents(total.Amount)
+		n.line.Count += total.Count
+	}
+
+	var section models.StatementSection
+	section.Lines = make([]models.StatementLine, 0, len(roots))
+	var sum int64
+	for _, root := range roots {
+		line, cents := finishStatementLine(root)
+		section.Lines = append(section.Lines, line)
+		sum += cents
+	}
+	sortStatementLines(section.Lines)
+	section.Total = float64(sum) / 100
+	return section
+}
+
+// finishStatementLine fills in the amounts of a node and its subtree,
+// returning the line and its total in cents
+func finishStatementLine(n *statementNode) (models.StatementLine, int64) {
+	line := n.line
+	line.Amount = float64(n.cents) / 100
+	total := n.cents
+	for _, child := range n.children {
+		childLine, cents := finishStatementLine(child)
+		line.Children = append(line.Children, childLine)
+		total += cents
+	}
+	sortStatementLines(line.Children)
+	line.Total = float64(total) / 100
+	return line, total
+}
+
+// sortStatementLines orders lines by total, largest first, then by name
+func sortStatementLines(lines []models.StatementLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].Total != lines[j].Total {
+			return lines[i].Total > lines[j].Total
+		}
+		return lines[i].CategoryName < lines[j].CategoryName
+	})
+}
+
+// ComputeBalanceSheet groups the accounts into assets and liabilities by
+// account type in the base currency, converting as ComputeNetWorth does.
+// Equity is assets less liabilities; yearEarnings, the net income from the
+// start of the year, is split out of it.
+func ComputeBalanceSheet(accounts []models.Account, baseCurrency string, rates map[string]float64, yearEarnings float64) *models.BalanceSheet {
+	sheet := &models.BalanceSheet{BaseCurrency: baseCurrency}
+
+	type group struct {
+		entry models.BalanceSheetGroup
+		cents int64
+	}
+	groups := make(map[models.AccountType]*group)
+	unconverted := make(map[string]bool)
+	var assets, liabilities int64
+
+	for _, account := range accounts {
+		currency := account.Currency
+		if currency == "" {
+			currency = baseCurrency
+		}
+		amount := account.Balance
+		if currency != baseCurrency {
+			rate, ok := rates[currency]
+			if !ok {
+				unconverted[currency] = true
+				continue
+			}
+			amount = ConvertAmount(amount, rate)
+		}
+
+		cents := toCents(amount)
+		if IsLiabilityAccount(account.AccountType) {
+			cents = owedCents(account.AccountType, amount)
+			liabilities += cents
+		} else {
+			assets += cents
+		}
+
+		g, ok := groups[account.AccountType]
+		if !ok {
+			g = &group{entry: models.BalanceSheetGroup{AccountType: account.AccountType}}
+			groups[account.AccountType] = g
+		}
+		g.cents += cents
+		g.entry.Accounts = append(g.entry.Accounts, models.BalanceSheetAccount{
+			AccountID: account.ID,
+			Name:      account.Name,
+			Currency:  currency,
+			Balance:   roundTo2(account.Balance),
+			Amount:    float64(cents) / 100,
+		})
+	}
+
+	sheet.Assets.Groups = []models.BalanceSheetGroup{}
+	sheet.Liabilities.Groups = []models.BalanceSheetGroup{}
+	for accountType, g := range groups {
+		g.entry.Total = float64(g.cents) / 100
+		if IsLiabilityAccount(accountType) {
+			sheet.Liabilities.Groups = append(sheet.Liabilities.Groups, g.entry)
+		} else {
+			sheet.Assets.Groups = append(sheet.Assets.Groups, g.entry)
+		}
+	}
+	for _, section := range []*models.BalanceSheetSection{&sheet.Assets, &sheet.Liabilities} {
+		sort.Slice(section.Groups, func(i, j int) bool {
+			return section.Groups[i].AccountType < section.Groups[j].AccountType
+		})
+	}
+
+	for currency := range unconverted {
+		sheet.UnconvertedCurrencies = append(sheet.UnconvertedCurrencies, currency)
+	}
+	sort.Strings(sheet.UnconvertedCurrencies)
+
+	sheet.Assets.Total = float64(assets) / 100
+	sheet.Liabilities.Total = float64(liabilities) / 100
+	equity := assets - liabilities
+	sheet.Equity = models.BalanceSheetEquity{
+		Total:               float64(equity) / 100,
+		CurrentYearEarnings: roundTo2(yearEarnings),
+		RetainedEarnings:    float64(equity-toCents(yearEarnings)) / 100,
+	}
+	return sheet
+}
+
+// FinancialStatementsStore is the data access the financial statements
+// service needs.
+type FinancialStatementsStore interface {
+	repositories.FinancialStatementsRepository
+	GetCategoryTotals(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.CategoryTotal, error)
+	GetBaseCurrency(ctx context.Context, userID string) (string, error)
+	GetLatestExchangeRate(ctx context.Context, baseCurrency, targetCurrency string) (float64, error)
+}
+
+// FinancialStatementsService produces income statements and balance sheets
+type FinancialStatementsService struct {
+	store FinancialStatementsStore
+}
+
+// NewFinancialStatementsService creates a financial statements service
+func NewFinancialStatementsService(store FinancialStatementsStore) *FinancialStatementsService {
+	return &FinancialStatementsService{store: store}
+}
+
+// IncomeStatement reports the user's revenue and expenses between the dates,
+// inclusive.
+func (s *FinancialStatementsService) IncomeStatement(ctx context.Context, userID string, startDate, endDate time.Time) (*models.IncomeStatement, error) {
+	categories, err := s.store.GetCategoriesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.store.GetCategoryTotals(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := BuildIncomeStatement(categories, totals, startDate, endDate)
+	statement.UserID = userID
+	return statement, nil
+}
+
+// BalanceSheet reports the user's position at the end of asOf. Balances in
+// other currencies are converted at the latest available rates.
+func (s *FinancialStatementsService) BalanceSheet(ctx context.Context, userID string, asOf time.Time) (*models.BalanceSheet, error) {
+	asOf = truncateDay(asOf)
+	accounts, err := s.store.GetAccountBalancesAsOf(ctx, userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	baseCurrency, err := s.store.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.store.GetCategoryTotals(ctx, userID, time.Date(asOf.Year(), 1, 1, 0, 0, 0, 0, time.UTC), asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	var earnings int64
+	for _, total := range totals {
+		switch total.TransactionType {
+		case models.TransactionTypeIncome:
+			earnings += toCents(total.Amount)
+		case models.TransactionTypeExpense:
+			earnings -= toCents(total.Amount)
+		}
+	}
+
+	sheet := ComputeBalanceSheet(accounts, baseCurrency, accountRates(ctx, s.store, accounts, baseCurrency), float64(earnings)/100)
+	sheet.UserID = userID
+	sheet.AsOf = asOf
+	sheet.GeneratedAt = time.Now()
+	return sheet, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildIncomeStatement(t *testing.T) {
+	business, food, groceries, dining := "business", "food", "groceries", "dining"
+	categories := []models.Category{
+		{ID: business, Name: "Business"},
+		{ID: "consulting", Name: "Consulting", ParentID: &business},
+		{ID: "sales", Name: "Sales", ParentID: &business},
+		{ID: food, Name: "Food"},
+		{ID: groceries, Name: "Groceries", ParentID: &food},
+		{ID: dining, Name: "Dining", ParentID: &food},
+		// A loop left by bad data must not hang the statement
+		{ID: "a", Name: "A", ParentID: strPtr("b")},
+		{ID: "b", Name: "B", ParentID: strPtr("a")},
+	}
+	totals := []models.CategoryTotal{
+		{CategoryID: "consulting", CategoryName: "Consulting", TransactionType: models.TransactionTypeIncome, Amount: 3000, Count: 2},
+		{CategoryID: "sales", CategoryName: "Sales", TransactionType: models.TransactionTypeIncome, Amount: 1000.5, Count: 10},
+		{CategoryID: "salary", CategoryName: "Salary", TransactionType: models.TransactionTypeIncome, Amount: 500, Count: 1},
+		{CategoryID: groceries, CategoryName: "Groceries", TransactionType: models.TransactionTypeExpense, Amount: 400.25, Count: 8},
+		{CategoryID: dining, CategoryName: "Dining", TransactionType: models.TransactionTypeExpense, Amount: 150, Count: 3},
+		{CategoryID: food, CategoryName: "Food", TransactionType: models.TransactionTypeExpense, Amount: 20, Count: 1},
+		{CategoryID: "", CategoryName: "Uncategorized", TransactionType: models.TransactionTypeExpense, Amount: 99.75, Count: 4},
+		{CategoryID: "a", CategoryName: "A", TransactionType: models.TransactionTypeExpense, Amount: 5, Count: 1},
+	}
+
+	statement := BuildIncomeStatement(categories, totals, forecastDate(2026, 1, 1), forecastDate(2026, 3, 31))
+
+	assert.Equal(t, 4500.5, statement.Revenue.Total)
+	assert.Equal(t, 675.0, statement.Expenses.Total)
+	assert.Equal(t, 3825.5, statement.NetIncome)
+	assert.Equal(t, 85.0, *statement.NetMargin)
+
+	if assert.Len(t, statement.Revenue.Lines, 2) {
+		businessLine := statement.Revenue.Lines[0]
+		assert.Equal(t, "Business", businessLine.CategoryName)
+		assert.Zero(t, businessLine.Amount)
+		assert.Equal(t, 4000.5, businessLine.Total)
+		if assert.Len(t, businessLine.Children, 2) {
+			assert.Equal(t, "Consulting", businessLine.Children[0].CategoryName)
+			assert.Equal(t, 10, businessLine.Children[1].Count)
+		}
+		assert.Equal(t, "Salary", statement.Revenue.Lines[1].CategoryName)
+	}
+
+	if assert.Len(t, statement.Expenses.Lines, 3) {
+		foodLine := statement.Expenses.Lines[0]
+		assert.Equal(t, 20.0, foodLine.Amount)
+		assert.Equal(t, 570.25, foodLine.Total)
+		assert.Equal(t, []string{"Groceries", "Dining"}, []string{foodLine.Children[0].CategoryName, foodLine.Children[1].CategoryName})
+		assert.Equal(t, "Uncategorized", statement.Expenses.Lines[1].CategoryName)
+		assert.Equal(t, 5.0, statement.Expenses.Lines[2].Total)
+	}
+
+	assert.Nil(t, BuildIncomeStatement(nil, nil, forecastDate(2026, 1, 1), forecastDate(2026, 1, 31)).NetMargin)
+}
+
+func TestComputeBalanceSheet(t *testing.T) {
+	accounts := []models.Account{
+		{ID: "chk", Name: "Checking", AccountType: models.AccountTypeChecking, Balance: 2500, Currency: "USD"},
+		{ID: "sav", Name: "Savings", AccountType: models.AccountTypeSavings, Balance: 1000, Currency: "EUR"},
+		{ID: "cc", Name: "Card", AccountType: models.AccountTypeCreditCard, Balance: -450.25, Currency: "USD"},
+		{ID: "jpy", Name: "Brokerage", AccountType: models.AccountTypeInvestment, Balance: 900, Currency: "JPY"},
+	}
+
+	sheet := ComputeBalanceSheet(accounts, "USD", map[string]float64{"EUR": 1.1}, 1200)
+
+	assert.Equal(t, 3600.0, sheet.Assets.Total)
+	assert.Equal(t, 450.25, sheet.Liabilities.Total)
+	assert.Equal(t, models.BalanceSheetEquity{Total: 3149.75, RetainedEarnings: 1949.75, CurrentYearEarnings: 1200}, sheet.Equity)
+	assert.Equal(t, []string{"JPY"}, sheet.UnconvertedCurrencies)
+
+	if assert.Len(t, sheet.Assets.Groups, 2) {
+		assert.Equal(t, models.AccountTypeChecking, sheet.Assets.Groups[0].AccountType)
+		savings := sheet.Assets.Groups[1]
+		assert.Equal(t, 1100.0, savings.Total)
+		assert.Equal(t, models.BalanceSheetAccount{AccountID: "sav", Name: "Savings", Currency: "EUR", Balance: 1000, Amount: 1100}, savings.Accounts[0])
+	}
+	if assert.Len(t, sheet.Liabilities.Groups, 1) {
+		assert.Equal(t, 450.25, sheet.Liabilities.Groups[0].Accounts[0].Amount)
+		assert.Equal(t, -450.25, sheet.Liabilities.Groups[0].Accounts[0].Balance)
+	}
+}
+
+func TestComputeBalanceSheet_LiabilitySigns(t *testing.T) {
+	accounts := []models.Account{
+		{ID: "chk", Name: "Checking", AccountType: models.AccountTypeChecking, Balance: 1000, Currency: "USD"},
+		{ID: "cc", Name: "Card", AccountType: models.AccountTypeCreditCard, Balance: 60, Currency: "USD"},
+		{ID: "loan", Name: "Car loan", AccountType: models.AccountTypeLoan, Balance: 400, Currency: "USD"},
+	}
+
+	sheet := ComputeBalanceSheet(accounts, "USD", nil, 0)
+
+	// The overpaid card lowers the liabilities and raises equity
+	assert.Equal(t, 340.0, sheet.Liabilities.Total)
+	assert.Equal(t, 660.0, sheet.Equity.Total)
+	for _, group := range sheet.Liabilities.Groups {
+		switch group.AccountType {
+		case models.AccountTypeCreditCard:
+			assert.Equal(t, -60.0, group.Total)
+		case models.AccountTypeLoan:
+			assert.Equal(t, 400.0, group.Total)
+		}
+	}
+}
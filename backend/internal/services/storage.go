@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrObjectNotFound is returned by ObjectStorage.Get for a missing key.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectStorage stores opaque file contents under slash-separated keys.
+// Implementations must make Put atomic: a reader never sees a partial object.
+type ObjectStorage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// storageKeyRegex limits keys to path segments of safe characters.
+var storageKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+(/[A-Za-z0-9_.-]+)*$`)
+
+// LocalStorage is an ObjectStorage backed by a directory on the local
+// filesystem. Each key maps to a file below the root directory.
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage creates the root directory if needed and returns a storage
+// rooted there.
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if root == "" {
+		return nil, fmt.Errorf("storage directory is required")
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage directory: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStorage{root: abs}, nil
+}
+
+// path maps a key to its file, rejecting keys that could escape the root.
+func (s *LocalStorage) path(key string) (string, error) {
+	if !storageKeyRegex.MatchString(key) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object to a temporary file and renames it into place.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+
+	return nil
+}
+
+// Get opens the object for reading. The caller must close it.
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+	return file, nil
+}
+
+// Delete removes the object. Deleting a missing object is not an error.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// Interface compliance check
+var _ ObjectStorage = (*LocalStorage)(nil)
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+)
+
+// minWebhookSecretLength is the shortest signing secret a user may choose
+const minWebhookSecretLength = 16
+
+// WebhookEventTypes lists every event type webhooks can subscribe to
+var WebhookEventTypes = []models.WebhookEventType{
+	models.WebhookEventTransactionCreated,
+	models.WebhookEventBudgetExceeded,
+	models.WebhookEventGoalCompleted,
+}
+
+// GenerateWebhookSecret returns a new random signing secret
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// ValidateWebhookSubscription checks a subscription's URL, secret and event
+// types, dropping repeated event types
+func ValidateWebhookSubscription(subscription *models.WebhookSubscription) error {
+	if err := validateWebhookURL(subscription.URL); err != nil {
+		return err
+	}
+	if len(subscription.Secret) < minWebhookSecretLength {
+		return fmt.Errorf("webhook secret must be at least %d characters", minWebhookSecretLength)
+	}
+	if len(subscription.EventTypes) == 0 {
+		return errors.New("subscribe to at least one event type")
+	}
+
+	seen := make(map[models.WebhookEventType]bool, len(subscription.EventTypes))
+	eventTypes := make([]models.WebhookEventType, 0, len(subscription.EventTypes))
+	for _, t := range subscription.EventTypes {
+		known := false
+		for _, k := range WebhookEventTypes {
+			known = known || t == k
+		}
+		if !known {
+			return fmt.Errorf("unknown event type %q, use transaction.created, budget.exceeded or goal.completed", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			eventTypes = append(eventTypes, t)
+		}
+	}
+	subscription.EventTypes = eventTypes
+	return nil
+}
+
+// webhookEventPayload is the body of an event webhook
+type webhookEventPayload struct {
+	ID        string                  `json:"id"`
+	Type      models.WebhookEventType `json:"type"`
+	CreatedAt time.Time               `json:"created_at"`
+	Data      json.RawMessage         `json:"data"`
+}
+
+// WebhookEventBody returns the JSON body sent for an event. It is the same
+// for every delivery of the event, retries and replays included, so
+// receivers can use its id to ignore events they have already handled.
+func WebhookEventBody(event models.WebhookEvent) ([]byte, error) {
+	data := event.Data
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	return json.Marshal(webhookEventPayload{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt.UTC(),
+		Data:      data,
+	})
+}
+
+// WebhookStore is the data access the webhook dispatcher needs
+type WebhookStore interface {
+	ClaimDueWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt, status models.WebhookDeliveryStatus, nextAttemptAt time.Time) error
+}
+
+// WebhookDispatcher works through the webhook delivery queue, POSTing each
+// due event to its subscription's URL signed with the subscription's
+// secret. Every attempt is logged; failed attempts are retried with
+// exponential backoff until maxDeliveryAttempts.
+type WebhookDispatcher struct {
+	store  WebhookStore
+	client *http.Client
+}
+
+// NewWebhookDispatcher creates a dispatcher using client, or
+// NewWebhookHTTPClient when it is nil
+func NewWebhookDispatcher(store WebhookStore, client *http.Client) *WebhookDispatcher {
+	if client == nil {
+		client = NewWebhookHTTPClient()
+	}
+	return &WebhookDispatcher{store: store, client: client}
+}
+
+// DispatchDue claims a batch of deliveries due by now and attempts them,
+// returning how many were claimed and how many were delivered
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context, now time.Time) (claimed, sent int, err error) {
+	return dispatchBatch(ctx, now, d.store.ClaimDueWebhookDeliveries, d.deliver)
+}
+
+// deliver attempts one delivery and records the outcome, reporting whether
+// it was delivered
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) (bool, error) {
+	started := time.Now().UTC()
+	attempt := &models.WebhookDeliveryAttempt{
+		DeliveryID:  delivery.ID,
+		Attempt:     delivery.Attempts + 1,
+		AttemptedAt: started,
+	}
+
+	body, err := WebhookEventBody(*delivery.Event)
+	if err != nil {
+		return false, err
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, deliverySendTimeout)
+	statusCode, err := PostWebhook(sendCtx, d.client, delivery.Subscription.URL, delivery.Subscription.Secret,
+		string(delivery.Event.EventType), delivery.ID, body, started)
+	cancel()
+	if ctx.Err() != nil {
+		// Shutting down: the lease runs out and the delivery is retried
+		return false, ctx.Err()
+	}
+	attempt.DurationMs = int(time.Since(started).Milliseconds())
+	if statusCode != 0 {
+		attempt.ResponseStatus = &statusCode
+	}
+
+	if err == nil {
+		return true, d.store.RecordWebhookAttempt(ctx, attempt, models.WebhookDeliverySent, started)
+	}
+	message := err.Error()
+	attempt.Error = &message
+	if giveUpDelivery(err, attempt.Attempt) {
+		return false, d.store.RecordWebhookAttempt(ctx, attempt, models.WebhookDeliveryFailed, started)
+	}
+	return false, d.store.RecordWebhookAttempt(ctx, attempt, models.WebhookDeliveryPending,
+		started.Add(deliveryBackoff(attempt.Attempt)))
+}
+
+// Run dispatches due deliveries every few seconds until the context is
+// cancelled, draining the queue a batch at a time
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	runDispatchLoop(ctx, "Webhook", d.DispatchDue)
+}
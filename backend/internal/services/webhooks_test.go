@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/personal-finance-management/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWebhookStore is an in-memory webhook delivery queue with its attempt log
+type fakeWebhookStore struct {
+	fakeQueue[models.WebhookDelivery]
+	attempts []models.WebhookDeliveryAttempt
+}
+
+func newFakeWebhookStore(deliveries ...models.WebhookDelivery) *fakeWebhookStore {
+	return &fakeWebhookStore{fakeQueue: newFakeQueue(
+		func(d models.WebhookDelivery) string { return d.ID },
+		func(d *models.WebhookDelivery, now time.Time) bool {
+			return d.Status == models.WebhookDeliveryPending && d.Subscription.IsActive && !d.NextAttemptAt.After(now)
+		},
+		func(d *models.WebhookDelivery, until time.Time) { d.NextAttemptAt = until },
+		deliveries...,
+	)}
+}
+
+func (s *fakeWebhookStore) ClaimDueWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	return s.claim(now, leaseUntil, limit), nil
+}
+
+func (s *fakeWebhookStore) RecordWebhookAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt, status models.WebhookDeliveryStatus, nextAttemptAt time.Time) error {
+	s.attempts = append(s.attempts, *attempt)
+	d := s.deliveries[attempt.DeliveryID]
+	d.Status, d.Attempts, d.NextAttemptAt = status, attempt.Attempt, nextAttemptAt
+	d.LastResponseStatus, d.LastError = attempt.ResponseStatus, attempt.Error
+	if status == models.WebhookDeliverySent {
+		d.DeliveredAt = &attempt.AttemptedAt
+	}
+	return nil
+}
+
+// webhookReceiver is a webhook endpoint answering with the queued statuses,
+// then 200, recording each request
+type webhookReceiver struct {
+	*httptest.Server
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func startWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	receiver := &webhookReceiver{statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		status := http.StatusOK
+		if len(receiver.statuses) > 0 {
+			status, receiver.statuses = receiver.statuses[0], receiver.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func testWebhookDelivery(id, url string, due time.Time) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:             id,
+		EventID:        "evt-" + id,
+		SubscriptionID: "sub-1",
+		UserID:         "user-1",
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  due,
+		Event: &models.WebhookEvent{
+			ID:        "evt-" + id,
+			UserID:    "user-1",
+			EventType: models.WebhookEventTransactionCreated,
+			Data:      json.RawMessage(`{"transaction_id":"txn-1","amount":42.5}`),
+			CreatedAt: due,
+		},
+		Subscription: &models.WebhookSubscription{
+			ID:       "sub-1",
+			UserID:   "user-1",
+			URL:      url,
+			Secret:   "whsec_test_secret",
+			IsActive: true,
+		},
+	}
+}
+
+func TestWebhookEventBody(t *testing.T) {
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	body, err := WebhookEventBody(models.WebhookEvent{
+		ID:        "evt-1",
+		EventType: models.WebhookEventGoalCompleted,
+		Data:      json.RawMessage(`{"goal_id":"goal-1"}`),
+		CreatedAt: created,
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"id":"evt-1","type":"goal.completed","created_at":"2026-05-01T10:00:00Z","data":{"goal_id":"goal-1"}}`,
+		string(body))
+
+	body, err = WebhookEventBody(models.WebhookEvent{ID: "evt-2", EventType: models.WebhookEventBudgetExceeded, CreatedAt: created})
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"data":{}`)
+}
+
+func TestWebhookDispatcher_SignsAndLogs(t *testing.T) {
+	ctx := context.Background()
+	receiver := startWebhookReceiver(t)
+	now := time.Now().UTC()
+	store := newFakeWebhookStore(testWebhookDelivery("d1", receiver.URL, now))
+	dispatcher := NewWebhookDispatcher(store, receiver.Client())
+
+	claimed, sent, err := dispatcher.DispatchDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	assert.Equal(t, 1, sent)
+	if !assert.Len(t, receiver.requests, 1) {
+		return
+	}
+
+	header, body := receiver.requests[0].Header, receiver.bodies[0]
+	assert.Equal(t, "transaction.created", header.Get(WebhookEventHeader))
+	assert.Equal(t, "d1", header.Get(WebhookDeliveryHeader))
+	timestamp, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, SignWebhookPayload("whsec_test_secret", timestamp, body), header.Get(WebhookSignatureHeader))
+
+	var payload map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, "evt-d1", payload["id"])
+	assert.Equal(t, "transaction.created", payload["type"])
+	assert.Equal(t, 42.5, payload["data"].(map[string]interface{})["amount"])
+
+	d := store.deliveries["d1"]
+	assert.Equal(t, models.WebhookDeliverySent, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.NotNil(t, d.DeliveredAt)
+	if assert.Len(t, store.attempts, 1) {
+		assert.Equal(t, 1, store.attempts[0].Attempt)
+		assert.Equal(t, http.StatusOK, *store.attempts[0].ResponseStatus)
+		assert.Nil(t, store.attempts[0].Error)
+	}
+
+	// Nothing is left to send
+	claimed, _, err = dispatcher.DispatchDue(ctx, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+}
+
+func TestWebhookDispatcher_RetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	receiver := startWebhookReceiver(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+	now := time.Now().UTC()
+	store := newFakeWebhookStore(testWebhookDelivery("d1", receiver.URL, now))
+	dispatcher := NewWebhookDispatcher(store, receiver.Client())
+
+	_, sent, err := dispatcher.DispatchDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	d := store.deliveries["d1"]
+	assert.Equal(t, models.WebhookDeliveryPending, d.Status)
+	assert.Equal(t, http.StatusInternalServerError, *d.LastResponseStatus)
+	assert.WithinDuration(t, now.Add(deliveryBackoff(1)), d.NextAttemptAt, 5*time.Second)
+
+	// Not due again until the backoff has passed
+	claimed, _, err := dispatcher.DispatchDue(ctx, now.Add(30*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+
+	_, _, err = dispatcher.DispatchDue(ctx, d.NextAttemptAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, d.Attempts)
+	assert.WithinDuration(t, time.Now().Add(deliveryBackoff(2)), d.NextAttemptAt, 5*time.Second)
+
+	_, sent, err = dispatcher.DispatchDue(ctx, d.NextAttemptAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, models.WebhookDeliverySent, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Nil(t, d.LastError)
+
+	// Every attempt is in the log, and each carries the same event
+	if assert.Len(t, store.attempts, 3) {
+		assert.Equal(t, []int{1, 2, 3}, []int{store.attempts[0].Attempt, store.attempts[1].Attempt, store.attempts[2].Attempt})
+		assert.Contains(t, *store.attempts[0].Error, "500")
+		assert.Equal(t, http.StatusTooManyRequests, *store.attempts[1].ResponseStatus)
+	}
+	assert.Equal(t, receiver.bodies[0], receiver.bodies[2])
+}
+
+func TestWebhookDispatcher_GivesUp(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// Client errors other than 408 and 429 are not retried
+	receiver := startWebhookReceiver(t, http.StatusGone)
+	store := newFakeWebhookStore(testWebhookDelivery("d1", receiver.URL, now))
+	_, _, err := NewWebhookDispatcher(store, receiver.Client()).DispatchDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryFailed, store.deliveries["d1"].Status)
+	assert.Equal(t, 1, store.deliveries["d1"].Attempts)
+
+	// Nor is anything after the last attempt
+	receiver = startWebhookReceiver(t, http.StatusBadGateway)
+	delivery := testWebhookDelivery("d2", receiver.URL, now)
+	delivery.Attempts = maxDeliveryAttempts - 1
+	store = newFakeWebhookStore(delivery)
+	_, _, err = NewWebhookDispatcher(store, receiver.Client()).DispatchDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryFailed, store.deliveries["d2"].Status)
+	assert.Equal(t, maxDeliveryAttempts, store.deliveries["d2"].Attempts)
+
+	// An unreachable endpoint is logged without a response status
+	receiver = startWebhookReceiver(t)
+	receiver.Close()
+	store = newFakeWebhookStore(testWebhookDelivery("d3", receiver.URL, now))
+	_, _, err = NewWebhookDispatcher(store, receiver.Client()).DispatchDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, store.deliveries["d3"].Status)
+	if assert.Len(t, store.attempts, 1) {
+		assert.Nil(t, store.attempts[0].ResponseStatus)
+		assert.NotNil(t, store.attempts[0].Error)
+	}
+}
+
+func TestWebhookDispatcher_SkipsInactiveSubscriptions(t *testing.T) {
+	receiver := startWebhookReceiver(t)
+	now := time.Now().UTC()
+	delivery := testWebhookDelivery("d1", receiver.URL, now)
+	delivery.Subscription.IsActive = false
+	store := newFakeWebhookStore(delivery)
+
+	claimed, _, err := NewWebhookDispatcher(store, receiver.Client()).DispatchDue(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+	assert.Empty(t, receiver.requests)
+	assert.Equal(t, models.WebhookDeliveryPending, store.deliveries["d1"].Status)
+}
+
+func TestValidateWebhookSubscription(t *testing.T) {
+	valid := func() *models.WebhookSubscription {
+		return &models.WebhookSubscription{
+			URL:        "https://example.com/hooks/finance",
+			Secret:     "whsec_0123456789abcdef",
+			EventTypes: []models.WebhookEventType{models.WebhookEventTransactionCreated, models.WebhookEventGoalCompleted},
+		}
+	}
+	assert.NoError(t, ValidateWebhookSubscription(valid()))
+
+	s := valid()
+	s.EventTypes = append(s.EventTypes, models.WebhookEventTransactionCreated)
+	assert.NoError(t, ValidateWebhookSubscription(s))
+	assert.Equal(t, []models.WebhookEventType{models.WebhookEventTransactionCreated, models.WebhookEventGoalCompleted}, s.EventTypes)
+
+	for name, change := range map[string]func(*models.WebhookSubscription){
+		"ftp url":        func(s *models.WebhookSubscription) { s.URL = "ftp://example.com" },
+		"no host":        func(s *models.WebhookSubscription) { s.URL = "https://" },
+		"loopback url":   func(s *models.WebhookSubscription) { s.URL = "http://127.0.0.1:5432/" },
+		"localhost url":  func(s *models.WebhookSubscription) { s.URL = "http://localhost/hooks" },
+		"private url":    func(s *models.WebhookSubscription) { s.URL = "https://[fd00::1]/hooks" },
+		"short secret":   func(s *models.WebhookSubscription) { s.Secret = "short" },
+		"no events":      func(s *models.WebhookSubscription) { s.EventTypes = nil },
+		"unknown events": func(s *models.WebhookSubscription) { s.EventTypes = []models.WebhookEventType{"account.deleted"} },
+	} {
+		s := valid()
+		change(s)
+		assert.Error(t, ValidateWebhookSubscription(s), name)
+	}
+}
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	a, err := GenerateWebhookSecret()
+	assert.NoError(t, err)
+	b, err := GenerateWebhookSecret()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(a, "whsec_"))
+	assert.Len(t, a, len("whsec_")+64)
+	assert.NotEqual(t, a, b)
+}
@@ -0,0 +1,90 @@
+-- =============================================================================
+-- Personal Finance Management System - Transaction Splits
+-- Migration 008: Split lines dividing a transaction across categories
+-- =============================================================================
+
+-- Create transaction splits table
+CREATE TABLE public.transaction_splits (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES public.transactions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES public.categories(id) ON DELETE SET NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    -- Split lines carry the same sign convention as their parent
+    CONSTRAINT positive_split_amount CHECK (amount > 0)
+);
+
+-- Create indexes for transaction splits
+CREATE INDEX idx_transaction_splits_transaction_id ON public.transaction_splits(transaction_id);
+CREATE INDEX idx_transaction_splits_user_id ON public.transaction_splits(user_id);
+CREATE INDEX idx_transaction_splits_category_id ON public.transaction_splits(category_id);
+
+-- Enable RLS on transaction splits
+ALTER TABLE public.transaction_splits ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own transaction splits"
+    ON public.transaction_splits
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own transaction splits"
+    ON public.transaction_splits
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own transaction splits"
+    ON public.transaction_splits
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own transaction splits"
+    ON public.transaction_splits
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_transaction_splits_updated_at
+    BEFORE UPDATE ON public.transaction_splits
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- =============================================================================
+-- TRANSACTION LINES VIEW
+-- =============================================================================
+
+-- One row per categorized amount: split lines for split transactions, the
+-- transaction itself otherwise. Reports aggregate over this view so that a
+-- split receipt is counted against each of its categories.
+CREATE OR REPLACE VIEW public.transaction_lines
+WITH (security_invoker = true) AS
+SELECT
+    t.id AS transaction_id,
+    t.user_id,
+    t.account_id,
+    s.category_id,
+    s.amount,
+    t.transaction_type,
+    t.transaction_date
+FROM public.transactions t
+JOIN public.transaction_splits s ON s.transaction_id = t.id
+UNION ALL
+SELECT
+    t.id AS transaction_id,
+    t.user_id,
+    t.account_id,
+    t.category_id,
+    t.amount,
+    t.transaction_type,
+    t.transaction_date
+FROM public.transactions t
+WHERE NOT EXISTS (
+    SELECT 1 FROM public.transaction_splits s WHERE s.transaction_id = t.id
+);
+
+-- Grant necessary permissions
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.transaction_splits TO authenticated;
+GRANT SELECT ON public.transaction_lines TO authenticated;
@@ -0,0 +1,186 @@
+-- =============================================================================
+-- Personal Finance Management System - Account Transfers
+-- Migration 009: Linked transfer legs sharing a transfer_id
+-- =============================================================================
+
+-- Both legs of a transfer now carry the same transfer_id, which identifies the
+-- transfer as a whole rather than pointing at the other leg. The foreign key
+-- made deleting a single leg impossible (ON DELETE SET NULL would violate
+-- transfer_requires_transfer_id), so it is dropped.
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_transfer_id_fkey;
+
+-- =============================================================================
+-- LEGACY TRANSFERS
+-- =============================================================================
+
+-- Transfers written before this migration are pairs of rows pointing at each
+-- other (or one leg pointing at the other and itself), often with a positive
+-- amount on both legs, and the old balance trigger never counted them. They
+-- are brought into the new shape before the trigger below starts counting
+-- transfers, so editing or deleting one later reverses what was applied.
+--
+-- Each pair collapses onto one shared transfer_id. In a pair of transfer rows
+-- the negative leg, or else the one created first, is the outgoing leg and is
+-- made negative; the other is made positive.
+--
+-- Rows that do not form such a pair (a lone leg, more than two linked rows,
+-- or a transfer linked to an income or expense) cannot be managed as a
+-- transfer, so they are unlinked. Transfer rows among them become an expense
+-- or an income by the sign of their amount.
+CREATE TEMP TABLE legacy_transfer_legs AS
+WITH keyed AS (
+    SELECT t.id, t.amount, t.transaction_type, t.created_at,
+           CASE
+               WHEN t.transfer_id <> t.id THEN LEAST(t.id, t.transfer_id)
+               ELSE LEAST(t.id, COALESCE((
+                   SELECT o.id FROM public.transactions o
+                   WHERE o.transfer_id = t.id AND o.id <> t.id
+                   ORDER BY o.id
+                   LIMIT 1
+               ), t.id))
+           END AS pair_id
+    FROM public.transactions t
+    WHERE t.transfer_id IS NOT NULL
+)
+SELECT k.id, k.pair_id,
+       COUNT(*) OVER (PARTITION BY k.pair_id) = 2
+           AND bool_and(k.transaction_type = 'transfer') OVER (PARTITION BY k.pair_id) AS paired,
+       ROW_NUMBER() OVER (PARTITION BY k.pair_id ORDER BY (k.amount < 0) DESC, k.created_at, k.id) AS leg
+FROM keyed k;
+
+-- The old trigger ignores transfer rows, so this moves no balances
+UPDATE public.transactions t
+SET transfer_id = l.pair_id,
+    amount = CASE WHEN l.leg = 1 THEN -ABS(t.amount) ELSE ABS(t.amount) END
+FROM legacy_transfer_legs l
+WHERE t.id = l.id AND l.paired;
+
+-- The old trigger applies the retyped rows to their account balances
+UPDATE public.transactions t
+SET transfer_id = NULL,
+    transaction_type = CASE
+        WHEN t.transaction_type <> 'transfer' THEN t.transaction_type
+        WHEN t.amount < 0 THEN 'expense'::transaction_type
+        ELSE 'income'::transaction_type
+    END,
+    amount = CASE WHEN t.transaction_type = 'transfer' THEN ABS(t.amount) ELSE t.amount END
+FROM legacy_transfer_legs l
+WHERE t.id = l.id AND NOT l.paired;
+
+DROP TABLE legacy_transfer_legs;
+
+-- Apply the transfers the old trigger skipped to the account balances
+UPDATE public.accounts a
+SET balance = a.balance + legacy.total
+FROM (
+    SELECT account_id, SUM(amount) AS total
+    FROM public.transactions
+    WHERE transaction_type = 'transfer'
+    GROUP BY account_id
+) legacy
+WHERE a.id = legacy.account_id;
+
+-- =============================================================================
+-- ACCOUNT BALANCE TRIGGER
+-- =============================================================================
+
+-- Transfer legs are signed: the outgoing leg is negative and the incoming leg
+-- positive, so a transfer moves money between accounts without touching
+-- income or expense totals.
+CREATE OR REPLACE FUNCTION public.update_account_balance()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Handle INSERT
+    IF TG_OP = 'INSERT' THEN
+        UPDATE public.accounts
+        SET balance = balance +
+            CASE
+                WHEN NEW.transaction_type = 'income' THEN NEW.amount
+                WHEN NEW.transaction_type = 'expense' THEN -NEW.amount
+                WHEN NEW.transaction_type = 'transfer' THEN NEW.amount
+                ELSE 0
+            END
+        WHERE id = NEW.account_id;
+        RETURN NEW;
+    END IF;
+
+    -- Handle UPDATE
+    IF TG_OP = 'UPDATE' THEN
+        -- Revert old transaction effect
+        UPDATE public.accounts
+        SET balance = balance -
+            CASE
+                WHEN OLD.transaction_type = 'income' THEN OLD.amount
+                WHEN OLD.transaction_type = 'expense' THEN -OLD.amount
+                WHEN OLD.transaction_type = 'transfer' THEN OLD.amount
+                ELSE 0
+            END
+        WHERE id = OLD.account_id;
+
+        -- Apply new transaction effect
+        UPDATE public.accounts
+        SET balance = balance +
+            CASE
+                WHEN NEW.transaction_type = 'income' THEN NEW.amount
+                WHEN NEW.transaction_type = 'expense' THEN -NEW.amount
+                WHEN NEW.transaction_type = 'transfer' THEN NEW.amount
+                ELSE 0
+            END
+        WHERE id = NEW.account_id;
+        RETURN NEW;
+    END IF;
+
+    -- Handle DELETE
+    IF TG_OP = 'DELETE' THEN
+        UPDATE public.accounts
+        SET balance = balance -
+            CASE
+                WHEN OLD.transaction_type = 'income' THEN OLD.amount
+                WHEN OLD.transaction_type = 'expense' THEN -OLD.amount
+                WHEN OLD.transaction_type = 'transfer' THEN OLD.amount
+                ELSE 0
+            END
+        WHERE id = OLD.account_id;
+        RETURN OLD;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+-- =============================================================================
+-- TRANSACTION LINES VIEW
+-- =============================================================================
+
+-- Reports read from transaction_lines, so transfers are excluded here once
+-- rather than in every query. Rows linked by transfer_id are excluded even when
+-- an older client recorded them as income or expense.
+CREATE OR REPLACE VIEW public.transaction_lines
+WITH (security_invoker = true) AS
+SELECT
+    t.id AS transaction_id,
+    t.user_id,
+    t.account_id,
+    s.category_id,
+    s.amount,
+    t.transaction_type,
+    t.transaction_date
+FROM public.transactions t
+JOIN public.transaction_splits s ON s.transaction_id = t.id
+WHERE t.transaction_type <> 'transfer'
+  AND t.transfer_id IS NULL
+UNION ALL
+SELECT
+    t.id AS transaction_id,
+    t.user_id,
+    t.account_id,
+    t.category_id,
+    t.amount,
+    t.transaction_type,
+    t.transaction_date
+FROM public.transactions t
+WHERE t.transaction_type <> 'transfer'
+  AND t.transfer_id IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM public.transaction_splits s WHERE s.transaction_id = t.id
+  );
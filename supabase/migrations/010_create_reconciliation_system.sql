@@ -0,0 +1,110 @@
+-- =============================================================================
+-- Personal Finance Management System - Account Reconciliation
+-- Migration 010: Statement reconciliations, cleared and locked transactions
+-- =============================================================================
+
+-- Create reconciliation status enum
+CREATE TYPE reconciliation_status AS ENUM ('in_progress', 'completed', 'cancelled');
+
+-- Create reconciliations table
+CREATE TABLE public.reconciliations (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES public.accounts(id) ON DELETE CASCADE,
+    statement_date DATE NOT NULL,
+    statement_balance DECIMAL(15,2) NOT NULL,
+    cleared_balance DECIMAL(15,2),
+    difference DECIMAL(15,2),
+    cleared_count INTEGER DEFAULT 0,
+    status reconciliation_status NOT NULL DEFAULT 'in_progress',
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Only one reconciliation may be open per account at a time
+CREATE UNIQUE INDEX unique_open_reconciliation_per_account
+    ON public.reconciliations(account_id)
+    WHERE status = 'in_progress';
+
+-- Add reconciliation fields to transactions
+ALTER TABLE public.transactions ADD COLUMN is_cleared BOOLEAN DEFAULT false;
+ALTER TABLE public.transactions ADD COLUMN cleared_at TIMESTAMPTZ;
+ALTER TABLE public.transactions ADD COLUMN is_locked BOOLEAN DEFAULT false;
+ALTER TABLE public.transactions ADD COLUMN reconciliation_id UUID REFERENCES public.reconciliations(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX idx_reconciliations_user_id ON public.reconciliations(user_id);
+CREATE INDEX idx_reconciliations_account_id ON public.reconciliations(account_id);
+CREATE INDEX idx_reconciliations_statement_date ON public.reconciliations(statement_date DESC);
+CREATE INDEX idx_transactions_reconciliation_id ON public.transactions(reconciliation_id);
+CREATE INDEX idx_transactions_account_cleared ON public.transactions(account_id, is_cleared);
+
+-- Enable RLS on reconciliations
+ALTER TABLE public.reconciliations ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own reconciliations"
+    ON public.reconciliations
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own reconciliations"
+    ON public.reconciliations
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own reconciliations"
+    ON public.reconciliations
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_reconciliations_updated_at
+    BEFORE UPDATE ON public.reconciliations
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- =============================================================================
+-- LOCKED TRANSACTION GUARD
+-- =============================================================================
+
+-- Reconciled transactions are locked. While the row stays locked its financial
+-- columns cannot change and it cannot be deleted; descriptive columns such as
+-- the category, description, notes, tags and merchant can still be edited. An
+-- update that clears is_locked is the explicit unlock and is allowed. Deletes
+-- cascading from a removed account (or user) are let through.
+CREATE OR REPLACE FUNCTION public.prevent_locked_transaction_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.is_locked AND EXISTS (SELECT 1 FROM public.accounts WHERE id = OLD.account_id) THEN
+            RAISE EXCEPTION 'transaction % is locked by a reconciliation', OLD.id;
+        END IF;
+        RETURN OLD;
+    END IF;
+
+    IF OLD.is_locked AND NEW.is_locked AND (
+        NEW.amount IS DISTINCT FROM OLD.amount
+        OR NEW.transaction_date IS DISTINCT FROM OLD.transaction_date
+        OR NEW.account_id IS DISTINCT FROM OLD.account_id
+        OR NEW.transaction_type IS DISTINCT FROM OLD.transaction_type
+        OR NEW.currency IS DISTINCT FROM OLD.currency
+        OR NEW.original_currency IS DISTINCT FROM OLD.original_currency
+        OR NEW.exchange_rate IS DISTINCT FROM OLD.exchange_rate
+        OR NEW.is_cleared IS DISTINCT FROM OLD.is_cleared
+        OR NEW.reconciliation_id IS DISTINCT FROM OLD.reconciliation_id
+    ) THEN
+        RAISE EXCEPTION 'transaction % is locked by a reconciliation', OLD.id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+CREATE TRIGGER prevent_locked_transaction_changes_trigger
+    BEFORE UPDATE OR DELETE ON public.transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION public.prevent_locked_transaction_changes();
+
+-- Grant necessary permissions
+GRANT SELECT, INSERT, UPDATE ON public.reconciliations TO authenticated;
@@ -0,0 +1,56 @@
+-- =============================================================================
+-- Personal Finance Management System - Categorization Rules
+-- Migration 011: User-defined auto-categorization rules
+-- =============================================================================
+
+-- Create categorization rules table
+-- conditions: {description_pattern, min_amount, max_amount, account_ids, weekdays, transaction_type}
+-- actions:    {category_id, tags, notes}
+CREATE TABLE public.categorization_rules (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 100,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    stop_processing BOOLEAN NOT NULL DEFAULT false,
+    conditions JSONB NOT NULL DEFAULT '{}',
+    actions JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX idx_categorization_rules_user_id ON public.categorization_rules(user_id);
+CREATE INDEX idx_categorization_rules_priority ON public.categorization_rules(user_id, priority) WHERE is_active;
+
+-- Enable RLS
+ALTER TABLE public.categorization_rules ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own categorization rules"
+    ON public.categorization_rules
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own categorization rules"
+    ON public.categorization_rules
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own categorization rules"
+    ON public.categorization_rules
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own categorization rules"
+    ON public.categorization_rules
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_categorization_rules_updated_at
+    BEFORE UPDATE ON public.categorization_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- Grant necessary permissions
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.categorization_rules TO authenticated;
@@ -0,0 +1,129 @@
+-- =============================================================================
+-- Personal Finance Management System - Merchants
+-- Migration 012: Canonical merchants, user aliases and transaction merchant link
+-- =============================================================================
+
+-- Create merchants table
+-- normalized_name is the lowercase alphanumeric key merchants are deduplicated on
+CREATE TABLE public.merchants (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    normalized_name TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, normalized_name)
+);
+
+-- Create merchant aliases table
+CREATE TABLE public.merchant_aliases (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    merchant_id UUID NOT NULL REFERENCES public.merchants(id) ON DELETE CASCADE,
+    pattern TEXT NOT NULL,
+    match_type TEXT NOT NULL DEFAULT 'contains' CHECK (match_type IN ('contains', 'exact', 'regex')),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Link transactions to merchants
+ALTER TABLE public.transactions
+    ADD COLUMN merchant_id UUID REFERENCES public.merchants(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX idx_merchants_user_id ON public.merchants(user_id);
+CREATE INDEX idx_merchant_aliases_user_id ON public.merchant_aliases(user_id);
+CREATE INDEX idx_merchant_aliases_merchant_id ON public.merchant_aliases(merchant_id);
+CREATE INDEX idx_transactions_merchant_id ON public.transactions(merchant_id);
+
+-- Enable RLS
+ALTER TABLE public.merchants ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.merchant_aliases ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own merchants"
+    ON public.merchants
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own merchants"
+    ON public.merchants
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own merchants"
+    ON public.merchants
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own merchants"
+    ON public.merchants
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can view own merchant aliases"
+    ON public.merchant_aliases
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own merchant aliases"
+    ON public.merchant_aliases
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own merchant aliases"
+    ON public.merchant_aliases
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own merchant aliases"
+    ON public.merchant_aliases
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_merchants_updated_at
+    BEFORE UPDATE ON public.merchants
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- =============================================================================
+-- TRANSACTION LINES VIEW
+-- =============================================================================
+
+-- Expose the merchant so per-merchant reports can read from the same view.
+-- Split lines inherit the merchant of their parent transaction.
+CREATE OR REPLACE VIEW public.transaction_lines
+WITH (security_invoker = true) AS
+SELECT
+    t.id AS transaction_id,
+    t.user_id,
+    t.account_id,
+    s.category_id,
+    s.amount,
+    t.transaction_type,
+    t.transaction_date,
+    t.merchant_id
+FROM public.transactions t
+JOIN public.transaction_splits s ON s.transaction_id = t.id
+WHERE t.transaction_type <> 'transfer'
+  AND t.transfer_id IS NULL
+UNION ALL
+SELECT
+    t.id AS transaction_id,
+    t.user_id,
+    t.account_id,
+    t.category_id,
+    t.amount,
+    t.transaction_type,
+    t.transaction_date,
+    t.merchant_id
+FROM public.transactions t
+WHERE t.transaction_type <> 'transfer'
+  AND t.transfer_id IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM public.transaction_splits s WHERE s.transaction_id = t.id
+  );
+
+-- Grant necessary permissions
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.merchants TO authenticated;
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.merchant_aliases TO authenticated;
+GRANT SELECT ON public.transaction_lines TO authenticated;
@@ -0,0 +1,103 @@
+-- =============================================================================
+-- Personal Finance Management System - Custom Fields
+-- Migration 013: Transaction tags and user-defined typed custom fields
+-- =============================================================================
+
+-- Add tags to transactions
+ALTER TABLE public.transactions ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_transactions_tags ON public.transactions USING GIN (tags);
+
+-- Create custom field definitions table
+-- options lists the allowed values of enum fields and is empty otherwise
+CREATE TABLE public.custom_field_definitions (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    field_type TEXT NOT NULL CHECK (field_type IN ('text', 'number', 'date', 'enum')),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, name),
+    CHECK (field_type <> 'enum' OR cardinality(options) > 0)
+);
+
+-- Custom field values keyed by definition id
+ALTER TABLE public.transactions ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+
+-- Create indexes
+CREATE INDEX idx_custom_field_definitions_user_id ON public.custom_field_definitions(user_id);
+CREATE INDEX idx_transactions_custom_fields ON public.transactions USING GIN (custom_fields);
+
+-- Enable RLS
+ALTER TABLE public.custom_field_definitions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own custom field definitions"
+    ON public.custom_field_definitions
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own custom field definitions"
+    ON public.custom_field_definitions
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own custom field definitions"
+    ON public.custom_field_definitions
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own custom field definitions"
+    ON public.custom_field_definitions
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_custom_field_definitions_updated_at
+    BEFORE UPDATE ON public.custom_field_definitions
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- =============================================================================
+-- TRANSACTION LINES VIEW
+-- =============================================================================
+
+-- Expose tags so tag reports can read from the same view. Split lines carry
+-- the tags of their parent transaction.
+CREATE OR REPLACE VIEW public.transaction_lines
+WITH (security_invoker = true) AS
+SELECT
+    t.id AS transaction_id,
+    t.user_id,
+    t.account_id,
+    s.category_id,
+    s.amount,
+    t.transaction_type,
+    t.transaction_date,
+    t.merchant_id,
+    t.tags
+FROM public.transactions t
+JOIN public.transaction_splits s ON s.transaction_id = t.id
+WHERE t.transaction_type <> 'transfer'
+  AND t.transfer_id IS NULL
+UNION ALL
+SELECT
+    t.id AS transaction_id,
+    t.user_id,
+    t.account_id,
+    t.category_id,
+    t.amount,
+    t.transaction_type,
+    t.transaction_date,
+    t.merchant_id,
+    t.tags
+FROM public.transactions t
+WHERE t.transaction_type <> 'transfer'
+  AND t.transfer_id IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM public.transaction_splits s WHERE s.transaction_id = t.id
+  );
+
+-- Grant necessary permissions
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.custom_field_definitions TO authenticated;
+GRANT SELECT ON public.transaction_lines TO authenticated;
@@ -0,0 +1,18 @@
+-- =============================================================================
+-- Personal Finance Management System - Transaction Search
+-- Migration 014: Full-text search over transaction descriptions and notes
+-- =============================================================================
+
+-- Descriptions weigh more than notes when ranking matches
+ALTER TABLE public.transactions
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english'::regconfig, COALESCE(description, '')), 'A') ||
+        setweight(to_tsvector('english'::regconfig, COALESCE(notes, '')), 'B')
+    ) STORED;
+
+-- Create indexes
+CREATE INDEX idx_transactions_search_vector ON public.transactions USING GIN (search_vector);
+
+-- Keyset pagination of unranked searches walks this index
+CREATE INDEX idx_transactions_user_date_id ON public.transactions(user_id, transaction_date DESC, id DESC);
+
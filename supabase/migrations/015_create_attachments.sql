@@ -0,0 +1,95 @@
+-- =============================================================================
+-- Personal Finance Management System - Attachments
+-- Migration 015: Receipt and document attachments for transactions and tax documents
+-- =============================================================================
+
+-- Create attachments table
+-- The file contents live in object storage under storage_key; content_hash is
+-- the hex SHA-256 of the contents and deduplicates uploads per user
+CREATE TABLE public.attachments (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    file_size BIGINT NOT NULL CHECK (file_size > 0),
+    content_hash TEXT NOT NULL,
+    storage_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, content_hash)
+);
+
+-- Create attachment links table
+-- Each link attaches a file to exactly one transaction or tax document
+CREATE TABLE public.attachment_links (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    attachment_id UUID NOT NULL REFERENCES public.attachments(id) ON DELETE CASCADE,
+    transaction_id UUID REFERENCES public.transactions(id) ON DELETE CASCADE,
+    tax_document_id UUID REFERENCES public.tax_documents(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK ((transaction_id IS NULL) <> (tax_document_id IS NULL))
+);
+
+-- Create indexes
+CREATE INDEX idx_attachments_user_id ON public.attachments(user_id);
+CREATE INDEX idx_attachment_links_attachment_id ON public.attachment_links(attachment_id);
+CREATE UNIQUE INDEX idx_attachment_links_transaction
+    ON public.attachment_links(attachment_id, transaction_id)
+    WHERE transaction_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_attachment_links_tax_document
+    ON public.attachment_links(attachment_id, tax_document_id)
+    WHERE tax_document_id IS NOT NULL;
+CREATE INDEX idx_attachment_links_transaction_id ON public.attachment_links(transaction_id);
+CREATE INDEX idx_attachment_links_tax_document_id ON public.attachment_links(tax_document_id);
+
+-- Enable RLS
+ALTER TABLE public.attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.attachment_links ENABLE ROW LEVEL SECURITY;
+
+-- Attachments policies
+CREATE POLICY "Users can view own attachments"
+    ON public.attachments
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own attachments"
+    ON public.attachments
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own attachments"
+    ON public.attachments
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own attachments"
+    ON public.attachments
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+-- Attachment links policies
+CREATE POLICY "Users can view own attachment links"
+    ON public.attachment_links
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own attachment links"
+    ON public.attachment_links
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own attachment links"
+    ON public.attachment_links
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_attachments_updated_at
+    BEFORE UPDATE ON public.attachments
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- Grant necessary permissions
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.attachments TO authenticated;
+GRANT SELECT, INSERT, DELETE ON public.attachment_links TO authenticated;
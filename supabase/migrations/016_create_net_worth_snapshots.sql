@@ -0,0 +1,56 @@
+-- =============================================================================
+-- Personal Finance Management System - Net Worth Snapshots
+-- Migration 016: Daily net worth history
+-- =============================================================================
+
+-- Create net worth snapshots table
+-- One row per user and day, in the user's base currency at the time.
+-- breakdown holds the per account type totals the figures were built from.
+CREATE TABLE public.net_worth_snapshots (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    base_currency TEXT NOT NULL DEFAULT 'USD',
+    total_assets DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    total_liabilities DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    net_worth DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    breakdown JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, snapshot_date)
+);
+
+-- Create indexes
+CREATE INDEX idx_net_worth_snapshots_user_date ON public.net_worth_snapshots(user_id, snapshot_date);
+
+-- Enable RLS
+ALTER TABLE public.net_worth_snapshots ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own net worth snapshots"
+    ON public.net_worth_snapshots
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own net worth snapshots"
+    ON public.net_worth_snapshots
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own net worth snapshots"
+    ON public.net_worth_snapshots
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own net worth snapshots"
+    ON public.net_worth_snapshots
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_net_worth_snapshots_updated_at
+    BEFORE UPDATE ON public.net_worth_snapshots
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- Grant necessary permissions
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.net_worth_snapshots TO authenticated;
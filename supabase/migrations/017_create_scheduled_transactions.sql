@@ -0,0 +1,61 @@
+-- =============================================================================
+-- Personal Finance Management System - Scheduled Transactions
+-- Migration 017: Planned one-off and repeating transactions for forecasting
+-- =============================================================================
+
+-- Create scheduled transactions table
+-- next_date is the first occurrence still to come; repeating schedules recur
+-- from it at the given frequency until end_date
+CREATE TABLE public.scheduled_transactions (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES public.accounts(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES public.categories(id) ON DELETE SET NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    transaction_type TEXT NOT NULL CHECK (transaction_type IN ('income', 'expense')),
+    description TEXT,
+    frequency TEXT NOT NULL DEFAULT 'monthly'
+        CHECK (frequency IN ('once', 'weekly', 'biweekly', 'monthly', 'quarterly', 'yearly')),
+    next_date DATE NOT NULL,
+    end_date DATE,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (end_date IS NULL OR end_date >= next_date)
+);
+
+-- Create indexes
+CREATE INDEX idx_scheduled_transactions_user_id ON public.scheduled_transactions(user_id);
+CREATE INDEX idx_scheduled_transactions_account_id ON public.scheduled_transactions(account_id);
+
+-- Enable RLS
+ALTER TABLE public.scheduled_transactions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own scheduled transactions"
+    ON public.scheduled_transactions
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can insert own scheduled transactions"
+    ON public.scheduled_transactions
+    FOR INSERT
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can update own scheduled transactions"
+    ON public.scheduled_transactions
+    FOR UPDATE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own scheduled transactions"
+    ON public.scheduled_transactions
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_scheduled_transactions_updated_at
+    BEFORE UPDATE ON public.scheduled_transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- Grant necessary permissions
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.scheduled_transactions TO authenticated;
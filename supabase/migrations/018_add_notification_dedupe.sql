@@ -0,0 +1,10 @@
+-- =============================================================================
+-- Personal Finance Management System - Notification Deduplication
+-- Migration 018: Unique dedupe keys for generated notifications
+-- =============================================================================
+
+-- Notifications raised by background checks carry a dedupe_key in their
+-- metadata so that the same alert is only ever created once per user
+CREATE UNIQUE INDEX idx_notifications_dedupe_key
+    ON public.notifications(user_id, (metadata->>'dedupe_key'))
+    WHERE metadata ? 'dedupe_key';
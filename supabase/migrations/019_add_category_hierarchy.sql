@@ -0,0 +1,51 @@
+-- =============================================================================
+-- Personal Finance Management System - Category Hierarchy
+-- Migration 019: Parent categories for grouping reports
+-- =============================================================================
+
+-- Categories can be nested under a parent category of the same user. Removing
+-- a parent moves its subcategories to the top level.
+ALTER TABLE public.categories
+    ADD COLUMN parent_id UUID REFERENCES public.categories(id) ON DELETE SET NULL,
+    ADD CONSTRAINT categories_parent_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX idx_categories_parent_id ON public.categories(parent_id);
+
+-- =============================================================================
+-- HIERARCHY GUARD
+-- =============================================================================
+
+-- A parent must belong to the same user and may not be one of the category's
+-- own descendants, so the hierarchy always stays a tree.
+CREATE OR REPLACE FUNCTION public.validate_category_parent()
+RETURNS TRIGGER AS $$
+DECLARE
+    ancestor_id UUID;
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM public.categories
+        WHERE id = NEW.parent_id AND user_id = NEW.user_id
+    ) THEN
+        RAISE EXCEPTION 'parent category % does not belong to the user', NEW.parent_id;
+    END IF;
+
+    ancestor_id := NEW.parent_id;
+    WHILE ancestor_id IS NOT NULL LOOP
+        IF ancestor_id = NEW.id THEN
+            RAISE EXCEPTION 'category % cannot be nested under its own subcategory', NEW.id;
+        END IF;
+        SELECT parent_id INTO ancestor_id FROM public.categories WHERE id = ancestor_id;
+    END LOOP;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+CREATE TRIGGER validate_category_parent_trigger
+    BEFORE INSERT OR UPDATE OF parent_id ON public.categories
+    FOR EACH ROW
+    EXECUTE FUNCTION public.validate_category_parent();
@@ -0,0 +1,48 @@
+-- =============================================================================
+-- Personal Finance Management System - Report Change Notifications
+-- Migration 020: Notify listeners when data behind a user's reports changes
+-- =============================================================================
+
+-- Sends the user's id on the report_data_changed channel so report caches can
+-- drop that user's entries. Notifications are delivered on commit and repeated
+-- payloads within one transaction are collapsed, so bulk writes send one.
+CREATE OR REPLACE FUNCTION public.notify_report_data_changed()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('report_data_changed', OLD.user_id::text);
+        RETURN OLD;
+    END IF;
+
+    PERFORM pg_notify('report_data_changed', NEW.user_id::text);
+    IF TG_OP = 'UPDATE' AND OLD.user_id IS DISTINCT FROM NEW.user_id THEN
+        PERFORM pg_notify('report_data_changed', OLD.user_id::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notify_report_data_changed_transactions
+    AFTER INSERT OR UPDATE OR DELETE ON public.transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION public.notify_report_data_changed();
+
+CREATE TRIGGER notify_report_data_changed_transaction_splits
+    AFTER INSERT OR UPDATE OR DELETE ON public.transaction_splits
+    FOR EACH ROW
+    EXECUTE FUNCTION public.notify_report_data_changed();
+
+CREATE TRIGGER notify_report_data_changed_budgets
+    AFTER INSERT OR UPDATE OR DELETE ON public.budgets
+    FOR EACH ROW
+    EXECUTE FUNCTION public.notify_report_data_changed();
+
+CREATE TRIGGER notify_report_data_changed_accounts
+    AFTER INSERT OR UPDATE OR DELETE ON public.accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION public.notify_report_data_changed();
+
+CREATE TRIGGER notify_report_data_changed_categories
+    AFTER INSERT OR UPDATE OR DELETE ON public.categories
+    FOR EACH ROW
+    EXECUTE FUNCTION public.notify_report_data_changed();
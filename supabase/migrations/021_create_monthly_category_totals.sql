@@ -0,0 +1,202 @@
+-- =============================================================================
+-- Personal Finance Management System - Monthly Category Totals
+-- Migration 021: Maintained per user, category and month report aggregates
+-- =============================================================================
+
+-- Create monthly category totals table
+-- One row per user, month, transaction type and category, summed from
+-- transaction_lines so split lines count against their own categories and
+-- transfers are left out. total_amount sums absolute amounts, net_amount the
+-- signed ones. category_id has no foreign key: deleting a category clears it
+-- on the transactions, which refreshes these rows.
+CREATE TABLE public.monthly_category_totals (
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    month DATE NOT NULL CHECK (month = date_trunc('month', month)::date),
+    category_id UUID,
+    transaction_type TEXT NOT NULL,
+    total_amount DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    net_amount DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    line_count INTEGER NOT NULL DEFAULT 0,
+    transaction_count INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE NULLS NOT DISTINCT (user_id, month, transaction_type, category_id)
+);
+
+-- Enable RLS
+-- Rows are only written by the functions below
+ALTER TABLE public.monthly_category_totals ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own monthly category totals"
+    ON public.monthly_category_totals
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+-- =============================================================================
+-- MAINTENANCE FUNCTIONS
+-- =============================================================================
+
+-- Recomputes a user's totals for the given months from transaction_lines.
+-- Writes for the same user are serialized so concurrent transactions cannot
+-- interleave their recomputations.
+CREATE OR REPLACE FUNCTION public.refresh_monthly_category_totals(p_user_id UUID, p_months DATE[])
+RETURNS VOID AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('monthly_category_totals'), hashtext(p_user_id::text));
+
+    DELETE FROM public.monthly_category_totals
+    WHERE user_id = p_user_id
+      AND month = ANY(p_months);
+
+    INSERT INTO public.monthly_category_totals (
+        user_id, month, category_id, transaction_type,
+        total_amount, net_amount, line_count, transaction_count
+    )
+    SELECT
+        l.user_id,
+        date_trunc('month', l.transaction_date)::date,
+        l.category_id,
+        l.transaction_type,
+        SUM(ABS(l.amount)),
+        SUM(l.amount),
+        COUNT(*),
+        COUNT(DISTINCT l.transaction_id)
+    FROM public.transaction_lines l
+    WHERE l.user_id = p_user_id
+      AND l.transaction_date >= (SELECT min(m) FROM unnest(p_months) AS m)
+      AND l.transaction_date < (SELECT max(m) FROM unnest(p_months) AS m) + interval '1 month'
+      AND date_trunc('month', l.transaction_date)::date = ANY(p_months)
+    GROUP BY 1, 2, 3, 4;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Rebuilds all of a user's totals, returning how many months have activity
+CREATE OR REPLACE FUNCTION public.rebuild_monthly_category_totals(p_user_id UUID)
+RETURNS INTEGER AS $$
+DECLARE
+    months DATE[];
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('monthly_category_totals'), hashtext(p_user_id::text));
+
+    DELETE FROM public.monthly_category_totals WHERE user_id = p_user_id;
+
+    SELECT array_agg(DISTINCT date_trunc('month', transaction_date)::date)
+    INTO months
+    FROM public.transaction_lines
+    WHERE user_id = p_user_id;
+
+    IF months IS NULL THEN
+        RETURN 0;
+    END IF;
+    PERFORM public.refresh_monthly_category_totals(p_user_id, months);
+    RETURN cardinality(months);
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Refreshes the months touched by a statement on transactions. Runs once per
+-- statement with the changed rows in transition tables, so bulk imports
+-- recompute each month once.
+CREATE OR REPLACE FUNCTION public.refresh_monthly_category_totals_for_transactions()
+RETURNS TRIGGER AS $$
+DECLARE
+    user_ids UUID[] := '{}';
+    dates DATE[] := '{}';
+    affected RECORD;
+BEGIN
+    -- A transition table missing for the event may not be referenced at all
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        SELECT user_ids || array_agg(user_id), dates || array_agg(transaction_date)
+        INTO user_ids, dates
+        FROM old_rows;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        SELECT user_ids || array_agg(user_id), dates || array_agg(transaction_date)
+        INTO user_ids, dates
+        FROM new_rows;
+    END IF;
+
+    FOR affected IN
+        SELECT changed.user_id, array_agg(DISTINCT date_trunc('month', changed.transaction_date)::date) AS months
+        FROM unnest(user_ids, dates) AS changed(user_id, transaction_date)
+        GROUP BY changed.user_id
+        ORDER BY changed.user_id
+    LOOP
+        PERFORM public.refresh_monthly_category_totals(affected.user_id, affected.months);
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Refreshes the months of the transactions whose splits a statement changed
+CREATE OR REPLACE FUNCTION public.refresh_monthly_category_totals_for_splits()
+RETURNS TRIGGER AS $$
+DECLARE
+    transaction_ids UUID[] := '{}';
+    affected RECORD;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        SELECT transaction_ids || array_agg(transaction_id) INTO transaction_ids FROM old_rows;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        SELECT transaction_ids || array_agg(transaction_id) INTO transaction_ids FROM new_rows;
+    END IF;
+
+    -- Splits deleted along with their transaction find no parent here; the
+    -- transaction's own trigger refreshes its month
+    FOR affected IN
+        SELECT t.user_id, array_agg(DISTINCT date_trunc('month', t.transaction_date)::date) AS months
+        FROM public.transactions t
+        WHERE t.id = ANY(transaction_ids)
+        GROUP BY t.user_id
+        ORDER BY t.user_id
+    LOOP
+        PERFORM public.refresh_monthly_category_totals(affected.user_id, affected.months);
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Transition tables need one trigger per event
+CREATE TRIGGER refresh_monthly_category_totals_on_transaction_insert
+    AFTER INSERT ON public.transactions
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION public.refresh_monthly_category_totals_for_transactions();
+
+CREATE TRIGGER refresh_monthly_category_totals_on_transaction_update
+    AFTER UPDATE ON public.transactions
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION public.refresh_monthly_category_totals_for_transactions();
+
+CREATE TRIGGER refresh_monthly_category_totals_on_transaction_delete
+    AFTER DELETE ON public.transactions
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION public.refresh_monthly_category_totals_for_transactions();
+
+CREATE TRIGGER refresh_monthly_category_totals_on_split_insert
+    AFTER INSERT ON public.transaction_splits
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION public.refresh_monthly_category_totals_for_splits();
+
+CREATE TRIGGER refresh_monthly_category_totals_on_split_update
+    AFTER UPDATE ON public.transaction_splits
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION public.refresh_monthly_category_totals_for_splits();
+
+CREATE TRIGGER refresh_monthly_category_totals_on_split_delete
+    AFTER DELETE ON public.transaction_splits
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION public.refresh_monthly_category_totals_for_splits();
+
+-- Backfill existing history
+SELECT public.rebuild_monthly_category_totals(user_id)
+FROM (SELECT DISTINCT user_id FROM public.transactions) AS users;
+
+-- Grant necessary permissions
+REVOKE EXECUTE ON FUNCTION public.refresh_monthly_category_totals(UUID, DATE[]) FROM PUBLIC;
+REVOKE EXECUTE ON FUNCTION public.rebuild_monthly_category_totals(UUID) FROM PUBLIC;
+GRANT SELECT ON public.monthly_category_totals TO authenticated;
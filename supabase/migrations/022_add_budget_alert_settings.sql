@@ -0,0 +1,24 @@
+-- =============================================================================
+-- Personal Finance Management System - Budget Alert Settings
+-- Migration 022: Per-budget alert thresholds and pace alerts
+-- =============================================================================
+
+-- alert_thresholds are the percentages of the budget at which an alert is
+-- raised, once per budget period each. The highest one below 100 also marks
+-- the budget as at risk in reports. pace_alerts warns when spending so far
+-- is on pace to exceed the budget before the period ends.
+ALTER TABLE public.budgets
+    ADD COLUMN alert_thresholds INTEGER[] NOT NULL DEFAULT '{50,80,100}',
+    ADD COLUMN pace_alerts BOOLEAN NOT NULL DEFAULT true;
+
+ALTER TABLE public.budgets
+    ADD CONSTRAINT valid_alert_thresholds CHECK (
+        cardinality(alert_thresholds) <= 10
+        AND 0 < ALL(alert_thresholds)
+        AND 1000 >= ALL(alert_thresholds)
+    );
+
+-- Budget alerts are raised by the backend's alert engine, which honours the
+-- settings above and deduplicates per period, instead of on every write
+DROP TRIGGER IF EXISTS check_budget_limits_trigger ON public.transactions;
+DROP FUNCTION IF EXISTS public.check_budget_limits();
@@ -0,0 +1,164 @@
+-- =============================================================================
+-- Personal Finance Management System - Notification Delivery
+-- Migration 023: Notification preferences, channels and the delivery queue
+-- =============================================================================
+
+-- Create notification settings table
+-- Per user channel settings. email falls back to the account's address.
+-- Quiet hours are local times in timezone and may span midnight; deliveries
+-- held by them go out when they end.
+CREATE TABLE public.notification_settings (
+    user_id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+    email TEXT,
+    webhook_url TEXT CHECK (webhook_url IS NULL OR webhook_url ~ '^https?://'),
+    webhook_secret TEXT,
+    quiet_hours_start TIME,
+    quiet_hours_end TIME,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT complete_quiet_hours CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+-- Create notification preferences table
+-- Per user and notification type. A type without a row is shown in-app only,
+-- immediately. delivery 'digest' collects email and webhook deliveries for
+-- the periodic digest instead of sending each one.
+CREATE TABLE public.notification_preferences (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    notification_type notification_type NOT NULL,
+    in_app BOOLEAN NOT NULL DEFAULT true,
+    email BOOLEAN NOT NULL DEFAULT false,
+    webhook BOOLEAN NOT NULL DEFAULT false,
+    delivery TEXT NOT NULL DEFAULT 'immediate' CHECK (delivery IN ('immediate', 'digest')),
+    respect_quiet_hours BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, notification_type)
+);
+
+-- Create notification deliveries table
+-- The outgoing queue: one row per notification and channel. Pending rows are
+-- sent once next_attempt_at has passed and retried with backoff on failure;
+-- digest rows wait for the next digest.
+CREATE TABLE public.notification_deliveries (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    notification_id UUID NOT NULL REFERENCES public.notifications(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'webhook')),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'digest', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (notification_id, channel)
+);
+
+-- Create indexes
+CREATE INDEX idx_notification_deliveries_due ON public.notification_deliveries(next_attempt_at)
+    WHERE status = 'pending';
+CREATE INDEX idx_notification_deliveries_user ON public.notification_deliveries(user_id, created_at DESC);
+
+-- Hide notifications whose type the user turned off in-app
+ALTER TABLE public.notifications ADD COLUMN in_app BOOLEAN NOT NULL DEFAULT true;
+
+-- Enable RLS
+ALTER TABLE public.notification_settings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.notification_preferences ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.notification_deliveries ENABLE ROW LEVEL SECURITY;
+
+-- Settings are only written through the API, which validates the webhook URL
+CREATE POLICY "Users can view own notification settings"
+    ON public.notification_settings
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can manage own notification preferences"
+    ON public.notification_preferences
+    FOR ALL
+    USING (auth.uid() = user_id)
+    WITH CHECK (auth.uid() = user_id);
+
+CREATE POLICY "Users can view own notification deliveries"
+    ON public.notification_deliveries
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+DROP POLICY "Users can view own notifications" ON public.notifications;
+CREATE POLICY "Users can view own notifications"
+    ON public.notifications
+    FOR SELECT
+    USING (auth.uid() = user_id AND in_app);
+
+-- Add updated_at triggers
+CREATE TRIGGER update_notification_settings_updated_at
+    BEFORE UPDATE ON public.notification_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+CREATE TRIGGER update_notification_preferences_updated_at
+    BEFORE UPDATE ON public.notification_preferences
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+CREATE TRIGGER update_notification_deliveries_updated_at
+    BEFORE UPDATE ON public.notification_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- =============================================================================
+-- DELIVERY FUNCTIONS AND TRIGGERS
+-- =============================================================================
+
+-- Hides a new notification in-app when the user turned its type off there
+CREATE OR REPLACE FUNCTION public.apply_notification_in_app_preference()
+RETURNS TRIGGER AS $$
+DECLARE
+    shown BOOLEAN;
+BEGIN
+    SELECT p.in_app INTO shown
+    FROM public.notification_preferences p
+    WHERE p.user_id = NEW.user_id AND p.notification_type = NEW.type;
+
+    IF FOUND THEN
+        NEW.in_app := shown;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Queues a new notification on each channel the user enabled for its type.
+-- Runs for every notification, whichever trigger or service created it.
+CREATE OR REPLACE FUNCTION public.queue_notification_deliveries()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO public.notification_deliveries (notification_id, user_id, channel, status)
+    SELECT NEW.id, NEW.user_id, channels.channel,
+           CASE WHEN p.delivery = 'digest' THEN 'digest' ELSE 'pending' END
+    FROM public.notification_preferences p
+    CROSS JOIN LATERAL (VALUES ('email', p.email), ('webhook', p.webhook)) AS channels(channel, enabled)
+    WHERE p.user_id = NEW.user_id
+      AND p.notification_type = NEW.type
+      AND channels.enabled;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+CREATE TRIGGER apply_notification_in_app_preference_trigger
+    BEFORE INSERT ON public.notifications
+    FOR EACH ROW
+    EXECUTE FUNCTION public.apply_notification_in_app_preference();
+
+CREATE TRIGGER queue_notification_deliveries_trigger
+    AFTER INSERT ON public.notifications
+    FOR EACH ROW
+    EXECUTE FUNCTION public.queue_notification_deliveries();
+
+-- Grant necessary permissions
+GRANT SELECT ON public.notification_settings TO authenticated;
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.notification_preferences TO authenticated;
+GRANT SELECT ON public.notification_deliveries TO authenticated;
@@ -0,0 +1,80 @@
+-- =============================================================================
+-- Personal Finance Management System - Notification Digests
+-- Migration 024: Weekly and monthly email digests
+-- =============================================================================
+
+-- Digest schedule. Weekly digests cover Monday to Sunday and monthly ones the
+-- calendar month; both go out the morning after the period ends in the
+-- user's timezone.
+ALTER TABLE public.notification_settings
+    ADD COLUMN digest_frequency TEXT NOT NULL DEFAULT 'off'
+    CHECK (digest_frequency IN ('off', 'weekly', 'monthly'));
+
+-- Create notification digests table
+-- One row per user and digest period, claimed before sending so a digest
+-- goes out once however many servers run the scheduler. Failed digests are
+-- retried until they succeed or run out of attempts.
+CREATE TABLE public.notification_digests (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    frequency TEXT NOT NULL CHECK (frequency IN ('weekly', 'monthly')),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'sending' CHECK (status IN ('sending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT,
+    claimed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, frequency, period_start),
+    CONSTRAINT valid_digest_period CHECK (period_end >= period_start)
+);
+
+-- Create indexes
+CREATE INDEX idx_notification_digests_user ON public.notification_digests(user_id, period_start DESC);
+CREATE INDEX idx_notification_deliveries_digest ON public.notification_deliveries(user_id)
+    WHERE status = 'digest';
+
+-- Enable RLS
+ALTER TABLE public.notification_digests ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Users can view own notification digests"
+    ON public.notification_digests
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+-- Add updated_at trigger
+CREATE TRIGGER update_notification_digests_updated_at
+    BEFORE UPDATE ON public.notification_digests
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- =============================================================================
+-- DIGEST DELIVERY
+-- =============================================================================
+
+-- The digest is an email: only email deliveries wait for it, webhooks are
+-- always sent as notifications are raised
+CREATE OR REPLACE FUNCTION public.queue_notification_deliveries()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO public.notification_deliveries (notification_id, user_id, channel, status)
+    SELECT NEW.id, NEW.user_id, channels.channel,
+           CASE WHEN p.delivery = 'digest' AND channels.channel = 'email' THEN 'digest' ELSE 'pending' END
+    FROM public.notification_preferences p
+    CROSS JOIN LATERAL (VALUES ('email', p.email), ('webhook', p.webhook)) AS channels(channel, enabled)
+    WHERE p.user_id = NEW.user_id
+      AND p.notification_type = NEW.type
+      AND channels.enabled;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+UPDATE public.notification_deliveries
+SET status = 'pending', next_attempt_at = NOW()
+WHERE channel = 'webhook' AND status = 'digest';
+
+-- Grant necessary permissions
+GRANT SELECT ON public.notification_digests TO authenticated;
@@ -0,0 +1,236 @@
+-- =============================================================================
+-- Personal Finance Management System - Outbound Webhooks
+-- Migration 025: Webhook subscriptions, finance events and delivery logs
+-- =============================================================================
+
+-- Create webhook subscriptions table
+-- Each subscription receives the event types it lists at url, signed with
+-- its secret. Inactive subscriptions receive no new events; deliveries
+-- already queued for them wait until they are turned back on.
+CREATE TABLE public.webhook_subscriptions (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL CHECK (url ~ '^https?://'),
+    secret TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    description TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT valid_event_types CHECK (
+        cardinality(event_types) > 0
+        AND event_types <@ ARRAY['transaction.created', 'budget.exceeded', 'goal.completed']
+    )
+);
+
+-- Create webhook events table
+-- A finance event as sent to subscribers. Events are only recorded while the
+-- user has an active subscription to their type.
+CREATE TABLE public.webhook_events (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK (event_type IN ('transaction.created', 'budget.exceeded', 'goal.completed')),
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create webhook deliveries table
+-- One row per event and subscription, sent once next_attempt_at has passed
+-- and retried with backoff on failure. Replaying a delivery queues a new one
+-- for the same event, linked by replay_of.
+CREATE TABLE public.webhook_deliveries (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES public.webhook_events(id) ON DELETE CASCADE,
+    subscription_id UUID NOT NULL REFERENCES public.webhook_subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_response_status INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    replay_of UUID REFERENCES public.webhook_deliveries(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Create webhook delivery attempts table
+-- The delivery log: one row per attempt with the response or error
+CREATE TABLE public.webhook_delivery_attempts (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    delivery_id UUID NOT NULL REFERENCES public.webhook_deliveries(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    response_status INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX idx_webhook_subscriptions_user_id ON public.webhook_subscriptions(user_id);
+CREATE INDEX idx_webhook_events_user_id ON public.webhook_events(user_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_due ON public.webhook_deliveries(next_attempt_at)
+    WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_subscription ON public.webhook_deliveries(subscription_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_event_id ON public.webhook_deliveries(event_id);
+CREATE INDEX idx_webhook_delivery_attempts_delivery_id ON public.webhook_delivery_attempts(delivery_id, attempt);
+
+-- Enable RLS
+ALTER TABLE public.webhook_subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.webhook_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.webhook_deliveries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.webhook_delivery_attempts ENABLE ROW LEVEL SECURITY;
+
+-- Subscriptions are only created and changed through the API, which
+-- validates the URL and secret; users may still read and remove their own
+CREATE POLICY "Users can view own webhook subscriptions"
+    ON public.webhook_subscriptions
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can delete own webhook subscriptions"
+    ON public.webhook_subscriptions
+    FOR DELETE
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can view own webhook events"
+    ON public.webhook_events
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can view own webhook deliveries"
+    ON public.webhook_deliveries
+    FOR SELECT
+    USING (auth.uid() = user_id);
+
+CREATE POLICY "Users can view own webhook delivery attempts"
+    ON public.webhook_delivery_attempts
+    FOR SELECT
+    USING (
+        EXISTS (
+            SELECT 1 FROM public.webhook_deliveries d
+            WHERE d.id = webhook_delivery_attempts.delivery_id
+              AND d.user_id = auth.uid()
+        )
+    );
+
+-- Add updated_at triggers
+CREATE TRIGGER update_webhook_subscriptions_updated_at
+    BEFORE UPDATE ON public.webhook_subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+CREATE TRIGGER update_webhook_deliveries_updated_at
+    BEFORE UPDATE ON public.webhook_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- =============================================================================
+-- EVENT FUNCTIONS AND TRIGGERS
+-- =============================================================================
+
+-- Records an event and queues a delivery to each of the user's active
+-- subscriptions to its type. Does nothing when there are none.
+CREATE OR REPLACE FUNCTION public.publish_webhook_event(
+    p_user_id UUID,
+    p_event_type TEXT,
+    p_data JSONB
+)
+RETURNS VOID AS $$
+DECLARE
+    new_event_id UUID;
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM public.webhook_subscriptions
+        WHERE user_id = p_user_id AND is_active AND p_event_type = ANY(event_types)
+    ) THEN
+        RETURN;
+    END IF;
+
+    INSERT INTO public.webhook_events (user_id, event_type, data)
+    VALUES (p_user_id, p_event_type, p_data)
+    RETURNING id INTO new_event_id;
+
+    INSERT INTO public.webhook_deliveries (event_id, subscription_id, user_id)
+    SELECT new_event_id, s.id, s.user_id
+    FROM public.webhook_subscriptions s
+    WHERE s.user_id = p_user_id AND s.is_active AND p_event_type = ANY(s.event_types);
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- transaction.created, for every new transaction however it was created
+CREATE OR REPLACE FUNCTION public.publish_transaction_created()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM public.publish_webhook_event(NEW.user_id, 'transaction.created', jsonb_build_object(
+        'transaction_id', NEW.id,
+        'account_id', NEW.account_id,
+        'category_id', NEW.category_id,
+        'amount', NEW.amount,
+        'transaction_type', NEW.transaction_type,
+        'description', NEW.description,
+        'transaction_date', NEW.transaction_date,
+        'transfer_id', NEW.transfer_id,
+        'created_at', NEW.created_at
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- goal.completed, when a goal is created or marked completed
+CREATE OR REPLACE FUNCTION public.publish_goal_completed()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.is_completed THEN
+        RETURN NULL;
+    END IF;
+
+    PERFORM public.publish_webhook_event(NEW.user_id, 'goal.completed', jsonb_build_object(
+        'goal_id', NEW.id,
+        'name', NEW.name,
+        'target_amount', NEW.target_amount,
+        'current_amount', NEW.current_amount,
+        'target_date', NEW.target_date,
+        'completed_at', NOW()
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- budget.exceeded, when the budget alert engine raises the alert for a
+-- budget reaching 100% or more. Alerts are deduplicated per budget period,
+-- so each budget publishes this at most once per period and threshold.
+CREATE OR REPLACE FUNCTION public.publish_budget_exceeded()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM public.publish_webhook_event(NEW.user_id, 'budget.exceeded', NEW.metadata - 'alert');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+CREATE TRIGGER publish_transaction_created_trigger
+    AFTER INSERT ON public.transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION public.publish_transaction_created();
+
+CREATE TRIGGER publish_goal_completed_trigger
+    AFTER INSERT OR UPDATE OF is_completed ON public.goals
+    FOR EACH ROW
+    WHEN (NEW.is_completed)
+    EXECUTE FUNCTION public.publish_goal_completed();
+
+CREATE TRIGGER publish_budget_exceeded_trigger
+    AFTER INSERT ON public.notifications
+    FOR EACH ROW
+    WHEN (
+        NEW.type = 'budget_alert'
+        AND NEW.metadata->>'alert' = 'threshold'
+        AND (NEW.metadata->>'threshold')::numeric >= 100
+    )
+    EXECUTE FUNCTION public.publish_budget_exceeded();
+
+-- Grant necessary permissions
+GRANT SELECT, DELETE ON public.webhook_subscriptions TO authenticated;
+GRANT SELECT ON public.webhook_events TO authenticated;
+GRANT SELECT ON public.webhook_deliveries TO authenticated;
+GRANT SELECT ON public.webhook_delivery_attempts TO authenticated;